@@ -36,11 +36,18 @@ func main() {
 		zipkinBridge   = fs.Bool("zipkin-ot-bridge", false, "Use Zipkin OpenTracing bridge instead of native implementation")
 		lightstepToken = fs.String("lightstep-token", "", "Enable LightStep tracing via a LightStep access token")
 		appdashAddr    = fs.String("appdash-addr", "", "Enable Appdash tracing via an Appdash server host:port")
-		method         = fs.String("method", "sum", "sum, concat, ping")
+		method         = fs.String("method", "sum", "sum, concat, multiply, divide, ping, factorize, jobStatus, cancelJob")
 	)
-	fs.Usage = usageFor(fs, os.Args[0]+" [flags] <a> <b>")
+	fs.Usage = usageFor(fs, os.Args[0]+" [flags] <a> [<b>]")
 	fs.Parse(os.Args[1:])
-	if len(fs.Args()) != 2 && *method != "ping" {
+	args := 2
+	switch *method {
+	case "ping":
+		args = 0
+	case "factorize", "jobStatus", "cancelJob":
+		args = 1
+	}
+	if len(fs.Args()) != args {
 		fs.Usage()
 		os.Exit(1)
 	}
@@ -118,6 +125,26 @@ func main() {
 		}
 		fmt.Fprintf(os.Stdout, "%q + %q = %q\n", a, b, v)
 
+	case "multiply":
+		a, _ := strconv.ParseInt(fs.Args()[0], 10, 64)
+		b, _ := strconv.ParseInt(fs.Args()[1], 10, 64)
+		v, err := svc.Multiply(context.Background(), int(a), int(b))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Fprintf(os.Stdout, "%d * %d = %d\n", a, b, v)
+
+	case "divide":
+		a, _ := strconv.ParseInt(fs.Args()[0], 10, 64)
+		b, _ := strconv.ParseInt(fs.Args()[1], 10, 64)
+		v, err := svc.Divide(context.Background(), int(a), int(b))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Fprintf(os.Stdout, "%d / %d = %d\n", a, b, v)
+
 	case "ping":
 		v, err := svc.Ping(context.Background())
 		if err != nil {
@@ -126,6 +153,37 @@ func main() {
 		}
 		fmt.Fprintf(os.Stdout, "ping: %v\n", v)
 
+	case "factorize":
+		n, _ := strconv.ParseInt(fs.Args()[0], 10, 64)
+		v, err := svc.Factorize(context.Background(), n)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Fprintf(os.Stdout, "factorize %d: job %s\n", n, v.ID)
+
+	case "jobStatus":
+		v, err := svc.JobStatus(context.Background(), fs.Args()[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Fprintf(os.Stdout, "job %s: %s %d/%d", v.ID, v.State, v.Done, v.Total)
+		if v.Result != nil {
+			fmt.Fprintf(os.Stdout, " result %v", v.Result)
+		}
+		if v.Error != "" {
+			fmt.Fprintf(os.Stdout, " error %s", v.Error)
+		}
+		fmt.Fprintln(os.Stdout)
+
+	case "cancelJob":
+		if err := svc.CancelJob(context.Background(), fs.Args()[0]); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Fprintf(os.Stdout, "job %s: cancelling\n", fs.Args()[0])
+
 	default:
 		fmt.Fprintf(os.Stderr, "error: invalid method %q\n", *method)
 		os.Exit(1)
@@ -1,33 +1,49 @@
 package main
 
 import (
+	"context"
+	"crypto/rand"
 	"flag"
 	"fmt"
-	"net"
+	"io"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"text/tabwriter"
+	"time"
 
+	"github.com/go-redis/redis"
 	lightstep "github.com/lightstep/lightstep-tracer-go"
-	"github.com/oklog/oklog/pkg/group"
 	stdopentracing "github.com/opentracing/opentracing-go"
 	zipkinot "github.com/openzipkin-contrib/zipkin-go-opentracing"
 	zipkin "github.com/openzipkin/zipkin-go"
 	zipkinhttp "github.com/openzipkin/zipkin-go/reporter/http"
-	stdprometheus "github.com/prometheus/client_golang/prometheus"
-	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"golang.org/x/time/rate"
 	"sourcegraph.com/sourcegraph/appdash"
 	appdashot "sourcegraph.com/sourcegraph/appdash/opentracing"
 
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/metrics"
-	"github.com/go-kit/kit/metrics/prometheus"
 
 	"ray.vhatt/todo-gokit/pkg/addendpoint"
 	"ray.vhatt/todo-gokit/pkg/addservice"
 	"ray.vhatt/todo-gokit/pkg/addtransport"
+	"ray.vhatt/todo-gokit/pkg/analytics"
+	"ray.vhatt/todo-gokit/pkg/dependency"
+	"ray.vhatt/todo-gokit/pkg/events"
+	"ray.vhatt/todo-gokit/pkg/fields"
+	"ray.vhatt/todo-gokit/pkg/jobs"
+	"ray.vhatt/todo-gokit/pkg/logging"
+	"ray.vhatt/todo-gokit/pkg/moderation"
+	"ray.vhatt/todo-gokit/pkg/notify"
+	"ray.vhatt/todo-gokit/pkg/profiling"
+	"ray.vhatt/todo-gokit/pkg/server"
+	"ray.vhatt/todo-gokit/pkg/store"
+	"ray.vhatt/todo-gokit/pkg/views"
+	"ray.vhatt/todo-gokit/pkg/webhooks"
+	"ray.vhatt/todo-gokit/pkg/workflow"
 )
 
 func main() {
@@ -36,12 +52,73 @@ func main() {
 	// on, but we do it here for demonstration purposes.
 	fs := flag.NewFlagSet("addsvc", flag.ExitOnError)
 	var (
-		debugAddr      = fs.String("debug.addr", ":8080", "Debug and metrics listen address")
-		httpAddr       = fs.String("http-addr", ":8081", "HTTP listen address")
+		debugAddr      = fs.String("debug.addr", ":8080", "Debug and metrics listen address, empty disables it")
+		httpAddr       = fs.String("http-addr", ":8081", "HTTP listen address, empty disables it")
+		shutdownWait   = fs.Duration("shutdown-timeout", 10*time.Second, "How long the listeners may drain the requests in flight on shutdown")
 		zipkinURL      = fs.String("zipkin-url", "", "Enable Zipkin tracing via HTTP reporter URL e.g. http://localhost:9411/api/v2/spans")
 		zipkinBridge   = fs.Bool("zipkin-ot-bridge", false, "Use Zipkin OpenTracing bridge instead of native implementation")
 		lightstepToken = fs.String("lightstep-token", "", "Enable LightStep tracing via a LightStep access token")
 		appdashAddr    = fs.String("appdash-addr", "", "Enable Appdash tracing via an Appdash server host:port")
+		storeURI       = fs.String("store-uri", "mongodb://localhost:27017", "Where to keep the todos, the scheme selects the backend: mongodb or mongodb+srv, sqlite:path/to/todos.db for a local database, or memory: to run without MongoDB")
+		mongoDB        = fs.String("mongo-db", "gokit-test", "Mongo database holding the todos, and the jobs, views, webhooks, schemas, workflows, dependencies and review queue")
+		mongoColl      = fs.String("mongo-collection", "todolist", "Mongo collection holding the todos")
+		slowQuery      = fs.Duration("mongo-slow-query", 0, "Log the explain plan of store operations slower than this, 0 disables it")
+		maxListSize    = fs.Int64("mongo-max-list", store.DefaultGuardrails.MaxListSize, "Largest page of todos a listing returns, longer listings are truncated with a cursor to resume them; 0 disables the cap")
+		maxRegexTerms  = fs.Int("mongo-max-regex-terms", store.DefaultGuardrails.MaxRegexTerms, "Reject regex searches over more words than this, 0 disables it")
+		regexIndex     = fs.Bool("mongo-require-regex-index", store.DefaultGuardrails.RequireRegexIndex, "Reject regex searches on unindexed fields")
+		partitions     = fs.Int("mongo-partitions", 1, "Spread todos over this many collections, queried in parallel")
+		shardParallel  = fs.Int("mongo-shard-concurrency", 0, "Query at most this many partitions at once, 0 means all")
+		shardTimeout   = fs.Duration("mongo-shard-timeout", 0, "Give up on a partition after this long, 0 disables it")
+		cursorKey      = fs.String("cursor-key", "", "Secret signing the listing cursors, shared by the replicas; empty picks a random one, invalidating cursors on restart")
+		allowPartial   = fs.Bool("mongo-allow-partial", false, "Answer listings from the healthy partitions when some fail")
+		concatMaxLen   = fs.Int("concat-max-len", addservice.DefaultConfig.MaxConcatLen, "Longest string Concat may return, in characters")
+		taskMaxLen     = fs.Int("task-max-len", addservice.DefaultConfig.MaxTaskLen, "Longest task a todo may have, in characters, 0 disables it")
+		batchMaxSize   = fs.Int("batch-max-size", addservice.DefaultConfig.MaxBatchSize, "Largest number of todos a batch may act on, 0 disables the limit")
+		twoZeroes      = fs.Bool("reject-two-zeroes", addservice.DefaultConfig.RejectTwoZeroes, "Reject sums of two zeroes")
+		intMin         = fs.Int64("int-min", addservice.DefaultConfig.IntMin, "Smallest integer result of the arithmetic methods")
+		intMax         = fs.Int64("int-max", addservice.DefaultConfig.IntMax, "Largest integer result of the arithmetic methods")
+		jobRetention   = fs.Duration("job-retention", addservice.DefaultConfig.JobRetention, "How long the status of finished jobs can be polled")
+		jobCollection  = fs.String("job-collection", "jobs", "Mongo collection persisting jobs across restarts, empty keeps them in memory as does a -store-uri other than Mongo")
+		viewCollection = fs.String("view-collection", "views", "Mongo collection persisting saved views, empty keeps them in memory as does a -store-uri other than Mongo")
+		webhookColl    = fs.String("webhook-collection", "webhooks", "Mongo collection persisting webhook subscriptions, empty keeps them in memory as does a -store-uri other than Mongo")
+		webhookNets    = fs.String("webhook-networks", "", "CIDR blocks of internal addresses webhooks may call back nonetheless, separated by commas")
+		fieldColl      = fs.String("field-collection", "field_schemas", "Mongo collection persisting the custom field schemas of the tenants, empty keeps them in memory as does a -store-uri other than Mongo")
+		workflowColl   = fs.String("workflow-collection", "workflows", "Mongo collection persisting the workflows of the tenants, empty keeps them in memory as does a -store-uri other than Mongo")
+		dependencyColl = fs.String("dependency-collection", "dependencies", "Mongo collection persisting the dependencies between the todos, empty keeps them in memory as does a -store-uri other than Mongo")
+		modPatterns    = fs.String("moderation-patterns", "", "File of regular expressions, one per line, flagging the tasks they match")
+		modAPI         = fs.String("moderation-api", "", "URL of an external moderation API screening the tasks")
+		modPolicy      = fs.String("moderation-policy", "reject", "Action on flagged tasks: the default then tenant=action overrides, separated by commas; actions are reject, review and allow")
+		analyticsSink  = fs.String("analytics", "", "Where to send product events about the todos: log, or the URL of a collector they're POSTed to; empty disables them")
+		analyticsOpt   = fs.String("analytics-opt-out", "", "Tenants whose product events aren't sent, separated by commas")
+		reminders      = fs.String("reminders", "", "Where to send the reminders of todos: log, the URL of a webhook they're POSTed to, or smtp://[user:password@]host:port?from=ADDR&to=ADDR[,ADDR] to mail them; empty disables them")
+		reminderEvery  = fs.Duration("reminder-interval", 30*time.Second, "How often to look for reminders due")
+		eventOutbox    = fs.String("event-outbox", "", "Mongo collection the events are written to in the transactions of the changes, then relayed to -events; needs a replica set. Empty publishes them after the changes")
+		relayEvery     = fs.Duration("event-relay-interval", time.Second, "How often to relay the events of the outbox")
+		eventBroker    = fs.String("events", "", "Where to publish the domain events of the todos: log, nats://[user:password@]host:port[?prefix=P&creds=FILE&tls=true&ca=FILE&cert=FILE&key=FILE] for a JetStream stream capturing their subjects, or kafka+http[s]://host:port?topic=T for a Kafka REST proxy; empty disables them")
+		reviewColl     = fs.String("review-collection", "review_queue", "Mongo collection holding the flagged todos awaiting review, empty keeps them in memory as does a -store-uri other than Mongo")
+		rateLimitRedis = fs.String("ratelimit-redis", "", "Redis address sharing the rate limits between replicas, empty keeps them per process")
+		breakerRedis   = fs.String("breaker-redis", "", "Redis address sharing open circuit breakers between replicas, empty keeps them per process")
+		deprecated     = fs.String("deprecated-routes", "", "Routes being retired, as path:since[:sunset] with dates like 2006-01-02, separated by commas")
+		deprecationDoc = fs.String("deprecation-link", "", "Documentation of the migration away from the deprecated routes")
+		metricsName    = fs.String("metrics", "prometheus", "Metrics backend: prometheus, scraped from the debug listener, or statsd or dogstatsd, pushed to -metrics-addr")
+		metricsAddr    = fs.String("metrics-addr", "localhost:8125", "UDP address of the StatsD or DogStatsD agent")
+		metricsBuckets = fs.String("metrics-buckets", "", "Upper bounds in seconds of the Prometheus duration histograms, separated by commas; empty uses buckets from 0.5ms to 10s")
+		metricsPush    = fs.Duration("metrics-interval", 10*time.Second, "How often to push metrics to the StatsD or DogStatsD agent")
+		logSink        = fs.String("log", "stderr", "Where to log: stderr, stdout, file:PATH, syslog[:TAG], syslog://HOST:PORT, tcp://HOST:PORT or udp://HOST:PORT")
+		logFormat      = fs.String("log-format", "logfmt", "Log format: logfmt or json")
+		accessLog      = fs.String("access-log", "", "File to write the access log to, - for stdout, empty disables it")
+		accessFormat   = fs.String("access-log-format", addtransport.AccessLogCombined, "Access log format: common, combined or json")
+		accessMaxSize  = fs.Int("access-log-max-size", logging.DefaultMaxSizeMB, "Rotate the access log file once it grows past this many megabytes")
+		accessBackups  = fs.Int("access-log-max-backups", logging.DefaultMaxBackups, "Rotated access log files to keep, 0 keeps them all")
+		profilingURL   = fs.String("profiling-url", "", "Pyroscope server to ship CPU and heap profiles to, empty disables continuous profiling")
+		profilingEvery = fs.Duration("profiling-interval", time.Minute, "How often to collect profiles")
+		profilingCPU   = fs.Duration("profiling-cpu", 10*time.Second, "How long to profile the CPU for, every interval")
+		trustedNets    = fs.String("trusted-networks", "", "CIDR blocks of monitoring callers bypassing rate limits and circuit breakers, separated by commas")
+		trustedToken   = fs.String("trusted-token", "", "Token the edge sets in the X-Trusted-Caller header of monitoring traffic, bypassing rate limits and circuit breakers; empty disables it")
+		deadlines      = fs.String("deadlines", "", "Time budgets of methods, as method:timeout[:reserve] separated by commas; listings answer with the todos read so far reserve before the timeout")
+		maxBodySize    = fs.Int64("max-body-size", addtransport.DefaultMaxBodySize, "Longest request body accepted, in bytes; longer ones are rejected with a 413")
+		wsOrigins      = fs.String("ws-origins", "", "Origins, like https://app.example.com, whose pages may open the WebSocket of /ws besides the service's own, separated by commas; the others are refused")
+		adminToken     = fs.String("admin-token", "", "Token allowing requests to redirect their store operations to another database or collection, and to review flagged todos, empty disables it")
 	)
 	fs.Usage = usageFor(fs, os.Args[0]+" [flags]")
 	fs.Parse(os.Args[1:])
@@ -49,7 +126,21 @@ func main() {
 	// Create a single logger, which we'll use and give to other components.
 	var logger log.Logger
 	{
-		logger = log.NewLogfmtLogger(os.Stderr)
+		sink, err := logging.Open(*logSink)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		defer sink.Close()
+		switch *logFormat {
+		case "logfmt":
+			logger = log.NewLogfmtLogger(log.NewSyncWriter(sink))
+		case "json":
+			logger = log.NewJSONLogger(log.NewSyncWriter(sink))
+		default:
+			fmt.Fprintf(os.Stderr, "error: unknown log format %q\n", *logFormat)
+			os.Exit(1)
+		}
 		logger = log.With(logger, "ts", log.DefaultTimestampUTC)
 		logger = log.With(logger, "caller", log.DefaultCaller)
 	}
@@ -100,47 +191,221 @@ func main() {
 
 	// Create the (sparse) metrics we'll use in the service. They, too, are
 	// dependencies that we pass to components that use them.
+	buckets := defaultBuckets
+	if *metricsBuckets != "" {
+		var err error
+		if buckets, err = parseBuckets(*metricsBuckets); err != nil {
+			logger.Log("during", "Metrics", "err", err)
+			os.Exit(1)
+		}
+	}
+	backend, err := newMetricsBackend(*metricsName, *metricsAddr, *metricsPush, buckets, logger)
+	if err != nil {
+		logger.Log("during", "Metrics", "err", err)
+		os.Exit(1)
+	}
 	var ints, chars metrics.Counter
-	var cubTodo, getTodo metrics.Histogram
+	var cubTodo, getTodo, timeToComplete metrics.Histogram
 	{
 		// Business-level metrics.
-		ints = prometheus.NewCounterFrom(stdprometheus.CounterOpts{
-			Namespace: "example",
-			Subsystem: "addsvc",
-			Name:      "integers_summed",
-			Help:      "Total count of integers summed via the Sum method.",
-		}, []string{})
-		chars = prometheus.NewCounterFrom(stdprometheus.CounterOpts{
-			Namespace: "example",
-			Subsystem: "addsvc",
-			Name:      "characters_concatenated",
-			Help:      "Total count of characters concatenated via the Concat method.",
-		}, []string{})
-		cubTodo = prometheus.NewSummaryFrom(stdprometheus.SummaryOpts{
-			Namespace: "example",
-			Subsystem: "addsvc",
-			Name:      "create_update_delete_todo_request_duration_seconds",
-			Help:      "Create update delete todo request duration in seconds.",
-		}, []string{"method", "error"})
-		getTodo = prometheus.NewSummaryFrom(stdprometheus.SummaryOpts{
-			Namespace: "example",
-			Subsystem: "addsvc",
-			Name:      "get_todo_request_duration_seconds",
-			Help:      "Get todo request duration in seconds.",
-		}, []string{"method", "error"})
+		ints = backend.counter("integers_summed", "Total count of integers summed via the Sum method.")
+		chars = backend.counter("characters_concatenated", "Total count of characters concatenated via the Concat method.")
+		cubTodo = backend.histogram("create_update_delete_todo_request_duration_seconds", "Create update delete todo request duration in seconds.", "method", "error")
+		getTodo = backend.histogram("get_todo_request_duration_seconds", "Get todo request duration in seconds.", "method", "error")
+		timeToComplete = backend.spanHistogram(completionBuckets, "todo_time_to_complete_seconds", "Time from the creation of todos to their completion in seconds.")
 	}
 
 	var duration metrics.Histogram
+	var cancelled, deprecatedUsed metrics.Counter
 	{
 		// Endpoint-level metrics.
-		duration = prometheus.NewSummaryFrom(stdprometheus.SummaryOpts{
-			Namespace: "example",
-			Subsystem: "addsvc",
-			Name:      "request_duration_seconds",
-			Help:      "Request duration in seconds.",
-		}, []string{"method", "success"})
-	}
-	http.DefaultServeMux.Handle("/metrics", promhttp.Handler())
+		duration = backend.histogram("request_duration_seconds", "Request duration in seconds.", "method", "success")
+		cancelled = backend.counter("requests_cancelled", "Total count of requests cancelled by the client.", "method")
+		deprecatedUsed = backend.counter("deprecated_requests", "Total count of requests to deprecated routes.", "route")
+	}
+	endpointOptions := []addendpoint.Option{addendpoint.WithProtectionMetrics(
+		backend.gauge("circuit_breaker_state", "State of the circuit breaker of each method: 0 closed, 1 half-open, 2 open.", "method"),
+		backend.counter("requests_rejected", "Total count of requests rejected by a rate limiter or a circuit breaker.", "method", "reason"),
+	)}
+
+	// Store guardrails are tuned per deployment, to protect shared database
+	// clusters. Diagnostics are opt-in, they cost an extra round trip per
+	// slow query.
+	storeOptions := []store.MongoOption{
+		store.WithGuardrails(store.Guardrails{
+			MaxListSize:       *maxListSize,
+			MaxRegexTerms:     *maxRegexTerms,
+			RequireRegexIndex: *regexIndex,
+		}),
+	}
+	if *cursorKey != "" {
+		storeOptions = append(storeOptions, store.WithCursorKey([]byte(*cursorKey)))
+	} else {
+		key := make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			logger.Log("during", "CursorKey", "err", err)
+			os.Exit(1)
+		}
+		storeOptions = append(storeOptions, store.WithCursorKey(key))
+	}
+	if *partitions > 1 {
+		logger.Log("store", "Mongo", "partitions", *partitions)
+		storeOptions = append(storeOptions, store.WithPartitions(*partitions, store.ShardOptions{
+			Concurrency:  *shardParallel,
+			Timeout:      *shardTimeout,
+			AllowPartial: *allowPartial,
+		}))
+	}
+	if *slowQuery > 0 {
+		logger.Log("store", "Mongo", "slow_query", *slowQuery)
+		storeOptions = append(storeOptions, store.WithSlowQueryExplain(*slowQuery, log.With(logger, "component", "store")))
+	}
+
+	// Business rules are tuned per deployment too.
+	serviceConfig := addservice.Config{
+		MaxConcatLen:    *concatMaxLen,
+		MaxTaskLen:      *taskMaxLen,
+		MaxBatchSize:    *batchMaxSize,
+		RejectTwoZeroes: *twoZeroes,
+		IntMin:          *intMin,
+		IntMax:          *intMax,
+		JobRetention:    *jobRetention,
+	}
+	// When the todos are kept in Mongo, the side stores share their
+	// connection to the database. Otherwise they're kept in memory too.
+	var db *store.MongoDB
+	if strings.HasPrefix(*storeURI, "mongodb") {
+		db, err = store.ConnectMongo(*storeURI, *mongoDB)
+		if err != nil {
+			logger.Log("during", "ConnectMongo", "err", err)
+			os.Exit(1)
+		}
+	}
+	if db != nil && *jobCollection != "" {
+		jobStore, err := jobs.NewMongoStore(db, *jobCollection)
+		if err != nil {
+			logger.Log("during", "NewMongoStore", "err", err)
+			os.Exit(1)
+		}
+		serviceConfig.JobStore = jobStore
+	}
+	if db != nil && *viewCollection != "" {
+		viewStore, err := views.NewMongoStore(db, *viewCollection)
+		if err != nil {
+			logger.Log("during", "NewMongoStore", "err", err)
+			os.Exit(1)
+		}
+		serviceConfig.ViewStore = viewStore
+	}
+	if db != nil && *webhookColl != "" {
+		webhookStore, err := webhooks.NewMongoStore(db, *webhookColl)
+		if err != nil {
+			logger.Log("during", "NewMongoStore", "err", err)
+			os.Exit(1)
+		}
+		serviceConfig.WebhookStore = webhookStore
+	}
+	webhookNetworks, err := addtransport.ParseNetworks(*webhookNets)
+	if err != nil {
+		logger.Log("during", "ParseNetworks", "err", err)
+		os.Exit(1)
+	}
+	serviceConfig.WebhookNetworks = webhookNetworks
+	if serviceConfig.WebhookStore == nil {
+		serviceConfig.WebhookStore = webhooks.NewMemoryStore()
+	}
+	dispatcher := webhooks.NewDispatcher(serviceConfig.WebhookStore, webhookNetworks, log.With(logger, "component", "webhooks"))
+	serviceConfig.Dispatcher = dispatcher
+	if db != nil && *fieldColl != "" {
+		fieldStore, err := fields.NewMongoStore(db, *fieldColl)
+		if err != nil {
+			logger.Log("during", "NewMongoStore", "err", err)
+			os.Exit(1)
+		}
+		serviceConfig.FieldStore = fieldStore
+	}
+	if db != nil && *workflowColl != "" {
+		workflowStore, err := workflow.NewMongoStore(db, *workflowColl)
+		if err != nil {
+			logger.Log("during", "NewMongoStore", "err", err)
+			os.Exit(1)
+		}
+		serviceConfig.WorkflowStore = workflowStore
+	}
+	if db != nil && *dependencyColl != "" {
+		dependencyStore, err := dependency.NewMongoStore(db, *dependencyColl)
+		if err != nil {
+			logger.Log("during", "NewMongoStore", "err", err)
+			os.Exit(1)
+		}
+		serviceConfig.DependencyStore = dependencyStore
+	}
+	if db != nil && *reviewColl != "" {
+		queue, err := moderation.NewMongoQueue(db, *reviewColl)
+		if err != nil {
+			logger.Log("during", "NewMongoQueue", "err", err)
+			os.Exit(1)
+		}
+		serviceConfig.ReviewQueue = queue
+	} else {
+		serviceConfig.ReviewQueue = moderation.NewMemoryQueue()
+	}
+	if *modPatterns != "" || *modAPI != "" {
+		var filters []moderation.Filter
+		if *modPatterns != "" {
+			f, err := os.Open(*modPatterns)
+			if err != nil {
+				logger.Log("during", "moderation-patterns", "err", err)
+				os.Exit(1)
+			}
+			patterns, err := moderation.ParseRegexList(f)
+			f.Close()
+			if err != nil {
+				logger.Log("during", "moderation-patterns", "err", err)
+				os.Exit(1)
+			}
+			filters = append(filters, moderation.NewRegexFilter(patterns))
+		}
+		if *modAPI != "" {
+			filters = append(filters, moderation.NewAPIFilter(*modAPI, &http.Client{Timeout: 5 * time.Second}))
+		}
+		policy, err := moderation.ParsePolicy(*modPolicy)
+		if err != nil {
+			logger.Log("during", "moderation-policy", "err", err)
+			os.Exit(1)
+		}
+		sink := moderation.Sinks(serviceConfig.ReviewQueue, moderation.NewLogSink(log.With(logger, "component", "moderation")))
+		serviceConfig.Moderator = moderation.New(moderation.Filters(filters...), policy, sink)
+	}
+	switch *analyticsSink {
+	case "":
+	case "log":
+		sink := analytics.NewLogSink(log.With(logger, "component", "analytics"))
+		serviceConfig.Analytics = analytics.NewTracker(sink, analytics.ParseOptOut(*analyticsOpt)...)
+	default:
+		sink := analytics.NewHTTPSink(*analyticsSink, &http.Client{Timeout: 2 * time.Second})
+		serviceConfig.Analytics = analytics.NewTracker(sink, analytics.ParseOptOut(*analyticsOpt)...)
+	}
+	var publisher events.Publisher
+	if *eventBroker != "" {
+		publisher, err = events.Open(*eventBroker, log.With(logger, "component", "events"))
+		if err != nil {
+			logger.Log("during", "events", "err", err)
+			os.Exit(1)
+		}
+	}
+	if *eventOutbox != "" {
+		// The store writes the events in the transactions of the changes,
+		// given those of the transitions and of the unblocked todos by the
+		// service, and the relay publishes them.
+		if publisher == nil || !strings.HasPrefix(*storeURI, "mongodb") {
+			logger.Log("during", "event-outbox", "err", "the outbox needs -events and a Mongo store")
+			os.Exit(1)
+		}
+		storeOptions = append(storeOptions, store.WithOutbox(*eventOutbox))
+	} else {
+		serviceConfig.Events = publisher
+	}
 
 	// Build the layers of the service "onion" from the inside out. First, the
 	// business logic service; then, the set of endpoints that wrap the service;
@@ -148,53 +413,191 @@ func main() {
 	// the HTTP handler or the gRPC server, are the bridge between Go kit and
 	// the interfaces that the transports expect. Note that we're not binding
 	// them to ports or anything yet; we'll do that next.
+	dbStore, err := store.Open(store.Config{
+		URI:          *storeURI,
+		Database:     *mongoDB,
+		Collection:   *mongoColl,
+		MongoOptions: storeOptions,
+		Mongo:        db,
+	})
+	if err != nil {
+		logger.Log("during", "store.Open", "err", err)
+		os.Exit(1)
+	}
+	service, err := addservice.New(dbStore, logger, ints, chars, cubTodo, getTodo, timeToComplete, serviceConfig)
+	if err != nil {
+		logger.Log("during", "NewService", "err", err)
+		os.Exit(1)
+	}
+	redisClients := make(map[string]*redis.Client)
+	redisClient := func(addr string) *redis.Client {
+		if client, ok := redisClients[addr]; ok {
+			return client
+		}
+		client := redis.NewClient(&redis.Options{Addr: addr})
+		if err := client.Ping().Err(); err != nil {
+			logger.Log("during", "Redis", "err", err)
+			os.Exit(1)
+		}
+		redisClients[addr] = client
+		return client
+	}
+	if *rateLimitRedis != "" {
+		client := redisClient(*rateLimitRedis)
+		limiterLogger := log.With(logger, "component", "ratelimit")
+		endpointOptions = append(endpointOptions, addendpoint.WithLimiters(func(method string, r rate.Limit, b int) addendpoint.Limiter {
+			return addendpoint.NewRedisLimiter(client, "ratelimit:"+method, r, b, limiterLogger)
+		}))
+	}
+	if *breakerRedis != "" {
+		bus := addendpoint.NewRedisBreakerBus(redisClient(*breakerRedis), "breakers", log.With(logger, "component", "breakers"))
+		endpointOptions = append(endpointOptions, addendpoint.WithBreakerBus(bus))
+	}
+	methodDeadlines, err := addendpoint.ParseDeadlines(*deadlines)
+	if err != nil {
+		logger.Log("during", "ParseDeadlines", "err", err)
+		os.Exit(1)
+	}
+	endpointOptions = append(endpointOptions, addendpoint.WithDeadlines(methodDeadlines))
+	trustedNetworks, err := addtransport.ParseNetworks(*trustedNets)
+	if err != nil {
+		logger.Log("during", "ParseNetworks", "err", err)
+		os.Exit(1)
+	}
+	webSocketOrigins, err := addtransport.ParseOrigins(*wsOrigins)
+	if err != nil {
+		logger.Log("during", "ParseOrigins", "err", err)
+		os.Exit(1)
+	}
+	deprecatedRoutes, err := addtransport.ParseDeprecations(*deprecated, *deprecationDoc)
+	if err != nil {
+		logger.Log("during", "ParseDeprecations", "err", err)
+		os.Exit(1)
+	}
 	var (
-		service     = addservice.New(logger, ints, chars, cubTodo, getTodo)
-		endpoints   = addendpoint.New(service, logger, duration, tracer, zipkinTracer)
-		httpHandler = addtransport.NewHTTPHandler(endpoints, tracer, zipkinTracer, logger)
+		endpoints   = addendpoint.New(service, logger, duration, cancelled, tracer, zipkinTracer, endpointOptions...)
+		httpHandler = addtransport.StoreTargetOverride(*adminToken, addtransport.ModerationAdmin(*adminToken, addtransport.NewHTTPHandler(endpoints, tracer, zipkinTracer, logger)))
 	)
+	httpHandler = addtransport.MaxBodySize(*maxBodySize, httpHandler)
+	httpHandler = addtransport.WebSocketOrigins(webSocketOrigins, httpHandler)
+	if len(trustedNetworks) > 0 || *trustedToken != "" {
+		httpHandler = addtransport.TrustedCallers(trustedNetworks, *trustedToken, httpHandler)
+	}
+	httpHandler = addtransport.Deprecated(deprecatedRoutes, deprecatedUsed, log.With(logger, "component", "deprecation"), httpHandler)
+	if *accessLog != "" {
+		var sink io.Writer = os.Stdout
+		if *accessLog != "-" {
+			file := logging.RotatingFile(*accessLog, *accessMaxSize, *accessBackups)
+			defer file.Close()
+			sink = file
+		}
+		httpHandler, err = addtransport.AccessLog(sink, *accessFormat, httpHandler)
+		if err != nil {
+			logger.Log("during", "AccessLog", "err", err)
+			os.Exit(1)
+		}
+	}
 
 	// Now we're to the part of the func main where we want to start actually
 	// running things, like servers bound to listeners to receive connections.
 	//
-	// The method is the same for each component: add a new actor to the group
-	// struct, which is a combination of 2 anonymous functions: the first
-	// function actually runs the component, and the second function should
-	// interrupt the first function and cause it to return. It's in these
-	// functions that we actually bind the Go kit server/handler structs to the
-	// concrete transports and run them.
+	// The transports are bound to their listeners by the bootstrap, which
+	// drains them in order on shutdown. The other components are actors: a
+	// combination of 2 anonymous functions, the first function actually runs
+	// the component, and the second function should interrupt the first
+	// function and cause it to return.
 	//
 	// Putting each component into its own block is mostly for aesthetics: it
 	// clearly demarcates the scope in which each listener/socket may be used.
-	var g group.Group
+	g := server.New(*shutdownWait, logger)
 	{
 		// The debug listener mounts the http.DefaultServeMux, and serves up
 		// stuff like the Prometheus metrics route, the Go debug and profiling
-		// routes, and so on.
-		debugListener, err := net.Listen("tcp", *debugAddr)
-		if err != nil {
+		// routes, and so on. It's registered first, to be shut down last.
+		if err := g.Listen("debug/HTTP", *debugAddr, server.HTTP(http.DefaultServeMux)); err != nil {
 			logger.Log("transport", "debug/HTTP", "during", "Listen", "err", err)
 			os.Exit(1)
 		}
+	}
+	{
+		// The HTTP listener mounts the Go kit HTTP handler we created.
+		if err := g.Listen("HTTP", *httpAddr, server.HTTP(httpHandler)); err != nil {
+			logger.Log("transport", "HTTP", "during", "Listen", "err", err)
+			os.Exit(1)
+		}
+	}
+	if *profilingURL != "" {
+		// The continuous profiler ships profiles until the group stops.
+		uploader, err := profiling.NewPyroscopeUploader(*profilingURL, "addsvc", nil)
+		if err != nil {
+			logger.Log("during", "Profiling", "err", err)
+			os.Exit(1)
+		}
+		profiler, err := profiling.New(uploader, *profilingEvery, *profilingCPU, log.With(logger, "component", "profiling"))
+		if err != nil {
+			logger.Log("during", "Profiling", "err", err)
+			os.Exit(1)
+		}
+		ctx, cancel := context.WithCancel(context.Background())
 		g.Add(func() error {
-			logger.Log("transport", "debug/HTTP", "addr", *debugAddr)
-			return http.Serve(debugListener, http.DefaultServeMux)
+			logger.Log("profiling", "Pyroscope", "URL", *profilingURL, "interval", *profilingEvery)
+			profiler.Run(ctx)
+			return nil
 		}, func(error) {
-			debugListener.Close()
+			cancel()
 		})
 	}
-	{
-		// The HTTP listener mounts the Go kit HTTP handler we created.
-		httpListener, err := net.Listen("tcp", *httpAddr)
+	if *reminders != "" {
+		// The notifier sends the reminders as they fall due, until the group
+		// stops.
+		sink, err := notify.Open(*reminders, log.With(logger, "component", "reminders"))
 		if err != nil {
-			logger.Log("transport", "HTTP", "during", "Listen", "err", err)
+			logger.Log("during", "Reminders", "err", err)
 			os.Exit(1)
 		}
+		notifier := notify.New(dbStore, sink, *reminderEvery, log.With(logger, "component", "reminders"))
+		ctx, cancel := context.WithCancel(context.Background())
 		g.Add(func() error {
-			logger.Log("transport", "HTTP", "addr", *httpAddr)
-			return http.Serve(httpListener, httpHandler)
+			logger.Log("reminders", "on", "interval", *reminderEvery)
+			notifier.Run(ctx)
+			return nil
+		}, func(error) {
+			cancel()
+		})
+	}
+	if *eventOutbox != "" {
+		// The relay publishes the events of the outbox, until the group
+		// stops.
+		outbox, err := store.NewMongoOutbox(db, *eventOutbox)
+		if err != nil {
+			logger.Log("during", "NewMongoOutbox", "err", err)
+			os.Exit(1)
+		}
+		relay := events.NewRelay(outbox, publisher, *relayEvery, log.With(logger, "component", "events"))
+		ctx, cancel := context.WithCancel(context.Background())
+		g.Add(func() error {
+			logger.Log("events", "outbox", "collection", *eventOutbox, "interval", *relayEvery)
+			relay.Run(ctx)
+			return nil
+		}, func(error) {
+			cancel()
+		})
+	}
+	{
+		// The dispatcher delivers the webhook callbacks in flight once the
+		// transports have drained their requests, or cancels them after
+		// -shutdown-timeout.
+		ctx, cancel := context.WithCancel(context.Background())
+		g.Add(func() error {
+			<-ctx.Done()
+			closeCtx, cancelClose := context.WithTimeout(context.Background(), *shutdownWait)
+			defer cancelClose()
+			if err := dispatcher.Close(closeCtx); err != nil {
+				logger.Log("component", "webhooks", "during", "Close", "err", err)
+			}
+			return nil
 		}, func(error) {
-			httpListener.Close()
+			cancel()
 		})
 	}
 	{
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	stdprometheus "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/metrics"
+	"github.com/go-kit/kit/metrics/dogstatsd"
+	"github.com/go-kit/kit/metrics/prometheus"
+	"github.com/go-kit/kit/metrics/statsd"
+)
+
+// metricsBackend builds the metrics of the service in the backend chosen by
+// the -metrics flag. Histograms observe seconds. spanHistogram builds the
+// histograms of spans longer than requests, with its own buckets.
+type metricsBackend struct {
+	counter       func(name, help string, labels ...string) metrics.Counter
+	gauge         func(name, help string, labels ...string) metrics.Gauge
+	histogram     func(name, help string, labels ...string) metrics.Histogram
+	spanHistogram func(buckets []float64, name, help string, labels ...string) metrics.Histogram
+}
+
+// defaultBuckets are the upper bounds, in seconds, of the Prometheus
+// histograms. Most requests take a few milliseconds, so the buckets are
+// finer there than the library's defaults.
+var defaultBuckets = []float64{.0005, .001, .002, .003, .005, .0075, .01, .015, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// completionBuckets are the upper bounds, in seconds, of the time todos
+// take to complete: from a minute to a month.
+var completionBuckets = []float64{60, 300, 900, 3600, 4 * 3600, 12 * 3600, 86400, 3 * 86400, 7 * 86400, 14 * 86400, 30 * 86400}
+
+// parseBuckets parses histogram bounds separated by commas, in increasing
+// order.
+func parseBuckets(s string) ([]float64, error) {
+	var buckets []float64
+	for _, item := range strings.Split(s, ",") {
+		bound, err := strconv.ParseFloat(strings.TrimSpace(item), 64)
+		if err != nil {
+			return nil, fmt.Errorf("histogram bucket %q: %v", item, err)
+		}
+		if n := len(buckets); n > 0 && bound <= buckets[n-1] {
+			return nil, fmt.Errorf("histogram buckets %q aren't increasing", s)
+		}
+		buckets = append(buckets, bound)
+	}
+	return buckets, nil
+}
+
+// newMetricsBackend returns the backend called name. Prometheus is scraped
+// from /metrics on the debug listener, its histograms bounded by buckets.
+// StatsD and DogStatsD are pushed over UDP to the agent at addr every
+// interval, and leave the distribution to the agent. StatsD has no labels,
+// so its metrics aggregate over them.
+func newMetricsBackend(name, addr string, interval time.Duration, buckets []float64, logger log.Logger) (metricsBackend, error) {
+	const prefix = "example.addsvc."
+	switch name {
+	case "prometheus":
+		http.DefaultServeMux.Handle("/metrics", promhttp.Handler())
+		histogram := func(buckets []float64, name, help string, labels ...string) metrics.Histogram {
+			return prometheus.NewHistogramFrom(stdprometheus.HistogramOpts{
+				Namespace: "example",
+				Subsystem: "addsvc",
+				Name:      name,
+				Help:      help,
+				Buckets:   buckets,
+			}, labels)
+		}
+		return metricsBackend{
+			counter: func(name, help string, labels ...string) metrics.Counter {
+				return prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+					Namespace: "example",
+					Subsystem: "addsvc",
+					Name:      name,
+					Help:      help,
+				}, labels)
+			},
+			gauge: func(name, help string, labels ...string) metrics.Gauge {
+				return prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+					Namespace: "example",
+					Subsystem: "addsvc",
+					Name:      name,
+					Help:      help,
+				}, labels)
+			},
+			histogram: func(name, help string, labels ...string) metrics.Histogram {
+				return histogram(buckets, name, help, labels...)
+			},
+			spanHistogram: histogram,
+		}, nil
+
+	case "statsd":
+		s := statsd.New(prefix, logger)
+		go s.SendLoop(context.Background(), time.Tick(interval), "udp", addr)
+		return metricsBackend{
+			counter: func(name, _ string, _ ...string) metrics.Counter {
+				return s.NewCounter(name, 1)
+			},
+			gauge: func(name, _ string, _ ...string) metrics.Gauge {
+				return s.NewGauge(name)
+			},
+			histogram: func(name, _ string, _ ...string) metrics.Histogram {
+				return milliseconds{s.NewTiming(name, 1)}
+			},
+			spanHistogram: func(_ []float64, name, _ string, _ ...string) metrics.Histogram {
+				return milliseconds{s.NewTiming(name, 1)}
+			},
+		}, nil
+
+	case "dogstatsd":
+		d := dogstatsd.New(prefix, logger)
+		go d.SendLoop(context.Background(), time.Tick(interval), "udp", addr)
+		return metricsBackend{
+			counter: func(name, _ string, _ ...string) metrics.Counter {
+				return d.NewCounter(name, 1)
+			},
+			gauge: func(name, _ string, _ ...string) metrics.Gauge {
+				return d.NewGauge(name)
+			},
+			histogram: func(name, _ string, _ ...string) metrics.Histogram {
+				return d.NewHistogram(name, 1)
+			},
+			spanHistogram: func(_ []float64, name, _ string, _ ...string) metrics.Histogram {
+				return d.NewHistogram(name, 1)
+			},
+		}, nil
+	}
+	return metricsBackend{}, fmt.Errorf("unknown metrics backend %q", name)
+}
+
+// milliseconds adapts a StatsD timing, which takes milliseconds, to
+// observations in seconds.
+type milliseconds struct{ metrics.Histogram }
+
+func (h milliseconds) With(labelValues ...string) metrics.Histogram {
+	return milliseconds{h.Histogram.With(labelValues...)}
+}
+
+func (h milliseconds) Observe(seconds float64) { h.Histogram.Observe(seconds * 1000) }
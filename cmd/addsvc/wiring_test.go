@@ -16,12 +16,16 @@ import (
 	"ray.vhatt/todo-gokit/pkg/addendpoint"
 	"ray.vhatt/todo-gokit/pkg/addservice"
 	"ray.vhatt/todo-gokit/pkg/addtransport"
+	"ray.vhatt/todo-gokit/pkg/store"
 )
 
 func TestHTTP(t *testing.T) {
 	zkt, _ := zipkin.NewTracer(nil, zipkin.WithNoopTracer(true))
-	svc := addservice.New(log.NewNopLogger(), discard.NewCounter(), discard.NewCounter())
-	eps := addendpoint.New(svc, log.NewNopLogger(), discard.NewHistogram(), opentracing.GlobalTracer(), zkt)
+	svc, err := addservice.New(store.NewInMemoryStore(), log.NewNopLogger(), discard.NewCounter(), discard.NewCounter(), discard.NewHistogram(), discard.NewHistogram(), discard.NewHistogram(), addservice.DefaultConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	eps := addendpoint.New(svc, log.NewNopLogger(), discard.NewHistogram(), discard.NewCounter(), opentracing.GlobalTracer(), zkt)
 	mux := addtransport.NewHTTPHandler(eps, opentracing.GlobalTracer(), zkt, log.NewNopLogger())
 	srv := httptest.NewServer(mux)
 	defer srv.Close()
@@ -29,14 +33,23 @@ func TestHTTP(t *testing.T) {
 	for _, testcase := range []struct {
 		method, url, body, want string
 	}{
-		{"GET", srv.URL + "/concat", `{"a":"1","b":"2"}`, `{"v":"12"}`},
-		{"GET", srv.URL + "/sum", `{"a":1,"b":2}`, `{"v":3}`},
+		{"POST", srv.URL + "/concat", `{"a":"1","b":"2"}`, `{"data":{"v":"12"},"meta":{"requestId":"wiring"}}`},
+		{"POST", srv.URL + "/sum", `{"a":1,"b":2}`, `{"data":{"v":3},"meta":{"requestId":"wiring"}}`},
+		{"POST", srv.URL + "/addToDo", `{"task":"water the plants"}`, `{"data":{"taskID":"000000000000000000000001"},"meta":{"requestId":"wiring"}}`},
+		{"GET", srv.URL + "/getToDoByID?taskID=000000000000000000000001", ``, `{"data":{"todo":{"_id":"000000000000000000000001","task":"water the plants","status":false,"state":"open","priority":"normal"}},"meta":{"requestId":"wiring"}}`},
 	} {
 		req, _ := http.NewRequest(testcase.method, testcase.url, strings.NewReader(testcase.body))
+		req.Header.Set("X-Request-ID", "wiring")
+		if testcase.body != "" {
+			req.Header.Set("Content-Type", "application/json")
+		}
 		resp, _ := http.DefaultClient.Do(req)
 		body, _ := ioutil.ReadAll(resp.Body)
 		if want, have := testcase.want, strings.TrimSpace(string(body)); want != have {
 			t.Errorf("%s %s %s: want %q, have %q", testcase.method, testcase.url, testcase.body, want, have)
 		}
+		if resp.Header.Get("X-RateLimit-Remaining") == "" {
+			t.Errorf("%s %s: missing rate limit headers", testcase.method, testcase.url)
+		}
 	}
 }
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"ray.vhatt/todo-gokit/pkg/fixtures"
+	"ray.vhatt/todo-gokit/pkg/store"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(1)
+	}
+
+	switch os.Args[1] {
+	case "seed":
+		seed(os.Args[2:])
+
+	default:
+		fmt.Fprintf(os.Stderr, "error: invalid command %q\n", os.Args[1])
+		usage()
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "USAGE\n")
+	fmt.Fprintf(os.Stderr, "  %s <command> [flags]\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "\n")
+	fmt.Fprintf(os.Stderr, "COMMANDS\n")
+	fmt.Fprintf(os.Stderr, "  seed  Populate a store with synthetic todos\n")
+	fmt.Fprintf(os.Stderr, "\n")
+}
+
+// seed populates a Mongo store with synthetic todos.
+func seed(args []string) {
+	fs := flag.NewFlagSet("seed", flag.ExitOnError)
+	var (
+		mongoURI   = fs.String("mongo-uri", "mongodb://localhost:27017", "MongoDB connection string")
+		database   = fs.String("mongo-db", "gokit-test", "Database to seed")
+		collection = fs.String("mongo-collection", "todolist", "Collection to seed")
+		partitions = fs.Int("mongo-partitions", 1, "Spread todos over this many collections, as addsvc does")
+		count      = fs.Int("count", 1000, "Number of todos to insert")
+		completed  = fs.Float64("completed", 0.3, "Fraction of the todos marked done")
+		randSeed   = fs.Int64("seed", time.Now().UnixNano(), "Random seed, reuse it to insert the same todos again")
+	)
+	fs.Usage = usageFor(fs, os.Args[0]+" seed [flags]")
+	fs.Parse(args)
+
+	var opts []store.MongoOption
+	if *partitions > 1 {
+		opts = append(opts, store.WithPartitions(*partitions, store.ShardOptions{}))
+	}
+	s, err := store.Open(store.Config{URI: *mongoURI, Database: *database, Collection: *collection, MongoOptions: opts})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+
+	begin := time.Now()
+	n, err := fixtures.Load(context.Background(), s, fixtures.Options{
+		Count:     *count,
+		Completed: *completed,
+		Seed:      *randSeed,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error after %d todos: %v\n", n, err)
+		os.Exit(1)
+	}
+	fmt.Fprintf(os.Stdout, "inserted %d todos in %s (seed %d)\n", n, time.Since(begin), *randSeed)
+}
+
+func usageFor(fs *flag.FlagSet, short string) func() {
+	return func() {
+		fmt.Fprintf(os.Stderr, "USAGE\n")
+		fmt.Fprintf(os.Stderr, "  %s\n", short)
+		fmt.Fprintf(os.Stderr, "\n")
+		fmt.Fprintf(os.Stderr, "FLAGS\n")
+		w := tabwriter.NewWriter(os.Stderr, 0, 2, 2, ' ', 0)
+		fs.VisitAll(func(f *flag.Flag) {
+			fmt.Fprintf(w, "\t-%s %s\t%s\n", f.Name, f.DefValue, f.Usage)
+		})
+		w.Flush()
+		fmt.Fprintf(os.Stderr, "\n")
+	}
+}
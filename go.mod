@@ -5,8 +5,14 @@ go 1.13
 require (
 	github.com/apache/thrift v0.13.0
 	github.com/go-kit/kit v0.10.0
+	github.com/go-redis/redis v6.15.9+incompatible
+	github.com/gorilla/websocket v1.4.2
+	github.com/json-iterator/go v1.1.12
 	github.com/lightstep/lightstep-tracer-go v0.18.1
+	github.com/mattn/go-sqlite3 v1.14.6
 	github.com/modern-go/concurrent v0.0.0-20180306012644-bacd9c7ef1dd // indirect
+	github.com/modern-go/reflect2 v1.0.2 // indirect
+	github.com/nats-io/nats.go v1.10.0
 	github.com/oklog/oklog v0.3.2
 	github.com/opentracing/opentracing-go v1.1.0
 	github.com/openzipkin-contrib/zipkin-go-opentracing v0.4.5
@@ -16,7 +22,9 @@ require (
 	github.com/sony/gobreaker v0.4.1
 	github.com/spf13/pflag v1.0.3 // indirect
 	go.mongodb.org/mongo-driver v1.3.0
+	golang.org/x/text v0.3.2
 	golang.org/x/time v0.0.0-20191024005414-555d28b269f0
 	google.golang.org/grpc v1.26.0
+	gopkg.in/natefinch/lumberjack.v2 v2.2.1
 	sourcegraph.com/sourcegraph/appdash v0.0.0-20190731080439-ebfcffb1b5c0
 )
@@ -0,0 +1,161 @@
+package addendpoint
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/circuitbreaker"
+	"github.com/go-kit/kit/endpoint"
+	"github.com/go-kit/kit/metrics"
+	"github.com/go-kit/kit/ratelimit"
+	"github.com/sony/gobreaker"
+)
+
+// peerOpenTimeout is how long a breaker opened by another replica keeps
+// rejecting requests here without news of it closing, the default Timeout
+// of gobreaker's open state.
+const peerOpenTimeout = 60 * time.Second
+
+// BreakerBus carries the state changes of the circuit breakers between the
+// replicas of the service, so that a replica seeing a downstream fail spares
+// the others from hammering it too.
+type BreakerBus interface {
+	// Publish tells the other replicas that the breaker of method changed to
+	// state.
+	Publish(method string, state gobreaker.State)
+	// Subscribe calls fn with the changes published by the other replicas.
+	Subscribe(fn func(method string, state gobreaker.State))
+}
+
+// WithBreakerBus makes New publish the state changes of the circuit breakers
+// on bus, and open the breaker of a method while another replica reports its
+// own open, until it reports it closing or half-open, or peerOpenTimeout
+// passes.
+func WithBreakerBus(bus BreakerBus) Option {
+	return func(o *options) {
+		o.breakerBus = bus
+	}
+}
+
+// breaker returns the circuit breaker of method. It also counts the requests
+// the rate limiter before it, or the breaker, rejects. Trusted callers
+// bypass it.
+func (o options) breaker(method string) endpoint.Middleware {
+	settings := gobreaker.Settings{Name: method}
+	var state metrics.Gauge
+	if o.breakerState != nil {
+		state = o.breakerState.With("method", method)
+		state.Set(float64(gobreaker.StateClosed))
+	}
+	settings.OnStateChange = func(_ string, _, to gobreaker.State) {
+		if state != nil {
+			state.Set(float64(to))
+		}
+		if o.breakerBus != nil {
+			o.breakerBus.Publish(method, to)
+		}
+	}
+	breaker := circuitbreaker.Gobreaker(gobreaker.NewCircuitBreaker(settings))
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		protected := ignoreCancelled(breaker, next)
+		if o.breakerBus != nil {
+			protected = o.peers.middleware(method)(protected)
+		}
+		if o.rejected != nil {
+			protected = countRejections(o.rejected.With("method", method))(protected)
+		}
+		return bypassForTrusted(protected, next)
+	}
+}
+
+// cancelledInvocation carries the error of an invocation the client
+// cancelled through a circuit breaker, which would count it as a failure
+// otherwise.
+type cancelledInvocation struct{ err error }
+
+// ignoreCancelled wraps next in breaker, hiding from it the invocations the
+// client cancelled: a client going away says nothing of the health of the
+// endpoint, and must not trip the breaker for everyone else.
+func ignoreCancelled(breaker endpoint.Middleware, next endpoint.Endpoint) endpoint.Endpoint {
+	protected := breaker(func(ctx context.Context, request interface{}) (interface{}, error) {
+		response, err := next(ctx, request)
+		if err != nil && ctx.Err() == context.Canceled {
+			return cancelledInvocation{err}, nil
+		}
+		return response, err
+	})
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		response, err := protected(ctx, request)
+		if c, ok := response.(cancelledInvocation); ok {
+			return nil, c.err
+		}
+		return response, err
+	}
+}
+
+// countRejections counts the requests rejected by a rate limiter, labelled
+// with reason ratelimit, or by a circuit breaker, labelled with reason
+// breaker.
+func countRejections(rejected metrics.Counter) endpoint.Middleware {
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(ctx context.Context, request interface{}) (interface{}, error) {
+			response, err := next(ctx, request)
+			switch err {
+			case ratelimit.ErrLimited:
+				rejected.With("reason", "ratelimit").Add(1)
+			case gobreaker.ErrOpenState, gobreaker.ErrTooManyRequests:
+				rejected.With("reason", "breaker").Add(1)
+			}
+			return response, err
+		}
+	}
+}
+
+// peerBreakers tracks the breakers the other replicas report open.
+type peerBreakers struct {
+	mtx  sync.Mutex
+	open map[string]time.Time // method → when to stop trusting the report
+}
+
+func newPeerBreakers(bus BreakerBus) *peerBreakers {
+	p := &peerBreakers{open: make(map[string]time.Time)}
+	if bus != nil {
+		bus.Subscribe(p.update)
+	}
+	return p
+}
+
+func (p *peerBreakers) update(method string, state gobreaker.State) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	if state == gobreaker.StateOpen {
+		p.open[method] = time.Now().Add(peerOpenTimeout)
+		return
+	}
+	delete(p.open, method)
+}
+
+func (p *peerBreakers) isOpen(method string) bool {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	until, ok := p.open[method]
+	if ok && time.Now().After(until) {
+		delete(p.open, method)
+		return false
+	}
+	return ok
+}
+
+// middleware rejects the requests to method with gobreaker.ErrOpenState
+// while another replica reports its breaker open.
+func (p *peerBreakers) middleware(method string) endpoint.Middleware {
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(ctx context.Context, request interface{}) (interface{}, error) {
+			if p.isOpen(method) {
+				return nil, gobreaker.ErrOpenState
+			}
+			return next(ctx, request)
+		}
+	}
+}
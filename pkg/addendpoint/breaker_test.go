@@ -0,0 +1,180 @@
+package addendpoint
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/go-kit/kit/metrics"
+	"github.com/go-kit/kit/ratelimit"
+	"github.com/sony/gobreaker"
+)
+
+// memoryBus connects replicas in the same process.
+type memoryBus struct {
+	mtx         sync.Mutex
+	subscribers []func(method string, state gobreaker.State)
+}
+
+// replica returns the bus as seen by one replica, which doesn't hear its own
+// changes.
+func (b *memoryBus) replica() BreakerBus { return &memoryReplica{bus: b, self: -1} }
+
+type memoryReplica struct {
+	bus  *memoryBus
+	self int
+}
+
+func (r *memoryReplica) Publish(method string, state gobreaker.State) {
+	r.bus.mtx.Lock()
+	subscribers := r.bus.subscribers
+	r.bus.mtx.Unlock()
+	for i, fn := range subscribers {
+		if i != r.self {
+			fn(method, state)
+		}
+	}
+}
+
+func (r *memoryReplica) Subscribe(fn func(method string, state gobreaker.State)) {
+	r.bus.mtx.Lock()
+	defer r.bus.mtx.Unlock()
+	r.self = len(r.bus.subscribers)
+	r.bus.subscribers = append(r.bus.subscribers, fn)
+}
+
+func TestSharedBreakers(t *testing.T) {
+	bus := &memoryBus{}
+	newOptions := func() options {
+		o := options{breakerBus: bus.replica()}
+		o.peers = newPeerBreakers(o.breakerBus)
+		return o
+	}
+	a, b := newOptions(), newOptions()
+
+	down := errors.New("mongo is down")
+	var calls int
+	failing := a.breaker("AddToDo")(func(context.Context, interface{}) (interface{}, error) { return nil, down })
+	working := b.breaker("AddToDo")(func(context.Context, interface{}) (interface{}, error) { calls++; return nil, nil })
+	other := b.breaker("Ping")(func(context.Context, interface{}) (interface{}, error) { return nil, nil })
+
+	// gobreaker trips after more than 5 consecutive failures.
+	for i := 0; i < 6; i++ {
+		if _, err := failing(context.Background(), nil); err != down {
+			t.Fatalf("call %d: want %v, have %v", i, down, err)
+		}
+	}
+	if _, err := failing(context.Background(), nil); err != gobreaker.ErrOpenState {
+		t.Fatalf("want the local breaker open, have %v", err)
+	}
+	if _, err := working(context.Background(), nil); err != gobreaker.ErrOpenState || calls != 0 {
+		t.Errorf("want the peer breaker open, have %v after %d calls", err, calls)
+	}
+	if _, err := other(context.Background(), nil); err != nil {
+		t.Errorf("want other methods unaffected, have %v", err)
+	}
+
+	a.breakerBus.Publish("AddToDo", gobreaker.StateHalfOpen)
+	if _, err := working(context.Background(), nil); err != nil || calls != 1 {
+		t.Errorf("want the peer breaker closed once the downstream is retried, have %v after %d calls", err, calls)
+	}
+}
+
+func TestProtectionMetrics(t *testing.T) {
+	state := &recordingGauge{values: make(map[string]float64)}
+	rejected := &recordingCounter{counts: make(map[string]float64)}
+	o := options{breakerState: state, rejected: rejected}
+	limited := o.breaker("Sum")(func(context.Context, interface{}) (interface{}, error) { return nil, ratelimit.ErrLimited })
+
+	if want, have := float64(gobreaker.StateClosed), state.values["method=Sum"]; want != have {
+		t.Errorf("want the breaker reported closed, have %v", have)
+	}
+	for i := 0; i < 7; i++ {
+		limited(context.Background(), nil)
+	}
+	if want, have := float64(gobreaker.StateOpen), state.values["method=Sum"]; want != have {
+		t.Errorf("want the breaker reported open, have %v", have)
+	}
+	want := map[string]float64{"method=Sum,reason=ratelimit": 6, "method=Sum,reason=breaker": 1}
+	if !reflect.DeepEqual(want, rejected.counts) {
+		t.Errorf("want rejections %v, have %v", want, rejected.counts)
+	}
+}
+
+func TestTrustedCallerBypass(t *testing.T) {
+	rejected := &recordingCounter{counts: make(map[string]float64)}
+	o := options{rejected: rejected}
+	down := errors.New("mongo is down")
+	e := limit(NewLimiter(0, 0))(func(context.Context, interface{}) (interface{}, error) { return nil, down })
+	e = o.breaker("Ping")(e)
+
+	trusted := WithTrustedCaller(context.Background())
+	for i := 0; i < 10; i++ {
+		if _, err := e(trusted, nil); err != down {
+			t.Fatalf("call %d: want %v, have %v", i, down, err)
+		}
+	}
+	if _, err := e(context.Background(), nil); err != ratelimit.ErrLimited {
+		t.Errorf("want others limited, have %v", err)
+	}
+	if want := map[string]float64{"method=Ping,reason=ratelimit": 1}; !reflect.DeepEqual(want, rejected.counts) {
+		t.Errorf("want rejections %v, have %v", want, rejected.counts)
+	}
+}
+
+// recordingGauge and recordingCounter record the values of each combination
+// of labels.
+type recordingGauge struct {
+	labels string
+	values map[string]float64
+}
+
+func (g *recordingGauge) With(labelValues ...string) metrics.Gauge {
+	return &recordingGauge{labels: joinLabels(g.labels, labelValues), values: g.values}
+}
+
+func (g *recordingGauge) Set(value float64) { g.values[g.labels] = value }
+
+func (g *recordingGauge) Add(delta float64) { g.values[g.labels] += delta }
+
+type recordingCounter struct {
+	labels string
+	counts map[string]float64
+}
+
+func (c *recordingCounter) With(labelValues ...string) metrics.Counter {
+	return &recordingCounter{labels: joinLabels(c.labels, labelValues), counts: c.counts}
+}
+
+func (c *recordingCounter) Add(delta float64) { c.counts[c.labels] += delta }
+
+func joinLabels(labels string, labelValues []string) string {
+	for i := 0; i+1 < len(labelValues); i += 2 {
+		if labels != "" {
+			labels += ","
+		}
+		labels += labelValues[i] + "=" + labelValues[i+1]
+	}
+	return labels
+}
+
+func TestBreakerIgnoresCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var calls int
+	ep := options{}.breaker("GetAllToDo")(func(ctx context.Context, _ interface{}) (interface{}, error) {
+		calls++
+		return nil, ctx.Err()
+	})
+
+	for i := 0; i < 10; i++ {
+		if _, err := ep(ctx, nil); err != context.Canceled {
+			t.Fatalf("call %d: want %v, have %v", i, context.Canceled, err)
+		}
+	}
+	if calls != 10 {
+		t.Errorf("want the breaker closed after cancelled calls, have %d calls through", calls)
+	}
+}
@@ -0,0 +1,86 @@
+package addendpoint
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-kit/kit/endpoint"
+
+	"ray.vhatt/todo-gokit/pkg/store"
+)
+
+// Deadline is the time budget of the invocations of a method.
+type Deadline struct {
+	// Timeout is how long an invocation may take.
+	Timeout time.Duration
+	// PartialReserve, when not zero, makes listings answer with the todos
+	// read so far this long before the deadline, rather than fail with it,
+	// see store.WithPartialResults. It has to leave time to send them back.
+	PartialReserve time.Duration
+}
+
+// WithDeadlines makes New give the invocations of the methods their
+// Deadline, keyed by method name. The other methods run until the client
+// gives up.
+func WithDeadlines(deadlines map[string]Deadline) Option {
+	return func(o *options) {
+		o.deadlines = deadlines
+	}
+}
+
+// deadline returns the middleware enforcing the Deadline of method, if any.
+func (o options) deadline(method string) endpoint.Middleware {
+	d, ok := o.deadlines[method]
+	if !ok || d.Timeout <= 0 {
+		return func(next endpoint.Endpoint) endpoint.Endpoint { return next }
+	}
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(ctx context.Context, request interface{}) (interface{}, error) {
+			ctx, cancel := context.WithTimeout(ctx, d.Timeout)
+			defer cancel()
+			if d.PartialReserve > 0 {
+				ctx = store.WithPartialResults(ctx, d.PartialReserve)
+			}
+			return next(ctx, request)
+		}
+	}
+}
+
+// ParseDeadlines parses a comma separated list of method deadlines, each
+// method:timeout or method:timeout:reserve, the durations formatted like
+// time.ParseDuration's, e.g.
+//
+//	GetAllToDo:2s:250ms,AddToDo:500ms
+//
+// The reserve is the PartialReserve of the Deadline.
+func ParseDeadlines(s string) (map[string]Deadline, error) {
+	deadlines := make(map[string]Deadline)
+	if s == "" {
+		return deadlines, nil
+	}
+	for _, item := range strings.Split(s, ",") {
+		fields := strings.Split(strings.TrimSpace(item), ":")
+		if len(fields) < 2 || len(fields) > 3 || fields[0] == "" {
+			return nil, fmt.Errorf("deadline %q: want method:timeout[:reserve]", item)
+		}
+		var (
+			d   Deadline
+			err error
+		)
+		if d.Timeout, err = time.ParseDuration(fields[1]); err != nil {
+			return nil, fmt.Errorf("deadline %q: %v", item, err)
+		}
+		if len(fields) == 3 {
+			if d.PartialReserve, err = time.ParseDuration(fields[2]); err != nil {
+				return nil, fmt.Errorf("deadline %q: %v", item, err)
+			}
+			if d.PartialReserve >= d.Timeout {
+				return nil, fmt.Errorf("deadline %q: reserve isn't shorter than the timeout", item)
+			}
+		}
+		deadlines[fields[0]] = d
+	}
+	return deadlines, nil
+}
@@ -0,0 +1,40 @@
+package addendpoint
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestDeadlines(t *testing.T) {
+	deadlines, err := ParseDeadlines("GetAllToDo:2s:250ms, AddToDo:500ms")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, have := (Deadline{Timeout: 2 * time.Second, PartialReserve: 250 * time.Millisecond}), deadlines["GetAllToDo"]; want != have {
+		t.Errorf("GetAllToDo: want %+v, have %+v", want, have)
+	}
+	if want, have := (Deadline{Timeout: 500 * time.Millisecond}), deadlines["AddToDo"]; want != have {
+		t.Errorf("AddToDo: want %+v, have %+v", want, have)
+	}
+	for _, s := range []string{"GetAllToDo", "GetAllToDo:soon", ":1s", "GetAllToDo:1s:1s", "GetAllToDo:1s:2s:3s"} {
+		if _, err := ParseDeadlines(s); err == nil {
+			t.Errorf("%q: want an error", s)
+		}
+	}
+
+	o := options{deadlines: deadlines}
+	remaining := func(ctx context.Context, _ interface{}) (interface{}, error) {
+		deadline, ok := ctx.Deadline()
+		if !ok {
+			return time.Duration(0), nil
+		}
+		return time.Until(deadline), nil
+	}
+	if v, _ := o.deadline("GetAllToDo")(remaining)(context.Background(), nil); v.(time.Duration) <= time.Second {
+		t.Errorf("want a 2s deadline, have %v left", v)
+	}
+	if v, _ := o.deadline("Ping")(remaining)(context.Background(), nil); v.(time.Duration) != 0 {
+		t.Errorf("want no deadline, have %v left", v)
+	}
+}
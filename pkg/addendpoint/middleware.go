@@ -12,14 +12,19 @@ import (
 
 // InstrumentingMiddleware returns an endpoint middleware that records
 // the duration of each invocation to the passed histogram. The middleware adds
-// a single field: "success", which is "true" if no error is returned, and
-// "false" otherwise.
+// a single field: "success", which is "true" if no error is returned,
+// "cancelled" if the client cancelled the invocation, and "false" otherwise,
+// so that failures of the service aren't mixed up with clients going away.
 func InstrumentingMiddleware(duration metrics.Histogram) endpoint.Middleware {
 	return func(next endpoint.Endpoint) endpoint.Endpoint {
 		return func(ctx context.Context, request interface{}) (response interface{}, err error) {
 
 			defer func(begin time.Time) {
-				duration.With("success", fmt.Sprint(err == nil)).Observe(time.Since(begin).Seconds())
+				success := fmt.Sprint(err == nil)
+				if err != nil && ctx.Err() == context.Canceled {
+					success = "cancelled"
+				}
+				duration.With("success", success).Observe(time.Since(begin).Seconds())
 			}(time.Now())
 			return next(ctx, request)
 
@@ -41,3 +46,20 @@ func LoggingMiddleware(logger log.Logger) endpoint.Middleware {
 		}
 	}
 }
+
+// CancellationMiddleware returns an endpoint middleware that counts the
+// invocations whose context was cancelled, typically because the client went
+// away. Those invocations fail with the context error, whatever the endpoint
+// returned, so that transports report them consistently.
+func CancellationMiddleware(cancelled metrics.Counter) endpoint.Middleware {
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(ctx context.Context, request interface{}) (interface{}, error) {
+			response, err := next(ctx, request)
+			if ctx.Err() == context.Canceled {
+				cancelled.Add(1)
+				return nil, ctx.Err()
+			}
+			return response, err
+		}
+	}
+}
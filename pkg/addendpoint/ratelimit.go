@@ -0,0 +1,75 @@
+package addendpoint
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// Limiter is a rate limiter whose state can be inspected, so transports can
+// tell clients how much of their quota is left. It implements
+// ratelimit.Allower.
+type Limiter interface {
+	// Allow reports whether an event may happen now, consuming a token if so.
+	Allow() bool
+	// State returns the bucket size, the number of events still allowed right
+	// now, and the time at which the bucket will be full again.
+	State() (limit, remaining int, reset time.Time)
+}
+
+// tokenBucket is a Limiter local to the process.
+type tokenBucket struct {
+	mu     sync.Mutex
+	limit  rate.Limit
+	burst  int
+	tokens float64
+	last   time.Time
+}
+
+// NewLimiter returns a full Limiter that allows events up to rate r and
+// permits bursts of at most b events. Its state is local to the process, so
+// every replica of the service gets its own quota.
+func NewLimiter(r rate.Limit, b int) Limiter {
+	return &tokenBucket{
+		limit:  r,
+		burst:  b,
+		tokens: float64(b),
+		last:   time.Now(),
+	}
+}
+
+func (l *tokenBucket) Allow() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.advance(time.Now())
+	if l.tokens < 1 {
+		return false
+	}
+	l.tokens--
+	return true
+}
+
+func (l *tokenBucket) State() (limit, remaining int, reset time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.advance(now)
+	reset = now
+	if missing := float64(l.burst) - l.tokens; missing > 0 && l.limit > 0 {
+		reset = now.Add(time.Duration(missing / float64(l.limit) * float64(time.Second)))
+	}
+	return l.burst, int(math.Floor(l.tokens)), reset
+}
+
+// advance refills the bucket for the time elapsed since the last call.
+// Callers must hold l.mu.
+func (l *tokenBucket) advance(now time.Time) {
+	if elapsed := now.Sub(l.last); elapsed > 0 {
+		l.tokens = math.Min(float64(l.burst), l.tokens+elapsed.Seconds()*float64(l.limit))
+		l.last = now
+	}
+}
@@ -0,0 +1,166 @@
+package addendpoint
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-redis/redis"
+	"github.com/sony/gobreaker"
+	"golang.org/x/time/rate"
+)
+
+// gcra implements the generic cell rate algorithm in Redis, so that every
+// replica shares the same quota. KEYS[1] holds the theoretical arrival time
+// (TAT) of the next event, in milliseconds of the Redis clock, which keeps
+// replicas with skewed clocks in agreement. ARGV[1] is the interval between
+// events in milliseconds, ARGV[2] the burst, and ARGV[3] is 1 to consume an
+// event. It returns whether the event is allowed, the events still allowed,
+// and the milliseconds until the bucket is full again.
+var gcra = redis.NewScript(`
+redis.replicate_commands()
+local interval = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local time = redis.call("TIME")
+local now = tonumber(time[1]) * 1000 + tonumber(time[2]) / 1000
+local tat = math.max(tonumber(redis.call("GET", KEYS[1])) or now, now)
+
+local allowed = 0
+if ARGV[3] == "1" and tat + interval - burst * interval <= now then
+	allowed = 1
+	tat = tat + interval
+	redis.call("SET", KEYS[1], tat, "PX", math.ceil(tat - now) + 1)
+end
+
+local remaining = math.floor((now - tat) / interval + burst)
+return {allowed, math.max(0, math.min(burst, remaining)), math.ceil(tat - now)}
+`)
+
+type redisLimiter struct {
+	client   *redis.Client
+	key      string
+	interval float64
+	burst    int
+	logger   log.Logger
+}
+
+// NewRedisLimiter returns a Limiter keeping its state in Redis under key, so
+// that all the replicas of the service share the same quota of events up to
+// rate r, with bursts of at most b events. r must be positive and finite.
+//
+// When Redis can't be reached, events are allowed and the error is logged:
+// the limits protect the service, they aren't worth an outage.
+func NewRedisLimiter(client *redis.Client, key string, r rate.Limit, b int, logger log.Logger) Limiter {
+	return &redisLimiter{
+		client:   client,
+		key:      key,
+		interval: 1000 / float64(r),
+		burst:    b,
+		logger:   logger,
+	}
+}
+
+func (l *redisLimiter) Allow() bool {
+	allowed, _, _, err := l.run(true)
+	if err != nil {
+		l.logger.Log("limiter", l.key, "err", err)
+		return true
+	}
+	return allowed
+}
+
+func (l *redisLimiter) State() (limit, remaining int, reset time.Time) {
+	_, remaining, reset, err := l.run(false)
+	if err != nil {
+		l.logger.Log("limiter", l.key, "err", err)
+		return l.burst, l.burst, time.Now()
+	}
+	return l.burst, remaining, reset
+}
+
+func (l *redisLimiter) run(consume bool) (allowed bool, remaining int, reset time.Time, err error) {
+	arg := 0
+	if consume {
+		arg = 1
+	}
+	res, err := gcra.Run(l.client, []string{l.key}, l.interval, l.burst, arg).Result()
+	if err != nil {
+		return false, 0, time.Time{}, err
+	}
+	values, ok := res.([]interface{})
+	if !ok || len(values) != 3 {
+		return false, 0, time.Time{}, fmt.Errorf("unexpected reply %v", res)
+	}
+	var ints [3]int64
+	for i, v := range values {
+		if ints[i], ok = v.(int64); !ok {
+			return false, 0, time.Time{}, fmt.Errorf("unexpected reply %v", res)
+		}
+	}
+	return ints[0] == 1, int(ints[1]), time.Now().Add(time.Duration(ints[2]) * time.Millisecond), nil
+}
+
+type redisBreakerBus struct {
+	client  *redis.Client
+	channel string
+	replica string
+	logger  log.Logger
+}
+
+// breakerEvent is the message a redisBreakerBus publishes. Replica tells the
+// publisher its own messages apart.
+type breakerEvent struct {
+	Replica string `json:"replica"`
+	Method  string `json:"method"`
+	State   string `json:"state"`
+}
+
+// NewRedisBreakerBus returns a BreakerBus publishing the state changes of the
+// circuit breakers on a Redis channel. Like NewRedisLimiter, it logs the
+// errors reaching Redis and carries on: the replicas then only lose each
+// other's reports.
+func NewRedisBreakerBus(client *redis.Client, channel string, logger log.Logger) BreakerBus {
+	id := make([]byte, 8)
+	rand.Read(id)
+	return &redisBreakerBus{
+		client:  client,
+		channel: channel,
+		replica: hex.EncodeToString(id),
+		logger:  logger,
+	}
+}
+
+func (b *redisBreakerBus) Publish(method string, state gobreaker.State) {
+	msg, _ := json.Marshal(breakerEvent{Replica: b.replica, Method: method, State: state.String()})
+	if err := b.client.Publish(b.channel, msg).Err(); err != nil {
+		b.logger.Log("channel", b.channel, "err", err)
+	}
+}
+
+func (b *redisBreakerBus) Subscribe(fn func(method string, state gobreaker.State)) {
+	// Channel reconnects and resubscribes after network errors.
+	messages := b.client.Subscribe(b.channel).Channel()
+	go func() {
+		for msg := range messages {
+			var e breakerEvent
+			if err := json.Unmarshal([]byte(msg.Payload), &e); err != nil {
+				b.logger.Log("channel", b.channel, "err", err)
+				continue
+			}
+			if e.Replica == b.replica {
+				continue
+			}
+			switch e.State {
+			case gobreaker.StateOpen.String():
+				fn(e.Method, gobreaker.StateOpen)
+			case gobreaker.StateHalfOpen.String():
+				fn(e.Method, gobreaker.StateHalfOpen)
+			case gobreaker.StateClosed.String():
+				fn(e.Method, gobreaker.StateClosed)
+			}
+		}
+	}()
+}
@@ -0,0 +1,54 @@
+package addendpoint
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-redis/redis"
+	"golang.org/x/time/rate"
+)
+
+func TestRedisLimiter(t *testing.T) {
+	addr := os.Getenv("REDIS_ADDR")
+	if addr == "" {
+		addr = "localhost:6379"
+	}
+	client := redis.NewClient(&redis.Options{Addr: addr})
+	if err := client.Ping().Err(); err != nil {
+		t.Skipf("no Redis to run against: %v", err)
+	}
+	key := "ratelimit:test:" + time.Now().Format(time.RFC3339Nano)
+	defer client.Del(key)
+
+	// Two replicas share the same bucket.
+	a := NewRedisLimiter(client, key, rate.Every(time.Hour), 3, log.NewNopLogger())
+	b := NewRedisLimiter(client, key, rate.Every(time.Hour), 3, log.NewNopLogger())
+	if limit, remaining, _ := a.State(); limit != 3 || remaining != 3 {
+		t.Errorf("want 3 of 3 remaining, have %d of %d", remaining, limit)
+	}
+	for i, l := range []Limiter{a, b, a} {
+		if !l.Allow() {
+			t.Fatalf("event %d denied within the burst", i)
+		}
+	}
+	if b.Allow() {
+		t.Error("event allowed past the burst")
+	}
+	_, remaining, reset := b.State()
+	if remaining != 0 || time.Until(reset) < 2*time.Hour {
+		t.Errorf("want none remaining for about 3 hours, have %d until %v", remaining, reset)
+	}
+}
+
+func TestRedisLimiterFailsOpen(t *testing.T) {
+	client := redis.NewClient(&redis.Options{Addr: "localhost:1", MaxRetries: -1})
+	l := NewRedisLimiter(client, "ratelimit:test", rate.Limit(1), 5, log.NewNopLogger())
+	if !l.Allow() {
+		t.Error("want events allowed without Redis")
+	}
+	if limit, remaining, _ := l.State(); limit != 5 || remaining != 5 {
+		t.Errorf("want a full bucket without Redis, have %d of %d", remaining, limit)
+	}
+}
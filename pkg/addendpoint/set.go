@@ -8,163 +8,775 @@ import (
 
 	stdopentracing "github.com/opentracing/opentracing-go"
 	stdzipkin "github.com/openzipkin/zipkin-go"
-	"github.com/sony/gobreaker"
 
-	"github.com/go-kit/kit/circuitbreaker"
 	"github.com/go-kit/kit/endpoint"
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/metrics"
-	"github.com/go-kit/kit/ratelimit"
 	"github.com/go-kit/kit/tracing/opentracing"
 	"github.com/go-kit/kit/tracing/zipkin"
 
 	"ray.vhatt/todo-gokit/pkg/addservice"
+	"ray.vhatt/todo-gokit/pkg/fields"
+	"ray.vhatt/todo-gokit/pkg/jobs"
 	"ray.vhatt/todo-gokit/pkg/models"
+	"ray.vhatt/todo-gokit/pkg/moderation"
+	"ray.vhatt/todo-gokit/pkg/views"
+	"ray.vhatt/todo-gokit/pkg/webhooks"
+	"ray.vhatt/todo-gokit/pkg/workflow"
 )
 
 // Set collects all of the endpoints that compose an add service. It's meant to
 // be used as a helper struct, to collect all the endpoints into a single
-// parameter.
+// parameter. Limiters holds the server-side rate limiter of each endpoint,
+// keyed by method name, so transports can report their state.
 type Set struct {
-	SumEndpoint          endpoint.Endpoint
-	ConcatEndpoint       endpoint.Endpoint
-	PingEndpoint         endpoint.Endpoint
-	AddToDoEndpoint      endpoint.Endpoint
-	CompleteToDoEndPoint endpoint.Endpoint
-	UnDoToDoEndpoint     endpoint.Endpoint
-	DeleteToDoEndpoint   endpoint.Endpoint
-	GetAllToDoEndpoint   endpoint.Endpoint
+	SumEndpoint              endpoint.Endpoint
+	MultiplyEndpoint         endpoint.Endpoint
+	DivideEndpoint           endpoint.Endpoint
+	ConcatEndpoint           endpoint.Endpoint
+	PingEndpoint             endpoint.Endpoint
+	AddToDoEndpoint          endpoint.Endpoint
+	CompleteToDoEndPoint     endpoint.Endpoint
+	UnDoToDoEndpoint         endpoint.Endpoint
+	UpdateToDoEndpoint       endpoint.Endpoint
+	DeleteToDoEndpoint       endpoint.Endpoint
+	BatchToDoEndpoint        endpoint.Endpoint
+	GetToDoByIDEndpoint      endpoint.Endpoint
+	GetAllToDoEndpoint       endpoint.Endpoint
+	SimilarToDoEndpoint      endpoint.Endpoint
+	SearchToDoEndpoint       endpoint.Endpoint
+	CompletionStatsEndpoint  endpoint.Endpoint
+	WatchToDosEndpoint       endpoint.Endpoint
+	ViewEndpoint             endpoint.Endpoint
+	SaveViewEndpoint         endpoint.Endpoint
+	ListViewsEndpoint        endpoint.Endpoint
+	DeleteViewEndpoint       endpoint.Endpoint
+	CreateWebhookEndpoint    endpoint.Endpoint
+	DeleteWebhookEndpoint    endpoint.Endpoint
+	GetFieldSchemaEndpoint   endpoint.Endpoint
+	SaveFieldSchemaEndpoint  endpoint.Endpoint
+	GetWorkflowEndpoint      endpoint.Endpoint
+	SaveWorkflowEndpoint     endpoint.Endpoint
+	AddDependencyEndpoint    endpoint.Endpoint
+	RemoveDependencyEndpoint endpoint.Endpoint
+	FactorizeEndpoint        endpoint.Endpoint
+	JobStatusEndpoint        endpoint.Endpoint
+	CancelJobEndpoint        endpoint.Endpoint
+	ListFlaggedEndpoint      endpoint.Endpoint
+	ApproveFlaggedEndpoint   endpoint.Endpoint
+	RejectFlaggedEndpoint    endpoint.Endpoint
+	Limiters                 map[string]Limiter
 }
 
-func New(svc addservice.Service, logger log.Logger, duration metrics.Histogram, otTracer stdopentracing.Tracer, zipkinTracer *stdzipkin.Tracer) Set {
+// Option tunes the endpoints built by New.
+type Option func(*options)
+
+type options struct {
+	newLimiter   func(method string, r rate.Limit, b int) Limiter
+	breakerBus   BreakerBus
+	peers        *peerBreakers
+	breakerState metrics.Gauge
+	rejected     metrics.Counter
+	deadlines    map[string]Deadline
+}
+
+// WithLimiters makes New build the rate limiter of each method with
+// newLimiter, instead of NewLimiter. Limiters shared between the replicas of
+// the service, like NewRedisLimiter's, keep the limits right behind a load
+// balancer.
+func WithLimiters(newLimiter func(method string, r rate.Limit, b int) Limiter) Option {
+	return func(o *options) {
+		o.newLimiter = newLimiter
+	}
+}
+
+// WithProtectionMetrics makes New report the state of the circuit breaker of
+// each method in breakerState, labelled with the method: 0 closed, 1
+// half-open, 2 open. The requests rejected by the rate limiters and circuit
+// breakers are counted in rejected, labelled with the method and the reason,
+// ratelimit or breaker.
+func WithProtectionMetrics(breakerState metrics.Gauge, rejected metrics.Counter) Option {
+	return func(o *options) {
+		o.breakerState = breakerState
+		o.rejected = rejected
+	}
+}
+
+func New(svc addservice.Service, logger log.Logger, duration metrics.Histogram, cancelled metrics.Counter, otTracer stdopentracing.Tracer, zipkinTracer *stdzipkin.Tracer, opts ...Option) Set {
+	o := options{newLimiter: func(_ string, r rate.Limit, b int) Limiter { return NewLimiter(r, b) }}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	o.peers = newPeerBreakers(o.breakerBus)
+	limiters := make(map[string]Limiter)
+
 	var sumEndpoint endpoint.Endpoint
 	{
 		sumEndpoint = MakeSumEndpoint(svc)
+		sumEndpoint = o.deadline("Sum")(sumEndpoint)
 		// Sum is limited to 1 request per second with burst of 1 request.
 		// Note, rate is defined as a time interval between requests.
-		sumEndpoint = ratelimit.NewErroringLimiter(rate.NewLimiter(rate.Every(time.Second), 1))(sumEndpoint)
-		sumEndpoint = circuitbreaker.Gobreaker(gobreaker.NewCircuitBreaker(gobreaker.Settings{}))(sumEndpoint)
+		limiters["Sum"] = o.newLimiter("Sum", rate.Every(time.Second), 1)
+		sumEndpoint = limit(limiters["Sum"])(sumEndpoint)
+		sumEndpoint = o.breaker("Sum")(sumEndpoint)
 		sumEndpoint = opentracing.TraceServer(otTracer, "Sum")(sumEndpoint)
 		if zipkinTracer != nil {
 			sumEndpoint = zipkin.TraceEndpoint(zipkinTracer, "Sum")(sumEndpoint)
 		}
 		sumEndpoint = LoggingMiddleware(log.With(logger, "method", "Sum"))(sumEndpoint)
 		sumEndpoint = InstrumentingMiddleware(duration.With("method", "Sum"))(sumEndpoint)
+		sumEndpoint = CancellationMiddleware(cancelled.With("method", "Sum"))(sumEndpoint)
 	}
 	var concatEndpoint endpoint.Endpoint
 	{
 		concatEndpoint = MakeConcatEndpoint(svc)
+		concatEndpoint = o.deadline("Concat")(concatEndpoint)
 		// Concat is limited to 1 request per second with burst of 100 requests.
 		// Note, rate is defined as a number of requests per second.
-		concatEndpoint = ratelimit.NewErroringLimiter(rate.NewLimiter(rate.Limit(1), 100))(concatEndpoint)
-		concatEndpoint = circuitbreaker.Gobreaker(gobreaker.NewCircuitBreaker(gobreaker.Settings{}))(concatEndpoint)
+		limiters["Concat"] = o.newLimiter("Concat", rate.Limit(1), 100)
+		concatEndpoint = limit(limiters["Concat"])(concatEndpoint)
+		concatEndpoint = o.breaker("Concat")(concatEndpoint)
 		concatEndpoint = opentracing.TraceServer(otTracer, "Concat")(concatEndpoint)
 		if zipkinTracer != nil {
 			concatEndpoint = zipkin.TraceEndpoint(zipkinTracer, "Concat")(concatEndpoint)
 		}
 		concatEndpoint = LoggingMiddleware(log.With(logger, "method", "Concat"))(concatEndpoint)
 		concatEndpoint = InstrumentingMiddleware(duration.With("method", "Concat"))(concatEndpoint)
+		concatEndpoint = CancellationMiddleware(cancelled.With("method", "Concat"))(concatEndpoint)
+	}
+
+	var multiplyEndpoint endpoint.Endpoint
+	{
+		multiplyEndpoint = MakeMultiplyEndpoint(svc)
+		multiplyEndpoint = o.deadline("Multiply")(multiplyEndpoint)
+		// Multiply is limited to 1 request per second with burst of 100 requests.
+		// Note, rate is defined as a number of requests per second.
+		limiters["Multiply"] = o.newLimiter("Multiply", rate.Limit(1), 100)
+		multiplyEndpoint = limit(limiters["Multiply"])(multiplyEndpoint)
+		multiplyEndpoint = o.breaker("Multiply")(multiplyEndpoint)
+		multiplyEndpoint = opentracing.TraceServer(otTracer, "Multiply")(multiplyEndpoint)
+		if zipkinTracer != nil {
+			multiplyEndpoint = zipkin.TraceEndpoint(zipkinTracer, "Multiply")(multiplyEndpoint)
+		}
+		multiplyEndpoint = LoggingMiddleware(log.With(logger, "method", "Multiply"))(multiplyEndpoint)
+		multiplyEndpoint = InstrumentingMiddleware(duration.With("method", "Multiply"))(multiplyEndpoint)
+		multiplyEndpoint = CancellationMiddleware(cancelled.With("method", "Multiply"))(multiplyEndpoint)
+	}
+
+	var divideEndpoint endpoint.Endpoint
+	{
+		divideEndpoint = MakeDivideEndpoint(svc)
+		divideEndpoint = o.deadline("Divide")(divideEndpoint)
+		// Divide is limited to 1 request per second with burst of 100 requests.
+		// Note, rate is defined as a number of requests per second.
+		limiters["Divide"] = o.newLimiter("Divide", rate.Limit(1), 100)
+		divideEndpoint = limit(limiters["Divide"])(divideEndpoint)
+		divideEndpoint = o.breaker("Divide")(divideEndpoint)
+		divideEndpoint = opentracing.TraceServer(otTracer, "Divide")(divideEndpoint)
+		if zipkinTracer != nil {
+			divideEndpoint = zipkin.TraceEndpoint(zipkinTracer, "Divide")(divideEndpoint)
+		}
+		divideEndpoint = LoggingMiddleware(log.With(logger, "method", "Divide"))(divideEndpoint)
+		divideEndpoint = InstrumentingMiddleware(duration.With("method", "Divide"))(divideEndpoint)
+		divideEndpoint = CancellationMiddleware(cancelled.With("method", "Divide"))(divideEndpoint)
 	}
 
 	var pingEndpoint endpoint.Endpoint
 	{
 		pingEndpoint = MakePingEndpoint(svc)
+		pingEndpoint = o.deadline("Ping")(pingEndpoint)
 		// Ping is limited to 1 request per second with burst of 100 requests.
 		// Note, rate is defined as a number of requests per second.
-		pingEndpoint = ratelimit.NewErroringLimiter(rate.NewLimiter(rate.Limit(1), 100))(pingEndpoint)
-		pingEndpoint = circuitbreaker.Gobreaker(gobreaker.NewCircuitBreaker(gobreaker.Settings{}))(pingEndpoint)
+		limiters["Ping"] = o.newLimiter("Ping", rate.Limit(1), 100)
+		pingEndpoint = limit(limiters["Ping"])(pingEndpoint)
+		pingEndpoint = o.breaker("Ping")(pingEndpoint)
 		pingEndpoint = opentracing.TraceServer(otTracer, "Ping")(pingEndpoint)
 		if zipkinTracer != nil {
-			concatEndpoint = zipkin.TraceEndpoint(zipkinTracer, "Ping")(pingEndpoint)
+			pingEndpoint = zipkin.TraceEndpoint(zipkinTracer, "Ping")(pingEndpoint)
 		}
 		pingEndpoint = LoggingMiddleware(log.With(logger, "method", "Ping"))(pingEndpoint)
 		pingEndpoint = InstrumentingMiddleware(duration.With("method", "Ping"))(pingEndpoint)
+		pingEndpoint = CancellationMiddleware(cancelled.With("method", "Ping"))(pingEndpoint)
 	}
 
 	var addToDoEndpoint endpoint.Endpoint
 	{
 		addToDoEndpoint = MakeAddToDoEndpoint(svc)
+		addToDoEndpoint = o.deadline("AddToDo")(addToDoEndpoint)
 		// AddToDo is limited to 1 request per second with burst of 100 requests.
 		// Note, rate is defined as a number of requests per second.
-		addToDoEndpoint = ratelimit.NewErroringLimiter(rate.NewLimiter(rate.Limit(1), 100))(addToDoEndpoint)
-		addToDoEndpoint = circuitbreaker.Gobreaker(gobreaker.NewCircuitBreaker(gobreaker.Settings{}))(addToDoEndpoint)
+		limiters["AddToDo"] = o.newLimiter("AddToDo", rate.Limit(1), 100)
+		addToDoEndpoint = limit(limiters["AddToDo"])(addToDoEndpoint)
+		addToDoEndpoint = o.breaker("AddToDo")(addToDoEndpoint)
 		addToDoEndpoint = opentracing.TraceServer(otTracer, "AddToDo")(addToDoEndpoint)
 		if zipkinTracer != nil {
 			addToDoEndpoint = zipkin.TraceEndpoint(zipkinTracer, "AddToDo")(addToDoEndpoint)
 		}
 		addToDoEndpoint = LoggingMiddleware(log.With(logger, "method", "AddToDo"))(addToDoEndpoint)
 		addToDoEndpoint = InstrumentingMiddleware(duration.With("method", "AddToDo"))(addToDoEndpoint)
+		addToDoEndpoint = CancellationMiddleware(cancelled.With("method", "AddToDo"))(addToDoEndpoint)
 	}
 
 	var completeToDoEndpoint endpoint.Endpoint
 	{
 		completeToDoEndpoint = MakeCompleteToDoEndpoint(svc)
+		completeToDoEndpoint = o.deadline("CompleteToDo")(completeToDoEndpoint)
 		// CompletToDo is limited to 1 request per second with burst of 100 requests.
 		// Note, rate is defined as a number of requests per second.
-		completeToDoEndpoint = ratelimit.NewErroringLimiter(rate.NewLimiter(rate.Limit(1), 100))(completeToDoEndpoint)
-		completeToDoEndpoint = circuitbreaker.Gobreaker(gobreaker.NewCircuitBreaker(gobreaker.Settings{}))(completeToDoEndpoint)
+		limiters["CompleteToDo"] = o.newLimiter("CompleteToDo", rate.Limit(1), 100)
+		completeToDoEndpoint = limit(limiters["CompleteToDo"])(completeToDoEndpoint)
+		completeToDoEndpoint = o.breaker("CompleteToDo")(completeToDoEndpoint)
 		completeToDoEndpoint = opentracing.TraceServer(otTracer, "CompleteToDo")(completeToDoEndpoint)
 		if zipkinTracer != nil {
 			completeToDoEndpoint = zipkin.TraceEndpoint(zipkinTracer, "CompleteToDo")(completeToDoEndpoint)
 		}
 		completeToDoEndpoint = LoggingMiddleware(log.With(logger, "method", "CompleteToDo"))(completeToDoEndpoint)
 		completeToDoEndpoint = InstrumentingMiddleware(duration.With("method", "CompleteToDo"))(completeToDoEndpoint)
+		completeToDoEndpoint = CancellationMiddleware(cancelled.With("method", "CompleteToDo"))(completeToDoEndpoint)
 	}
 
 	var unDoToDoEndpoint endpoint.Endpoint
 	{
 		unDoToDoEndpoint = MakeUnDoToDoEndpoint(svc)
+		unDoToDoEndpoint = o.deadline("UnDoToDo")(unDoToDoEndpoint)
 		// unDoToDo is limited to 1 request per second with burst of 100 requests.
 		// Note, rate is defined as a number of requests per second.
-		unDoToDoEndpoint = ratelimit.NewErroringLimiter(rate.NewLimiter(rate.Limit(1), 100))(unDoToDoEndpoint)
-		unDoToDoEndpoint = circuitbreaker.Gobreaker(gobreaker.NewCircuitBreaker(gobreaker.Settings{}))(unDoToDoEndpoint)
+		limiters["UnDoToDo"] = o.newLimiter("UnDoToDo", rate.Limit(1), 100)
+		unDoToDoEndpoint = limit(limiters["UnDoToDo"])(unDoToDoEndpoint)
+		unDoToDoEndpoint = o.breaker("UnDoToDo")(unDoToDoEndpoint)
 		unDoToDoEndpoint = opentracing.TraceServer(otTracer, "UndoToDo")(unDoToDoEndpoint)
 		if zipkinTracer != nil {
 			unDoToDoEndpoint = zipkin.TraceEndpoint(zipkinTracer, "UndoToDo")(unDoToDoEndpoint)
 		}
 		unDoToDoEndpoint = LoggingMiddleware(log.With(logger, "method", "UnDoToDo"))(unDoToDoEndpoint)
 		unDoToDoEndpoint = InstrumentingMiddleware(duration.With("method", "UnDoToDo"))(unDoToDoEndpoint)
+		unDoToDoEndpoint = CancellationMiddleware(cancelled.With("method", "UnDoToDo"))(unDoToDoEndpoint)
+	}
+
+	var updateToDoEndpoint endpoint.Endpoint
+	{
+		updateToDoEndpoint = MakeUpdateToDoEndpoint(svc)
+		updateToDoEndpoint = o.deadline("UpdateToDo")(updateToDoEndpoint)
+		// updateToDo is limited to 1 request per second with burst of 100 requests.
+		// Note, rate is defined as a number of requests per second.
+		limiters["UpdateToDo"] = o.newLimiter("UpdateToDo", rate.Limit(1), 100)
+		updateToDoEndpoint = limit(limiters["UpdateToDo"])(updateToDoEndpoint)
+		updateToDoEndpoint = o.breaker("UpdateToDo")(updateToDoEndpoint)
+		updateToDoEndpoint = opentracing.TraceServer(otTracer, "UpdateToDo")(updateToDoEndpoint)
+		if zipkinTracer != nil {
+			updateToDoEndpoint = zipkin.TraceEndpoint(zipkinTracer, "UpdateToDo")(updateToDoEndpoint)
+		}
+		updateToDoEndpoint = LoggingMiddleware(log.With(logger, "method", "UpdateToDo"))(updateToDoEndpoint)
+		updateToDoEndpoint = InstrumentingMiddleware(duration.With("method", "UpdateToDo"))(updateToDoEndpoint)
+		updateToDoEndpoint = CancellationMiddleware(cancelled.With("method", "UpdateToDo"))(updateToDoEndpoint)
 	}
 
 	var deleteToDoEndpoint endpoint.Endpoint
 	{
 		deleteToDoEndpoint = MakeDeleteToDoEndpoint(svc)
+		deleteToDoEndpoint = o.deadline("DeleteToDo")(deleteToDoEndpoint)
 		// deleteToDo is limited to 1 request per second with burst of 100 requests.
 		// Note, rate is defined as a number of requests per second.
-		deleteToDoEndpoint = ratelimit.NewErroringLimiter(rate.NewLimiter(rate.Limit(1), 100))(deleteToDoEndpoint)
-		deleteToDoEndpoint = circuitbreaker.Gobreaker(gobreaker.NewCircuitBreaker(gobreaker.Settings{}))(deleteToDoEndpoint)
+		limiters["DeleteToDo"] = o.newLimiter("DeleteToDo", rate.Limit(1), 100)
+		deleteToDoEndpoint = limit(limiters["DeleteToDo"])(deleteToDoEndpoint)
+		deleteToDoEndpoint = o.breaker("DeleteToDo")(deleteToDoEndpoint)
 		deleteToDoEndpoint = opentracing.TraceServer(otTracer, "DeleteToDo")(deleteToDoEndpoint)
 		if zipkinTracer != nil {
 			deleteToDoEndpoint = zipkin.TraceEndpoint(zipkinTracer, "DeleteToDo")(deleteToDoEndpoint)
 		}
 		deleteToDoEndpoint = LoggingMiddleware(log.With(logger, "method", "DeleteToDo"))(deleteToDoEndpoint)
 		deleteToDoEndpoint = InstrumentingMiddleware(duration.With("method", "DeleteToDo"))(deleteToDoEndpoint)
+		deleteToDoEndpoint = CancellationMiddleware(cancelled.With("method", "DeleteToDo"))(deleteToDoEndpoint)
+	}
+
+	var batchToDoEndpoint endpoint.Endpoint
+	{
+		batchToDoEndpoint = MakeBatchToDoEndpoint(svc)
+		batchToDoEndpoint = o.deadline("BatchToDo")(batchToDoEndpoint)
+		// batchToDo is limited to 1 request per second with burst of 100 requests.
+		// Note, rate is defined as a number of requests per second.
+		limiters["BatchToDo"] = o.newLimiter("BatchToDo", rate.Limit(1), 100)
+		batchToDoEndpoint = limit(limiters["BatchToDo"])(batchToDoEndpoint)
+		batchToDoEndpoint = o.breaker("BatchToDo")(batchToDoEndpoint)
+		batchToDoEndpoint = opentracing.TraceServer(otTracer, "BatchToDo")(batchToDoEndpoint)
+		if zipkinTracer != nil {
+			batchToDoEndpoint = zipkin.TraceEndpoint(zipkinTracer, "BatchToDo")(batchToDoEndpoint)
+		}
+		batchToDoEndpoint = LoggingMiddleware(log.With(logger, "method", "BatchToDo"))(batchToDoEndpoint)
+		batchToDoEndpoint = InstrumentingMiddleware(duration.With("method", "BatchToDo"))(batchToDoEndpoint)
+		batchToDoEndpoint = CancellationMiddleware(cancelled.With("method", "BatchToDo"))(batchToDoEndpoint)
+	}
+
+	var getToDoByIDEndpoint endpoint.Endpoint
+	{
+		getToDoByIDEndpoint = MakeGetToDoByIDEndpoint(svc)
+		getToDoByIDEndpoint = o.deadline("GetToDoByID")(getToDoByIDEndpoint)
+		// getToDoByID is limited to 1 request per second with burst of 100 requests.
+		// Note, rate is defined as a number of requests per second.
+		limiters["GetToDoByID"] = o.newLimiter("GetToDoByID", rate.Limit(1), 100)
+		getToDoByIDEndpoint = limit(limiters["GetToDoByID"])(getToDoByIDEndpoint)
+		getToDoByIDEndpoint = o.breaker("GetToDoByID")(getToDoByIDEndpoint)
+		getToDoByIDEndpoint = opentracing.TraceServer(otTracer, "GetToDoByID")(getToDoByIDEndpoint)
+		if zipkinTracer != nil {
+			getToDoByIDEndpoint = zipkin.TraceEndpoint(zipkinTracer, "GetToDoByID")(getToDoByIDEndpoint)
+		}
+		getToDoByIDEndpoint = LoggingMiddleware(log.With(logger, "method", "GetToDoByID"))(getToDoByIDEndpoint)
+		getToDoByIDEndpoint = InstrumentingMiddleware(duration.With("method", "GetToDoByID"))(getToDoByIDEndpoint)
+		getToDoByIDEndpoint = CancellationMiddleware(cancelled.With("method", "GetToDoByID"))(getToDoByIDEndpoint)
 	}
 
 	var getAllToDoEndpoint endpoint.Endpoint
 	{
 		getAllToDoEndpoint = MakeGetAllToDoEndpoint(svc)
+		getAllToDoEndpoint = o.deadline("GetAllToDo")(getAllToDoEndpoint)
 		// getAllToDo is limited to 1 request per second with burst of 100 requests.
 		// Note, rate is defined as a number of requests per second.
-		getAllToDoEndpoint = ratelimit.NewErroringLimiter(rate.NewLimiter(rate.Limit(1), 100))(getAllToDoEndpoint)
-		getAllToDoEndpoint = circuitbreaker.Gobreaker(gobreaker.NewCircuitBreaker(gobreaker.Settings{}))(getAllToDoEndpoint)
+		limiters["GetAllToDo"] = o.newLimiter("GetAllToDo", rate.Limit(1), 100)
+		getAllToDoEndpoint = limit(limiters["GetAllToDo"])(getAllToDoEndpoint)
+		getAllToDoEndpoint = o.breaker("GetAllToDo")(getAllToDoEndpoint)
 		getAllToDoEndpoint = opentracing.TraceServer(otTracer, "GetAllToDo")(getAllToDoEndpoint)
 		if zipkinTracer != nil {
 			getAllToDoEndpoint = zipkin.TraceEndpoint(zipkinTracer, "GetAllToDo")(getAllToDoEndpoint)
 		}
 		getAllToDoEndpoint = LoggingMiddleware(log.With(logger, "method", "GetAllToDo"))(getAllToDoEndpoint)
 		getAllToDoEndpoint = InstrumentingMiddleware(duration.With("method", "GetAllToDo"))(getAllToDoEndpoint)
+		getAllToDoEndpoint = CancellationMiddleware(cancelled.With("method", "GetAllToDo"))(getAllToDoEndpoint)
+	}
+
+	var similarToDoEndpoint endpoint.Endpoint
+	{
+		similarToDoEndpoint = MakeSimilarToDoEndpoint(svc)
+		similarToDoEndpoint = o.deadline("SimilarToDo")(similarToDoEndpoint)
+		// similarToDo is limited to 1 request per second with burst of 100 requests.
+		// Note, rate is defined as a number of requests per second.
+		limiters["SimilarToDo"] = o.newLimiter("SimilarToDo", rate.Limit(1), 100)
+		similarToDoEndpoint = limit(limiters["SimilarToDo"])(similarToDoEndpoint)
+		similarToDoEndpoint = o.breaker("SimilarToDo")(similarToDoEndpoint)
+		similarToDoEndpoint = opentracing.TraceServer(otTracer, "SimilarToDo")(similarToDoEndpoint)
+		if zipkinTracer != nil {
+			similarToDoEndpoint = zipkin.TraceEndpoint(zipkinTracer, "SimilarToDo")(similarToDoEndpoint)
+		}
+		similarToDoEndpoint = LoggingMiddleware(log.With(logger, "method", "SimilarToDo"))(similarToDoEndpoint)
+		similarToDoEndpoint = InstrumentingMiddleware(duration.With("method", "SimilarToDo"))(similarToDoEndpoint)
+		similarToDoEndpoint = CancellationMiddleware(cancelled.With("method", "SimilarToDo"))(similarToDoEndpoint)
+	}
+
+	var searchToDoEndpoint endpoint.Endpoint
+	{
+		searchToDoEndpoint = MakeSearchToDoEndpoint(svc)
+		searchToDoEndpoint = o.deadline("SearchToDo")(searchToDoEndpoint)
+		// searchToDo is limited to 1 request per second with burst of 100 requests.
+		// Note, rate is defined as a number of requests per second.
+		limiters["SearchToDo"] = o.newLimiter("SearchToDo", rate.Limit(1), 100)
+		searchToDoEndpoint = limit(limiters["SearchToDo"])(searchToDoEndpoint)
+		searchToDoEndpoint = o.breaker("SearchToDo")(searchToDoEndpoint)
+		searchToDoEndpoint = opentracing.TraceServer(otTracer, "SearchToDo")(searchToDoEndpoint)
+		if zipkinTracer != nil {
+			searchToDoEndpoint = zipkin.TraceEndpoint(zipkinTracer, "SearchToDo")(searchToDoEndpoint)
+		}
+		searchToDoEndpoint = LoggingMiddleware(log.With(logger, "method", "SearchToDo"))(searchToDoEndpoint)
+		searchToDoEndpoint = InstrumentingMiddleware(duration.With("method", "SearchToDo"))(searchToDoEndpoint)
+		searchToDoEndpoint = CancellationMiddleware(cancelled.With("method", "SearchToDo"))(searchToDoEndpoint)
+	}
+
+	var completionStatsEndpoint endpoint.Endpoint
+	{
+		completionStatsEndpoint = MakeCompletionStatsEndpoint(svc)
+		completionStatsEndpoint = o.deadline("CompletionStats")(completionStatsEndpoint)
+		// CompletionStats is limited to 1 request per second with burst of 100 requests.
+		// Note, rate is defined as a number of requests per second.
+		limiters["CompletionStats"] = o.newLimiter("CompletionStats", rate.Limit(1), 100)
+		completionStatsEndpoint = limit(limiters["CompletionStats"])(completionStatsEndpoint)
+		completionStatsEndpoint = o.breaker("CompletionStats")(completionStatsEndpoint)
+		completionStatsEndpoint = opentracing.TraceServer(otTracer, "CompletionStats")(completionStatsEndpoint)
+		if zipkinTracer != nil {
+			completionStatsEndpoint = zipkin.TraceEndpoint(zipkinTracer, "CompletionStats")(completionStatsEndpoint)
+		}
+		completionStatsEndpoint = LoggingMiddleware(log.With(logger, "method", "CompletionStats"))(completionStatsEndpoint)
+		completionStatsEndpoint = InstrumentingMiddleware(duration.With("method", "CompletionStats"))(completionStatsEndpoint)
+		completionStatsEndpoint = CancellationMiddleware(cancelled.With("method", "CompletionStats"))(completionStatsEndpoint)
+	}
+
+	var watchToDosEndpoint endpoint.Endpoint
+	{
+		// WatchToDos has no deadline: it returns the changes as they come,
+		// and they stop once its context is done.
+		watchToDosEndpoint = MakeWatchToDosEndpoint(svc)
+		// WatchToDos is limited to 1 request per second with burst of 100 requests.
+		// Note, rate is defined as a number of requests per second.
+		limiters["WatchToDos"] = o.newLimiter("WatchToDos", rate.Limit(1), 100)
+		watchToDosEndpoint = limit(limiters["WatchToDos"])(watchToDosEndpoint)
+		watchToDosEndpoint = o.breaker("WatchToDos")(watchToDosEndpoint)
+		watchToDosEndpoint = opentracing.TraceServer(otTracer, "WatchToDos")(watchToDosEndpoint)
+		if zipkinTracer != nil {
+			watchToDosEndpoint = zipkin.TraceEndpoint(zipkinTracer, "WatchToDos")(watchToDosEndpoint)
+		}
+		watchToDosEndpoint = LoggingMiddleware(log.With(logger, "method", "WatchToDos"))(watchToDosEndpoint)
+		watchToDosEndpoint = InstrumentingMiddleware(duration.With("method", "WatchToDos"))(watchToDosEndpoint)
+		watchToDosEndpoint = CancellationMiddleware(cancelled.With("method", "WatchToDos"))(watchToDosEndpoint)
+	}
+
+	var viewEndpoint endpoint.Endpoint
+	{
+		viewEndpoint = MakeViewEndpoint(svc)
+		viewEndpoint = o.deadline("View")(viewEndpoint)
+		// view is limited to 1 request per second with burst of 100 requests.
+		// Note, rate is defined as a number of requests per second.
+		limiters["View"] = o.newLimiter("View", rate.Limit(1), 100)
+		viewEndpoint = limit(limiters["View"])(viewEndpoint)
+		viewEndpoint = o.breaker("View")(viewEndpoint)
+		viewEndpoint = opentracing.TraceServer(otTracer, "View")(viewEndpoint)
+		if zipkinTracer != nil {
+			viewEndpoint = zipkin.TraceEndpoint(zipkinTracer, "View")(viewEndpoint)
+		}
+		viewEndpoint = LoggingMiddleware(log.With(logger, "method", "View"))(viewEndpoint)
+		viewEndpoint = InstrumentingMiddleware(duration.With("method", "View"))(viewEndpoint)
+		viewEndpoint = CancellationMiddleware(cancelled.With("method", "View"))(viewEndpoint)
+	}
+
+	var saveViewEndpoint endpoint.Endpoint
+	{
+		saveViewEndpoint = MakeSaveViewEndpoint(svc)
+		saveViewEndpoint = o.deadline("SaveView")(saveViewEndpoint)
+		// saveView is limited to 1 request per second with burst of 100 requests.
+		// Note, rate is defined as a number of requests per second.
+		limiters["SaveView"] = o.newLimiter("SaveView", rate.Limit(1), 100)
+		saveViewEndpoint = limit(limiters["SaveView"])(saveViewEndpoint)
+		saveViewEndpoint = o.breaker("SaveView")(saveViewEndpoint)
+		saveViewEndpoint = opentracing.TraceServer(otTracer, "SaveView")(saveViewEndpoint)
+		if zipkinTracer != nil {
+			saveViewEndpoint = zipkin.TraceEndpoint(zipkinTracer, "SaveView")(saveViewEndpoint)
+		}
+		saveViewEndpoint = LoggingMiddleware(log.With(logger, "method", "SaveView"))(saveViewEndpoint)
+		saveViewEndpoint = InstrumentingMiddleware(duration.With("method", "SaveView"))(saveViewEndpoint)
+		saveViewEndpoint = CancellationMiddleware(cancelled.With("method", "SaveView"))(saveViewEndpoint)
+	}
+
+	var listViewsEndpoint endpoint.Endpoint
+	{
+		listViewsEndpoint = MakeListViewsEndpoint(svc)
+		listViewsEndpoint = o.deadline("ListViews")(listViewsEndpoint)
+		// listViews is limited to 1 request per second with burst of 100 requests.
+		// Note, rate is defined as a number of requests per second.
+		limiters["ListViews"] = o.newLimiter("ListViews", rate.Limit(1), 100)
+		listViewsEndpoint = limit(limiters["ListViews"])(listViewsEndpoint)
+		listViewsEndpoint = o.breaker("ListViews")(listViewsEndpoint)
+		listViewsEndpoint = opentracing.TraceServer(otTracer, "ListViews")(listViewsEndpoint)
+		if zipkinTracer != nil {
+			listViewsEndpoint = zipkin.TraceEndpoint(zipkinTracer, "ListViews")(listViewsEndpoint)
+		}
+		listViewsEndpoint = LoggingMiddleware(log.With(logger, "method", "ListViews"))(listViewsEndpoint)
+		listViewsEndpoint = InstrumentingMiddleware(duration.With("method", "ListViews"))(listViewsEndpoint)
+		listViewsEndpoint = CancellationMiddleware(cancelled.With("method", "ListViews"))(listViewsEndpoint)
+	}
+
+	var deleteViewEndpoint endpoint.Endpoint
+	{
+		deleteViewEndpoint = MakeDeleteViewEndpoint(svc)
+		deleteViewEndpoint = o.deadline("DeleteView")(deleteViewEndpoint)
+		// deleteView is limited to 1 request per second with burst of 100 requests.
+		// Note, rate is defined as a number of requests per second.
+		limiters["DeleteView"] = o.newLimiter("DeleteView", rate.Limit(1), 100)
+		deleteViewEndpoint = limit(limiters["DeleteView"])(deleteViewEndpoint)
+		deleteViewEndpoint = o.breaker("DeleteView")(deleteViewEndpoint)
+		deleteViewEndpoint = opentracing.TraceServer(otTracer, "DeleteView")(deleteViewEndpoint)
+		if zipkinTracer != nil {
+			deleteViewEndpoint = zipkin.TraceEndpoint(zipkinTracer, "DeleteView")(deleteViewEndpoint)
+		}
+		deleteViewEndpoint = LoggingMiddleware(log.With(logger, "method", "DeleteView"))(deleteViewEndpoint)
+		deleteViewEndpoint = InstrumentingMiddleware(duration.With("method", "DeleteView"))(deleteViewEndpoint)
+		deleteViewEndpoint = CancellationMiddleware(cancelled.With("method", "DeleteView"))(deleteViewEndpoint)
+	}
+
+	var createWebhookEndpoint endpoint.Endpoint
+	{
+		createWebhookEndpoint = MakeCreateWebhookEndpoint(svc)
+		createWebhookEndpoint = o.deadline("CreateWebhook")(createWebhookEndpoint)
+		// createWebhook is limited to 1 request per second with burst of 100 requests.
+		// Note, rate is defined as a number of requests per second.
+		limiters["CreateWebhook"] = o.newLimiter("CreateWebhook", rate.Limit(1), 100)
+		createWebhookEndpoint = limit(limiters["CreateWebhook"])(createWebhookEndpoint)
+		createWebhookEndpoint = o.breaker("CreateWebhook")(createWebhookEndpoint)
+		createWebhookEndpoint = opentracing.TraceServer(otTracer, "CreateWebhook")(createWebhookEndpoint)
+		if zipkinTracer != nil {
+			createWebhookEndpoint = zipkin.TraceEndpoint(zipkinTracer, "CreateWebhook")(createWebhookEndpoint)
+		}
+		createWebhookEndpoint = LoggingMiddleware(log.With(logger, "method", "CreateWebhook"))(createWebhookEndpoint)
+		createWebhookEndpoint = InstrumentingMiddleware(duration.With("method", "CreateWebhook"))(createWebhookEndpoint)
+		createWebhookEndpoint = CancellationMiddleware(cancelled.With("method", "CreateWebhook"))(createWebhookEndpoint)
+	}
+
+	var deleteWebhookEndpoint endpoint.Endpoint
+	{
+		deleteWebhookEndpoint = MakeDeleteWebhookEndpoint(svc)
+		deleteWebhookEndpoint = o.deadline("DeleteWebhook")(deleteWebhookEndpoint)
+		// deleteWebhook is limited to 1 request per second with burst of 100 requests.
+		// Note, rate is defined as a number of requests per second.
+		limiters["DeleteWebhook"] = o.newLimiter("DeleteWebhook", rate.Limit(1), 100)
+		deleteWebhookEndpoint = limit(limiters["DeleteWebhook"])(deleteWebhookEndpoint)
+		deleteWebhookEndpoint = o.breaker("DeleteWebhook")(deleteWebhookEndpoint)
+		deleteWebhookEndpoint = opentracing.TraceServer(otTracer, "DeleteWebhook")(deleteWebhookEndpoint)
+		if zipkinTracer != nil {
+			deleteWebhookEndpoint = zipkin.TraceEndpoint(zipkinTracer, "DeleteWebhook")(deleteWebhookEndpoint)
+		}
+		deleteWebhookEndpoint = LoggingMiddleware(log.With(logger, "method", "DeleteWebhook"))(deleteWebhookEndpoint)
+		deleteWebhookEndpoint = InstrumentingMiddleware(duration.With("method", "DeleteWebhook"))(deleteWebhookEndpoint)
+		deleteWebhookEndpoint = CancellationMiddleware(cancelled.With("method", "DeleteWebhook"))(deleteWebhookEndpoint)
+	}
+
+	var getFieldSchemaEndpoint endpoint.Endpoint
+	{
+		getFieldSchemaEndpoint = MakeGetFieldSchemaEndpoint(svc)
+		getFieldSchemaEndpoint = o.deadline("GetFieldSchema")(getFieldSchemaEndpoint)
+		// getFieldSchema is limited to 1 request per second with burst of 100 requests.
+		// Note, rate is defined as a number of requests per second.
+		limiters["GetFieldSchema"] = o.newLimiter("GetFieldSchema", rate.Limit(1), 100)
+		getFieldSchemaEndpoint = limit(limiters["GetFieldSchema"])(getFieldSchemaEndpoint)
+		getFieldSchemaEndpoint = o.breaker("GetFieldSchema")(getFieldSchemaEndpoint)
+		getFieldSchemaEndpoint = opentracing.TraceServer(otTracer, "GetFieldSchema")(getFieldSchemaEndpoint)
+		if zipkinTracer != nil {
+			getFieldSchemaEndpoint = zipkin.TraceEndpoint(zipkinTracer, "GetFieldSchema")(getFieldSchemaEndpoint)
+		}
+		getFieldSchemaEndpoint = LoggingMiddleware(log.With(logger, "method", "GetFieldSchema"))(getFieldSchemaEndpoint)
+		getFieldSchemaEndpoint = InstrumentingMiddleware(duration.With("method", "GetFieldSchema"))(getFieldSchemaEndpoint)
+		getFieldSchemaEndpoint = CancellationMiddleware(cancelled.With("method", "GetFieldSchema"))(getFieldSchemaEndpoint)
+	}
+
+	var saveFieldSchemaEndpoint endpoint.Endpoint
+	{
+		saveFieldSchemaEndpoint = MakeSaveFieldSchemaEndpoint(svc)
+		saveFieldSchemaEndpoint = o.deadline("SaveFieldSchema")(saveFieldSchemaEndpoint)
+		// saveFieldSchema is limited to 1 request per second with burst of 100 requests.
+		// Note, rate is defined as a number of requests per second.
+		limiters["SaveFieldSchema"] = o.newLimiter("SaveFieldSchema", rate.Limit(1), 100)
+		saveFieldSchemaEndpoint = limit(limiters["SaveFieldSchema"])(saveFieldSchemaEndpoint)
+		saveFieldSchemaEndpoint = o.breaker("SaveFieldSchema")(saveFieldSchemaEndpoint)
+		saveFieldSchemaEndpoint = opentracing.TraceServer(otTracer, "SaveFieldSchema")(saveFieldSchemaEndpoint)
+		if zipkinTracer != nil {
+			saveFieldSchemaEndpoint = zipkin.TraceEndpoint(zipkinTracer, "SaveFieldSchema")(saveFieldSchemaEndpoint)
+		}
+		saveFieldSchemaEndpoint = LoggingMiddleware(log.With(logger, "method", "SaveFieldSchema"))(saveFieldSchemaEndpoint)
+		saveFieldSchemaEndpoint = InstrumentingMiddleware(duration.With("method", "SaveFieldSchema"))(saveFieldSchemaEndpoint)
+		saveFieldSchemaEndpoint = CancellationMiddleware(cancelled.With("method", "SaveFieldSchema"))(saveFieldSchemaEndpoint)
+	}
+
+	var getWorkflowEndpoint endpoint.Endpoint
+	{
+		getWorkflowEndpoint = MakeGetWorkflowEndpoint(svc)
+		getWorkflowEndpoint = o.deadline("GetWorkflow")(getWorkflowEndpoint)
+		// getWorkflow is limited to 1 request per second with burst of 100 requests.
+		// Note, rate is defined as a number of requests per second.
+		limiters["GetWorkflow"] = o.newLimiter("GetWorkflow", rate.Limit(1), 100)
+		getWorkflowEndpoint = limit(limiters["GetWorkflow"])(getWorkflowEndpoint)
+		getWorkflowEndpoint = o.breaker("GetWorkflow")(getWorkflowEndpoint)
+		getWorkflowEndpoint = opentracing.TraceServer(otTracer, "GetWorkflow")(getWorkflowEndpoint)
+		if zipkinTracer != nil {
+			getWorkflowEndpoint = zipkin.TraceEndpoint(zipkinTracer, "GetWorkflow")(getWorkflowEndpoint)
+		}
+		getWorkflowEndpoint = LoggingMiddleware(log.With(logger, "method", "GetWorkflow"))(getWorkflowEndpoint)
+		getWorkflowEndpoint = InstrumentingMiddleware(duration.With("method", "GetWorkflow"))(getWorkflowEndpoint)
+		getWorkflowEndpoint = CancellationMiddleware(cancelled.With("method", "GetWorkflow"))(getWorkflowEndpoint)
+	}
+
+	var saveWorkflowEndpoint endpoint.Endpoint
+	{
+		saveWorkflowEndpoint = MakeSaveWorkflowEndpoint(svc)
+		saveWorkflowEndpoint = o.deadline("SaveWorkflow")(saveWorkflowEndpoint)
+		// saveWorkflow is limited to 1 request per second with burst of 100 requests.
+		// Note, rate is defined as a number of requests per second.
+		limiters["SaveWorkflow"] = o.newLimiter("SaveWorkflow", rate.Limit(1), 100)
+		saveWorkflowEndpoint = limit(limiters["SaveWorkflow"])(saveWorkflowEndpoint)
+		saveWorkflowEndpoint = o.breaker("SaveWorkflow")(saveWorkflowEndpoint)
+		saveWorkflowEndpoint = opentracing.TraceServer(otTracer, "SaveWorkflow")(saveWorkflowEndpoint)
+		if zipkinTracer != nil {
+			saveWorkflowEndpoint = zipkin.TraceEndpoint(zipkinTracer, "SaveWorkflow")(saveWorkflowEndpoint)
+		}
+		saveWorkflowEndpoint = LoggingMiddleware(log.With(logger, "method", "SaveWorkflow"))(saveWorkflowEndpoint)
+		saveWorkflowEndpoint = InstrumentingMiddleware(duration.With("method", "SaveWorkflow"))(saveWorkflowEndpoint)
+		saveWorkflowEndpoint = CancellationMiddleware(cancelled.With("method", "SaveWorkflow"))(saveWorkflowEndpoint)
+	}
+
+	var addDependencyEndpoint endpoint.Endpoint
+	{
+		addDependencyEndpoint = MakeAddDependencyEndpoint(svc)
+		addDependencyEndpoint = o.deadline("AddDependency")(addDependencyEndpoint)
+		// addDependency is limited to 1 request per second with burst of 100 requests.
+		// Note, rate is defined as a number of requests per second.
+		limiters["AddDependency"] = o.newLimiter("AddDependency", rate.Limit(1), 100)
+		addDependencyEndpoint = limit(limiters["AddDependency"])(addDependencyEndpoint)
+		addDependencyEndpoint = o.breaker("AddDependency")(addDependencyEndpoint)
+		addDependencyEndpoint = opentracing.TraceServer(otTracer, "AddDependency")(addDependencyEndpoint)
+		if zipkinTracer != nil {
+			addDependencyEndpoint = zipkin.TraceEndpoint(zipkinTracer, "AddDependency")(addDependencyEndpoint)
+		}
+		addDependencyEndpoint = LoggingMiddleware(log.With(logger, "method", "AddDependency"))(addDependencyEndpoint)
+		addDependencyEndpoint = InstrumentingMiddleware(duration.With("method", "AddDependency"))(addDependencyEndpoint)
+		addDependencyEndpoint = CancellationMiddleware(cancelled.With("method", "AddDependency"))(addDependencyEndpoint)
+	}
+
+	var removeDependencyEndpoint endpoint.Endpoint
+	{
+		removeDependencyEndpoint = MakeRemoveDependencyEndpoint(svc)
+		removeDependencyEndpoint = o.deadline("RemoveDependency")(removeDependencyEndpoint)
+		// removeDependency is limited to 1 request per second with burst of 100 requests.
+		// Note, rate is defined as a number of requests per second.
+		limiters["RemoveDependency"] = o.newLimiter("RemoveDependency", rate.Limit(1), 100)
+		removeDependencyEndpoint = limit(limiters["RemoveDependency"])(removeDependencyEndpoint)
+		removeDependencyEndpoint = o.breaker("RemoveDependency")(removeDependencyEndpoint)
+		removeDependencyEndpoint = opentracing.TraceServer(otTracer, "RemoveDependency")(removeDependencyEndpoint)
+		if zipkinTracer != nil {
+			removeDependencyEndpoint = zipkin.TraceEndpoint(zipkinTracer, "RemoveDependency")(removeDependencyEndpoint)
+		}
+		removeDependencyEndpoint = LoggingMiddleware(log.With(logger, "method", "RemoveDependency"))(removeDependencyEndpoint)
+		removeDependencyEndpoint = InstrumentingMiddleware(duration.With("method", "RemoveDependency"))(removeDependencyEndpoint)
+		removeDependencyEndpoint = CancellationMiddleware(cancelled.With("method", "RemoveDependency"))(removeDependencyEndpoint)
+	}
+
+	var factorizeEndpoint endpoint.Endpoint
+	{
+		factorizeEndpoint = MakeFactorizeEndpoint(svc)
+		factorizeEndpoint = o.deadline("Factorize")(factorizeEndpoint)
+		// factorize is limited to 1 request per second with burst of 100 requests.
+		// Note, rate is defined as a number of requests per second.
+		limiters["Factorize"] = o.newLimiter("Factorize", rate.Limit(1), 100)
+		factorizeEndpoint = limit(limiters["Factorize"])(factorizeEndpoint)
+		factorizeEndpoint = o.breaker("Factorize")(factorizeEndpoint)
+		factorizeEndpoint = opentracing.TraceServer(otTracer, "Factorize")(factorizeEndpoint)
+		if zipkinTracer != nil {
+			factorizeEndpoint = zipkin.TraceEndpoint(zipkinTracer, "Factorize")(factorizeEndpoint)
+		}
+		factorizeEndpoint = LoggingMiddleware(log.With(logger, "method", "Factorize"))(factorizeEndpoint)
+		factorizeEndpoint = InstrumentingMiddleware(duration.With("method", "Factorize"))(factorizeEndpoint)
+		factorizeEndpoint = CancellationMiddleware(cancelled.With("method", "Factorize"))(factorizeEndpoint)
+	}
+
+	var jobStatusEndpoint endpoint.Endpoint
+	{
+		jobStatusEndpoint = MakeJobStatusEndpoint(svc)
+		jobStatusEndpoint = o.deadline("JobStatus")(jobStatusEndpoint)
+		// jobStatus is limited to 1 request per second with burst of 100 requests.
+		// Note, rate is defined as a number of requests per second.
+		limiters["JobStatus"] = o.newLimiter("JobStatus", rate.Limit(1), 100)
+		jobStatusEndpoint = limit(limiters["JobStatus"])(jobStatusEndpoint)
+		jobStatusEndpoint = o.breaker("JobStatus")(jobStatusEndpoint)
+		jobStatusEndpoint = opentracing.TraceServer(otTracer, "JobStatus")(jobStatusEndpoint)
+		if zipkinTracer != nil {
+			jobStatusEndpoint = zipkin.TraceEndpoint(zipkinTracer, "JobStatus")(jobStatusEndpoint)
+		}
+		jobStatusEndpoint = LoggingMiddleware(log.With(logger, "method", "JobStatus"))(jobStatusEndpoint)
+		jobStatusEndpoint = InstrumentingMiddleware(duration.With("method", "JobStatus"))(jobStatusEndpoint)
+		jobStatusEndpoint = CancellationMiddleware(cancelled.With("method", "JobStatus"))(jobStatusEndpoint)
+	}
+
+	var cancelJobEndpoint endpoint.Endpoint
+	{
+		cancelJobEndpoint = MakeCancelJobEndpoint(svc)
+		cancelJobEndpoint = o.deadline("CancelJob")(cancelJobEndpoint)
+		// cancelJob is limited to 1 request per second with burst of 100 requests.
+		// Note, rate is defined as a number of requests per second.
+		limiters["CancelJob"] = o.newLimiter("CancelJob", rate.Limit(1), 100)
+		cancelJobEndpoint = limit(limiters["CancelJob"])(cancelJobEndpoint)
+		cancelJobEndpoint = o.breaker("CancelJob")(cancelJobEndpoint)
+		cancelJobEndpoint = opentracing.TraceServer(otTracer, "CancelJob")(cancelJobEndpoint)
+		if zipkinTracer != nil {
+			cancelJobEndpoint = zipkin.TraceEndpoint(zipkinTracer, "CancelJob")(cancelJobEndpoint)
+		}
+		cancelJobEndpoint = LoggingMiddleware(log.With(logger, "method", "CancelJob"))(cancelJobEndpoint)
+		cancelJobEndpoint = InstrumentingMiddleware(duration.With("method", "CancelJob"))(cancelJobEndpoint)
+		cancelJobEndpoint = CancellationMiddleware(cancelled.With("method", "CancelJob"))(cancelJobEndpoint)
+	}
+
+	var listFlaggedEndpoint endpoint.Endpoint
+	{
+		listFlaggedEndpoint = MakeListFlaggedEndpoint(svc)
+		listFlaggedEndpoint = o.deadline("ListFlagged")(listFlaggedEndpoint)
+		// listFlagged is limited to 1 request per second with burst of 100 requests.
+		// Note, rate is defined as a number of requests per second.
+		limiters["ListFlagged"] = o.newLimiter("ListFlagged", rate.Limit(1), 100)
+		listFlaggedEndpoint = limit(limiters["ListFlagged"])(listFlaggedEndpoint)
+		listFlaggedEndpoint = o.breaker("ListFlagged")(listFlaggedEndpoint)
+		listFlaggedEndpoint = opentracing.TraceServer(otTracer, "ListFlagged")(listFlaggedEndpoint)
+		if zipkinTracer != nil {
+			listFlaggedEndpoint = zipkin.TraceEndpoint(zipkinTracer, "ListFlagged")(listFlaggedEndpoint)
+		}
+		listFlaggedEndpoint = LoggingMiddleware(log.With(logger, "method", "ListFlagged"))(listFlaggedEndpoint)
+		listFlaggedEndpoint = InstrumentingMiddleware(duration.With("method", "ListFlagged"))(listFlaggedEndpoint)
+		listFlaggedEndpoint = CancellationMiddleware(cancelled.With("method", "ListFlagged"))(listFlaggedEndpoint)
+	}
+
+	var approveFlaggedEndpoint endpoint.Endpoint
+	{
+		approveFlaggedEndpoint = MakeApproveFlaggedEndpoint(svc)
+		approveFlaggedEndpoint = o.deadline("ApproveFlagged")(approveFlaggedEndpoint)
+		// approveFlagged is limited to 1 request per second with burst of 100 requests.
+		// Note, rate is defined as a number of requests per second.
+		limiters["ApproveFlagged"] = o.newLimiter("ApproveFlagged", rate.Limit(1), 100)
+		approveFlaggedEndpoint = limit(limiters["ApproveFlagged"])(approveFlaggedEndpoint)
+		approveFlaggedEndpoint = o.breaker("ApproveFlagged")(approveFlaggedEndpoint)
+		approveFlaggedEndpoint = opentracing.TraceServer(otTracer, "ApproveFlagged")(approveFlaggedEndpoint)
+		if zipkinTracer != nil {
+			approveFlaggedEndpoint = zipkin.TraceEndpoint(zipkinTracer, "ApproveFlagged")(approveFlaggedEndpoint)
+		}
+		approveFlaggedEndpoint = LoggingMiddleware(log.With(logger, "method", "ApproveFlagged"))(approveFlaggedEndpoint)
+		approveFlaggedEndpoint = InstrumentingMiddleware(duration.With("method", "ApproveFlagged"))(approveFlaggedEndpoint)
+		approveFlaggedEndpoint = CancellationMiddleware(cancelled.With("method", "ApproveFlagged"))(approveFlaggedEndpoint)
+	}
+
+	var rejectFlaggedEndpoint endpoint.Endpoint
+	{
+		rejectFlaggedEndpoint = MakeRejectFlaggedEndpoint(svc)
+		rejectFlaggedEndpoint = o.deadline("RejectFlagged")(rejectFlaggedEndpoint)
+		// rejectFlagged is limited to 1 request per second with burst of 100 requests.
+		// Note, rate is defined as a number of requests per second.
+		limiters["RejectFlagged"] = o.newLimiter("RejectFlagged", rate.Limit(1), 100)
+		rejectFlaggedEndpoint = limit(limiters["RejectFlagged"])(rejectFlaggedEndpoint)
+		rejectFlaggedEndpoint = o.breaker("RejectFlagged")(rejectFlaggedEndpoint)
+		rejectFlaggedEndpoint = opentracing.TraceServer(otTracer, "RejectFlagged")(rejectFlaggedEndpoint)
+		if zipkinTracer != nil {
+			rejectFlaggedEndpoint = zipkin.TraceEndpoint(zipkinTracer, "RejectFlagged")(rejectFlaggedEndpoint)
+		}
+		rejectFlaggedEndpoint = LoggingMiddleware(log.With(logger, "method", "RejectFlagged"))(rejectFlaggedEndpoint)
+		rejectFlaggedEndpoint = InstrumentingMiddleware(duration.With("method", "RejectFlagged"))(rejectFlaggedEndpoint)
+		rejectFlaggedEndpoint = CancellationMiddleware(cancelled.With("method", "RejectFlagged"))(rejectFlaggedEndpoint)
 	}
 
 	return Set{
-		SumEndpoint:          sumEndpoint,
-		ConcatEndpoint:       concatEndpoint,
-		PingEndpoint:         pingEndpoint,
-		AddToDoEndpoint:      addToDoEndpoint,
-		CompleteToDoEndPoint: completeToDoEndpoint,
-		UnDoToDoEndpoint:     unDoToDoEndpoint,
-		DeleteToDoEndpoint:   deleteToDoEndpoint,
-		GetAllToDoEndpoint:   getAllToDoEndpoint,
+		SumEndpoint:              sumEndpoint,
+		MultiplyEndpoint:         multiplyEndpoint,
+		DivideEndpoint:           divideEndpoint,
+		ConcatEndpoint:           concatEndpoint,
+		PingEndpoint:             pingEndpoint,
+		AddToDoEndpoint:          addToDoEndpoint,
+		CompleteToDoEndPoint:     completeToDoEndpoint,
+		UnDoToDoEndpoint:         unDoToDoEndpoint,
+		UpdateToDoEndpoint:       updateToDoEndpoint,
+		DeleteToDoEndpoint:       deleteToDoEndpoint,
+		BatchToDoEndpoint:        batchToDoEndpoint,
+		GetToDoByIDEndpoint:      getToDoByIDEndpoint,
+		GetAllToDoEndpoint:       getAllToDoEndpoint,
+		SimilarToDoEndpoint:      similarToDoEndpoint,
+		SearchToDoEndpoint:       searchToDoEndpoint,
+		CompletionStatsEndpoint:  completionStatsEndpoint,
+		WatchToDosEndpoint:       watchToDosEndpoint,
+		ViewEndpoint:             viewEndpoint,
+		SaveViewEndpoint:         saveViewEndpoint,
+		ListViewsEndpoint:        listViewsEndpoint,
+		DeleteViewEndpoint:       deleteViewEndpoint,
+		CreateWebhookEndpoint:    createWebhookEndpoint,
+		DeleteWebhookEndpoint:    deleteWebhookEndpoint,
+		GetFieldSchemaEndpoint:   getFieldSchemaEndpoint,
+		SaveFieldSchemaEndpoint:  saveFieldSchemaEndpoint,
+		GetWorkflowEndpoint:      getWorkflowEndpoint,
+		SaveWorkflowEndpoint:     saveWorkflowEndpoint,
+		AddDependencyEndpoint:    addDependencyEndpoint,
+		RemoveDependencyEndpoint: removeDependencyEndpoint,
+		FactorizeEndpoint:        factorizeEndpoint,
+		JobStatusEndpoint:        jobStatusEndpoint,
+		CancelJobEndpoint:        cancelJobEndpoint,
+		ListFlaggedEndpoint:      listFlaggedEndpoint,
+		ApproveFlaggedEndpoint:   approveFlaggedEndpoint,
+		RejectFlaggedEndpoint:    rejectFlaggedEndpoint,
+		Limiters:                 limiters,
 	}
 }
 
@@ -192,6 +804,30 @@ func (s Set) Concat(ctx context.Context, a, b string) (string, error) {
 	return response.V, response.Err
 }
 
+// Multiply implements the service interface, so Set may be used a
+// service. This is primarily useful in the context of a client library.
+func (s Set) Multiply(ctx context.Context, a, b int) (int, error) {
+	resp, err := s.MultiplyEndpoint(ctx, MultiplyRequest{A: a, B: b})
+	if err != nil {
+		return 0, err
+	}
+
+	response := resp.(MultiplyResponse)
+	return response.V, response.Err
+}
+
+// Divide implements the service interface, so Set may be used a
+// service. This is primarily useful in the context of a client library.
+func (s Set) Divide(ctx context.Context, a, b int) (int, error) {
+	resp, err := s.DivideEndpoint(ctx, DivideRequest{A: a, B: b})
+	if err != nil {
+		return 0, err
+	}
+
+	response := resp.(DivideResponse)
+	return response.V, response.Err
+}
+
 // Ping implements the service interface, so Set may be used a
 // service. This is primarily useful in the context of a client library.
 func (s Set) Ping(ctx context.Context) (string, error) {
@@ -206,8 +842,8 @@ func (s Set) Ping(ctx context.Context) (string, error) {
 
 // AddToDo implements the service interface, so Set may be used a
 // service. This is primarily useful in the context of a client library.
-func (s Set) AddToDo(ctx context.Context, task models.ToDoItem) (string, error) {
-	resp, err := s.AddToDoEndpoint(ctx, AddToDoRequest(task))
+func (s Set) AddToDo(ctx context.Context, task models.ToDoItem) (models.TaskID, error) {
+	resp, err := s.AddToDoEndpoint(ctx, AddToDoRequest{ToDoItem: task})
 	if err != nil {
 		return "", err
 	}
@@ -218,7 +854,7 @@ func (s Set) AddToDo(ctx context.Context, task models.ToDoItem) (string, error)
 
 // CompleteToDo implements the service interface, so Set may be used a
 // service. This is primarily useful in the context of a client library.
-func (s Set) CompleteToDo(ctx context.Context, taskID string) (string, error) {
+func (s Set) CompleteToDo(ctx context.Context, taskID models.TaskID) (models.TaskID, error) {
 	resp, err := s.CompleteToDoEndPoint(ctx, CompleteToDoRequest{TaskID: taskID})
 	if err != nil {
 		return "", err
@@ -230,7 +866,7 @@ func (s Set) CompleteToDo(ctx context.Context, taskID string) (string, error) {
 
 // UndoToDo implements the service interface, so Set may be used a
 // service. This is primarily useful in the context of a client library.
-func (s Set) UnDoToDo(ctx context.Context, taskID string) (string, error) {
+func (s Set) UnDoToDo(ctx context.Context, taskID models.TaskID) (models.TaskID, error) {
 	resp, err := s.UnDoToDoEndpoint(ctx, UnDoToDoRequest{TaskID: taskID})
 	if err != nil {
 		return "", err
@@ -240,9 +876,21 @@ func (s Set) UnDoToDo(ctx context.Context, taskID string) (string, error) {
 	return response.TaskID, response.Err
 }
 
+// UpdateToDo implements the service interface, so Set may be used a
+// service. This is primarily useful in the context of a client library.
+func (s Set) UpdateToDo(ctx context.Context, taskID models.TaskID, updates models.ToDoUpdate) (models.TaskID, error) {
+	resp, err := s.UpdateToDoEndpoint(ctx, UpdateToDoRequest{TaskID: taskID, ToDoUpdate: updates})
+	if err != nil {
+		return "", err
+	}
+
+	response := resp.(UpdateToDoResponse)
+	return response.TaskID, response.Err
+}
+
 // DeleteToDo implements the service interface, so Set may be used a
 // service. This is primarily useful in the context of a client library.
-func (s Set) DeleteToDo(ctx context.Context, taskID string) (string, error) {
+func (s Set) DeleteToDo(ctx context.Context, taskID models.TaskID) (models.TaskID, error) {
 	resp, err := s.DeleteToDoEndpoint(ctx, DeleteToDoRequest{TaskID: taskID})
 	if err != nil {
 		return "", err
@@ -252,72 +900,396 @@ func (s Set) DeleteToDo(ctx context.Context, taskID string) (string, error) {
 	return response.TaskID, response.Err
 }
 
-// GetAllToDo implements the service interface, so Set may be used a
+// BatchToDo implements the service interface, so Set may be used a
 // service. This is primarily useful in the context of a client library.
-func (s Set) GetAllToDo(ctx context.Context) ([]models.ToDoItem, error) {
-	resp, err := s.GetAllToDoEndpoint(ctx, GetAllToDoRequest{})
+func (s Set) BatchToDo(ctx context.Context, action models.BatchAction, ids []models.TaskID) (models.BatchResult, error) {
+	resp, err := s.BatchToDoEndpoint(ctx, BatchToDoRequest{Action: action, IDs: ids})
 	if err != nil {
-		return nil, err
+		return models.BatchResult{}, err
 	}
 
-	response := resp.(GetAllToDoResponse)
-	return response.Todos, response.Err
+	response := resp.(BatchToDoResponse)
+	return response.BatchResult, response.Err
 }
 
-// MakeSumEndpoint constructs a Sum endpoint wrapping the service.
-func MakeSumEndpoint(s addservice.Service) endpoint.Endpoint {
-	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
-		req := request.(SumRequest)
-		v, err := s.Sum(ctx, req.A, req.B)
-		return SumResponse{V: v, Err: err}, nil
+// GetToDoByID implements the service interface, so Set may be used a
+// service. This is primarily useful in the context of a client library.
+func (s Set) GetToDoByID(ctx context.Context, taskID models.TaskID) (models.ToDoItem, error) {
+	resp, err := s.GetToDoByIDEndpoint(ctx, GetToDoByIDRequest{TaskID: taskID})
+	if err != nil {
+		return models.ToDoItem{}, err
 	}
-}
 
-// MakeConcatEndpoint constructs a Concat endpoint wrapping the service.
-func MakeConcatEndpoint(s addservice.Service) endpoint.Endpoint {
-	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
-		req := request.(ConcatRequest)
-		v, err := s.Concat(ctx, req.A, req.B)
-		return ConcatResponse{V: v, Err: err}, nil
-	}
+	response := resp.(GetToDoByIDResponse)
+	return response.ToDo, response.Err
 }
 
-// MakePingEndpoint constructs a Ping endpoint wrapping the service.
-func MakePingEndpoint(s addservice.Service) endpoint.Endpoint {
-	return func(ctx context.Context, _ interface{}) (response interface{}, err error) {
-		v, err := s.Ping(ctx)
-		return ConcatResponse{V: v, Err: err}, nil
+// GetAllToDo implements the service interface, so Set may be used a
+// service. This is primarily useful in the context of a client library.
+func (s Set) GetAllToDo(ctx context.Context, opts models.ListOptions) (models.ToDoPage, error) {
+	resp, err := s.GetAllToDoEndpoint(ctx, GetAllToDoRequest{ListOptions: opts})
+	if err != nil {
+		return models.ToDoPage{}, err
 	}
+
+	response := resp.(GetAllToDoResponse)
+	return response.ToDoPage, response.Err
 }
 
-// MakeAddToDoEndpoint constructs a AddToDo endpoint wrapping the service.
-func MakeAddToDoEndpoint(s addservice.Service) endpoint.Endpoint {
-	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
-		req := request.(AddToDoRequest)
-		v, err := s.AddToDo(ctx, req)
-		return AddToDoResponse{TaskID: v, Err: err}, nil
+// SimilarToDo implements the service interface, so Set may be used a
+// service. This is primarily useful in the context of a client library.
+func (s Set) SimilarToDo(ctx context.Context, task string) ([]models.ToDoItem, error) {
+	resp, err := s.SimilarToDoEndpoint(ctx, SimilarToDoRequest{Task: task})
+	if err != nil {
+		return nil, err
 	}
+
+	response := resp.(SimilarToDoResponse)
+	return response.Todos, response.Err
 }
 
-// MakeCompleteToDoEndpoint constructs a CompleteToDo endpoint wrapping the service.
-func MakeCompleteToDoEndpoint(s addservice.Service) endpoint.Endpoint {
-	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
-		req := request.(CompleteToDoRequest)
-		v, err := s.CompleteToDo(ctx, req.TaskID)
-		return CompleteToDoResponse{TaskID: v, Err: err}, nil
+// SearchToDo implements the service interface, so Set may be used a
+// service. This is primarily useful in the context of a client library.
+func (s Set) SearchToDo(ctx context.Context, query string) ([]models.SearchResult, error) {
+	resp, err := s.SearchToDoEndpoint(ctx, SearchToDoRequest{Query: query})
+	if err != nil {
+		return nil, err
 	}
+
+	response := resp.(SearchToDoResponse)
+	return response.Results, response.Err
 }
 
-// MakeUnDoToDoEndpoint constructs a UnDoToDo endpoint wrapping the service.
-func MakeUnDoToDoEndpoint(s addservice.Service) endpoint.Endpoint {
-	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
-		req := request.(UnDoToDoRequest)
-		v, err := s.UnDoToDo(ctx, req.TaskID)
-		return UnDoToDoResponse{TaskID: v, Err: err}, nil
+// CompletionStats implements the service interface, so Set may be used a
+// service. This is primarily useful in the context of a client library.
+func (s Set) CompletionStats(ctx context.Context) (models.CompletionStats, error) {
+	resp, err := s.CompletionStatsEndpoint(ctx, CompletionStatsRequest{})
+	if err != nil {
+		return models.CompletionStats{}, err
 	}
+
+	response := resp.(CompletionStatsResponse)
+	return response.Stats, response.Err
 }
 
-// MakeDeleteToDoEndpoint constructs a DeleteToDo endpoint wrapping the service.
+// WatchToDos implements the service interface, so Set may be used a
+// service. This is primarily useful in the context of a client library.
+func (s Set) WatchToDos(ctx context.Context) (<-chan models.ToDoEvent, error) {
+	resp, err := s.WatchToDosEndpoint(ctx, WatchToDosRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	response := resp.(WatchToDosResponse)
+	return response.Changes, response.Err
+}
+
+// View implements the service interface, so Set may be used a
+// service. This is primarily useful in the context of a client library.
+func (s Set) View(ctx context.Context, name string) ([]models.ToDoItem, error) {
+	resp, err := s.ViewEndpoint(ctx, ViewRequest{Name: name})
+	if err != nil {
+		return nil, err
+	}
+
+	response := resp.(ViewResponse)
+	return response.Todos, response.Err
+}
+
+// SaveView implements the service interface, so Set may be used a
+// service. This is primarily useful in the context of a client library.
+func (s Set) SaveView(ctx context.Context, v views.View) error {
+	resp, err := s.SaveViewEndpoint(ctx, SaveViewRequest{View: v})
+	if err != nil {
+		return err
+	}
+
+	response := resp.(SaveViewResponse)
+	return response.Err
+}
+
+// ListViews implements the service interface, so Set may be used a
+// service. This is primarily useful in the context of a client library.
+func (s Set) ListViews(ctx context.Context) ([]views.View, error) {
+	resp, err := s.ListViewsEndpoint(ctx, ListViewsRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	response := resp.(ListViewsResponse)
+	return response.Views, response.Err
+}
+
+// DeleteView implements the service interface, so Set may be used a
+// service. This is primarily useful in the context of a client library.
+func (s Set) DeleteView(ctx context.Context, name string) error {
+	resp, err := s.DeleteViewEndpoint(ctx, DeleteViewRequest{Name: name})
+	if err != nil {
+		return err
+	}
+
+	response := resp.(DeleteViewResponse)
+	return response.Err
+}
+
+// CreateWebhook implements the service interface, so Set may be used a
+// service. This is primarily useful in the context of a client library.
+func (s Set) CreateWebhook(ctx context.Context, sub webhooks.Subscription) (webhooks.Subscription, error) {
+	resp, err := s.CreateWebhookEndpoint(ctx, CreateWebhookRequest{Subscription: sub})
+	if err != nil {
+		return webhooks.Subscription{}, err
+	}
+
+	response := resp.(CreateWebhookResponse)
+	return response.Webhook, response.Err
+}
+
+// DeleteWebhook implements the service interface, so Set may be used a
+// service. This is primarily useful in the context of a client library.
+func (s Set) DeleteWebhook(ctx context.Context, id string) error {
+	resp, err := s.DeleteWebhookEndpoint(ctx, DeleteWebhookRequest{ID: id})
+	if err != nil {
+		return err
+	}
+
+	response := resp.(DeleteWebhookResponse)
+	return response.Err
+}
+
+// GetFieldSchema implements the service interface, so Set may be used a
+// service. This is primarily useful in the context of a client library.
+func (s Set) GetFieldSchema(ctx context.Context) (fields.Schema, error) {
+	resp, err := s.GetFieldSchemaEndpoint(ctx, GetFieldSchemaRequest{})
+	if err != nil {
+		return fields.Schema{}, err
+	}
+
+	response := resp.(GetFieldSchemaResponse)
+	return response.Schema, response.Err
+}
+
+// SaveFieldSchema implements the service interface, so Set may be used a
+// service. This is primarily useful in the context of a client library.
+func (s Set) SaveFieldSchema(ctx context.Context, schema fields.Schema) (fields.Schema, error) {
+	resp, err := s.SaveFieldSchemaEndpoint(ctx, SaveFieldSchemaRequest{Schema: schema})
+	if err != nil {
+		return fields.Schema{}, err
+	}
+
+	response := resp.(SaveFieldSchemaResponse)
+	return response.Schema, response.Err
+}
+
+// GetWorkflow implements the service interface, so Set may be used a
+// service. This is primarily useful in the context of a client library.
+func (s Set) GetWorkflow(ctx context.Context) (workflow.Workflow, error) {
+	resp, err := s.GetWorkflowEndpoint(ctx, GetWorkflowRequest{})
+	if err != nil {
+		return workflow.Workflow{}, err
+	}
+
+	response := resp.(GetWorkflowResponse)
+	return response.Workflow, response.Err
+}
+
+// SaveWorkflow implements the service interface, so Set may be used a
+// service. This is primarily useful in the context of a client library.
+func (s Set) SaveWorkflow(ctx context.Context, w workflow.Workflow) (workflow.Workflow, error) {
+	resp, err := s.SaveWorkflowEndpoint(ctx, SaveWorkflowRequest{Workflow: w})
+	if err != nil {
+		return workflow.Workflow{}, err
+	}
+
+	response := resp.(SaveWorkflowResponse)
+	return response.Workflow, response.Err
+}
+
+// AddDependency implements the service interface, so Set may be used a
+// service. This is primarily useful in the context of a client library.
+func (s Set) AddDependency(ctx context.Context, taskID, blockedBy models.TaskID) error {
+	resp, err := s.AddDependencyEndpoint(ctx, AddDependencyRequest{TaskID: taskID, BlockedBy: blockedBy})
+	if err != nil {
+		return err
+	}
+
+	response := resp.(AddDependencyResponse)
+	return response.Err
+}
+
+// RemoveDependency implements the service interface, so Set may be used a
+// service. This is primarily useful in the context of a client library.
+func (s Set) RemoveDependency(ctx context.Context, taskID, blockedBy models.TaskID) error {
+	resp, err := s.RemoveDependencyEndpoint(ctx, RemoveDependencyRequest{TaskID: taskID, BlockedBy: blockedBy})
+	if err != nil {
+		return err
+	}
+
+	response := resp.(RemoveDependencyResponse)
+	return response.Err
+}
+
+// Factorize implements the service interface, so Set may be used a
+// service. This is primarily useful in the context of a client library.
+func (s Set) Factorize(ctx context.Context, n int64) (jobs.Status, error) {
+	resp, err := s.FactorizeEndpoint(ctx, FactorizeRequest{N: n})
+	if err != nil {
+		return jobs.Status{}, err
+	}
+
+	response := resp.(FactorizeResponse)
+	return response.Status, response.Err
+}
+
+// JobStatus implements the service interface, so Set may be used a
+// service. This is primarily useful in the context of a client library.
+func (s Set) JobStatus(ctx context.Context, jobID string) (jobs.Status, error) {
+	resp, err := s.JobStatusEndpoint(ctx, JobStatusRequest{JobID: jobID})
+	if err != nil {
+		return jobs.Status{}, err
+	}
+
+	response := resp.(JobStatusResponse)
+	return response.Status, response.Err
+}
+
+// CancelJob implements the service interface, so Set may be used a
+// service. This is primarily useful in the context of a client library.
+func (s Set) CancelJob(ctx context.Context, jobID string) error {
+	resp, err := s.CancelJobEndpoint(ctx, CancelJobRequest{JobID: jobID})
+	if err != nil {
+		return err
+	}
+
+	response := resp.(CancelJobResponse)
+	return response.Err
+}
+
+// ListFlagged implements the service interface, so Set may be used a
+// service. This is primarily useful in the context of a client library.
+func (s Set) ListFlagged(ctx context.Context) ([]moderation.Event, error) {
+	resp, err := s.ListFlaggedEndpoint(ctx, ListFlaggedRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	response := resp.(ListFlaggedResponse)
+	return response.Flagged, response.Err
+}
+
+// ApproveFlagged implements the service interface, so Set may be used a
+// service. This is primarily useful in the context of a client library.
+func (s Set) ApproveFlagged(ctx context.Context, taskID models.TaskID) error {
+	resp, err := s.ApproveFlaggedEndpoint(ctx, ApproveFlaggedRequest{TaskID: taskID})
+	if err != nil {
+		return err
+	}
+
+	response := resp.(ApproveFlaggedResponse)
+	return response.Err
+}
+
+// RejectFlagged implements the service interface, so Set may be used a
+// service. This is primarily useful in the context of a client library.
+func (s Set) RejectFlagged(ctx context.Context, taskID models.TaskID) error {
+	resp, err := s.RejectFlaggedEndpoint(ctx, RejectFlaggedRequest{TaskID: taskID})
+	if err != nil {
+		return err
+	}
+
+	response := resp.(RejectFlaggedResponse)
+	return response.Err
+}
+
+// MakeSumEndpoint constructs a Sum endpoint wrapping the service.
+func MakeSumEndpoint(s addservice.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		req := request.(SumRequest)
+		v, err := s.Sum(ctx, req.A, req.B)
+		return SumResponse{V: v, Err: err}, nil
+	}
+}
+
+// MakeMultiplyEndpoint constructs a Multiply endpoint wrapping the service.
+func MakeMultiplyEndpoint(s addservice.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		req := request.(MultiplyRequest)
+		v, err := s.Multiply(ctx, req.A, req.B)
+		return MultiplyResponse{V: v, Err: err}, nil
+	}
+}
+
+// MakeDivideEndpoint constructs a Divide endpoint wrapping the service.
+func MakeDivideEndpoint(s addservice.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		req := request.(DivideRequest)
+		v, err := s.Divide(ctx, req.A, req.B)
+		return DivideResponse{V: v, Err: err}, nil
+	}
+}
+
+// MakeConcatEndpoint constructs a Concat endpoint wrapping the service.
+func MakeConcatEndpoint(s addservice.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		req := request.(ConcatRequest)
+		v, err := s.Concat(ctx, req.A, req.B)
+		return ConcatResponse{V: v, Err: err}, nil
+	}
+}
+
+// MakePingEndpoint constructs a Ping endpoint wrapping the service.
+func MakePingEndpoint(s addservice.Service) endpoint.Endpoint {
+	return func(ctx context.Context, _ interface{}) (response interface{}, err error) {
+		v, err := s.Ping(ctx)
+		return ConcatResponse{V: v, Err: err}, nil
+	}
+}
+
+// MakeAddToDoEndpoint constructs a AddToDo endpoint wrapping the service.
+// When the request asks for it, near-duplicates are looked up before the
+// todo is added and returned alongside the new task ID.
+func MakeAddToDoEndpoint(s addservice.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		req := request.(AddToDoRequest)
+		var similar []models.ToDoItem
+		if req.CheckSimilar {
+			similar, err = s.SimilarToDo(ctx, req.Task)
+			if err != nil {
+				return AddToDoResponse{Err: err}, nil
+			}
+		}
+		v, err := s.AddToDo(ctx, req.ToDoItem)
+		return AddToDoResponse{TaskID: v, Similar: similar, Err: err}, nil
+	}
+}
+
+// MakeCompleteToDoEndpoint constructs a CompleteToDo endpoint wrapping the service.
+func MakeCompleteToDoEndpoint(s addservice.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		req := request.(CompleteToDoRequest)
+		v, err := s.CompleteToDo(ctx, req.TaskID)
+		return CompleteToDoResponse{TaskID: v, Err: err}, nil
+	}
+}
+
+// MakeUnDoToDoEndpoint constructs a UnDoToDo endpoint wrapping the service.
+func MakeUnDoToDoEndpoint(s addservice.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		req := request.(UnDoToDoRequest)
+		v, err := s.UnDoToDo(ctx, req.TaskID)
+		return UnDoToDoResponse{TaskID: v, Err: err}, nil
+	}
+}
+
+// MakeUpdateToDoEndpoint constructs a UpdateToDo endpoint wrapping the service.
+func MakeUpdateToDoEndpoint(s addservice.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		req := request.(UpdateToDoRequest)
+		v, err := s.UpdateToDo(ctx, req.TaskID, req.ToDoUpdate)
+		return UpdateToDoResponse{TaskID: v, Err: err}, nil
+	}
+}
+
+// MakeDeleteToDoEndpoint constructs a DeleteToDo endpoint wrapping the service.
 func MakeDeleteToDoEndpoint(s addservice.Service) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
 		req := request.(DeleteToDoRequest)
@@ -326,24 +1298,272 @@ func MakeDeleteToDoEndpoint(s addservice.Service) endpoint.Endpoint {
 	}
 }
 
+// MakeBatchToDoEndpoint constructs a BatchToDo endpoint wrapping the service.
+func MakeBatchToDoEndpoint(s addservice.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		req := request.(BatchToDoRequest)
+		v, err := s.BatchToDo(ctx, req.Action, req.IDs)
+		return BatchToDoResponse{BatchResult: v, Err: err}, nil
+	}
+}
+
+// MakeGetToDoByIDEndpoint constructs a GetToDoByID endpoint wrapping the service.
+func MakeGetToDoByIDEndpoint(s addservice.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		req := request.(GetToDoByIDRequest)
+		v, err := s.GetToDoByID(ctx, req.TaskID)
+		return GetToDoByIDResponse{ToDo: v, Err: err}, nil
+	}
+}
+
 // MakeGetAllToDoEndpoint constructs a GetAllToDo endpoint wrapping the service.
 func MakeGetAllToDoEndpoint(s addservice.Service) endpoint.Endpoint {
-	return func(ctx context.Context, _ interface{}) (response interface{}, err error) {
-		v, err := s.GetAllToDo(ctx)
-		return GetAllToDoResponse{Todos: v, Err: err}, nil
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		req := request.(GetAllToDoRequest)
+		v, err := s.GetAllToDo(ctx, req.ListOptions)
+		return GetAllToDoResponse{ToDoPage: v, Err: err}, nil
+	}
+}
+
+// MakeSimilarToDoEndpoint constructs a SimilarToDo endpoint wrapping the service.
+func MakeSimilarToDoEndpoint(s addservice.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		req := request.(SimilarToDoRequest)
+		v, err := s.SimilarToDo(ctx, req.Task)
+		return SimilarToDoResponse{Todos: v, Err: err}, nil
+	}
+}
+
+// MakeSearchToDoEndpoint constructs a SearchToDo endpoint wrapping the service.
+func MakeSearchToDoEndpoint(s addservice.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		req := request.(SearchToDoRequest)
+		v, err := s.SearchToDo(ctx, req.Query)
+		return SearchToDoResponse{Results: v, Err: err}, nil
+	}
+}
+
+// MakeCompletionStatsEndpoint constructs a CompletionStats endpoint wrapping
+// the service.
+func MakeCompletionStatsEndpoint(s addservice.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		v, err := s.CompletionStats(ctx)
+		return CompletionStatsResponse{Stats: v, Err: err}, nil
+	}
+}
+
+// MakeWatchToDosEndpoint constructs a WatchToDos endpoint wrapping the
+// service.
+func MakeWatchToDosEndpoint(s addservice.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		v, err := s.WatchToDos(ctx)
+		return WatchToDosResponse{Changes: v, Err: err}, nil
+	}
+}
+
+// MakeViewEndpoint constructs a View endpoint wrapping the service.
+func MakeViewEndpoint(s addservice.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		req := request.(ViewRequest)
+		v, err := s.View(ctx, req.Name)
+		return ViewResponse{Todos: v, Err: err}, nil
+	}
+}
+
+// MakeSaveViewEndpoint constructs a SaveView endpoint wrapping the service.
+func MakeSaveViewEndpoint(s addservice.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		req := request.(SaveViewRequest)
+		err = s.SaveView(ctx, req.View)
+		return SaveViewResponse{Err: err}, nil
+	}
+}
+
+// MakeListViewsEndpoint constructs a ListViews endpoint wrapping the service.
+func MakeListViewsEndpoint(s addservice.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		v, err := s.ListViews(ctx)
+		return ListViewsResponse{Views: v, Err: err}, nil
+	}
+}
+
+// MakeDeleteViewEndpoint constructs a DeleteView endpoint wrapping the service.
+func MakeDeleteViewEndpoint(s addservice.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		req := request.(DeleteViewRequest)
+		err = s.DeleteView(ctx, req.Name)
+		return DeleteViewResponse{Err: err}, nil
+	}
+}
+
+// MakeCreateWebhookEndpoint constructs a CreateWebhook endpoint wrapping the
+// service.
+func MakeCreateWebhookEndpoint(s addservice.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		req := request.(CreateWebhookRequest)
+		sub, err := s.CreateWebhook(ctx, req.Subscription)
+		return CreateWebhookResponse{Webhook: sub, Err: err}, nil
+	}
+}
+
+// MakeDeleteWebhookEndpoint constructs a DeleteWebhook endpoint wrapping the
+// service.
+func MakeDeleteWebhookEndpoint(s addservice.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		req := request.(DeleteWebhookRequest)
+		err = s.DeleteWebhook(ctx, req.ID)
+		return DeleteWebhookResponse{Err: err}, nil
+	}
+}
+
+// MakeGetFieldSchemaEndpoint constructs a GetFieldSchema endpoint wrapping
+// the service.
+func MakeGetFieldSchemaEndpoint(s addservice.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		schema, err := s.GetFieldSchema(ctx)
+		return GetFieldSchemaResponse{Schema: schema, Err: err}, nil
+	}
+}
+
+// MakeSaveFieldSchemaEndpoint constructs a SaveFieldSchema endpoint wrapping
+// the service.
+func MakeSaveFieldSchemaEndpoint(s addservice.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		req := request.(SaveFieldSchemaRequest)
+		schema, err := s.SaveFieldSchema(ctx, req.Schema)
+		return SaveFieldSchemaResponse{Schema: schema, Err: err}, nil
+	}
+}
+
+// MakeGetWorkflowEndpoint constructs a GetWorkflow endpoint wrapping
+// the service.
+func MakeGetWorkflowEndpoint(s addservice.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		w, err := s.GetWorkflow(ctx)
+		return GetWorkflowResponse{Workflow: w, Err: err}, nil
+	}
+}
+
+// MakeSaveWorkflowEndpoint constructs a SaveWorkflow endpoint wrapping
+// the service.
+func MakeSaveWorkflowEndpoint(s addservice.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		req := request.(SaveWorkflowRequest)
+		w, err := s.SaveWorkflow(ctx, req.Workflow)
+		return SaveWorkflowResponse{Workflow: w, Err: err}, nil
+	}
+}
+
+// MakeAddDependencyEndpoint constructs an AddDependency endpoint wrapping
+// the service.
+func MakeAddDependencyEndpoint(s addservice.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		req := request.(AddDependencyRequest)
+		err = s.AddDependency(ctx, req.TaskID, req.BlockedBy)
+		return AddDependencyResponse{Err: err}, nil
+	}
+}
+
+// MakeRemoveDependencyEndpoint constructs a RemoveDependency endpoint
+// wrapping the service.
+func MakeRemoveDependencyEndpoint(s addservice.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		req := request.(RemoveDependencyRequest)
+		err = s.RemoveDependency(ctx, req.TaskID, req.BlockedBy)
+		return RemoveDependencyResponse{Err: err}, nil
+	}
+}
+
+// MakeFactorizeEndpoint constructs a Factorize endpoint wrapping the service.
+func MakeFactorizeEndpoint(s addservice.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		req := request.(FactorizeRequest)
+		v, err := s.Factorize(ctx, req.N)
+		return FactorizeResponse{Status: v, Err: err}, nil
+	}
+}
+
+// MakeJobStatusEndpoint constructs a JobStatus endpoint wrapping the service.
+func MakeJobStatusEndpoint(s addservice.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		req := request.(JobStatusRequest)
+		v, err := s.JobStatus(ctx, req.JobID)
+		return JobStatusResponse{Status: v, Err: err}, nil
+	}
+}
+
+// MakeCancelJobEndpoint constructs a CancelJob endpoint wrapping the service.
+func MakeCancelJobEndpoint(s addservice.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		req := request.(CancelJobRequest)
+		err = s.CancelJob(ctx, req.JobID)
+		return CancelJobResponse{Err: err}, nil
+	}
+}
+
+// MakeListFlaggedEndpoint constructs a ListFlagged endpoint wrapping the service.
+func MakeListFlaggedEndpoint(s addservice.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		v, err := s.ListFlagged(ctx)
+		return ListFlaggedResponse{Flagged: v, Err: err}, nil
+	}
+}
+
+// MakeApproveFlaggedEndpoint constructs a ApproveFlagged endpoint wrapping the service.
+func MakeApproveFlaggedEndpoint(s addservice.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		req := request.(ApproveFlaggedRequest)
+		err = s.ApproveFlagged(ctx, req.TaskID)
+		return ApproveFlaggedResponse{Err: err}, nil
+	}
+}
+
+// MakeRejectFlaggedEndpoint constructs a RejectFlagged endpoint wrapping the service.
+func MakeRejectFlaggedEndpoint(s addservice.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		req := request.(RejectFlaggedRequest)
+		err = s.RejectFlagged(ctx, req.TaskID)
+		return RejectFlaggedResponse{Err: err}, nil
 	}
 }
 
 // compile time assertions for our response types implements endpoint.Failer.
 var (
 	_ endpoint.Failer = SumResponse{}
+	_ endpoint.Failer = MultiplyResponse{}
+	_ endpoint.Failer = DivideResponse{}
 	_ endpoint.Failer = ConcatResponse{}
 	_ endpoint.Failer = PingResponse{}
 	_ endpoint.Failer = AddToDoResponse{}
 	_ endpoint.Failer = CompleteToDoResponse{}
 	_ endpoint.Failer = UnDoToDoResponse{}
+	_ endpoint.Failer = UpdateToDoResponse{}
+	_ endpoint.Failer = GetToDoByIDResponse{}
 	_ endpoint.Failer = DeleteToDoResponse{}
+	_ endpoint.Failer = BatchToDoResponse{}
 	_ endpoint.Failer = GetAllToDoResponse{}
+	_ endpoint.Failer = ViewResponse{}
+	_ endpoint.Failer = SaveViewResponse{}
+	_ endpoint.Failer = ListViewsResponse{}
+	_ endpoint.Failer = DeleteViewResponse{}
+	_ endpoint.Failer = CreateWebhookResponse{}
+	_ endpoint.Failer = DeleteWebhookResponse{}
+	_ endpoint.Failer = GetFieldSchemaResponse{}
+	_ endpoint.Failer = SaveFieldSchemaResponse{}
+	_ endpoint.Failer = GetWorkflowResponse{}
+	_ endpoint.Failer = SaveWorkflowResponse{}
+	_ endpoint.Failer = AddDependencyResponse{}
+	_ endpoint.Failer = RemoveDependencyResponse{}
+	_ endpoint.Failer = SimilarToDoResponse{}
+	_ endpoint.Failer = SearchToDoResponse{}
+	_ endpoint.Failer = CompletionStatsResponse{}
+	_ endpoint.Failer = WatchToDosResponse{}
+	_ endpoint.Failer = FactorizeResponse{}
+	_ endpoint.Failer = JobStatusResponse{}
+	_ endpoint.Failer = CancelJobResponse{}
+	_ endpoint.Failer = ListFlaggedResponse{}
+	_ endpoint.Failer = ApproveFlaggedResponse{}
+	_ endpoint.Failer = RejectFlaggedResponse{}
 )
 
 // SumRequest collects the request parameters for the Sum method.
@@ -360,6 +1580,34 @@ type SumResponse struct {
 // Failed implements endpoint.Failer.
 func (r SumResponse) Failed() error { return r.Err }
 
+// MultiplyRequest collects the request parameters for the Multiply method.
+type MultiplyRequest struct {
+	A, B int
+}
+
+// MultiplyResponse collects the response values for the Multiply method.
+type MultiplyResponse struct {
+	V   int   `json:"v"`
+	Err error `json:"-"` // should be intercepted by Failed/errorEncoder
+}
+
+// Failed implements endpoint.Failer.
+func (r MultiplyResponse) Failed() error { return r.Err }
+
+// DivideRequest collects the request parameters for the Divide method.
+type DivideRequest struct {
+	A, B int
+}
+
+// DivideResponse collects the response values for the Divide method.
+type DivideResponse struct {
+	V   int   `json:"v"`
+	Err error `json:"-"` // should be intercepted by Failed/errorEncoder
+}
+
+// Failed implements endpoint.Failer.
+func (r DivideResponse) Failed() error { return r.Err }
+
 // ConcatRequest collects the request parameters for the Concat method.
 type ConcatRequest struct {
 	A, B string
@@ -387,13 +1635,18 @@ type PingResponse struct {
 // Failed implements endpoint.Failer.
 func (r PingResponse) Failed() error { return r.Err }
 
-// AddToDo collect request parameters for the AddTodo method
-type AddToDoRequest = models.ToDoItem
+// AddToDoRequest collect request parameters for the AddTodo method.
+// CheckSimilar asks for near-duplicates of the task to be returned.
+type AddToDoRequest struct {
+	models.ToDoItem
+	CheckSimilar bool `json:"checkSimilar,omitempty"`
+}
 
 // AddToDoResponse collects the response values for the AddToDo method.
 type AddToDoResponse struct {
-	TaskID string `json:"taskID"`
-	Err    error  `json:"-"` // should be intercepted by Failed/errEncoder
+	TaskID  models.TaskID     `json:"taskID"`
+	Similar []models.ToDoItem `json:"similar,omitempty"`
+	Err     error             `json:"-"` // should be intercepted by Failed/errEncoder
 }
 
 // Failed implements endpoint.Failer.
@@ -401,13 +1654,13 @@ func (r AddToDoResponse) Failed() error { return r.Err }
 
 // CompleteToDoRequest collect request parameters for the CompleteToDo method
 type CompleteToDoRequest struct {
-	TaskID string `json:"taskID"`
+	TaskID models.TaskID `json:"taskID"`
 }
 
 // CompleteToDoResponse collects the response values for the CompleteToDo method.
 type CompleteToDoResponse struct {
-	TaskID string `json:"taskID"`
-	Err    error  `json:"-"` // should be intercepted by Failed/errEncoder
+	TaskID models.TaskID `json:"taskID"`
+	Err    error         `json:"-"` // should be intercepted by Failed/errEncoder
 }
 
 // Failed implements endpoint.Failer.
@@ -415,40 +1668,397 @@ func (r CompleteToDoResponse) Failed() error { return r.Err }
 
 // UnDoToDoRequest collect request parameters for the UnDoToDoRequest method
 type UnDoToDoRequest struct {
-	TaskID string `json:"taskID"`
+	TaskID models.TaskID `json:"taskID"`
 }
 
 // UnDoToDoResponse collects the response values for the UnDoToDoResponse method.
 type UnDoToDoResponse struct {
-	TaskID string `json:"taskID"`
-	Err    error  `json:"-"` // should be intercepted by Failed/errEncoder
+	TaskID models.TaskID `json:"taskID"`
+	Err    error         `json:"-"` // should be intercepted by Failed/errEncoder
 }
 
 // Failed implements endpoint.Failer.
 func (r UnDoToDoResponse) Failed() error { return r.Err }
 
+// UpdateToDoRequest collect request parameters for the UpdateToDo method
+type UpdateToDoRequest struct {
+	TaskID models.TaskID `json:"taskID"`
+	models.ToDoUpdate
+}
+
+// UpdateToDoResponse collects the response values for the UpdateToDo method.
+type UpdateToDoResponse struct {
+	TaskID models.TaskID `json:"taskID"`
+	Err    error         `json:"-"` // should be intercepted by Failed/errEncoder
+}
+
+// Failed implements endpoint.Failer.
+func (r UpdateToDoResponse) Failed() error { return r.Err }
+
 // DeleteDoRequest collect request parameters for the DeleteDoRequest method
 type DeleteToDoRequest struct {
-	TaskID string `json:"taskID"`
+	TaskID models.TaskID `json:"taskID"`
 }
 
 // DeleteToDoResponse collects the response values for the DeleteToDoResponse method.
 type DeleteToDoResponse struct {
-	TaskID string `json:"taskID"`
-	Err    error  `json:"-"` // should be intercepted by Failed/errEncoder
+	TaskID models.TaskID `json:"taskID"`
+	Err    error         `json:"-"` // should be intercepted by Failed/errEncoder
 }
 
 // Failed implements endpoint.Failer.
 func (r DeleteToDoResponse) Failed() error { return r.Err }
 
-// GetAllToDoRequest collect request parameters for the GetAllToDoRequest method
-type GetAllToDoRequest struct{}
+// BatchToDoRequest collects the request parameters for the BatchToDo method.
+type BatchToDoRequest struct {
+	Action models.BatchAction `json:"action"`
+	IDs    []models.TaskID    `json:"ids"`
+}
+
+// BatchToDoResponse collects the response values for the BatchToDo method.
+type BatchToDoResponse struct {
+	models.BatchResult
+	Err error `json:"-"` // should be intercepted by Failed/errEncoder
+}
+
+// Failed implements endpoint.Failer.
+func (r BatchToDoResponse) Failed() error { return r.Err }
+
+// GetToDoByIDRequest collect request parameters for the GetToDoByID method
+type GetToDoByIDRequest struct {
+	TaskID models.TaskID `json:"taskID"`
+}
+
+// GetToDoByIDResponse collects the response values for the GetToDoByID method.
+type GetToDoByIDResponse struct {
+	ToDo models.ToDoItem `json:"todo"`
+	Err  error           `json:"-"` // should be intercepted by Failed/errEncoder
+}
+
+// Failed implements endpoint.Failer.
+func (r GetToDoByIDResponse) Failed() error { return r.Err }
+
+// GetAllToDoRequest collect request parameters for the GetAllToDoRequest method.
+type GetAllToDoRequest struct {
+	models.ListOptions
+}
 
 // GetAllToDoResponse collects the response values for the GetAllToDoResponse method.
 type GetAllToDoResponse struct {
+	models.ToDoPage
+	Err error `json:"-"` // should be intercepted by Failed/errEncoder
+}
+
+// Failed implements endpoint.Failer.
+func (r GetAllToDoResponse) Failed() error { return r.Err }
+
+// SimilarToDoRequest collect request parameters for the SimilarToDo method
+type SimilarToDoRequest struct {
+	Task string `json:"task"`
+}
+
+// SimilarToDoResponse collects the response values for the SimilarToDo method.
+type SimilarToDoResponse struct {
 	Todos []models.ToDoItem `json:"todos"`
 	Err   error             `json:"-"` // should be intercepted by Failed/errEncoder
 }
 
 // Failed implements endpoint.Failer.
-func (r GetAllToDoResponse) Failed() error { return r.Err }
+func (r SimilarToDoResponse) Failed() error { return r.Err }
+
+// SearchToDoRequest collects the request parameters for the SearchToDo method.
+type SearchToDoRequest struct {
+	Query string `json:"q"`
+}
+
+// SearchToDoResponse collects the response values for the SearchToDo method.
+type SearchToDoResponse struct {
+	Results []models.SearchResult `json:"results"`
+	Err     error                 `json:"-"` // should be intercepted by Failed/errEncoder
+}
+
+// Failed implements endpoint.Failer.
+func (r SearchToDoResponse) Failed() error { return r.Err }
+
+// CompletionStatsRequest collects the request parameters for the
+// CompletionStats method.
+type CompletionStatsRequest struct{}
+
+// CompletionStatsResponse collects the response values for the
+// CompletionStats method.
+type CompletionStatsResponse struct {
+	Stats models.CompletionStats `json:"stats"`
+	Err   error                  `json:"-"`
+}
+
+// Failed implements endpoint.Failer.
+func (r CompletionStatsResponse) Failed() error { return r.Err }
+
+// WatchToDosRequest collects the request parameters for the WatchToDos
+// method.
+type WatchToDosRequest struct{}
+
+// WatchToDosResponse collects the response values for the WatchToDos method.
+// The changes are streamed, not encoded as a whole.
+type WatchToDosResponse struct {
+	Changes <-chan models.ToDoEvent `json:"-"`
+	Err     error                   `json:"-"`
+}
+
+// Failed implements endpoint.Failer.
+func (r WatchToDosResponse) Failed() error { return r.Err }
+
+// ViewRequest collects the request parameters for the View method.
+type ViewRequest struct {
+	Name string `json:"name"`
+}
+
+// ViewResponse collects the response values for the View method.
+type ViewResponse struct {
+	Todos []models.ToDoItem `json:"todos"`
+	Err   error             `json:"-"`
+}
+
+// Failed implements endpoint.Failer.
+func (r ViewResponse) Failed() error { return r.Err }
+
+// SaveViewRequest collects the request parameters for the SaveView method.
+type SaveViewRequest struct {
+	views.View
+}
+
+// SaveViewResponse collects the response values for the SaveView method.
+type SaveViewResponse struct {
+	Err error `json:"-"`
+}
+
+// Failed implements endpoint.Failer.
+func (r SaveViewResponse) Failed() error { return r.Err }
+
+// ListViewsRequest collects the request parameters for the ListViews method.
+type ListViewsRequest struct{}
+
+// ListViewsResponse collects the response values for the ListViews method.
+type ListViewsResponse struct {
+	Views []views.View `json:"views"`
+	Err   error        `json:"-"`
+}
+
+// Failed implements endpoint.Failer.
+func (r ListViewsResponse) Failed() error { return r.Err }
+
+// DeleteViewRequest collects the request parameters for the DeleteView method.
+type DeleteViewRequest struct {
+	Name string `json:"name"`
+}
+
+// DeleteViewResponse collects the response values for the DeleteView method.
+type DeleteViewResponse struct {
+	Err error `json:"-"`
+}
+
+// Failed implements endpoint.Failer.
+func (r DeleteViewResponse) Failed() error { return r.Err }
+
+// CreateWebhookRequest collects the request parameters for the CreateWebhook
+// method.
+type CreateWebhookRequest struct {
+	webhooks.Subscription
+}
+
+// CreateWebhookResponse collects the response values for the CreateWebhook
+// method.
+type CreateWebhookResponse struct {
+	Webhook webhooks.Subscription `json:"webhook"`
+	Err     error                 `json:"-"`
+}
+
+// Failed implements endpoint.Failer.
+func (r CreateWebhookResponse) Failed() error { return r.Err }
+
+// DeleteWebhookRequest collects the request parameters for the DeleteWebhook
+// method.
+type DeleteWebhookRequest struct {
+	ID string `json:"id"`
+}
+
+// DeleteWebhookResponse collects the response values for the DeleteWebhook
+// method.
+type DeleteWebhookResponse struct {
+	Err error `json:"-"`
+}
+
+// Failed implements endpoint.Failer.
+func (r DeleteWebhookResponse) Failed() error { return r.Err }
+
+// GetFieldSchemaRequest collects the request parameters for the
+// GetFieldSchema method.
+type GetFieldSchemaRequest struct{}
+
+// GetFieldSchemaResponse collects the response values for the
+// GetFieldSchema method.
+type GetFieldSchemaResponse struct {
+	Schema fields.Schema `json:"schema"`
+	Err    error         `json:"-"`
+}
+
+// Failed implements endpoint.Failer.
+func (r GetFieldSchemaResponse) Failed() error { return r.Err }
+
+// SaveFieldSchemaRequest collects the request parameters for the
+// SaveFieldSchema method.
+type SaveFieldSchemaRequest struct {
+	fields.Schema
+}
+
+// SaveFieldSchemaResponse collects the response values for the
+// SaveFieldSchema method.
+type SaveFieldSchemaResponse struct {
+	Schema fields.Schema `json:"schema"`
+	Err    error         `json:"-"`
+}
+
+// Failed implements endpoint.Failer.
+func (r SaveFieldSchemaResponse) Failed() error { return r.Err }
+
+// GetWorkflowRequest collects the request parameters for the
+// GetWorkflow method.
+type GetWorkflowRequest struct{}
+
+// GetWorkflowResponse collects the response values for the
+// GetWorkflow method.
+type GetWorkflowResponse struct {
+	Workflow workflow.Workflow `json:"workflow"`
+	Err      error             `json:"-"`
+}
+
+// Failed implements endpoint.Failer.
+func (r GetWorkflowResponse) Failed() error { return r.Err }
+
+// SaveWorkflowRequest collects the request parameters for the
+// SaveWorkflow method.
+type SaveWorkflowRequest struct {
+	workflow.Workflow
+}
+
+// SaveWorkflowResponse collects the response values for the
+// SaveWorkflow method.
+type SaveWorkflowResponse struct {
+	Workflow workflow.Workflow `json:"workflow"`
+	Err      error             `json:"-"`
+}
+
+// Failed implements endpoint.Failer.
+func (r SaveWorkflowResponse) Failed() error { return r.Err }
+
+// AddDependencyRequest collects the request parameters for the
+// AddDependency method.
+type AddDependencyRequest struct {
+	TaskID    models.TaskID `json:"taskID"`
+	BlockedBy models.TaskID `json:"blockedBy"`
+}
+
+// AddDependencyResponse collects the response values for the
+// AddDependency method.
+type AddDependencyResponse struct {
+	Err error `json:"-"`
+}
+
+// Failed implements endpoint.Failer.
+func (r AddDependencyResponse) Failed() error { return r.Err }
+
+// RemoveDependencyRequest collects the request parameters for the
+// RemoveDependency method.
+type RemoveDependencyRequest struct {
+	TaskID    models.TaskID `json:"taskID"`
+	BlockedBy models.TaskID `json:"blockedBy"`
+}
+
+// RemoveDependencyResponse collects the response values for the
+// RemoveDependency method.
+type RemoveDependencyResponse struct {
+	Err error `json:"-"`
+}
+
+// Failed implements endpoint.Failer.
+func (r RemoveDependencyResponse) Failed() error { return r.Err }
+
+// FactorizeRequest collects the request parameters for the Factorize method.
+type FactorizeRequest struct {
+	N int64 `json:"n"`
+}
+
+// FactorizeResponse collects the response values for the Factorize method,
+// the job computing the factors.
+type FactorizeResponse struct {
+	jobs.Status
+	Err error `json:"-"`
+}
+
+// Failed implements endpoint.Failer.
+func (r FactorizeResponse) Failed() error { return r.Err }
+
+// JobStatusRequest collects the request parameters for the JobStatus method.
+type JobStatusRequest struct {
+	JobID string `json:"jobID"`
+}
+
+// JobStatusResponse collects the response values for the JobStatus method.
+type JobStatusResponse struct {
+	jobs.Status
+	Err error `json:"-"`
+}
+
+// Failed implements endpoint.Failer.
+func (r JobStatusResponse) Failed() error { return r.Err }
+
+// CancelJobRequest collects the request parameters for the CancelJob method.
+type CancelJobRequest struct {
+	JobID string `json:"jobID"`
+}
+
+// CancelJobResponse collects the response values for the CancelJob method.
+type CancelJobResponse struct {
+	Err error `json:"-"`
+}
+
+// Failed implements endpoint.Failer.
+func (r CancelJobResponse) Failed() error { return r.Err }
+
+// ListFlaggedRequest collects the request parameters for the ListFlagged method.
+type ListFlaggedRequest struct{}
+
+// ListFlaggedResponse collects the response values for the ListFlagged method.
+type ListFlaggedResponse struct {
+	Flagged []moderation.Event `json:"flagged"`
+	Err     error              `json:"-"`
+}
+
+// Failed implements endpoint.Failer.
+func (r ListFlaggedResponse) Failed() error { return r.Err }
+
+// ApproveFlaggedRequest collects the request parameters for the ApproveFlagged method.
+type ApproveFlaggedRequest struct {
+	TaskID models.TaskID `json:"taskID"`
+}
+
+// ApproveFlaggedResponse collects the response values for the ApproveFlagged method.
+type ApproveFlaggedResponse struct {
+	Err error `json:"-"`
+}
+
+// Failed implements endpoint.Failer.
+func (r ApproveFlaggedResponse) Failed() error { return r.Err }
+
+// RejectFlaggedRequest collects the request parameters for the RejectFlagged method.
+type RejectFlaggedRequest struct {
+	TaskID models.TaskID `json:"taskID"`
+}
+
+// RejectFlaggedResponse collects the response values for the RejectFlagged method.
+type RejectFlaggedResponse struct {
+	Err error `json:"-"`
+}
+
+// Failed implements endpoint.Failer.
+func (r RejectFlaggedResponse) Failed() error { return r.Err }
@@ -0,0 +1,44 @@
+package addendpoint
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/go-kit/kit/ratelimit"
+)
+
+type trustedCallerKey struct{}
+
+// WithTrustedCaller returns a copy of ctx marking its requests as coming from
+// a trusted caller, like monitoring probes. Their requests bypass the rate
+// limiters and circuit breakers, and don't count toward them, so synthetic
+// checks neither get throttled nor trip a breaker. Transports are
+// responsible for establishing the trust.
+func WithTrustedCaller(ctx context.Context) context.Context {
+	return context.WithValue(ctx, trustedCallerKey{}, true)
+}
+
+// TrustedCaller reports whether ctx was marked by WithTrustedCaller.
+func TrustedCaller(ctx context.Context) bool {
+	trusted, _ := ctx.Value(trustedCallerKey{}).(bool)
+	return trusted
+}
+
+// limit rejects the requests limiter doesn't allow with ratelimit.ErrLimited,
+// except for trusted callers.
+func limit(limiter Limiter) endpoint.Middleware {
+	limited := ratelimit.NewErroringLimiter(limiter)
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return bypassForTrusted(limited(next), next)
+	}
+}
+
+// bypassForTrusted calls protected, or next for trusted callers.
+func bypassForTrusted(protected, next endpoint.Endpoint) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		if TrustedCaller(ctx) {
+			return next(ctx, request)
+		}
+		return protected(ctx, request)
+	}
+}
@@ -0,0 +1,82 @@
+package addservice
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kit/kit/log"
+
+	"ray.vhatt/todo-gokit/pkg/analytics"
+	"ray.vhatt/todo-gokit/pkg/models"
+)
+
+// AnalyticsMiddleware tracks the todos created and completed through next,
+// one by one or in batches. Completions carry the seconds since the todo
+// was created. Tracking never fails a request: the errors of the sink are
+// logged to logger.
+func AnalyticsMiddleware(tracker *analytics.Tracker, logger log.Logger) Middleware {
+	return func(next Service) Service {
+		return analyticsMiddleware{Service: next, tracker: tracker, logger: logger}
+	}
+}
+
+type analyticsMiddleware struct {
+	Service
+	tracker *analytics.Tracker
+	logger  log.Logger
+}
+
+func (mw analyticsMiddleware) AddToDo(ctx context.Context, task models.ToDoItem) (models.TaskID, error) {
+	id, err := mw.Service.AddToDo(ctx, task)
+	if err == nil {
+		mw.track(ctx, analytics.TaskCreated, id, nil)
+	}
+	return id, err
+}
+
+func (mw analyticsMiddleware) CompleteToDo(ctx context.Context, taskID models.TaskID) (models.TaskID, error) {
+	id, err := mw.Service.CompleteToDo(ctx, taskID)
+	if err == nil {
+		mw.completed(ctx, id)
+	}
+	return id, err
+}
+
+func (mw analyticsMiddleware) UpdateToDo(ctx context.Context, taskID models.TaskID, updates models.ToDoUpdate) (models.TaskID, error) {
+	id, err := mw.Service.UpdateToDo(ctx, taskID, updates)
+	if err == nil && updates.Status != nil && *updates.Status {
+		mw.completed(ctx, id)
+	}
+	return id, err
+}
+
+// BatchToDo tracks the completion of each of the todos the batch found
+// pending and completed.
+func (mw analyticsMiddleware) BatchToDo(ctx context.Context, action models.BatchAction, ids []models.TaskID) (models.BatchResult, error) {
+	if action != models.BatchComplete {
+		return mw.Service.BatchToDo(ctx, action, ids)
+	}
+	found := lookup(ctx, mw.Service, ids...)
+	result, err := mw.Service.BatchToDo(ctx, action, ids)
+	if err != nil {
+		return result, err
+	}
+	for _, todo := range actedOn(ids, found, result) {
+		if !todo.Status {
+			mw.completed(ctx, todo.ID)
+		}
+	}
+	return result, err
+}
+
+func (mw analyticsMiddleware) completed(ctx context.Context, taskID models.TaskID) {
+	mw.track(ctx, analytics.TaskCompleted, taskID, map[string]interface{}{
+		"secondsToComplete": int64(time.Since(taskID.Time()) / time.Second),
+	})
+}
+
+func (mw analyticsMiddleware) track(ctx context.Context, name string, taskID models.TaskID, properties map[string]interface{}) {
+	if err := mw.tracker.Track(ctx, name, taskID, properties); err != nil {
+		mw.logger.Log("analytics", name, "taskID", taskID, "err", err)
+	}
+}
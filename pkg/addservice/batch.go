@@ -0,0 +1,38 @@
+package addservice
+
+import (
+	"context"
+
+	"ray.vhatt/todo-gokit/pkg/models"
+)
+
+// lookup returns the todos of ids found through svc, looked up before a
+// batch acts on them: its result doesn't tell the todos missing apart from
+// those it acted on.
+func lookup(ctx context.Context, svc Service, ids ...models.TaskID) map[models.TaskID]models.ToDoItem {
+	found := make(map[models.TaskID]models.ToDoItem, len(ids))
+	for _, id := range ids {
+		if todo, err := svc.GetToDoByID(ctx, id); err == nil {
+			found[id] = todo
+		}
+	}
+	return found
+}
+
+// actedOn returns those of the todos found before a batch on ids that its
+// result doesn't report failed, as they were before, in the order of ids
+// and once each.
+func actedOn(ids []models.TaskID, found map[models.TaskID]models.ToDoItem, result models.BatchResult) []models.ToDoItem {
+	failed := make(map[models.TaskID]bool, len(result.Failures))
+	for _, f := range result.Failures {
+		failed[f.ID] = true
+	}
+	var todos []models.ToDoItem
+	for _, id := range ids {
+		if todo, ok := found[id]; ok && !failed[id] {
+			failed[id] = true
+			todos = append(todos, todo)
+		}
+	}
+	return todos
+}
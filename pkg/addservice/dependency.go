@@ -0,0 +1,101 @@
+package addservice
+
+import (
+	"context"
+	"time"
+
+	"ray.vhatt/todo-gokit/pkg/dependency"
+	"ray.vhatt/todo-gokit/pkg/models"
+	"ray.vhatt/todo-gokit/pkg/tenant"
+)
+
+// AddDependency makes the todo taskID blocked by the todo blockedBy, until
+// that one is done. Both must exist, and the link mustn't make a cycle.
+// Adding a link twice keeps one.
+func (s basicService) AddDependency(ctx context.Context, taskID, blockedBy models.TaskID) error {
+	for _, id := range []models.TaskID{taskID, blockedBy} {
+		if _, err := s.dbStore.FindByID(ctx, id); err != nil {
+			return err
+		}
+	}
+	t := tenant.FromContext(ctx)
+	links, err := s.dependencies.List(ctx, t)
+	if err != nil {
+		return err
+	}
+	l := dependency.Link{Tenant: t, Blocked: taskID, Blocker: blockedBy, Created: time.Now().UTC()}
+	if err := dependency.CheckCycle(links, l); err != nil {
+		return err
+	}
+	return s.dependencies.Add(ctx, l)
+}
+
+// RemoveDependency removes the link making the todo taskID blocked by the
+// todo blockedBy.
+func (s basicService) RemoveDependency(ctx context.Context, taskID, blockedBy models.TaskID) error {
+	return s.dependencies.Remove(ctx, tenant.FromContext(ctx), taskID, blockedBy)
+}
+
+// dependenciesOf returns the todos the todo taskID is blocked by and those
+// it blocks, nil if none. The todos deleted behind the back of the service
+// are left out.
+func (s basicService) dependenciesOf(ctx context.Context, taskID models.TaskID) (*models.Dependencies, error) {
+	links, err := s.dependencies.Of(ctx, tenant.FromContext(ctx), taskID)
+	if err != nil || len(links) == 0 {
+		return nil, err
+	}
+	deps := models.Dependencies{BlockedBy: []models.TaskID{}, Blocks: []models.TaskID{}}
+	for _, l := range links {
+		if l.Blocker == taskID {
+			deps.Blocks = append(deps.Blocks, l.Blocked)
+			continue
+		}
+		blocker, err := s.dbStore.FindByID(ctx, l.Blocker)
+		if err != nil {
+			continue
+		}
+		deps.BlockedBy = append(deps.BlockedBy, l.Blocker)
+		if !blocker.Status {
+			deps.Blocked = true
+		}
+	}
+	return &deps, nil
+}
+
+// blockers returns those of the todos ids found pending that block others,
+// looked up through svc before they may be completed.
+func blockers(ctx context.Context, svc Service, ids ...models.TaskID) []models.TaskID {
+	var blocking []models.TaskID
+	for _, id := range ids {
+		todo, err := svc.GetToDoByID(ctx, id)
+		if err == nil && !todo.Status && todo.Dependencies != nil && len(todo.Dependencies.Blocks) > 0 {
+			blocking = append(blocking, id)
+		}
+	}
+	return blocking
+}
+
+// unblocked returns the pending todos no longer blocked now that those of
+// blocking done are, once each.
+func unblocked(ctx context.Context, svc Service, blocking []models.TaskID) []models.TaskID {
+	seen := make(map[models.TaskID]bool)
+	var ids []models.TaskID
+	for _, id := range blocking {
+		blocker, err := svc.GetToDoByID(ctx, id)
+		if err != nil || !blocker.Status || blocker.Dependencies == nil {
+			continue
+		}
+		for _, blockedID := range blocker.Dependencies.Blocks {
+			if seen[blockedID] {
+				continue
+			}
+			seen[blockedID] = true
+			todo, err := svc.GetToDoByID(ctx, blockedID)
+			if err != nil || todo.Status || (todo.Dependencies != nil && todo.Dependencies.Blocked) {
+				continue
+			}
+			ids = append(ids, blockedID)
+		}
+	}
+	return ids
+}
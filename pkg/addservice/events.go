@@ -0,0 +1,179 @@
+package addservice
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/log"
+
+	"ray.vhatt/todo-gokit/pkg/events"
+	"ray.vhatt/todo-gokit/pkg/models"
+	"ray.vhatt/todo-gokit/pkg/tenant"
+)
+
+// EventsMiddleware publishes an event to publisher for each todo created,
+// updated, completed, reopened, transitioned to another state or deleted
+// through next, and for each todo unblocked by the completion of the last
+// todo it was blocked by. Publishing never fails a request: the errors are
+// logged to logger.
+func EventsMiddleware(publisher events.Publisher, logger log.Logger) Middleware {
+	return func(next Service) Service {
+		return eventsMiddleware{Service: next, publisher: publisher, logger: logger}
+	}
+}
+
+type eventsMiddleware struct {
+	Service
+	publisher events.Publisher
+	logger    log.Logger
+}
+
+func (mw eventsMiddleware) AddToDo(ctx context.Context, task models.ToDoItem) (models.TaskID, error) {
+	id, err := mw.Service.AddToDo(ctx, task)
+	if err == nil {
+		mw.publish(ctx, events.ToDoCreated, id, true)
+	}
+	return id, err
+}
+
+func (mw eventsMiddleware) CompleteToDo(ctx context.Context, taskID models.TaskID) (models.TaskID, error) {
+	states := mw.states(ctx, taskID)
+	blocking := blockers(ctx, mw.Service, taskID)
+	id, err := mw.Service.CompleteToDo(ctx, taskID)
+	if err == nil {
+		mw.publish(ctx, events.ToDoCompleted, id, false)
+		mw.transitioned(ctx, states)
+		mw.unblocked(ctx, blocking)
+	}
+	return id, err
+}
+
+func (mw eventsMiddleware) UnDoToDo(ctx context.Context, taskID models.TaskID) (models.TaskID, error) {
+	states := mw.states(ctx, taskID)
+	id, err := mw.Service.UnDoToDo(ctx, taskID)
+	if err == nil {
+		mw.publish(ctx, events.ToDoReopened, id, false)
+		mw.transitioned(ctx, states)
+	}
+	return id, err
+}
+
+func (mw eventsMiddleware) UpdateToDo(ctx context.Context, taskID models.TaskID, updates models.ToDoUpdate) (models.TaskID, error) {
+	var states map[models.TaskID]string
+	var blocking []models.TaskID
+	if updates.State != nil || updates.Status != nil {
+		states = mw.states(ctx, taskID)
+		blocking = blockers(ctx, mw.Service, taskID)
+	}
+	id, err := mw.Service.UpdateToDo(ctx, taskID, updates)
+	if err != nil {
+		return id, err
+	}
+	mw.publish(ctx, events.ToDoUpdated, id, true)
+	if updates.Status != nil {
+		if *updates.Status {
+			mw.publish(ctx, events.ToDoCompleted, id, false)
+		} else {
+			mw.publish(ctx, events.ToDoReopened, id, false)
+		}
+	}
+	mw.transitioned(ctx, states)
+	mw.unblocked(ctx, blocking)
+	return id, err
+}
+
+func (mw eventsMiddleware) DeleteToDo(ctx context.Context, taskID models.TaskID) (models.TaskID, error) {
+	id, err := mw.Service.DeleteToDo(ctx, taskID)
+	if err == nil {
+		mw.publish(ctx, events.ToDoDeleted, id, false)
+	}
+	return id, err
+}
+
+var batchEvents = map[models.BatchAction]string{
+	models.BatchComplete: events.ToDoCompleted,
+	models.BatchUndo:     events.ToDoReopened,
+	models.BatchDelete:   events.ToDoDeleted,
+}
+
+// BatchToDo publishes an event per todo acted on. The batch doesn't tell the
+// missing todos apart, so those found are looked up before it, along with
+// their states.
+func (mw eventsMiddleware) BatchToDo(ctx context.Context, action models.BatchAction, ids []models.TaskID) (models.BatchResult, error) {
+	var states map[models.TaskID]string
+	var blocking []models.TaskID
+	if action.Validate() == nil {
+		states = mw.states(ctx, ids...)
+	}
+	if action == models.BatchComplete {
+		blocking = blockers(ctx, mw.Service, ids...)
+	}
+	result, err := mw.Service.BatchToDo(ctx, action, ids)
+	if err != nil {
+		return result, err
+	}
+	for _, f := range result.Failures {
+		delete(states, f.ID)
+	}
+	acted := make(map[models.TaskID]string, len(states))
+	for _, id := range ids {
+		if state, ok := states[id]; ok {
+			delete(states, id)
+			acted[id] = state
+			mw.publish(ctx, batchEvents[action], id, false)
+		}
+	}
+	if action != models.BatchDelete {
+		mw.transitioned(ctx, acted)
+	}
+	mw.unblocked(ctx, blocking)
+	return result, err
+}
+
+// states returns the states of the todos of ids found.
+func (mw eventsMiddleware) states(ctx context.Context, ids ...models.TaskID) map[models.TaskID]string {
+	states := make(map[models.TaskID]string, len(ids))
+	for _, id := range ids {
+		if todo, err := mw.Service.GetToDoByID(ctx, id); err == nil {
+			states[id] = todo.State
+		}
+	}
+	return states
+}
+
+// transitioned publishes an event about each of the todos whose state
+// changed since it was in before.
+func (mw eventsMiddleware) transitioned(ctx context.Context, before map[models.TaskID]string) {
+	for id, from := range before {
+		todo, err := mw.Service.GetToDoByID(ctx, id)
+		if err != nil || todo.State == from {
+			continue
+		}
+		e := events.New(events.ToDoTransitioned, id, tenant.FromContext(ctx))
+		e.Transition = &events.Transition{From: from, To: todo.State}
+		if err := mw.publisher.Publish(ctx, e); err != nil {
+			mw.logger.Log("event", e.Type, "taskID", id, "err", err)
+		}
+	}
+}
+
+// unblocked publishes an event about each of the todos no longer blocked
+// now that those of blocking done are.
+func (mw eventsMiddleware) unblocked(ctx context.Context, blocking []models.TaskID) {
+	for _, id := range unblocked(ctx, mw.Service, blocking) {
+		mw.publish(ctx, events.ToDoUnblocked, id, true)
+	}
+}
+
+// publish publishes an event of eventType about taskID, carrying the todo
+// as it now is if withTodo.
+func (mw eventsMiddleware) publish(ctx context.Context, eventType string, taskID models.TaskID, withTodo bool) {
+	e := events.New(eventType, taskID, tenant.FromContext(ctx))
+	if withTodo {
+		if todo, err := mw.Service.GetToDoByID(ctx, taskID); err == nil {
+			e.Todo = &todo
+		}
+	}
+	if err := mw.publisher.Publish(ctx, e); err != nil {
+		mw.logger.Log("event", eventType, "taskID", taskID, "err", err)
+	}
+}
@@ -0,0 +1,56 @@
+package addservice
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+)
+
+// ErrNotFactorizable protects the Factorize method.
+var ErrNotFactorizable = errors.New("only integers greater than 1 can be factorized")
+
+// factorizeJob is the kind of the jobs run by Factorize.
+const factorizeJob = "factorize"
+
+type factorizeParams struct {
+	N int64 `json:"n"`
+}
+
+// runFactorize is the jobs.Func of factorizeJob.
+func runFactorize(ctx context.Context, params json.RawMessage, progress func(done, total int64)) (interface{}, error) {
+	var p factorizeParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, err
+	}
+	return factorize(ctx, p.N, progress)
+}
+
+// progressEvery is the number of candidate divisors tried between progress
+// reports and cancellation checks.
+const progressEvery = 1 << 20
+
+// factorize returns the prime factors of n, in increasing order. It's a
+// deliberately naive trial division, slow enough on large primes to
+// demonstrate long-running jobs.
+func factorize(ctx context.Context, n int64, progress func(done, total int64)) ([]int64, error) {
+	total := int64(math.Sqrt(float64(n)))
+	var factors []int64
+	for d := int64(2); d <= n/d; d++ {
+		if d%progressEvery == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			progress(d, total)
+		}
+		for n%d == 0 {
+			factors = append(factors, d)
+			n /= d
+		}
+	}
+	if n > 1 {
+		factors = append(factors, n)
+	}
+	progress(total, total)
+	return factors, nil
+}
@@ -0,0 +1,43 @@
+package addservice
+
+import (
+	"context"
+	"time"
+
+	"ray.vhatt/todo-gokit/pkg/fields"
+	"ray.vhatt/todo-gokit/pkg/models"
+	"ray.vhatt/todo-gokit/pkg/tenant"
+)
+
+// GetFieldSchema returns the custom fields of the todos of the tenant of the
+// request.
+func (s basicService) GetFieldSchema(ctx context.Context) (fields.Schema, error) {
+	return s.fields.Get(ctx, tenant.FromContext(ctx))
+}
+
+// SaveFieldSchema replaces the custom fields of the todos of the tenant of
+// the request. The schema is returned as saved.
+func (s basicService) SaveFieldSchema(ctx context.Context, schema fields.Schema) (fields.Schema, error) {
+	if err := schema.Validate(); err != nil {
+		return fields.Schema{}, err
+	}
+	if schema.Fields == nil {
+		schema.Fields = []fields.Field{}
+	}
+	schema.Tenant = tenant.FromContext(ctx)
+	schema.Updated = time.Now().UTC()
+	if err := s.fields.Save(ctx, schema); err != nil {
+		return fields.Schema{}, err
+	}
+	return schema, nil
+}
+
+// customFields checks values against the schema of the tenant of the
+// request, see fields.Schema.Check.
+func (s basicService) customFields(ctx context.Context, values models.CustomFields, partial bool) (models.CustomFields, error) {
+	schema, err := s.fields.Get(ctx, tenant.FromContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	return schema.Check(values, partial)
+}
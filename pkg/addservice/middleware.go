@@ -7,7 +7,13 @@ import (
 
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/metrics"
+	"ray.vhatt/todo-gokit/pkg/fields"
+	"ray.vhatt/todo-gokit/pkg/jobs"
 	"ray.vhatt/todo-gokit/pkg/models"
+	"ray.vhatt/todo-gokit/pkg/moderation"
+	"ray.vhatt/todo-gokit/pkg/views"
+	"ray.vhatt/todo-gokit/pkg/webhooks"
+	"ray.vhatt/todo-gokit/pkg/workflow"
 )
 
 // Middleware describe a service (as opposed to endpoint) middleware.
@@ -41,6 +47,20 @@ func (mw loggingMiddleware) Concat(ctx context.Context, a, b string) (v string,
 	return mw.next.Concat(ctx, a, b)
 }
 
+func (mw loggingMiddleware) Multiply(ctx context.Context, a, b int) (v int, err error) {
+	defer func() {
+		mw.logger.Log("method", "Multiply", "a", a, "b", b, "v", v, "err", err)
+	}()
+	return mw.next.Multiply(ctx, a, b)
+}
+
+func (mw loggingMiddleware) Divide(ctx context.Context, a, b int) (v int, err error) {
+	defer func() {
+		mw.logger.Log("method", "Divide", "a", a, "b", b, "v", v, "err", err)
+	}()
+	return mw.next.Divide(ctx, a, b)
+}
+
 func (mw loggingMiddleware) Ping(ctx context.Context) (v string, err error) {
 	defer func() {
 		mw.logger.Log("method", "Ping", "v", v, "err", err)
@@ -49,7 +69,7 @@ func (mw loggingMiddleware) Ping(ctx context.Context) (v string, err error) {
 	return mw.next.Ping(ctx)
 }
 
-func (mw loggingMiddleware) AddToDo(ctx context.Context, task models.ToDoItem) (v string, err error) {
+func (mw loggingMiddleware) AddToDo(ctx context.Context, task models.ToDoItem) (v models.TaskID, err error) {
 	defer func() {
 		mw.logger.Log("method", "AddToDo", "task", task, "v", v, "err", err)
 	}()
@@ -57,7 +77,7 @@ func (mw loggingMiddleware) AddToDo(ctx context.Context, task models.ToDoItem) (
 	return
 }
 
-func (mw loggingMiddleware) CompleteToDo(ctx context.Context, taskID string) (v string, err error) {
+func (mw loggingMiddleware) CompleteToDo(ctx context.Context, taskID models.TaskID) (v models.TaskID, err error) {
 	defer func() {
 		mw.logger.Log("method", "CompleteTod", "taskID", taskID, "v", v, "err", err)
 	}()
@@ -65,7 +85,7 @@ func (mw loggingMiddleware) CompleteToDo(ctx context.Context, taskID string) (v
 	return
 }
 
-func (mw loggingMiddleware) UnDoToDo(ctx context.Context, taskID string) (v string, err error) {
+func (mw loggingMiddleware) UnDoToDo(ctx context.Context, taskID models.TaskID) (v models.TaskID, err error) {
 	defer func() {
 		mw.logger.Log("method", "UnDoTodo", "taskID", taskID, "v", v, "err", err)
 	}()
@@ -73,7 +93,15 @@ func (mw loggingMiddleware) UnDoToDo(ctx context.Context, taskID string) (v stri
 	return
 }
 
-func (mw loggingMiddleware) DeleteToDo(ctx context.Context, taskID string) (v string, err error) {
+func (mw loggingMiddleware) UpdateToDo(ctx context.Context, taskID models.TaskID, updates models.ToDoUpdate) (v models.TaskID, err error) {
+	defer func() {
+		mw.logger.Log("method", "UpdateToDo", "taskID", taskID, "updates", updates, "v", v, "err", err)
+	}()
+	v, err = mw.next.UpdateToDo(ctx, taskID, updates)
+	return
+}
+
+func (mw loggingMiddleware) DeleteToDo(ctx context.Context, taskID models.TaskID) (v models.TaskID, err error) {
 	defer func() {
 		mw.logger.Log("method", "DeleteToDo", "taskID", taskID, "v", v, "err", err)
 	}()
@@ -81,25 +109,201 @@ func (mw loggingMiddleware) DeleteToDo(ctx context.Context, taskID string) (v st
 	return
 }
 
-func (mw loggingMiddleware) GetAllToDo(ctx context.Context) (results []models.ToDoItem, err error) {
+func (mw loggingMiddleware) BatchToDo(ctx context.Context, action models.BatchAction, ids []models.TaskID) (result models.BatchResult, err error) {
 	defer func() {
-		mw.logger.Log("method", "GetAllToDo", "results", results, "err", err)
+		mw.logger.Log("method", "BatchToDo", "action", action, "ids", len(ids), "matched", result.Matched, "modified", result.Modified, "failures", len(result.Failures), "err", err)
 	}()
-	results, err = mw.next.GetAllToDo(ctx)
+	result, err = mw.next.BatchToDo(ctx, action, ids)
 	return
 }
 
+func (mw loggingMiddleware) GetToDoByID(ctx context.Context, taskID models.TaskID) (todo models.ToDoItem, err error) {
+	defer func() {
+		mw.logger.Log("method", "GetToDoByID", "taskID", taskID, "result", todo, "err", err)
+	}()
+	todo, err = mw.next.GetToDoByID(ctx, taskID)
+	return
+}
+
+func (mw loggingMiddleware) GetAllToDo(ctx context.Context, opts models.ListOptions) (page models.ToDoPage, err error) {
+	defer func() {
+		mw.logger.Log("method", "GetAllToDo", "cursor", opts.Cursor, "scheduled", opts.Scheduled, "query", opts.Query, "status", opts.Status, "sort", opts.Sort, "order", opts.Order, "overdue", opts.Overdue, "dueBefore", opts.DueBefore, "dueAfter", opts.DueAfter, "priority", opts.Priority, "results", page.Todos, "truncated", page.Truncated, "err", err)
+	}()
+	page, err = mw.next.GetAllToDo(ctx, opts)
+	return
+}
+
+func (mw loggingMiddleware) SimilarToDo(ctx context.Context, task string) (results []models.ToDoItem, err error) {
+	defer func() {
+		mw.logger.Log("method", "SimilarToDo", "task", task, "results", results, "err", err)
+	}()
+	results, err = mw.next.SimilarToDo(ctx, task)
+	return
+}
+
+func (mw loggingMiddleware) SearchToDo(ctx context.Context, query string) (results []models.SearchResult, err error) {
+	defer func() {
+		mw.logger.Log("method", "SearchToDo", "query", query, "results", results, "err", err)
+	}()
+	results, err = mw.next.SearchToDo(ctx, query)
+	return
+}
+
+func (mw loggingMiddleware) CompletionStats(ctx context.Context) (stats models.CompletionStats, err error) {
+	defer func() {
+		mw.logger.Log("method", "CompletionStats", "stats", fmt.Sprintf("%+v", stats), "err", err)
+	}()
+	stats, err = mw.next.CompletionStats(ctx)
+	return
+}
+
+func (mw loggingMiddleware) WatchToDos(ctx context.Context) (changes <-chan models.ToDoEvent, err error) {
+	defer func() {
+		mw.logger.Log("method", "WatchToDos", "err", err)
+	}()
+	changes, err = mw.next.WatchToDos(ctx)
+	return
+}
+
+func (mw loggingMiddleware) View(ctx context.Context, name string) (results []models.ToDoItem, err error) {
+	defer func() {
+		mw.logger.Log("method", "View", "name", name, "results", results, "err", err)
+	}()
+	results, err = mw.next.View(ctx, name)
+	return
+}
+
+func (mw loggingMiddleware) SaveView(ctx context.Context, v views.View) (err error) {
+	defer func() {
+		mw.logger.Log("method", "SaveView", "name", v.Name, "err", err)
+	}()
+	return mw.next.SaveView(ctx, v)
+}
+
+func (mw loggingMiddleware) ListViews(ctx context.Context) (results []views.View, err error) {
+	defer func() {
+		mw.logger.Log("method", "ListViews", "results", len(results), "err", err)
+	}()
+	return mw.next.ListViews(ctx)
+}
+
+func (mw loggingMiddleware) DeleteView(ctx context.Context, name string) (err error) {
+	defer func() {
+		mw.logger.Log("method", "DeleteView", "name", name, "err", err)
+	}()
+	return mw.next.DeleteView(ctx, name)
+}
+
+func (mw loggingMiddleware) CreateWebhook(ctx context.Context, sub webhooks.Subscription) (result webhooks.Subscription, err error) {
+	defer func() {
+		mw.logger.Log("method", "CreateWebhook", "url", sub.URL, "events", fmt.Sprint(sub.Events), "id", result.ID, "err", err)
+	}()
+	return mw.next.CreateWebhook(ctx, sub)
+}
+
+func (mw loggingMiddleware) DeleteWebhook(ctx context.Context, id string) (err error) {
+	defer func() {
+		mw.logger.Log("method", "DeleteWebhook", "id", id, "err", err)
+	}()
+	return mw.next.DeleteWebhook(ctx, id)
+}
+
+func (mw loggingMiddleware) GetFieldSchema(ctx context.Context) (schema fields.Schema, err error) {
+	defer func() {
+		mw.logger.Log("method", "GetFieldSchema", "fields", len(schema.Fields), "err", err)
+	}()
+	return mw.next.GetFieldSchema(ctx)
+}
+
+func (mw loggingMiddleware) SaveFieldSchema(ctx context.Context, schema fields.Schema) (result fields.Schema, err error) {
+	defer func() {
+		mw.logger.Log("method", "SaveFieldSchema", "fields", len(schema.Fields), "err", err)
+	}()
+	return mw.next.SaveFieldSchema(ctx, schema)
+}
+
+func (mw loggingMiddleware) GetWorkflow(ctx context.Context) (w workflow.Workflow, err error) {
+	defer func() {
+		mw.logger.Log("method", "GetWorkflow", "states", len(w.States), "err", err)
+	}()
+	return mw.next.GetWorkflow(ctx)
+}
+
+func (mw loggingMiddleware) SaveWorkflow(ctx context.Context, w workflow.Workflow) (result workflow.Workflow, err error) {
+	defer func() {
+		mw.logger.Log("method", "SaveWorkflow", "states", len(w.States), "transitions", len(w.Transitions), "err", err)
+	}()
+	return mw.next.SaveWorkflow(ctx, w)
+}
+
+func (mw loggingMiddleware) AddDependency(ctx context.Context, taskID, blockedBy models.TaskID) (err error) {
+	defer func() {
+		mw.logger.Log("method", "AddDependency", "taskID", taskID, "blockedBy", blockedBy, "err", err)
+	}()
+	return mw.next.AddDependency(ctx, taskID, blockedBy)
+}
+
+func (mw loggingMiddleware) RemoveDependency(ctx context.Context, taskID, blockedBy models.TaskID) (err error) {
+	defer func() {
+		mw.logger.Log("method", "RemoveDependency", "taskID", taskID, "blockedBy", blockedBy, "err", err)
+	}()
+	return mw.next.RemoveDependency(ctx, taskID, blockedBy)
+}
+
+func (mw loggingMiddleware) Factorize(ctx context.Context, n int64) (status jobs.Status, err error) {
+	defer func() {
+		mw.logger.Log("method", "Factorize", "n", n, "jobID", status.ID, "err", err)
+	}()
+	return mw.next.Factorize(ctx, n)
+}
+
+func (mw loggingMiddleware) JobStatus(ctx context.Context, jobID string) (status jobs.Status, err error) {
+	defer func() {
+		mw.logger.Log("method", "JobStatus", "jobID", jobID, "state", status.State, "err", err)
+	}()
+	return mw.next.JobStatus(ctx, jobID)
+}
+
+func (mw loggingMiddleware) CancelJob(ctx context.Context, jobID string) (err error) {
+	defer func() {
+		mw.logger.Log("method", "CancelJob", "jobID", jobID, "err", err)
+	}()
+	return mw.next.CancelJob(ctx, jobID)
+}
+
+func (mw loggingMiddleware) ListFlagged(ctx context.Context) (results []moderation.Event, err error) {
+	defer func() {
+		mw.logger.Log("method", "ListFlagged", "results", len(results), "err", err)
+	}()
+	return mw.next.ListFlagged(ctx)
+}
+
+func (mw loggingMiddleware) ApproveFlagged(ctx context.Context, taskID models.TaskID) (err error) {
+	defer func() {
+		mw.logger.Log("method", "ApproveFlagged", "taskID", taskID, "err", err)
+	}()
+	return mw.next.ApproveFlagged(ctx, taskID)
+}
+
+func (mw loggingMiddleware) RejectFlagged(ctx context.Context, taskID models.TaskID) (err error) {
+	defer func() {
+		mw.logger.Log("method", "RejectFlagged", "taskID", taskID, "err", err)
+	}()
+	return mw.next.RejectFlagged(ctx, taskID)
+}
+
 // InstrumentingMiddleware returns a service middleware that instruments
 // the number of integers summed and characters concatenated over the lifetime of
 // the service.
-func InstrumentingMiddleware(ints, chars metrics.Counter, cubToDo, getTodo metrics.Histogram) Middleware {
+func InstrumentingMiddleware(ints, chars metrics.Counter, cubToDo, getTodo, timeToComplete metrics.Histogram) Middleware {
 	return func(next Service) Service {
 		return instrumentingMiddleware{
-			ints:    ints,
-			chars:   chars,
-			cubToDo: cubToDo,
-			getToDo: getTodo,
-			next:    next,
+			ints:           ints,
+			chars:          chars,
+			cubToDo:        cubToDo,
+			getToDo:        getTodo,
+			timeToComplete: timeToComplete,
+			next:           next,
 		}
 	}
 }
@@ -110,7 +314,10 @@ type instrumentingMiddleware struct {
 	// CRUB without R.
 	cubToDo metrics.Histogram
 	getToDo metrics.Histogram
-	next    Service
+	// timeToComplete observes the age of the todos completed, from their
+	// IDs.
+	timeToComplete metrics.Histogram
+	next           Service
 }
 
 func (mw instrumentingMiddleware) Sum(ctx context.Context, a, b int) (int, error) {
@@ -125,13 +332,21 @@ func (mw instrumentingMiddleware) Concat(ctx context.Context, a, b string) (stri
 	return v, err
 }
 
+func (mw instrumentingMiddleware) Multiply(ctx context.Context, a, b int) (int, error) {
+	return mw.next.Multiply(ctx, a, b)
+}
+
+func (mw instrumentingMiddleware) Divide(ctx context.Context, a, b int) (int, error) {
+	return mw.next.Divide(ctx, a, b)
+}
+
 func (mw instrumentingMiddleware) Ping(ctx context.Context) (string, error) {
 	v, err := mw.next.Ping(ctx)
 	mw.chars.Add(1)
 	return v, err
 }
 
-func (mw instrumentingMiddleware) AddToDo(ctx context.Context, task models.ToDoItem) (v string, err error) {
+func (mw instrumentingMiddleware) AddToDo(ctx context.Context, task models.ToDoItem) (v models.TaskID, err error) {
 	defer func(begin time.Time) {
 		lvs := []string{"method", "AddToDo", "error", fmt.Sprint(err != nil)}
 		mw.cubToDo.With(lvs...).Observe(time.Since(begin).Seconds())
@@ -140,16 +355,19 @@ func (mw instrumentingMiddleware) AddToDo(ctx context.Context, task models.ToDoI
 	return
 }
 
-func (mw instrumentingMiddleware) CompleteToDo(ctx context.Context, taskID string) (v string, err error) {
+func (mw instrumentingMiddleware) CompleteToDo(ctx context.Context, taskID models.TaskID) (v models.TaskID, err error) {
 	defer func(begin time.Time) {
 		lvs := []string{"method", "CompleteToDo", "error", fmt.Sprint(err != nil)}
 		mw.cubToDo.With(lvs...).Observe(time.Since(begin).Seconds())
 	}(time.Now())
 	v, err = mw.next.CompleteToDo(ctx, taskID)
+	if err == nil {
+		mw.timeToComplete.Observe(time.Since(v.Time()).Seconds())
+	}
 	return
 }
 
-func (mw instrumentingMiddleware) UnDoToDo(ctx context.Context, taskID string) (v string, err error) {
+func (mw instrumentingMiddleware) UnDoToDo(ctx context.Context, taskID models.TaskID) (v models.TaskID, err error) {
 	defer func(begin time.Time) {
 		lvs := []string{"method", "UnDoToDo", "error", fmt.Sprint(err != nil)}
 		mw.cubToDo.With(lvs...).Observe(time.Since(begin).Seconds())
@@ -158,7 +376,19 @@ func (mw instrumentingMiddleware) UnDoToDo(ctx context.Context, taskID string) (
 	return
 }
 
-func (mw instrumentingMiddleware) DeleteToDo(ctx context.Context, taskID string) (v string, err error) {
+func (mw instrumentingMiddleware) UpdateToDo(ctx context.Context, taskID models.TaskID, updates models.ToDoUpdate) (v models.TaskID, err error) {
+	defer func(begin time.Time) {
+		lvs := []string{"method", "UpdateToDo", "error", fmt.Sprint(err != nil)}
+		mw.cubToDo.With(lvs...).Observe(time.Since(begin).Seconds())
+	}(time.Now())
+	v, err = mw.next.UpdateToDo(ctx, taskID, updates)
+	if err == nil && updates.Status != nil && *updates.Status {
+		mw.timeToComplete.Observe(time.Since(v.Time()).Seconds())
+	}
+	return
+}
+
+func (mw instrumentingMiddleware) DeleteToDo(ctx context.Context, taskID models.TaskID) (v models.TaskID, err error) {
 	defer func(begin time.Time) {
 		lvs := []string{"method", "DeleteToDo", "error", fmt.Sprint(err != nil)}
 		mw.cubToDo.With(lvs...).Observe(time.Since(begin).Seconds())
@@ -167,11 +397,142 @@ func (mw instrumentingMiddleware) DeleteToDo(ctx context.Context, taskID string)
 	return
 }
 
-func (mw instrumentingMiddleware) GetAllToDo(ctx context.Context) (results []models.ToDoItem, err error) {
+func (mw instrumentingMiddleware) BatchToDo(ctx context.Context, action models.BatchAction, ids []models.TaskID) (result models.BatchResult, err error) {
+	defer func(begin time.Time) {
+		lvs := []string{"method", "BatchToDo", "error", fmt.Sprint(err != nil)}
+		mw.cubToDo.With(lvs...).Observe(time.Since(begin).Seconds())
+	}(time.Now())
+	result, err = mw.next.BatchToDo(ctx, action, ids)
+	return
+}
+
+func (mw instrumentingMiddleware) GetToDoByID(ctx context.Context, taskID models.TaskID) (todo models.ToDoItem, err error) {
+	defer func(begin time.Time) {
+		lvs := []string{"method", "GetToDoByID", "error", fmt.Sprint(err != nil)}
+		mw.getToDo.With(lvs...).Observe(time.Since(begin).Seconds())
+	}(time.Now())
+	todo, err = mw.next.GetToDoByID(ctx, taskID)
+	return
+}
+
+func (mw instrumentingMiddleware) GetAllToDo(ctx context.Context, opts models.ListOptions) (page models.ToDoPage, err error) {
+	defer func(begin time.Time) {
+		lvs := []string{"method", "GetAllToDo", "error", fmt.Sprint(err != nil)}
+		mw.getToDo.With(lvs...).Observe(time.Since(begin).Seconds())
+	}(time.Now())
+	page, err = mw.next.GetAllToDo(ctx, opts)
+	return
+}
+
+func (mw instrumentingMiddleware) SimilarToDo(ctx context.Context, task string) (results []models.ToDoItem, err error) {
+	defer func(begin time.Time) {
+		lvs := []string{"method", "SimilarToDo", "error", fmt.Sprint(err != nil)}
+		mw.getToDo.With(lvs...).Observe(time.Since(begin).Seconds())
+	}(time.Now())
+	results, err = mw.next.SimilarToDo(ctx, task)
+	return
+}
+
+func (mw instrumentingMiddleware) SearchToDo(ctx context.Context, query string) (results []models.SearchResult, err error) {
+	defer func(begin time.Time) {
+		lvs := []string{"method", "SearchToDo", "error", fmt.Sprint(err != nil)}
+		mw.getToDo.With(lvs...).Observe(time.Since(begin).Seconds())
+	}(time.Now())
+	results, err = mw.next.SearchToDo(ctx, query)
+	return
+}
+
+func (mw instrumentingMiddleware) CompletionStats(ctx context.Context) (stats models.CompletionStats, err error) {
+	defer func(begin time.Time) {
+		lvs := []string{"method", "CompletionStats", "error", fmt.Sprint(err != nil)}
+		mw.getToDo.With(lvs...).Observe(time.Since(begin).Seconds())
+	}(time.Now())
+	stats, err = mw.next.CompletionStats(ctx)
+	return
+}
+
+func (mw instrumentingMiddleware) WatchToDos(ctx context.Context) (changes <-chan models.ToDoEvent, err error) {
+	defer func(begin time.Time) {
+		lvs := []string{"method", "WatchToDos", "error", fmt.Sprint(err != nil)}
+		mw.getToDo.With(lvs...).Observe(time.Since(begin).Seconds())
+	}(time.Now())
+	changes, err = mw.next.WatchToDos(ctx)
+	return
+}
+
+func (mw instrumentingMiddleware) View(ctx context.Context, name string) (results []models.ToDoItem, err error) {
 	defer func(begin time.Time) {
-		lvs := []string{"method", "DeleteToDo",  "error", fmt.Sprint(err != nil)}
+		lvs := []string{"method", "View", "error", fmt.Sprint(err != nil)}
 		mw.getToDo.With(lvs...).Observe(time.Since(begin).Seconds())
 	}(time.Now())
-	results, err = mw.next.GetAllToDo(ctx)
+	results, err = mw.next.View(ctx, name)
 	return
 }
+
+func (mw instrumentingMiddleware) SaveView(ctx context.Context, v views.View) error {
+	return mw.next.SaveView(ctx, v)
+}
+
+func (mw instrumentingMiddleware) ListViews(ctx context.Context) ([]views.View, error) {
+	return mw.next.ListViews(ctx)
+}
+
+func (mw instrumentingMiddleware) DeleteView(ctx context.Context, name string) error {
+	return mw.next.DeleteView(ctx, name)
+}
+
+func (mw instrumentingMiddleware) CreateWebhook(ctx context.Context, sub webhooks.Subscription) (webhooks.Subscription, error) {
+	return mw.next.CreateWebhook(ctx, sub)
+}
+
+func (mw instrumentingMiddleware) DeleteWebhook(ctx context.Context, id string) error {
+	return mw.next.DeleteWebhook(ctx, id)
+}
+
+func (mw instrumentingMiddleware) GetFieldSchema(ctx context.Context) (fields.Schema, error) {
+	return mw.next.GetFieldSchema(ctx)
+}
+
+func (mw instrumentingMiddleware) SaveFieldSchema(ctx context.Context, schema fields.Schema) (fields.Schema, error) {
+	return mw.next.SaveFieldSchema(ctx, schema)
+}
+
+func (mw instrumentingMiddleware) GetWorkflow(ctx context.Context) (workflow.Workflow, error) {
+	return mw.next.GetWorkflow(ctx)
+}
+
+func (mw instrumentingMiddleware) SaveWorkflow(ctx context.Context, w workflow.Workflow) (workflow.Workflow, error) {
+	return mw.next.SaveWorkflow(ctx, w)
+}
+
+func (mw instrumentingMiddleware) AddDependency(ctx context.Context, taskID, blockedBy models.TaskID) error {
+	return mw.next.AddDependency(ctx, taskID, blockedBy)
+}
+
+func (mw instrumentingMiddleware) RemoveDependency(ctx context.Context, taskID, blockedBy models.TaskID) error {
+	return mw.next.RemoveDependency(ctx, taskID, blockedBy)
+}
+
+func (mw instrumentingMiddleware) Factorize(ctx context.Context, n int64) (jobs.Status, error) {
+	return mw.next.Factorize(ctx, n)
+}
+
+func (mw instrumentingMiddleware) JobStatus(ctx context.Context, jobID string) (jobs.Status, error) {
+	return mw.next.JobStatus(ctx, jobID)
+}
+
+func (mw instrumentingMiddleware) CancelJob(ctx context.Context, jobID string) error {
+	return mw.next.CancelJob(ctx, jobID)
+}
+
+func (mw instrumentingMiddleware) ListFlagged(ctx context.Context) ([]moderation.Event, error) {
+	return mw.next.ListFlagged(ctx)
+}
+
+func (mw instrumentingMiddleware) ApproveFlagged(ctx context.Context, taskID models.TaskID) error {
+	return mw.next.ApproveFlagged(ctx, taskID)
+}
+
+func (mw instrumentingMiddleware) RejectFlagged(ctx context.Context, taskID models.TaskID) error {
+	return mw.next.RejectFlagged(ctx, taskID)
+}
@@ -0,0 +1,63 @@
+package addservice
+
+import (
+	"context"
+
+	"ray.vhatt/todo-gokit/pkg/models"
+	"ray.vhatt/todo-gokit/pkg/moderation"
+)
+
+// ListFlagged returns the todos awaiting review, oldest first.
+func (s basicService) ListFlagged(ctx context.Context) ([]moderation.Event, error) {
+	return s.review.List(ctx)
+}
+
+// ApproveFlagged takes the todo taskID off the review queue, listing it
+// again.
+func (s basicService) ApproveFlagged(ctx context.Context, taskID models.TaskID) error {
+	if err := taskID.Validate(); err != nil {
+		return err
+	}
+	return s.review.Remove(ctx, taskID)
+}
+
+// RejectFlagged deletes the todo taskID awaiting review.
+func (s basicService) RejectFlagged(ctx context.Context, taskID models.TaskID) error {
+	if err := taskID.Validate(); err != nil {
+		return err
+	}
+	pending, err := s.review.Pending(ctx, []models.TaskID{taskID})
+	if err != nil {
+		return err
+	}
+	if !pending[taskID] {
+		return moderation.ErrNotQueued
+	}
+	if _, err := s.dbStore.DeleteToDo(ctx, taskID); err != nil {
+		return err
+	}
+	return s.review.Remove(ctx, taskID)
+}
+
+// visible drops the todos awaiting review from todos: they're hidden from
+// the listings and views until approved. They can still be fetched by ID.
+func (s basicService) visible(ctx context.Context, todos []models.ToDoItem) ([]models.ToDoItem, error) {
+	if len(todos) == 0 {
+		return todos, nil
+	}
+	ids := make([]models.TaskID, len(todos))
+	for i, todo := range todos {
+		ids[i] = todo.ID
+	}
+	pending, err := s.review.Pending(ctx, ids)
+	if err != nil || len(pending) == 0 {
+		return todos, err
+	}
+	results := make([]models.ToDoItem, 0, len(todos)-len(pending))
+	for _, todo := range todos {
+		if !pending[todo.ID] {
+			results = append(results, todo)
+		}
+	}
+	return results, nil
+}
@@ -0,0 +1,80 @@
+package addservice
+
+import (
+	"context"
+	"errors"
+
+	"github.com/go-kit/kit/log"
+
+	"ray.vhatt/todo-gokit/pkg/events"
+	"ray.vhatt/todo-gokit/pkg/models"
+	"ray.vhatt/todo-gokit/pkg/store"
+)
+
+// OutboxMiddleware makes the changes of the todos through next in
+// transactions of s, which writes their events to its outbox, see
+// store.WithOutbox. The events s can't tell, those of the todos
+// transitioned to another state and of the todos unblocked, are recorded
+// in the same transactions: an event is relayed if and only if its change
+// is made.
+func OutboxMiddleware(s store.Store, logger log.Logger) Middleware {
+	return func(next Service) Service {
+		return outboxMiddleware{Service: EventsMiddleware(outboxRecorder{}, logger)(next), store: s}
+	}
+}
+
+type outboxMiddleware struct {
+	Service
+	store store.Store
+}
+
+func (mw outboxMiddleware) CompleteToDo(ctx context.Context, taskID models.TaskID) (id models.TaskID, err error) {
+	err = store.Transact(ctx, mw.store, func(ctx context.Context) error {
+		id, err = mw.Service.CompleteToDo(ctx, taskID)
+		return err
+	})
+	return id, err
+}
+
+func (mw outboxMiddleware) UnDoToDo(ctx context.Context, taskID models.TaskID) (id models.TaskID, err error) {
+	err = store.Transact(ctx, mw.store, func(ctx context.Context) error {
+		id, err = mw.Service.UnDoToDo(ctx, taskID)
+		return err
+	})
+	return id, err
+}
+
+func (mw outboxMiddleware) UpdateToDo(ctx context.Context, taskID models.TaskID, updates models.ToDoUpdate) (id models.TaskID, err error) {
+	err = store.Transact(ctx, mw.store, func(ctx context.Context) error {
+		id, err = mw.Service.UpdateToDo(ctx, taskID, updates)
+		return err
+	})
+	return id, err
+}
+
+func (mw outboxMiddleware) BatchToDo(ctx context.Context, action models.BatchAction, ids []models.TaskID) (result models.BatchResult, err error) {
+	err = store.Transact(ctx, mw.store, func(ctx context.Context) error {
+		result, err = mw.Service.BatchToDo(ctx, action, ids)
+		return err
+	})
+	return result, err
+}
+
+// errNoTransaction is returned recording an event outside of a transaction
+// of the store.
+var errNoTransaction = errors.New("not in a transaction of the store")
+
+// outboxRecorder is the events.Publisher recording the events the store
+// can't tell in the transaction of the change, and dropping the others,
+// which the store records itself.
+type outboxRecorder struct{}
+
+func (outboxRecorder) Publish(ctx context.Context, e events.Event) error {
+	if e.Type != events.ToDoTransitioned && e.Type != events.ToDoUnblocked {
+		return nil
+	}
+	if !store.Record(ctx, e) {
+		return errNoTransaction
+	}
+	return nil
+}
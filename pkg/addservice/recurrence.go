@@ -0,0 +1,113 @@
+package addservice
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"ray.vhatt/todo-gokit/pkg/models"
+	"ray.vhatt/todo-gokit/pkg/store"
+)
+
+// maxSkippedOccurrences bounds the occurrences of a todo long overdue
+// skipped to reach the first one ahead: past it, the next occurrence is
+// counted from now.
+const maxSkippedOccurrences = 1000
+
+// recurring returns those of the todos ids about to be completed that
+// repeat, and aren't done yet. The todos that don't exist are left to the
+// store to report.
+func (s basicService) recurring(ctx context.Context, ids ...models.TaskID) ([]models.ToDoItem, error) {
+	var todos []models.ToDoItem
+	for _, id := range ids {
+		todo, err := s.dbStore.FindByID(ctx, id)
+		if errors.Is(err, store.ErrToDoNotFound) || errors.Is(err, models.ErrInvalidTaskID) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if todo.Recurrence != "" && !todo.Status {
+			todos = append(todos, todo)
+		}
+	}
+	return todos, nil
+}
+
+// completeRecurring completes the todos of recurring one by one, adding
+// their next occurrence along, and returns the others of ids, left to
+// complete in a batch, with the result of those completed.
+func (s basicService) completeRecurring(ctx context.Context, ids []models.TaskID, recurring []models.ToDoItem) ([]models.TaskID, models.BatchResult) {
+	var result models.BatchResult
+	repeats := make(map[models.TaskID]bool, len(recurring))
+	for _, todo := range recurring {
+		repeats[todo.ID] = true
+		var flipped bool
+		ctx := store.OnCompleted(ctx, func(ctx context.Context, todo models.ToDoItem) error {
+			flipped = true
+			return s.recur(ctx, todo)
+		})
+		_, err := s.dbStore.CompleteToDo(ctx, todo.ID)
+		switch {
+		case errors.Is(err, store.ErrToDoNotFound):
+		case err != nil:
+			result.Failures = append(result.Failures, models.BatchFailure{ID: todo.ID, Error: err.Error()})
+		default:
+			result.Matched++
+			if flipped {
+				result.Modified++
+			}
+		}
+	}
+	others := make([]models.TaskID, 0, len(ids)-len(recurring))
+	for _, id := range ids {
+		if !repeats[id] {
+			others = append(others, id)
+		}
+	}
+	return others, result
+}
+
+// recur adds the next occurrence of todo, which was just completed, if it
+// repeats. It's called by the store, see store.OnCompleted.
+func (s basicService) recur(ctx context.Context, todo models.ToDoItem) error {
+	if todo.Recurrence == "" {
+		return nil
+	}
+	_, err := s.dbStore.InsertToDo(ctx, nextOccurrence(todo, time.Now()))
+	return err
+}
+
+// nextOccurrence returns the occurrence of todo following the one done at
+// now. It's due, scheduled and reminded of the first period after now its
+// due date, or else its schedule, moves to; a todo with neither is scheduled
+// a period from now.
+func nextOccurrence(todo models.ToDoItem, now time.Time) models.ToDoItem {
+	next := models.ToDoItem{Task: todo.Task, Priority: todo.Priority, Recurrence: todo.Recurrence, CustomFields: todo.CustomFields}
+	anchor := todo.DueDate
+	if anchor == nil {
+		anchor = todo.ScheduleAt
+	}
+	if anchor == nil {
+		at := todo.Recurrence.Next(now, 1)
+		next.ScheduleAt = &at
+		return next
+	}
+	n := 1
+	for n <= maxSkippedOccurrences && !todo.Recurrence.Next(*anchor, n).After(now) {
+		n++
+	}
+	shift := func(t *time.Time) *time.Time {
+		if t == nil {
+			return nil
+		}
+		if n > maxSkippedOccurrences {
+			at := todo.Recurrence.Next(now, 1).Add(t.Sub(*anchor))
+			return &at
+		}
+		at := todo.Recurrence.Next(*t, n)
+		return &at
+	}
+	next.DueDate, next.ScheduleAt, next.RemindAt = shift(todo.DueDate), shift(todo.ScheduleAt), shift(todo.RemindAt)
+	return next
+}
@@ -3,36 +3,128 @@ package addservice
 import (
 	"context"
 	"errors"
+	"fmt"
+	"math"
+	"net"
+	"time"
+	"unicode/utf8"
 
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/metrics"
+	"github.com/go-kit/kit/metrics/discard"
 
+	"ray.vhatt/todo-gokit/pkg/analytics"
+	"ray.vhatt/todo-gokit/pkg/dependency"
+	"ray.vhatt/todo-gokit/pkg/events"
+	"ray.vhatt/todo-gokit/pkg/fields"
+	"ray.vhatt/todo-gokit/pkg/jobs"
 	"ray.vhatt/todo-gokit/pkg/models"
+	"ray.vhatt/todo-gokit/pkg/moderation"
+	"ray.vhatt/todo-gokit/pkg/query"
 	"ray.vhatt/todo-gokit/pkg/store"
+	"ray.vhatt/todo-gokit/pkg/tenant"
+	"ray.vhatt/todo-gokit/pkg/views"
+	"ray.vhatt/todo-gokit/pkg/webhooks"
+	"ray.vhatt/todo-gokit/pkg/workflow"
 )
 
 // Service describe a service that adds things together
 type Service interface {
 	Sum(ctx context.Context, a, b int) (int, error)
 	Concat(ctx context.Context, a, b string) (string, error)
+	Multiply(ctx context.Context, a, b int) (int, error)
+	Divide(ctx context.Context, a, b int) (int, error)
 	Ping(ctx context.Context) (string, error)
-	AddToDo(ctx context.Context, task models.ToDoItem) (string, error)
-	CompleteToDo(ctx context.Context, taskId string) (string, error)
-	UnDoToDo(ctx context.Context, taskId string) (string, error)
-	DeleteToDo(ctx context.Context, taskId string) (string, error)
-	GetAllToDo(ctx context.Context) ([]models.ToDoItem, error)
+	AddToDo(ctx context.Context, task models.ToDoItem) (models.TaskID, error)
+	CompleteToDo(ctx context.Context, taskID models.TaskID) (models.TaskID, error)
+	UnDoToDo(ctx context.Context, taskID models.TaskID) (models.TaskID, error)
+	UpdateToDo(ctx context.Context, taskID models.TaskID, updates models.ToDoUpdate) (models.TaskID, error)
+	DeleteToDo(ctx context.Context, taskID models.TaskID) (models.TaskID, error)
+	BatchToDo(ctx context.Context, action models.BatchAction, ids []models.TaskID) (models.BatchResult, error)
+	GetToDoByID(ctx context.Context, taskID models.TaskID) (models.ToDoItem, error)
+	GetAllToDo(ctx context.Context, opts models.ListOptions) (models.ToDoPage, error)
+	SimilarToDo(ctx context.Context, task string) ([]models.ToDoItem, error)
+	SearchToDo(ctx context.Context, query string) ([]models.SearchResult, error)
+	CompletionStats(ctx context.Context) (models.CompletionStats, error)
+	WatchToDos(ctx context.Context) (<-chan models.ToDoEvent, error)
+	View(ctx context.Context, name string) ([]models.ToDoItem, error)
+	SaveView(ctx context.Context, v views.View) error
+	ListViews(ctx context.Context) ([]views.View, error)
+	DeleteView(ctx context.Context, name string) error
+	CreateWebhook(ctx context.Context, sub webhooks.Subscription) (webhooks.Subscription, error)
+	DeleteWebhook(ctx context.Context, id string) error
+	GetFieldSchema(ctx context.Context) (fields.Schema, error)
+	SaveFieldSchema(ctx context.Context, schema fields.Schema) (fields.Schema, error)
+	GetWorkflow(ctx context.Context) (workflow.Workflow, error)
+	SaveWorkflow(ctx context.Context, w workflow.Workflow) (workflow.Workflow, error)
+	AddDependency(ctx context.Context, taskID, blockedBy models.TaskID) error
+	RemoveDependency(ctx context.Context, taskID, blockedBy models.TaskID) error
+	Factorize(ctx context.Context, n int64) (jobs.Status, error)
+	JobStatus(ctx context.Context, jobID string) (jobs.Status, error)
+	CancelJob(ctx context.Context, jobID string) error
+	ListFlagged(ctx context.Context) ([]moderation.Event, error)
+	ApproveFlagged(ctx context.Context, taskID models.TaskID) error
+	RejectFlagged(ctx context.Context, taskID models.TaskID) error
 }
 
-// New return a basic Service with all the expected middlewares wired in.
-func New(logger log.Logger, ints, chars metrics.Counter, cubTodo, getTodo metrics.Histogram) Service {
+// New return a basic Service with all the expected middlewares wired in,
+// keeping the todos in dbStore. cfg sets its business rules. A nil logger or
+// metric is replaced by a no-op one. timeToComplete observes the seconds
+// the todos completed took since they were created. The subscriptions of
+// Config.WebhookStore are called back with the changes of the todos. Without
+// Config.Events, a dbStore writing an outbox is given the events it can't
+// tell, see OutboxMiddleware.
+func New(dbStore store.Store, logger log.Logger, ints, chars metrics.Counter, cubTodo, getTodo, timeToComplete metrics.Histogram, cfg Config) (Service, error) {
+	if logger == nil {
+		logger = log.NewNopLogger()
+	}
+	if ints == nil {
+		ints = discard.NewCounter()
+	}
+	if chars == nil {
+		chars = discard.NewCounter()
+	}
+	if cubTodo == nil {
+		cubTodo = discard.NewHistogram()
+	}
+	if getTodo == nil {
+		getTodo = discard.NewHistogram()
+	}
+	if timeToComplete == nil {
+		timeToComplete = discard.NewHistogram()
+	}
+
+	if cfg.WebhookStore == nil {
+		cfg.WebhookStore = webhooks.NewMemoryStore()
+	}
+
 	var svc Service
 	{
-		svc = NewBasicService()
+		var err error
+		svc, err = NewBasicService(dbStore, cfg)
+		if err != nil {
+			return nil, err
+		}
+		if cfg.Events == nil && store.WritesOutbox(dbStore) {
+			svc = OutboxMiddleware(dbStore, log.With(logger, "component", "events"))(svc)
+		}
 		svc = LoggingMiddleware(logger)(svc)
-		svc = InstrumentingMiddleware(ints, chars, cubTodo, getTodo)(svc)
+		svc = InstrumentingMiddleware(ints, chars, cubTodo, getTodo, timeToComplete)(svc)
+		if cfg.Analytics != nil {
+			svc = AnalyticsMiddleware(cfg.Analytics, log.With(logger, "component", "analytics"))(svc)
+		}
+		webhookLogger := log.With(logger, "component", "webhooks")
+		dispatcher := cfg.Dispatcher
+		if dispatcher == nil {
+			dispatcher = webhooks.NewDispatcher(cfg.WebhookStore, cfg.WebhookNetworks, webhookLogger)
+		}
+		svc = WebhooksMiddleware(dispatcher, webhookLogger)(svc)
+		if cfg.Events != nil {
+			svc = EventsMiddleware(cfg.Events, log.With(logger, "component", "events"))(svc)
+		}
 	}
 
-	return svc
+	return svc, nil
 }
 
 var (
@@ -47,46 +139,211 @@ var (
 
 	// ErrMaxSizeExceeded protects the Concat method.
 	ErrMaxSizeExceeded = errors.New("result exceeds maximum size")
+
+	// ErrDivideByZero protects the Divide method.
+	ErrDivideByZero = errors.New("can't divide by zero")
+
+	// ErrEmptyUpdate is returned by UpdateToDo for an update setting no
+	// field.
+	ErrEmptyUpdate = errors.New("update sets no field")
+
+	// ErrEmptySearch is returned by SearchToDo for a search without words.
+	ErrEmptySearch = errors.New("search has no words")
+
+	// ErrBatchTooLarge is returned by BatchToDo for a batch of more todos
+	// than Config.MaxBatchSize.
+	ErrBatchTooLarge = errors.New("batch too large")
 )
 
-// NewBasicService return a naive, stateless implementation of Service.
-func NewBasicService() Service {
-	dbStore, _ := store.NewMongoStore("mongodb://localhost:27017", "gokit-test", "todolist")
-	return basicService{
-		dbStore: dbStore,
+// Config holds the tunable business rules of the service. Start from
+// DefaultConfig, the zero value bounds integer results to 0.
+type Config struct {
+	// MaxConcatLen is the longest string Concat may return, in runes.
+	MaxConcatLen int
+	// MaxTaskLen is the longest task a todo may have, in runes once
+	// normalized. 0 disables the limit.
+	MaxTaskLen int
+	// MaxBatchSize is the largest number of todos BatchToDo acts on at
+	// once. 0 disables the limit.
+	MaxBatchSize int
+	// RejectTwoZeroes makes Sum fail with ErrTwoZeroes when both operands
+	// are zero.
+	RejectTwoZeroes bool
+	// IntMin and IntMax bound the results of the arithmetic methods, results
+	// out of bounds fail with ErrIntOverflow.
+	IntMin, IntMax int64
+	// JobRetention is how long the status of finished jobs can be polled.
+	JobRetention time.Duration
+	// JobStore persists the jobs, nil keeps them in memory.
+	JobStore jobs.Store
+	// ViewStore persists the saved views, nil keeps them in memory.
+	ViewStore views.Store
+	// Moderator screens the tasks of the todos added and updated, nil lets
+	// them all through.
+	Moderator *moderation.Moderator
+	// ReviewQueue holds the flagged todos awaiting review, hidden from the
+	// listings until approved, nil keeps them in memory. The Moderator must
+	// record to it.
+	ReviewQueue moderation.Queue
+	// Analytics tracks the todos created and completed, nil tracks
+	// nothing.
+	Analytics *analytics.Tracker
+	// WebhookStore persists the webhook subscriptions, nil keeps them in
+	// memory.
+	WebhookStore webhooks.Store
+	// WebhookNetworks are the internal networks webhooks may call back
+	// nonetheless, see webhooks.Subscription.CheckAddress.
+	WebhookNetworks []*net.IPNet
+	// Dispatcher calls the webhooks back, nil calls them back with one of
+	// WebhookStore and WebhookNetworks, never closed. Its store should be
+	// WebhookStore.
+	Dispatcher *webhooks.Dispatcher
+	// Events publishes the domain events of the todos changed, nil
+	// publishes none.
+	Events events.Publisher
+	// FieldStore persists the custom field schemas of the tenants, nil
+	// keeps them in memory.
+	FieldStore fields.Store
+	// WorkflowStore persists the workflows of the tenants, nil keeps them
+	// in memory.
+	WorkflowStore workflow.Store
+	// DependencyStore persists the links between the todos, nil keeps them
+	// in memory.
+	DependencyStore dependency.Store
+}
+
+// DefaultConfig is the configuration of the service unless told otherwise.
+// Integer results must fit in 32 bits, whatever the size of int.
+var DefaultConfig = Config{
+	MaxConcatLen:    10,
+	MaxTaskLen:      500,
+	MaxBatchSize:    1000,
+	RejectTwoZeroes: true,
+	IntMin:          -1 << 31,
+	IntMax:          1<<31 - 1,
+	JobRetention:    time.Hour,
+}
+
+// NewBasicService return a naive, stateless implementation of Service,
+// keeping the todos in dbStore, see store.Open.
+func NewBasicService(dbStore store.Store, cfg Config) (Service, error) {
+	jobStore := cfg.JobStore
+	if jobStore == nil {
+		jobStore = jobs.NewMemoryStore()
 	}
+	manager := jobs.NewManager(jobStore, cfg.JobRetention)
+	manager.Register(factorizeJob, runFactorize)
+	if err := manager.Resume(context.TODO()); err != nil {
+		return nil, fmt.Errorf("resuming jobs: %w", err)
+	}
+
+	viewStore := cfg.ViewStore
+	if viewStore == nil {
+		viewStore = views.NewMemoryStore()
+	}
+
+	review := cfg.ReviewQueue
+	if review == nil {
+		review = moderation.NewMemoryQueue()
+	}
+
+	webhookStore := cfg.WebhookStore
+	if webhookStore == nil {
+		webhookStore = webhooks.NewMemoryStore()
+	}
+
+	fieldStore := cfg.FieldStore
+	if fieldStore == nil {
+		fieldStore = fields.NewMemoryStore()
+	}
+
+	workflowStore := cfg.WorkflowStore
+	if workflowStore == nil {
+		workflowStore = workflow.NewMemoryStore()
+	}
+
+	dependencyStore := cfg.DependencyStore
+	if dependencyStore == nil {
+		dependencyStore = dependency.NewMemoryStore()
+	}
+
+	return basicService{
+		dbStore:      dbStore,
+		cfg:          cfg,
+		jobs:         manager,
+		views:        viewStore,
+		review:       review,
+		webhooks:     webhookStore,
+		fields:       fieldStore,
+		workflows:    workflowStore,
+		dependencies: dependencyStore,
+	}, nil
 }
 
 type basicService struct {
-	dbStore store.Store
+	dbStore      store.Store
+	cfg          Config
+	jobs         *jobs.Manager
+	views        views.Store
+	review       moderation.Queue
+	webhooks     webhooks.Store
+	fields       fields.Store
+	workflows    workflow.Store
+	dependencies dependency.Store
 }
 
-const (
-	intMax = 1<<31 - 1
-	intMin = -(intMax + 1)
-	maxLen = 10
-)
-
 // Sum implements Sum
 func (s basicService) Sum(_ context.Context, a, b int) (int, error) {
-	if a == 0 && b == 0 {
+	if s.cfg.RejectTwoZeroes && a == 0 && b == 0 {
 		return 0, ErrTwoZeroes
 	}
 
-	if (b > 0 && a > (intMax-b)) || (b < 0 && a < (intMin-b)) {
+	// The sum is computed in 64 bits, which can only overflow when a and b
+	// have the same sign and the result doesn't.
+	sum := int64(a) + int64(b)
+	if (a > 0 && b > 0 && sum < 0) || (a < 0 && b < 0 && sum >= 0) {
 		return 0, ErrIntOverflow
 	}
-
-	return a + b, nil
+	return s.bounded(sum)
 }
 
 func (s basicService) Concat(_ context.Context, a, b string) (string, error) {
-	if len(a)+len(b) > maxLen {
+	if utf8.RuneCountInString(a)+utf8.RuneCountInString(b) > s.cfg.MaxConcatLen {
 		return "", ErrMaxSizeExceeded
 	}
 	return a + b, nil
 }
 
+// Multiply implements Multiply
+func (s basicService) Multiply(_ context.Context, a, b int) (int, error) {
+	x, y := int64(a), int64(b)
+	product := x * y
+	if x != 0 && (product/x != y || (x == -1 && y == math.MinInt64)) {
+		return 0, ErrIntOverflow
+	}
+	return s.bounded(product)
+}
+
+// Divide implements Divide, it truncates the quotient towards zero.
+func (s basicService) Divide(_ context.Context, a, b int) (int, error) {
+	if b == 0 {
+		return 0, ErrDivideByZero
+	}
+	x, y := int64(a), int64(b)
+	if x == math.MinInt64 && y == -1 {
+		return 0, ErrIntOverflow
+	}
+	return s.bounded(x / y)
+}
+
+// bounded checks v against the configured integer bounds.
+func (s basicService) bounded(v int64) (int, error) {
+	if v > s.cfg.IntMax || v < s.cfg.IntMin {
+		return 0, ErrIntOverflow
+	}
+	return int(v), nil
+}
+
 func (s basicService) Ping(ctx context.Context) (string, error) {
 	err := s.dbStore.Ping(ctx)
 	if err != nil {
@@ -95,16 +352,51 @@ func (s basicService) Ping(ctx context.Context) (string, error) {
 	return "up", nil
 }
 
-func (s basicService) AddToDo(ctx context.Context, task models.ToDoItem) (string, error) {
+// AddToDo adds task in the state it gives, or in the initial state of the
+// workflow of the tenant, the done state if its status is set.
+func (s basicService) AddToDo(ctx context.Context, task models.ToDoItem) (models.TaskID, error) {
+	var err error
+	if task.Task, err = s.task(task.Task); err != nil {
+		return "", err
+	}
+	if err := task.Priority.Validate(); err != nil {
+		return "", err
+	}
+	if err := task.Recurrence.Validate(); err != nil {
+		return "", err
+	}
+	if task.CustomFields, err = s.customFields(ctx, task.CustomFields, false); err != nil {
+		return "", err
+	}
+	if err := s.startState(ctx, &task); err != nil {
+		return "", err
+	}
+	task.Dependencies = nil
+	verdict, err := s.screen(ctx, task.Task)
+	if err != nil {
+		return "", err
+	}
 	insertResult, err := s.dbStore.InsertToDo(ctx, task)
 	if err != nil {
 		return "", err
 	}
+	if err := s.recordModeration(ctx, insertResult, task.Task, verdict); err != nil {
+		return "", err
+	}
 	return insertResult, nil
 }
 
-func (s basicService) CompleteToDo(ctx context.Context, taskID string) (string, error) {
-	resultID, err := s.dbStore.CompleteToDo(ctx, taskID)
+// CompleteToDo marks the todo taskID done, moving it to the done state of
+// the workflow. A todo that repeats gets its next occurrence added.
+func (s basicService) CompleteToDo(ctx context.Context, taskID models.TaskID) (models.TaskID, error) {
+	disallowed, err := s.disallowed(ctx, true, taskID)
+	if err != nil {
+		return "", err
+	}
+	if err := disallowed[taskID]; err != nil {
+		return "", err
+	}
+	resultID, err := s.dbStore.CompleteToDo(store.OnCompleted(ctx, s.recur), taskID)
 	if err != nil {
 		return "", err
 	}
@@ -112,7 +404,16 @@ func (s basicService) CompleteToDo(ctx context.Context, taskID string) (string,
 	return resultID, nil
 }
 
-func (s basicService) UnDoToDo(ctx context.Context, taskID string) (string, error) {
+// UnDoToDo reopens the todo taskID, moving it back to the initial state of
+// the workflow.
+func (s basicService) UnDoToDo(ctx context.Context, taskID models.TaskID) (models.TaskID, error) {
+	disallowed, err := s.disallowed(ctx, false, taskID)
+	if err != nil {
+		return "", err
+	}
+	if err := disallowed[taskID]; err != nil {
+		return "", err
+	}
 	resultID, err := s.dbStore.UnDoToDo(ctx, taskID)
 	if err != nil {
 		return "", err
@@ -121,19 +422,258 @@ func (s basicService) UnDoToDo(ctx context.Context, taskID string) (string, erro
 	return resultID, nil
 }
 
-func (s basicService) DeleteToDo(ctx context.Context, taskID string) (string, error) {
+// UpdateToDo sets the fields of the todo taskID the updates give. Setting
+// its state moves it there, setting its status to whether the state is
+// done; setting its status alone completes or reopens it, see CompleteToDo
+// and UnDoToDo.
+func (s basicService) UpdateToDo(ctx context.Context, taskID models.TaskID, updates models.ToDoUpdate) (models.TaskID, error) {
+	if updates.Empty() {
+		return "", ErrEmptyUpdate
+	}
+	var verdict moderation.Verdict
+	if updates.Task != nil {
+		task, err := s.task(*updates.Task)
+		if err != nil {
+			return "", err
+		}
+		if verdict, err = s.screen(ctx, task); err != nil {
+			return "", err
+		}
+		updates.Task = &task
+	}
+	if updates.Priority != nil {
+		if err := updates.Priority.Validate(); err != nil {
+			return "", err
+		}
+	}
+	if updates.Recurrence != nil {
+		if err := updates.Recurrence.Validate(); err != nil {
+			return "", err
+		}
+	}
+	if len(updates.CustomFields) > 0 {
+		var err error
+		if updates.CustomFields, err = s.customFields(ctx, updates.CustomFields, true); err != nil {
+			return "", err
+		}
+	}
+	if updates.State != nil || updates.Status != nil {
+		if err := s.move(ctx, taskID, &updates); err != nil {
+			return "", err
+		}
+	}
+	resultID, err := s.dbStore.UpdateToDo(store.OnCompleted(ctx, s.recur), taskID, updates)
+	if err != nil {
+		return "", err
+	}
+	if updates.Task != nil {
+		if err := s.recordModeration(ctx, resultID, *updates.Task, verdict); err != nil {
+			return "", err
+		}
+	}
+
+	return resultID, nil
+}
+
+// DeleteToDo deletes the todo taskID, and its links to the others.
+func (s basicService) DeleteToDo(ctx context.Context, taskID models.TaskID) (models.TaskID, error) {
 	resultID, err := s.dbStore.DeleteToDo(ctx, taskID)
 	if err != nil {
 		return "", err
 	}
+	if err := s.dependencies.RemoveAll(ctx, tenant.FromContext(ctx), taskID); err != nil {
+		return "", err
+	}
 
 	return resultID, nil
 }
 
-func (s basicService) GetAllToDo(ctx context.Context) ([]models.ToDoItem, error) {
-	results, err := s.dbStore.GetAllToDo(ctx)
+// BatchToDo applies action to the todos ids at once. An ID given twice is
+// acted on once. The todos the workflow doesn't let be completed or
+// reopened fail. The todos that repeat are completed one by one, to get
+// their next occurrence added along, the deleted ones lose their links to
+// the others.
+func (s basicService) BatchToDo(ctx context.Context, action models.BatchAction, ids []models.TaskID) (models.BatchResult, error) {
+	if err := action.Validate(); err != nil {
+		return models.BatchResult{}, err
+	}
+	seen := make(map[models.TaskID]bool, len(ids))
+	unique := make([]models.TaskID, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	if max := s.cfg.MaxBatchSize; max > 0 && len(unique) > max {
+		return models.BatchResult{}, fmt.Errorf("%w: %d todos, the limit is %d", ErrBatchTooLarge, len(unique), max)
+	}
+	if len(unique) == 0 {
+		return models.BatchResult{}, nil
+	}
+	var disallowed []models.BatchFailure
+	if action == models.BatchComplete || action == models.BatchUndo {
+		failed, err := s.disallowed(ctx, action == models.BatchComplete, unique...)
+		if err != nil {
+			return models.BatchResult{}, err
+		}
+		allowed := unique[:0]
+		for _, id := range unique {
+			if err := failed[id]; err != nil {
+				disallowed = append(disallowed, models.BatchFailure{ID: id, Error: err.Error()})
+			} else {
+				allowed = append(allowed, id)
+			}
+		}
+		if unique = allowed; len(unique) == 0 {
+			return models.BatchResult{Failures: disallowed}, nil
+		}
+	}
+	var result models.BatchResult
+	if action == models.BatchComplete {
+		recurring, err := s.recurring(ctx, unique...)
+		if err != nil {
+			return models.BatchResult{}, err
+		}
+		if unique, result = s.completeRecurring(ctx, unique, recurring); len(unique) == 0 {
+			result.Failures = append(disallowed, result.Failures...)
+			return result, nil
+		}
+	}
+	batched, err := s.dbStore.BatchToDo(ctx, action, unique)
+	if err != nil {
+		return models.BatchResult{}, err
+	}
+	result.Matched += batched.Matched
+	result.Modified += batched.Modified
+	result.Failures = append(append(disallowed, result.Failures...), batched.Failures...)
+	failed := make(map[models.TaskID]bool, len(result.Failures))
+	for _, f := range result.Failures {
+		failed[f.ID] = true
+	}
+	if action == models.BatchDelete {
+		for _, id := range unique {
+			if failed[id] {
+				continue
+			}
+			if err := s.dependencies.RemoveAll(ctx, tenant.FromContext(ctx), id); err != nil {
+				return models.BatchResult{}, err
+			}
+		}
+	}
+	return result, nil
+}
+
+// GetToDoByID returns the todo taskID, with the todos it's blocked by and
+// those it blocks if any.
+func (s basicService) GetToDoByID(ctx context.Context, taskID models.TaskID) (models.ToDoItem, error) {
+	todo, err := s.dbStore.FindByID(ctx, taskID)
+	if err != nil {
+		return models.ToDoItem{}, err
+	}
+	todos, err := s.withStates(ctx, []models.ToDoItem{todo})
+	if err != nil {
+		return models.ToDoItem{}, err
+	}
+	if len(todos) == 0 {
+		return models.ToDoItem{}, store.ErrToDoNotFound
+	}
+	if todos[0].Dependencies, err = s.dependenciesOf(ctx, taskID); err != nil {
+		return models.ToDoItem{}, err
+	}
+	return todos[0], nil
+}
+
+func (s basicService) GetAllToDo(ctx context.Context, opts models.ListOptions) (models.ToDoPage, error) {
+	// Invalid options are the caller's fault, reject them before they reach
+	// the store or, when sharded, count as a failure of every shard.
+	if err := opts.Validate(); err != nil {
+		return models.ToDoPage{}, err
+	}
+	if opts.Query != "" {
+		if _, err := query.Parse(opts.Query); err != nil {
+			return models.ToDoPage{}, err
+		}
+	}
+	page, err := s.dbStore.GetAllToDo(ctx, opts)
+	if err != nil {
+		return models.ToDoPage{}, err
+	}
+	if page.Todos, err = s.visible(ctx, page.Todos); err != nil {
+		return models.ToDoPage{}, err
+	}
+	if page.Todos, err = s.withStates(ctx, page.Todos); err != nil {
+		return models.ToDoPage{}, err
+	}
+	return page, nil
+}
+
+// SimilarToDo returns the existing todos that look like near-duplicates of
+// task, most similar first.
+func (s basicService) SimilarToDo(ctx context.Context, task string) ([]models.ToDoItem, error) {
+	// Normalized like the stored tasks, so they compare equal.
+	task = normalizeText(task)
+	candidates, err := s.dbStore.FindSimilarToDo(ctx, task)
 	if err != nil {
 		return nil, err
 	}
-	return results, nil
+	if candidates, err = s.visible(ctx, candidates); err != nil {
+		return nil, err
+	}
+	if candidates, err = s.withStates(ctx, candidates); err != nil {
+		return nil, err
+	}
+	return rankSimilar(task, candidates), nil
+}
+
+// SearchToDo returns the todos whose task matches the words of query, best
+// matches first.
+func (s basicService) SearchToDo(ctx context.Context, query string) ([]models.SearchResult, error) {
+	query = normalizeText(query)
+	if query == "" {
+		return nil, ErrEmptySearch
+	}
+	results, err := s.dbStore.SearchToDo(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	todos := make([]models.ToDoItem, len(results))
+	for i, r := range results {
+		todos[i] = r.ToDoItem
+	}
+	if todos, err = s.visible(ctx, todos); err != nil {
+		return nil, err
+	}
+	if todos, err = s.withStates(ctx, todos); err != nil {
+		return nil, err
+	}
+	shown := make(map[models.TaskID]string, len(todos))
+	for _, todo := range todos {
+		shown[todo.ID] = todo.State
+	}
+	kept := results[:0]
+	for _, r := range results {
+		if state, ok := shown[r.ID]; ok {
+			r.State = state
+			kept = append(kept, r)
+		}
+	}
+	return kept, nil
+}
+
+// Factorize starts the prime factorization of n as a job. The factors are the
+// result of the job.
+func (s basicService) Factorize(ctx context.Context, n int64) (jobs.Status, error) {
+	if n < 2 {
+		return jobs.Status{}, ErrNotFactorizable
+	}
+	return s.jobs.Submit(ctx, factorizeJob, factorizeParams{N: n})
+}
+
+func (s basicService) JobStatus(ctx context.Context, jobID string) (jobs.Status, error) {
+	return s.jobs.Status(ctx, jobID)
+}
+
+func (s basicService) CancelJob(ctx context.Context, jobID string) error {
+	return s.jobs.Cancel(ctx, jobID)
 }
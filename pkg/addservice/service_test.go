@@ -0,0 +1,1084 @@
+package addservice
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"testing/quick"
+	"time"
+	"unicode/utf8"
+
+	"ray.vhatt/todo-gokit/pkg/analytics"
+	"ray.vhatt/todo-gokit/pkg/dependency"
+	"ray.vhatt/todo-gokit/pkg/events"
+	"ray.vhatt/todo-gokit/pkg/fields"
+	"ray.vhatt/todo-gokit/pkg/models"
+	"ray.vhatt/todo-gokit/pkg/moderation"
+	"ray.vhatt/todo-gokit/pkg/store"
+	"ray.vhatt/todo-gokit/pkg/store/mongotest"
+	"ray.vhatt/todo-gokit/pkg/tenant"
+	"ray.vhatt/todo-gokit/pkg/views"
+	"ray.vhatt/todo-gokit/pkg/webhooks"
+	"ray.vhatt/todo-gokit/pkg/workflow"
+)
+
+// exact is the reference for an arithmetic method: the exact result of op,
+// unless it's out of the bounds of cfg.
+func exact(cfg Config, op func(z, x, y *big.Int) *big.Int, a, b int) (int, error) {
+	v := op(new(big.Int), big.NewInt(int64(a)), big.NewInt(int64(b)))
+	if v.Cmp(big.NewInt(cfg.IntMax)) > 0 || v.Cmp(big.NewInt(cfg.IntMin)) < 0 {
+		return 0, ErrIntOverflow
+	}
+	return int(v.Int64()), nil
+}
+
+// checkArithmetic checks method against want over random operands.
+func checkArithmetic(t *testing.T, method, want func(a, b int) (int, error)) {
+	t.Helper()
+	prop := func(a, b int) bool {
+		wantV, wantErr := want(a, b)
+		haveV, haveErr := method(a, b)
+		return wantV == haveV && wantErr == haveErr
+	}
+
+	// Over the whole int range, results mostly overflow.
+	if err := quick.Check(prop, nil); err != nil {
+		t.Error(err)
+	}
+	// Narrower operands exercise the results within and around the
+	// bounds of DefaultConfig.
+	if err := quick.Check(func(a, b int16) bool { return prop(int(a), int(b)) }, nil); err != nil {
+		t.Error(err)
+	}
+	if err := quick.Check(func(a, b int32) bool { return prop(int(a), int(b)) }, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSumProperties(t *testing.T) {
+	svc := basicService{cfg: DefaultConfig}
+	checkArithmetic(t, func(a, b int) (int, error) { return svc.Sum(context.Background(), a, b) }, func(a, b int) (int, error) {
+		if a == 0 && b == 0 {
+			return 0, ErrTwoZeroes
+		}
+		return exact(svc.cfg, (*big.Int).Add, a, b)
+	})
+
+	svc.cfg.RejectTwoZeroes = false
+	if v, err := svc.Sum(context.Background(), 0, 0); v != 0 || err != nil {
+		t.Errorf("want 0 with two zeroes allowed, have %d, %v", v, err)
+	}
+}
+
+func TestMultiplyProperties(t *testing.T) {
+	svc := basicService{cfg: DefaultConfig}
+	checkArithmetic(t, func(a, b int) (int, error) { return svc.Multiply(context.Background(), a, b) }, func(a, b int) (int, error) {
+		return exact(svc.cfg, (*big.Int).Mul, a, b)
+	})
+}
+
+func TestDivideProperties(t *testing.T) {
+	svc := basicService{cfg: DefaultConfig}
+	checkArithmetic(t, func(a, b int) (int, error) { return svc.Divide(context.Background(), a, b) }, func(a, b int) (int, error) {
+		if b == 0 {
+			return 0, ErrDivideByZero
+		}
+		return exact(svc.cfg, (*big.Int).Quo, a, b)
+	})
+
+	if _, err := svc.Divide(context.Background(), 1, 0); err != ErrDivideByZero {
+		t.Errorf("want %v, have %v", ErrDivideByZero, err)
+	}
+}
+
+func TestConcatProperties(t *testing.T) {
+	concat := func(a, b string, max uint8) bool {
+		svc := basicService{cfg: Config{MaxConcatLen: int(max)}}
+		v, err := svc.Concat(context.Background(), a, b)
+		if utf8.RuneCountInString(a)+utf8.RuneCountInString(b) > int(max) {
+			return v == "" && err == ErrMaxSizeExceeded
+		}
+		return v == a+b && err == nil
+	}
+	if err := quick.Check(concat, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestFactorize(t *testing.T) {
+	nop := func(done, total int64) {}
+	for n, want := range map[int64][]int64{
+		2:              {2},
+		12:             {2, 2, 3},
+		97:             {97},
+		1024:           {2, 2, 2, 2, 2, 2, 2, 2, 2, 2},
+		600851475143:   {71, 839, 1471, 6857},
+		2147483647 * 3: {3, 2147483647},
+	} {
+		have, err := factorize(context.Background(), n, nop)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if fmt.Sprint(want) != fmt.Sprint(have) {
+			t.Errorf("factorize(%d): want %v, have %v", n, want, have)
+		}
+	}
+
+	// A large prime takes seconds, unless cancelled.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := factorize(ctx, 9223372036854775783, nop); err != context.Canceled {
+		t.Errorf("want %v, have %v", context.Canceled, err)
+	}
+}
+
+func TestView(t *testing.T) {
+	now := time.Date(2020, 3, 14, 15, 9, 26, 0, time.UTC)
+	at := func(d time.Duration) *time.Time { v := now.Add(d); return &v }
+	todos := []models.ToDoItem{
+		{Task: "unscheduled"},
+		{Task: "done", Status: true},
+		{Task: "next week", ScheduleAt: at(7 * 24 * time.Hour)},
+		{Task: "overdue", ScheduleAt: at(-time.Hour)},
+		{Task: "tonight", ScheduleAt: at(8 * time.Hour)},
+		{Task: "tomorrow", ScheduleAt: at(9 * time.Hour)},
+	}
+	tasks := func(todos []models.ToDoItem) (tasks []string) {
+		for _, t := range todos {
+			tasks = append(tasks, t.Task)
+		}
+		return tasks
+	}
+
+	for name, want := range map[string][]string{
+		ViewToday:    {"unscheduled", "overdue", "tonight"},
+		ViewUpcoming: {"tomorrow", "next week"},
+	} {
+		if have := tasks(view(name, todos, now)); fmt.Sprint(want) != fmt.Sprint(have) {
+			t.Errorf("%s: want %v, have %v", name, want, have)
+		}
+	}
+
+	svc := basicService{views: views.NewMemoryStore()}
+	if _, err := svc.View(context.Background(), "someday"); err != ErrUnknownView {
+		t.Errorf("want %v, have %v", ErrUnknownView, err)
+	}
+	if err := svc.SaveView(context.Background(), views.View{Name: ViewToday}); !errors.Is(err, views.ErrInvalidView) {
+		t.Errorf("want built in name rejected, have %v", err)
+	}
+}
+
+func TestUpdateToDoEmpty(t *testing.T) {
+	svc := basicService{}
+	if _, err := svc.UpdateToDo(context.Background(), models.NewTaskID(), models.ToDoUpdate{}); err != ErrEmptyUpdate {
+		t.Errorf("want %v, have %v", ErrEmptyUpdate, err)
+	}
+}
+
+func TestToDos(t *testing.T) {
+	svc, err := NewBasicService(store.NewInMemoryStore(), DefaultConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	id, err := svc.AddToDo(ctx, models.ToDoItem{Task: "water the plants"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.CompleteToDo(ctx, id); err != nil {
+		t.Fatal(err)
+	}
+	task := "water the cactus"
+	if _, err := svc.UpdateToDo(ctx, id, models.ToDoUpdate{Task: &task}); err != nil {
+		t.Fatal(err)
+	}
+	if todo, err := svc.GetToDoByID(ctx, id); err != nil || todo.Task != task || !todo.Status {
+		t.Errorf("want the todo done and renamed, have %+v, %v", todo, err)
+	}
+	if page, err := svc.GetAllToDo(ctx, models.ListOptions{Query: "status = done"}); err != nil || len(page.Todos) != 1 {
+		t.Errorf("want the todo listed, have %+v, %v", page, err)
+	}
+	if _, err := svc.DeleteToDo(ctx, id); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.GetToDoByID(ctx, id); err != store.ErrToDoNotFound {
+		t.Errorf("want %v, have %v", store.ErrToDoNotFound, err)
+	}
+}
+
+func TestBatchToDo(t *testing.T) {
+	cfg := DefaultConfig
+	cfg.MaxBatchSize = 4
+	svc, err := NewBasicService(store.NewInMemoryStore(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	var ids []models.TaskID
+	for _, task := range []string{"a", "b", "c"} {
+		id, err := svc.AddToDo(ctx, models.ToDoItem{Task: task})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, id)
+	}
+	if _, err := svc.CompleteToDo(ctx, ids[1]); err != nil {
+		t.Fatal(err)
+	}
+
+	batch := []models.TaskID{ids[0], ids[1], ids[0], models.NewTaskID(), "bogus"}
+	result, err := svc.BatchToDo(ctx, models.BatchComplete, batch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Matched != 2 || result.Modified != 1 || len(result.Failures) != 1 || result.Failures[0].ID != "bogus" {
+		t.Errorf("want a and b matched, a completed and bogus failed, have %+v", result)
+	}
+	if result, err := svc.BatchToDo(ctx, models.BatchDelete, ids); err != nil || result.Modified != 3 {
+		t.Errorf("want the todos deleted, have %+v, %v", result, err)
+	}
+	if _, err := svc.BatchToDo(ctx, models.BatchUndo, append(batch, models.NewTaskID())); !errors.Is(err, ErrBatchTooLarge) {
+		t.Errorf("want %v, have %v", ErrBatchTooLarge, err)
+	}
+	if _, err := svc.BatchToDo(ctx, "archive", ids); !errors.Is(err, models.ErrInvalidBatchAction) {
+		t.Errorf("want %v, have %v", models.ErrInvalidBatchAction, err)
+	}
+}
+
+func TestSearchToDo(t *testing.T) {
+	svc, err := NewBasicService(store.NewInMemoryStore(), DefaultConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	for _, task := range []string{"water the plants", "buy milk", "water the garden plants, then the lawn"} {
+		if _, err := svc.AddToDo(ctx, models.ToDoItem{Task: task}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	results, err := svc.SearchToDo(ctx, "Plants water")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 || results[0].Task != "water the plants" || results[0].Score != 2 {
+		t.Errorf("want the plants found, have %+v", results)
+	}
+	if _, err := svc.SearchToDo(ctx, " \t"); err != ErrEmptySearch {
+		t.Errorf("want %v, have %v", ErrEmptySearch, err)
+	}
+}
+
+func TestTaskText(t *testing.T) {
+	svc, err := NewBasicService(store.NewInMemoryStore(), Config{MaxTaskLen: 6})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	// The decomposed é alone would make the task 7 runes long, the
+	// normalized task is 6.
+	id, err := svc.AddToDo(ctx, models.ToDoItem{Task: " cafe\u0301\tx\x00\n"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if todo, err := svc.GetToDoByID(ctx, id); err != nil || todo.Task != "caf\u00e9 x" {
+		t.Errorf("want the task normalized, have %+v, %v", todo, err)
+	}
+	long := "tâches!"
+	if _, err := svc.AddToDo(ctx, models.ToDoItem{Task: long}); err != ErrTaskTooLong {
+		t.Errorf("want %v, have %v", ErrTaskTooLong, err)
+	}
+	if _, err := svc.UpdateToDo(ctx, id, models.ToDoUpdate{Task: &long}); err != ErrTaskTooLong {
+		t.Errorf("want %v, have %v", ErrTaskTooLong, err)
+	}
+}
+
+func TestPriority(t *testing.T) {
+	svc, err := NewBasicService(store.NewInMemoryStore(), DefaultConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	if _, err := svc.AddToDo(ctx, models.ToDoItem{Task: "file taxes", Priority: "asap"}); !errors.Is(err, models.ErrInvalidPriority) {
+		t.Errorf("want %v, have %v", models.ErrInvalidPriority, err)
+	}
+	id, err := svc.AddToDo(ctx, models.ToDoItem{Task: "file taxes"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if todo, err := svc.GetToDoByID(ctx, id); err != nil || todo.Priority != models.PriorityNormal {
+		t.Errorf("want a normal priority by default, have %+v, %v", todo, err)
+	}
+	bad, urgent := models.Priority("asap"), models.PriorityUrgent
+	if _, err := svc.UpdateToDo(ctx, id, models.ToDoUpdate{Priority: &bad}); !errors.Is(err, models.ErrInvalidPriority) {
+		t.Errorf("want %v, have %v", models.ErrInvalidPriority, err)
+	}
+	if _, err := svc.UpdateToDo(ctx, id, models.ToDoUpdate{Priority: &urgent}); err != nil {
+		t.Fatal(err)
+	}
+	if todo, err := svc.GetToDoByID(ctx, id); err != nil || todo.Priority != models.PriorityUrgent {
+		t.Errorf("want the priority updated, have %+v, %v", todo, err)
+	}
+	if _, err := svc.GetAllToDo(ctx, models.ListOptions{Priority: bad}); !errors.Is(err, models.ErrInvalidListOptions) {
+		t.Errorf("want %v, have %v", models.ErrInvalidListOptions, err)
+	}
+}
+
+func TestCustomFields(t *testing.T) {
+	svc, err := NewBasicService(store.NewInMemoryStore(), DefaultConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	acme, globex := tenant.NewContext(context.Background(), "acme"), tenant.NewContext(context.Background(), "globex")
+
+	if _, err := svc.SaveFieldSchema(acme, fields.Schema{Fields: []fields.Field{{Name: "points", Type: "integer"}}}); !errors.Is(err, fields.ErrInvalidSchema) {
+		t.Errorf("want %v, have %v", fields.ErrInvalidSchema, err)
+	}
+	schema, err := svc.SaveFieldSchema(acme, fields.Schema{Fields: []fields.Field{
+		{Name: "points", Type: fields.Number},
+		{Name: "team", Type: fields.Enum, Values: []string{"backend", "frontend"}, Required: true},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if schema.Tenant != "acme" || schema.Updated.IsZero() {
+		t.Errorf("want the schema of the tenant, stamped, have %+v", schema)
+	}
+
+	if _, err := svc.AddToDo(acme, models.ToDoItem{Task: "ship it"}); !errors.Is(err, fields.ErrInvalidCustomFields) {
+		t.Errorf("want %v without the required team, have %v", fields.ErrInvalidCustomFields, err)
+	}
+	if _, err := svc.AddToDo(globex, models.ToDoItem{Task: "ship it", CustomFields: models.CustomFields{"team": "backend"}}); !errors.Is(err, fields.ErrInvalidCustomFields) {
+		t.Errorf("want %v for a field another tenant defines, have %v", fields.ErrInvalidCustomFields, err)
+	}
+	id, err := svc.AddToDo(acme, models.ToDoItem{Task: "ship it", CustomFields: models.CustomFields{"team": "backend", "points": 3.0}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.UpdateToDo(acme, id, models.ToDoUpdate{CustomFields: models.CustomFields{"team": nil}}); !errors.Is(err, fields.ErrInvalidCustomFields) {
+		t.Errorf("want %v removing the required team, have %v", fields.ErrInvalidCustomFields, err)
+	}
+	if _, err := svc.UpdateToDo(acme, id, models.ToDoUpdate{CustomFields: models.CustomFields{"points": nil, "team": "frontend"}}); err != nil {
+		t.Fatal(err)
+	}
+	if todo, err := svc.GetToDoByID(acme, id); err != nil || fmt.Sprint(todo.CustomFields) != "map[team:frontend]" {
+		t.Errorf("want the custom fields updated, have %+v, %v", todo, err)
+	}
+	if page, err := svc.GetAllToDo(acme, models.ListOptions{Query: "customFields.team = frontend"}); err != nil || len(page.Todos) != 1 {
+		t.Errorf("want the todo listed by its team, have %+v, %v", page, err)
+	}
+}
+
+func TestWorkflow(t *testing.T) {
+	svc, err := NewBasicService(store.NewInMemoryStore(), DefaultConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	acme, globex := tenant.NewContext(context.Background(), "acme"), tenant.NewContext(context.Background(), "globex")
+
+	if _, err := svc.SaveWorkflow(acme, workflow.Workflow{States: []workflow.State{{Name: "open"}}}); !errors.Is(err, workflow.ErrInvalidWorkflow) {
+		t.Errorf("want %v, have %v", workflow.ErrInvalidWorkflow, err)
+	}
+	w, err := svc.SaveWorkflow(acme, workflow.Workflow{
+		States: []workflow.State{{Name: "open"}, {Name: "review"}, {Name: "done", Done: true}},
+		Transitions: []workflow.Transition{
+			{From: "open", To: "review"},
+			{From: "review", To: "done"},
+			{From: "done", To: "open"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w.Tenant != "acme" || w.Updated.IsZero() {
+		t.Errorf("want the workflow of the tenant, stamped, have %+v", w)
+	}
+
+	if _, err := svc.AddToDo(acme, models.ToDoItem{Task: "ship it", State: "blocked"}); !errors.Is(err, workflow.ErrUnknownState) {
+		t.Errorf("want %v for a state another tenant defines, have %v", workflow.ErrUnknownState, err)
+	}
+	id, err := svc.AddToDo(acme, models.ToDoItem{Task: "ship it"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if todo, err := svc.GetToDoByID(acme, id); err != nil || todo.State != "open" || todo.Status {
+		t.Errorf("want the todo open, have %+v, %v", todo, err)
+	}
+	if _, err := svc.CompleteToDo(acme, id); !errors.Is(err, workflow.ErrTransitionNotAllowed) {
+		t.Errorf("want %v completing a todo not reviewed, have %v", workflow.ErrTransitionNotAllowed, err)
+	}
+	review, done := "review", true
+	if _, err := svc.UpdateToDo(acme, id, models.ToDoUpdate{State: &review}); err != nil {
+		t.Fatal(err)
+	}
+	if result, err := svc.BatchToDo(acme, models.BatchUndo, []models.TaskID{id}); err != nil || len(result.Failures) != 1 {
+		t.Errorf("want reopening a todo in review to fail, have %+v, %v", result, err)
+	}
+	if _, err := svc.UpdateToDo(acme, id, models.ToDoUpdate{Status: &done}); err != nil {
+		t.Fatal(err)
+	}
+	if todo, err := svc.GetToDoByID(acme, id); err != nil || todo.State != "done" || !todo.Status {
+		t.Errorf("want the todo done, have %+v, %v", todo, err)
+	}
+	if _, err := svc.UpdateToDo(acme, id, models.ToDoUpdate{State: &review}); !errors.Is(err, workflow.ErrTransitionNotAllowed) {
+		t.Errorf("want %v, have %v", workflow.ErrTransitionNotAllowed, err)
+	}
+	if _, err := svc.UnDoToDo(acme, id); err != nil {
+		t.Fatal(err)
+	}
+
+	// The default workflow allows every move.
+	blocked := "blocked"
+	other, err := svc.AddToDo(globex, models.ToDoItem{Task: "ship it", State: "in-progress"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.UpdateToDo(globex, other, models.ToDoUpdate{State: &blocked}); err != nil {
+		t.Fatal(err)
+	}
+	if todo, err := svc.GetToDoByID(globex, other); err != nil || todo.State != "blocked" {
+		t.Errorf("want the todo blocked, have %+v, %v", todo, err)
+	}
+
+	// The workflow of a tenant never sees nor moves the todos of another.
+	if todo, err := svc.GetToDoByID(globex, id); !errors.Is(err, store.ErrToDoNotFound) {
+		t.Errorf("want %v for the todo of acme, have %+v, %v", store.ErrToDoNotFound, todo, err)
+	}
+	if _, err := svc.UpdateToDo(globex, id, models.ToDoUpdate{State: &blocked}); !errors.Is(err, store.ErrToDoNotFound) {
+		t.Errorf("want %v moving the todo of acme, have %v", store.ErrToDoNotFound, err)
+	}
+	for ctx, want := range map[context.Context]models.ToDoItem{
+		acme:   {ID: id, State: "open"},
+		globex: {ID: other, State: "blocked"},
+	} {
+		page, err := svc.GetAllToDo(ctx, models.ListOptions{})
+		if err != nil || len(page.Todos) != 1 || page.Todos[0].ID != want.ID || page.Todos[0].State != want.State {
+			t.Errorf("%s: want %s %s only, have %+v, %v", tenant.FromContext(ctx), want.ID, want.State, page.Todos, err)
+		}
+	}
+	mixed := []models.ToDoItem{{ID: id, Tenant: "acme", Status: true}, {ID: other, Tenant: "globex"}}
+	if todos, err := svc.(basicService).withStates(globex, mixed); err != nil || len(todos) != 1 || todos[0].ID != other {
+		t.Errorf("want the todos of globex only, have %+v, %v", todos, err)
+	}
+}
+
+func TestDependencies(t *testing.T) {
+	var unblocked []models.TaskID
+	cfg := DefaultConfig
+	cfg.Events = events.PublisherFunc(func(_ context.Context, e events.Event) error {
+		if e.Type == events.ToDoUnblocked {
+			unblocked = append(unblocked, e.TaskID)
+		}
+		return nil
+	})
+	svc, err := New(store.NewInMemoryStore(), nil, nil, nil, nil, nil, nil, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := tenant.NewContext(context.Background(), "acme")
+
+	var design, build, ship models.TaskID
+	for task, id := range map[string]*models.TaskID{"design it": &design, "build it": &build, "ship it": &ship} {
+		if *id, err = svc.AddToDo(ctx, models.ToDoItem{Task: task}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, link := range [][2]models.TaskID{{build, design}, {ship, build}, {ship, design}, {ship, design}} {
+		if err := svc.AddDependency(ctx, link[0], link[1]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := svc.AddDependency(ctx, design, ship); !errors.Is(err, dependency.ErrCycle) {
+		t.Errorf("want %v, have %v", dependency.ErrCycle, err)
+	}
+	if err := svc.AddDependency(ctx, design, design); !errors.Is(err, dependency.ErrCycle) {
+		t.Errorf("want %v for a todo blocked by itself, have %v", dependency.ErrCycle, err)
+	}
+	if err := svc.AddDependency(ctx, design, "000000000000000000000000"); !errors.Is(err, store.ErrToDoNotFound) {
+		t.Errorf("want %v, have %v", store.ErrToDoNotFound, err)
+	}
+
+	if todo, err := svc.GetToDoByID(ctx, ship); err != nil || todo.Dependencies == nil || fmt.Sprint(todo.Dependencies.BlockedBy) != fmt.Sprint([]models.TaskID{build, design}) || !todo.Dependencies.Blocked {
+		t.Errorf("want the todo blocked by two others, have %+v, %v", todo.Dependencies, err)
+	}
+	if todo, err := svc.GetToDoByID(ctx, design); err != nil || todo.Dependencies == nil || len(todo.Dependencies.Blocks) != 2 || todo.Dependencies.Blocked {
+		t.Errorf("want the todo blocking two others, have %+v, %v", todo.Dependencies, err)
+	}
+
+	if _, err := svc.CompleteToDo(ctx, design); err != nil {
+		t.Fatal(err)
+	}
+	done := true
+	if _, err := svc.UpdateToDo(ctx, build, models.ToDoUpdate{Status: &done}); err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(unblocked) != fmt.Sprint([]models.TaskID{build, ship}) {
+		t.Errorf("want each todo unblocked once its last blocker is done, have %v", unblocked)
+	}
+
+	if err := svc.RemoveDependency(ctx, ship, design); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.RemoveDependency(ctx, ship, design); !errors.Is(err, dependency.ErrLinkNotFound) {
+		t.Errorf("want %v, have %v", dependency.ErrLinkNotFound, err)
+	}
+	if _, err := svc.DeleteToDo(ctx, build); err != nil {
+		t.Fatal(err)
+	}
+	if todo, err := svc.GetToDoByID(ctx, ship); err != nil || todo.Dependencies != nil {
+		t.Errorf("want the links of the deleted todo gone, have %+v, %v", todo.Dependencies, err)
+	}
+}
+
+func TestRecurrence(t *testing.T) {
+	svc, err := NewBasicService(store.NewInMemoryStore(), DefaultConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	if _, err := svc.AddToDo(ctx, models.ToDoItem{Task: "water plants", Recurrence: "fortnightly"}); !errors.Is(err, models.ErrInvalidRecurrence) {
+		t.Errorf("want %v, have %v", models.ErrInvalidRecurrence, err)
+	}
+	due := time.Now().Add(-36 * time.Hour)
+	id, err := svc.AddToDo(ctx, models.ToDoItem{Task: "water plants", DueDate: &due, Priority: models.PriorityHigh, Recurrence: "every 2 days"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := svc.CompleteToDo(ctx, id); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	page, err := svc.GetAllToDo(ctx, models.ListOptions{Status: models.StatusPending})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Todos) != 1 {
+		t.Fatalf("want the next occurrence added once, have %+v", page.Todos)
+	}
+	next := page.Todos[0]
+	if next.Task != "water plants" || next.Priority != models.PriorityHigh || next.Recurrence != "every 2 days" {
+		t.Errorf("want the next occurrence to repeat the todo, have %+v", next)
+	}
+	if want := due.AddDate(0, 0, 2); next.DueDate == nil || !next.DueDate.Equal(want) {
+		t.Errorf("want the next occurrence due %v, have %v", want, next.DueDate)
+	}
+
+	stop := models.Recurrence("")
+	if _, err := svc.UpdateToDo(ctx, next.ID, models.ToDoUpdate{Recurrence: &stop}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.BatchToDo(ctx, models.BatchComplete, []models.TaskID{next.ID}); err != nil {
+		t.Fatal(err)
+	}
+	if page, err := svc.GetAllToDo(ctx, models.ListOptions{Status: models.StatusPending}); err != nil || len(page.Todos) != 0 {
+		t.Errorf("want no occurrence of a todo that stopped repeating, have %+v, %v", page.Todos, err)
+	}
+}
+
+// failingInserts is a store.Store failing the inserts while fail is set.
+type failingInserts struct {
+	store.Store
+	fail bool
+}
+
+func (s *failingInserts) InsertToDo(ctx context.Context, todo models.ToDoItem) (models.TaskID, error) {
+	if s.fail {
+		return "", errors.New("store down")
+	}
+	return s.Store.InsertToDo(ctx, todo)
+}
+
+func TestRecurrenceRetried(t *testing.T) {
+	dbStore := &failingInserts{Store: store.NewInMemoryStore()}
+	svc, err := NewBasicService(dbStore, DefaultConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	id, err := svc.AddToDo(ctx, models.ToDoItem{Task: "water plants", Recurrence: "daily"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, complete := range []func() error{
+		func() error { _, err := svc.CompleteToDo(ctx, id); return err },
+		func() error {
+			done := true
+			_, err := svc.UpdateToDo(ctx, id, models.ToDoUpdate{Status: &done})
+			return err
+		},
+		func() error {
+			result, err := svc.BatchToDo(ctx, models.BatchComplete, []models.TaskID{id})
+			if err == nil && len(result.Failures) > 0 {
+				err = errors.New(result.Failures[0].Error)
+			}
+			return err
+		},
+	} {
+		dbStore.fail = true
+		if err := complete(); err == nil {
+			t.Fatal("want the completion failed with the next occurrence")
+		}
+		if todo, err := svc.GetToDoByID(ctx, id); err != nil || todo.Status {
+			t.Fatalf("want the todo left pending, have %+v, %v", todo, err)
+		}
+	}
+	dbStore.fail = false
+	if _, err := svc.CompleteToDo(ctx, id); err != nil {
+		t.Fatal(err)
+	}
+	page, err := svc.GetAllToDo(ctx, models.ListOptions{Status: models.StatusPending, Scheduled: true})
+	if err != nil || len(page.Todos) != 1 || page.Todos[0].ID == id {
+		t.Errorf("want the next occurrence added once retried, have %+v, %v", page.Todos, err)
+	}
+}
+
+func TestNextOccurrence(t *testing.T) {
+	now := time.Date(2020, 3, 10, 12, 0, 0, 0, time.UTC)
+	at := func(t time.Time) *time.Time { return &t }
+	for _, test := range []struct {
+		todo     models.ToDoItem
+		due, sch *time.Time
+	}{
+		{models.ToDoItem{Recurrence: "daily"}, nil, at(now.AddDate(0, 0, 1))},
+		{models.ToDoItem{Recurrence: "weekly", ScheduleAt: at(now.Add(-time.Hour))}, nil, at(now.Add(-time.Hour).AddDate(0, 0, 7))},
+		{models.ToDoItem{Recurrence: "monthly", DueDate: at(now.AddDate(0, -3, 0)), ScheduleAt: at(now.AddDate(0, -3, -1))}, at(now.AddDate(0, 1, 0)), at(now.AddDate(0, 1, -1))},
+		{models.ToDoItem{Recurrence: "Every 1 Year", DueDate: at(now.AddDate(0, 0, 3))}, at(now.AddDate(1, 0, 3)), nil},
+		{models.ToDoItem{Recurrence: "daily", DueDate: at(now.Add(-12*time.Hour).AddDate(0, 0, -500))}, at(now.Add(12 * time.Hour)), nil},
+		{models.ToDoItem{Recurrence: "daily", DueDate: at(now.AddDate(-10, 0, 0))}, at(now.AddDate(0, 0, 1)), nil},
+	} {
+		next := nextOccurrence(test.todo, now)
+		if !sameTime(next.DueDate, test.due) || !sameTime(next.ScheduleAt, test.sch) {
+			t.Errorf("%+v: want due %v, scheduled %v, have %v, %v", test.todo, test.due, test.sch, next.DueDate, next.ScheduleAt)
+		}
+	}
+}
+
+func sameTime(a, b *time.Time) bool {
+	return a == nil && b == nil || a != nil && b != nil && a.Equal(*b)
+}
+
+func TestCompletionStats(t *testing.T) {
+	svc, err := NewBasicService(store.NewInMemoryStore(), DefaultConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	if stats, err := svc.CompletionStats(ctx); err != nil || stats.Count != 0 {
+		t.Errorf("want no completions, have %+v, %v", stats, err)
+	}
+	var ids []models.TaskID
+	for _, task := range []string{"a", "b", "c"} {
+		id, err := svc.AddToDo(ctx, models.ToDoItem{Task: task})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, id)
+	}
+	done := true
+	svc.CompleteToDo(ctx, ids[0])
+	svc.UpdateToDo(ctx, ids[1], models.ToDoUpdate{Status: &done})
+	svc.CompleteToDo(ctx, ids[2])
+	svc.UnDoToDo(ctx, ids[2])
+	first, err := svc.GetToDoByID(ctx, ids[0])
+	if err != nil || first.CompletedAt == nil {
+		t.Fatalf("want a completed, have %+v, %v", first, err)
+	}
+	svc.CompleteToDo(ctx, ids[0])
+	if again, _ := svc.GetToDoByID(ctx, ids[0]); !again.CompletedAt.Equal(*first.CompletedAt) {
+		t.Errorf("want the first completion kept, have %v", again.CompletedAt)
+	}
+
+	stats, err := svc.CompletionStats(ctx)
+	if err != nil || stats.Count != 2 || stats.Mean < 0 || stats.P50 > stats.Max {
+		t.Errorf("want the stats of a and b, have %+v, %v", stats, err)
+	}
+}
+
+func TestQuantile(t *testing.T) {
+	sorted := []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	for q, want := range map[float64]float64{0: 1, .5: 5, .9: 9, .99: 10, 1: 10} {
+		if have := quantile(sorted, q); have != want {
+			t.Errorf("quantile %v: want %v, have %v", q, want, have)
+		}
+	}
+}
+
+type trackFunc func(analytics.Event) error
+
+func (f trackFunc) Track(_ context.Context, e analytics.Event) error { return f(e) }
+
+func TestAnalytics(t *testing.T) {
+	var events []analytics.Event
+	cfg := DefaultConfig
+	cfg.Analytics = analytics.NewTracker(trackFunc(func(e analytics.Event) error {
+		events = append(events, e)
+		return errors.New("collector down")
+	}))
+	svc, err := New(store.NewInMemoryStore(), nil, nil, nil, nil, nil, nil, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	id, err := svc.AddToDo(ctx, models.ToDoItem{Task: "water the plants"})
+	if err != nil {
+		t.Fatalf("want tracking errors ignored, have %v", err)
+	}
+	done, task := true, "water the cactus"
+	if _, err := svc.UpdateToDo(ctx, id, models.ToDoUpdate{Task: &task}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.UpdateToDo(ctx, id, models.ToDoUpdate{Status: &done}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.CompleteToDo(ctx, "nope"); err == nil {
+		t.Fatal("want an error completing a malformed ID")
+	}
+	if len(events) != 2 || events[0].Name != analytics.TaskCreated || events[1].Name != analytics.TaskCompleted || events[1].TaskID != id {
+		t.Fatalf("want a creation and a completion, have %+v", events)
+	}
+	if _, ok := events[1].Properties["secondsToComplete"]; !ok {
+		t.Errorf("want the time to complete, have %+v", events[1].Properties)
+	}
+
+	events = nil
+	other, err := svc.AddToDo(ctx, models.ToDoItem{Task: "water the garden"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.BatchToDo(ctx, models.BatchComplete, []models.TaskID{id, other, other, "000000000000000000000000"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 || events[1].Name != analytics.TaskCompleted || events[1].TaskID != other {
+		t.Errorf("want the completion of the pending todo of the batch only, have %+v", events)
+	}
+}
+
+func TestWebhooks(t *testing.T) {
+	received := make(chan webhooks.Event, 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var e webhooks.Event
+		json.NewDecoder(r.Body).Decode(&e)
+		received <- e
+	}))
+	defer srv.Close()
+
+	svc, err := New(store.NewInMemoryStore(), nil, nil, nil, nil, nil, nil, DefaultConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := tenant.NewContext(context.Background(), "acme")
+	for _, url := range []string{"localhost", srv.URL} {
+		if _, err := svc.CreateWebhook(ctx, webhooks.Subscription{URL: url}); !errors.Is(err, webhooks.ErrInvalidSubscription) {
+			t.Errorf("%s: want %v, have %v", url, webhooks.ErrInvalidSubscription, err)
+		}
+	}
+
+	cfg := DefaultConfig
+	_, loopback, _ := net.ParseCIDR("127.0.0.0/8")
+	cfg.WebhookNetworks = []*net.IPNet{loopback}
+	if svc, err = New(store.NewInMemoryStore(), nil, nil, nil, nil, nil, nil, cfg); err != nil {
+		t.Fatal(err)
+	}
+	sub, err := svc.CreateWebhook(ctx, webhooks.Subscription{URL: srv.URL, Events: []string{webhooks.ToDoCompleted, webhooks.ToDoDeleted}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sub.ID == "" || sub.Secret == "" || sub.Tenant != "acme" {
+		t.Errorf("want the webhook of the tenant, with an ID and a secret, have %+v", sub)
+	}
+
+	id, err := svc.AddToDo(ctx, models.ToDoItem{Task: "water the plants"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.CompleteToDo(ctx, id); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.DeleteToDo(ctx, id); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{webhooks.ToDoCompleted, webhooks.ToDoDeleted} {
+		select {
+		case e := <-received:
+			if e.TaskID != id || e.Tenant != "acme" {
+				t.Errorf("want the event of %s, have %+v", id, e)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("want a %s callback", want)
+		}
+	}
+
+	id, err = svc.AddToDo(ctx, models.ToDoItem{Task: "water the garden"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, action := range []models.BatchAction{models.BatchComplete, models.BatchComplete, models.BatchDelete} {
+		if _, err := svc.BatchToDo(ctx, action, []models.TaskID{id, id, "000000000000000000000000"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	types := map[string]bool{}
+	for len(types) < 2 {
+		select {
+		case e := <-received:
+			if e.TaskID != id || types[e.Type] {
+				t.Errorf("want a completion and a deletion of %s, have %+v", id, e)
+			}
+			types[e.Type] = true
+		case <-time.After(5 * time.Second):
+			t.Fatalf("want the callbacks of the batches, have %v", types)
+		}
+	}
+	select {
+	case e := <-received:
+		t.Errorf("want a callback per todo the batches acted on, have %+v too", e)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	if err := svc.DeleteWebhook(context.Background(), sub.ID); !errors.Is(err, webhooks.ErrSubscriptionNotFound) {
+		t.Errorf("want %v deleting the webhook of another tenant, have %v", webhooks.ErrSubscriptionNotFound, err)
+	}
+	if err := svc.DeleteWebhook(ctx, sub.ID); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestEvents(t *testing.T) {
+	var published []events.Event
+	cfg := DefaultConfig
+	cfg.Events = events.PublisherFunc(func(_ context.Context, e events.Event) error {
+		published = append(published, e)
+		return nil
+	})
+	svc, err := New(store.NewInMemoryStore(), nil, nil, nil, nil, nil, nil, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := tenant.NewContext(context.Background(), "acme")
+
+	id, err := svc.AddToDo(ctx, models.ToDoItem{Task: "water the plants"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := svc.AddToDo(ctx, models.ToDoItem{Task: "feed the cat"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := true
+	if _, err := svc.UpdateToDo(ctx, id, models.ToDoUpdate{Status: &done}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.UnDoToDo(ctx, id); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.CompleteToDo(ctx, "not an id"); err == nil {
+		t.Fatal("want completing an invalid ID to fail")
+	}
+	if _, err := svc.BatchToDo(ctx, models.BatchDelete, []models.TaskID{id, other, "000000000000000000000000"}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct {
+		Type   string
+		TaskID models.TaskID
+	}{
+		{events.ToDoCreated, id},
+		{events.ToDoCreated, other},
+		{events.ToDoUpdated, id},
+		{events.ToDoCompleted, id},
+		{events.ToDoTransitioned, id},
+		{events.ToDoReopened, id},
+		{events.ToDoTransitioned, id},
+		{events.ToDoDeleted, id},
+		{events.ToDoDeleted, other},
+	}
+	if len(published) != len(want) {
+		t.Fatalf("want %d events, have %+v", len(want), published)
+	}
+	for i, e := range published {
+		if e.Type != want[i].Type || e.TaskID != want[i].TaskID || e.Tenant != "acme" || e.ID == "" {
+			t.Errorf("event %d: want %s of %s, have %+v", i, want[i].Type, want[i].TaskID, e)
+		}
+	}
+	if todo := published[0].Todo; todo == nil || todo.Task != "water the plants" {
+		t.Errorf("want the todo created in its event, have %+v", todo)
+	}
+	if move := published[4].Transition; move == nil || *move != (events.Transition{From: "open", To: "done"}) {
+		t.Errorf("want the todo moved from open to done, have %+v", move)
+	}
+}
+
+func TestOutboxEvents(t *testing.T) {
+	db := mongotest.DB(t)
+	dbStore, err := store.Open(store.Config{
+		URI:          "mongodb://",
+		Collection:   "todos",
+		Mongo:        db,
+		MongoOptions: []store.MongoOption{store.WithOutbox("outbox")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	outbox, err := store.NewMongoOutbox(db, "outbox")
+	if err != nil {
+		t.Fatal(err)
+	}
+	svc, err := New(dbStore, nil, nil, nil, nil, nil, nil, DefaultConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := tenant.NewContext(context.Background(), "acme")
+
+	id, err := svc.AddToDo(ctx, models.ToDoItem{Task: "water the plants"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	next, err := svc.AddToDo(ctx, models.ToDoItem{Task: "repot the plants"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.AddDependency(ctx, next, id); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.CompleteToDo(ctx, id); err != nil {
+		t.Fatal(err)
+	}
+	claimed, err := outbox.Claim(ctx, time.Now(), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	types := map[string]events.Event{}
+	for _, e := range claimed {
+		if e.Tenant != "acme" {
+			t.Errorf("want the events of acme, have %+v", e)
+		}
+		if e.TaskID == id {
+			types[e.Type] = e
+		}
+	}
+	if len(types) != 3 {
+		t.Fatalf("want the todo created, completed and transitioned, have %+v", claimed)
+	}
+	if move := types[events.ToDoTransitioned].Transition; move == nil || *move != (events.Transition{From: "open", To: "done"}) {
+		t.Errorf("want the todo moved from open to done in the outbox, have %+v", move)
+	}
+	var unblocked []events.Event
+	for _, e := range claimed {
+		if e.Type == events.ToDoUnblocked {
+			unblocked = append(unblocked, e)
+		}
+	}
+	if len(unblocked) != 1 || unblocked[0].TaskID != next || unblocked[0].Todo == nil {
+		t.Errorf("want the todo it blocked unblocked in the outbox, with the todo, have %+v", unblocked)
+	}
+}
+
+func TestModeration(t *testing.T) {
+	patterns, _ := moderation.ParseRegexList(strings.NewReader("darn"))
+	cfg := DefaultConfig
+	cfg.Moderator = moderation.New(moderation.NewRegexFilter(patterns), moderation.Policy{Default: moderation.Reject}, nil)
+	svc, err := NewBasicService(store.NewInMemoryStore(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	if _, err := svc.AddToDo(ctx, models.ToDoItem{Task: "fix the darn sink"}); !errors.Is(err, moderation.ErrRejected) {
+		t.Errorf("want %v, have %v", moderation.ErrRejected, err)
+	}
+	id, err := svc.AddToDo(ctx, models.ToDoItem{Task: "fix the sink"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	task := "fix the darn sink"
+	if _, err := svc.UpdateToDo(ctx, id, models.ToDoUpdate{Task: &task}); !errors.Is(err, moderation.ErrRejected) {
+		t.Errorf("want %v, have %v", moderation.ErrRejected, err)
+	}
+	if todo, _ := svc.GetToDoByID(ctx, id); todo.Task != "fix the sink" {
+		t.Errorf("want the task left unchanged, have %q", todo.Task)
+	}
+}
+
+func TestReviewQueue(t *testing.T) {
+	patterns, _ := moderation.ParseRegexList(strings.NewReader("darn"))
+	queue := moderation.NewMemoryQueue()
+	cfg := DefaultConfig
+	cfg.ReviewQueue = queue
+	cfg.Moderator = moderation.New(moderation.NewRegexFilter(patterns), moderation.Policy{Default: moderation.Review}, queue)
+	svc, err := NewBasicService(store.NewInMemoryStore(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	var ids []models.TaskID
+	for _, task := range []string{"fix the sink", "fix the darn sink", "fix the darn tap"} {
+		id, err := svc.AddToDo(ctx, models.ToDoItem{Task: task})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, id)
+	}
+	listed := func() string {
+		page, err := svc.GetAllToDo(ctx, models.ListOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var tasks []string
+		for _, todo := range page.Todos {
+			tasks = append(tasks, todo.Task)
+		}
+		return fmt.Sprint(tasks)
+	}
+	if want, have := "[fix the sink]", listed(); want != have {
+		t.Errorf("want the flagged todos hidden, have %s", have)
+	}
+	if similar, err := svc.SimilarToDo(ctx, "darn"); err != nil || len(similar) != 0 {
+		t.Errorf("want the flagged todos hidden, have %+v, %v", similar, err)
+	}
+	if results, err := svc.SearchToDo(ctx, "darn"); err != nil || len(results) != 0 {
+		t.Errorf("want the flagged todos hidden, have %+v, %v", results, err)
+	}
+	if flagged, err := svc.ListFlagged(ctx); err != nil || len(flagged) != 2 || flagged[0].TaskID != ids[1] {
+		t.Fatalf("want two todos awaiting review, have %+v, %v", flagged, err)
+	}
+
+	if err := svc.ApproveFlagged(ctx, ids[1]); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.RejectFlagged(ctx, ids[2]); err != nil {
+		t.Fatal(err)
+	}
+	if want, have := "[fix the sink fix the darn sink]", listed(); want != have {
+		t.Errorf("want the approved todo listed, have %s", have)
+	}
+	if _, err := svc.GetToDoByID(ctx, ids[2]); err != store.ErrToDoNotFound {
+		t.Errorf("want the rejected todo deleted, have %v", err)
+	}
+	if err := svc.RejectFlagged(ctx, ids[0]); err != moderation.ErrNotQueued {
+		t.Errorf("want %v, have %v", moderation.ErrNotQueued, err)
+	}
+}
@@ -0,0 +1,72 @@
+package addservice
+
+import (
+	"sort"
+	"strings"
+
+	"ray.vhatt/todo-gokit/pkg/models"
+)
+
+const (
+	// similarityThreshold is the minimum trigram similarity for a todo to be
+	// reported as a near-duplicate.
+	similarityThreshold = 0.5
+	// maxSimilar caps the number of near-duplicates reported.
+	maxSimilar = 5
+)
+
+// trigrams returns the set of character trigrams of s. Words are padded so
+// that short words still produce trigrams.
+func trigrams(s string) map[string]struct{} {
+	set := map[string]struct{}{}
+	for _, word := range strings.Fields(strings.ToLower(s)) {
+		r := []rune("  " + word + " ")
+		for i := 0; i+3 <= len(r); i++ {
+			set[string(r[i:i+3])] = struct{}{}
+		}
+	}
+	return set
+}
+
+// similarity returns the Jaccard index of the trigram sets of a and b, from
+// 0 (nothing in common) to 1 (same trigrams).
+func similarity(a, b string) float64 {
+	ta, tb := trigrams(a), trigrams(b)
+	if len(ta) == 0 || len(tb) == 0 {
+		return 0
+	}
+
+	shared := 0
+	for t := range ta {
+		if _, ok := tb[t]; ok {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(ta)+len(tb)-shared)
+}
+
+// rankSimilar keeps the candidates similar enough to task, most similar
+// first.
+func rankSimilar(task string, candidates []models.ToDoItem) []models.ToDoItem {
+	type scored struct {
+		item  models.ToDoItem
+		score float64
+	}
+
+	var matches []scored
+	for _, c := range candidates {
+		if score := similarity(task, c.Task); score >= similarityThreshold {
+			matches = append(matches, scored{c, score})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].score > matches[j].score })
+
+	if len(matches) > maxSimilar {
+		matches = matches[:maxSimilar]
+	}
+	results := make([]models.ToDoItem, len(matches))
+	for i, m := range matches {
+		results[i] = m.item
+	}
+	return results
+}
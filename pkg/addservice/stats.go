@@ -0,0 +1,49 @@
+package addservice
+
+import (
+	"context"
+	"math"
+	"sort"
+
+	"ray.vhatt/todo-gokit/pkg/models"
+)
+
+// completionSample is the number of the latest completions CompletionStats
+// describes, bounding what it reads from the store.
+const completionSample = 10000
+
+// CompletionStats returns the distribution of the time the todos done most
+// recently took to complete.
+func (s basicService) CompletionStats(ctx context.Context) (models.CompletionStats, error) {
+	completions, err := s.dbStore.Completions(ctx, completionSample)
+	if err != nil {
+		return models.CompletionStats{}, err
+	}
+	if len(completions) == 0 {
+		return models.CompletionStats{}, nil
+	}
+	seconds := make([]float64, len(completions))
+	var sum float64
+	for i, c := range completions {
+		seconds[i] = c.Took.Seconds()
+		sum += seconds[i]
+	}
+	sort.Float64s(seconds)
+	return models.CompletionStats{
+		Count: len(seconds),
+		Mean:  sum / float64(len(seconds)),
+		P50:   quantile(seconds, .5),
+		P90:   quantile(seconds, .9),
+		P99:   quantile(seconds, .99),
+		Max:   seconds[len(seconds)-1],
+	}, nil
+}
+
+// quantile returns the q quantile of sorted, by the nearest rank.
+func quantile(sorted []float64, q float64) float64 {
+	rank := int(math.Ceil(q*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	return sorted[rank]
+}
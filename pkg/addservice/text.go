@@ -0,0 +1,62 @@
+package addservice
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+
+	"ray.vhatt/todo-gokit/pkg/models"
+	"ray.vhatt/todo-gokit/pkg/moderation"
+)
+
+// ErrTaskTooLong is returned for a task longer than Config.MaxTaskLen.
+var ErrTaskTooLong = errors.New("task exceeds maximum length")
+
+// normalizeText puts s in NFC, so the same text typed on different systems is
+// stored, compared and counted the same. Whitespace control characters like
+// tabs and newlines become spaces, the other ones are dropped, and the result
+// is trimmed.
+func normalizeText(s string) string {
+	s = strings.Map(func(r rune) rune {
+		switch {
+		case !unicode.IsControl(r):
+			return r
+		case unicode.IsSpace(r):
+			return ' '
+		default:
+			return -1
+		}
+	}, s)
+	return strings.TrimSpace(norm.NFC.String(s))
+}
+
+// task normalizes the text of a todo and checks it against the configured
+// length, counted in runes: an accented letter is one character whatever
+// its encoding.
+func (s basicService) task(text string) (string, error) {
+	text = normalizeText(text)
+	if s.cfg.MaxTaskLen > 0 && utf8.RuneCountInString(text) > s.cfg.MaxTaskLen {
+		return "", ErrTaskTooLong
+	}
+	return text, nil
+}
+
+// screen runs a task by the configured moderator, if any.
+func (s basicService) screen(ctx context.Context, text string) (moderation.Verdict, error) {
+	if s.cfg.Moderator == nil {
+		return moderation.Verdict{}, nil
+	}
+	return s.cfg.Moderator.Screen(ctx, text)
+}
+
+// recordModeration records the todo taskID if screen flagged its task.
+func (s basicService) recordModeration(ctx context.Context, taskID models.TaskID, text string, v moderation.Verdict) error {
+	if s.cfg.Moderator == nil {
+		return nil
+	}
+	return s.cfg.Moderator.Record(ctx, taskID, text, v)
+}
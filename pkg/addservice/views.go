@@ -0,0 +1,130 @@
+package addservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"ray.vhatt/todo-gokit/pkg/models"
+	"ray.vhatt/todo-gokit/pkg/store"
+	"ray.vhatt/todo-gokit/pkg/tenant"
+	"ray.vhatt/todo-gokit/pkg/views"
+)
+
+// ErrUnknownView is returned for a view name that's neither built in nor
+// saved.
+var ErrUnknownView = errors.New("unknown view")
+
+// The views built in the View method. Saved views can't take their names.
+const (
+	// ViewToday lists the open todos to do by the end of the day: those
+	// without a schedule, those whose schedule passed and those scheduled
+	// later today.
+	ViewToday = "today"
+	// ViewUpcoming lists the open todos scheduled from tomorrow on, soonest
+	// first.
+	ViewUpcoming = "upcoming"
+)
+
+// View lists the todos of the built in or saved view name, at the current
+// time.
+func (s basicService) View(ctx context.Context, name string) ([]models.ToDoItem, error) {
+	if builtIn(name) {
+		todos, err := s.listAll(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return view(name, todos, time.Now()), nil
+	}
+
+	saved, err := s.views.Get(ctx, tenant.FromContext(ctx), name)
+	if err == views.ErrViewNotFound {
+		return nil, ErrUnknownView
+	}
+	if err != nil {
+		return nil, err
+	}
+	todos, err := s.listAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return saved.Apply(todos), nil
+}
+
+// SaveView creates or replaces the saved view of the same name, of the
+// tenant of the request.
+func (s basicService) SaveView(ctx context.Context, v views.View) error {
+	if err := v.Validate(); err != nil {
+		return err
+	}
+	if builtIn(v.Name) {
+		return fmt.Errorf("%w: %q is built in", views.ErrInvalidView, v.Name)
+	}
+	v.Tenant = tenant.FromContext(ctx)
+	return s.views.Save(ctx, v)
+}
+
+// ListViews returns the saved views of the tenant of the request, ordered
+// by name.
+func (s basicService) ListViews(ctx context.Context) ([]views.View, error) {
+	return s.views.List(ctx, tenant.FromContext(ctx))
+}
+
+// DeleteView forgets the saved view name of the tenant of the request.
+func (s basicService) DeleteView(ctx context.Context, name string) error {
+	return s.views.Delete(ctx, tenant.FromContext(ctx), name)
+}
+
+func builtIn(name string) bool {
+	return name == ViewToday || name == ViewUpcoming
+}
+
+// listAll pages through the listing of every todo, scheduled ones included.
+// A view is computed from every todo, so its listing isn't cut short by the
+// deadline of the request.
+func (s basicService) listAll(ctx context.Context) ([]models.ToDoItem, error) {
+	ctx = store.WithPartialResults(ctx, 0)
+	var (
+		todos []models.ToDoItem
+		opts  = models.ListOptions{Scheduled: true}
+	)
+	for {
+		page, err := s.dbStore.GetAllToDo(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		todos = append(todos, page.Todos...)
+		if !page.Truncated {
+			if todos, err = s.visible(ctx, todos); err != nil {
+				return nil, err
+			}
+			return s.withStates(ctx, todos)
+		}
+		opts.Cursor = page.Cursor
+	}
+}
+
+// view filters todos down to the known view name, as of now.
+func view(name string, todos []models.ToDoItem, now time.Time) []models.ToDoItem {
+	year, month, day := now.Date()
+	tomorrow := time.Date(year, month, day+1, 0, 0, 0, 0, now.Location())
+
+	var results []models.ToDoItem
+	for _, t := range todos {
+		if t.Status {
+			continue
+		}
+		today := t.ScheduleAt == nil || t.ScheduleAt.Before(tomorrow)
+		if today == (name == ViewToday) {
+			results = append(results, t)
+		}
+	}
+	if name == ViewUpcoming {
+		sort.SliceStable(results, func(i, j int) bool {
+			return results[i].ScheduleAt.Before(*results[j].ScheduleAt)
+		})
+	}
+	return results
+}
@@ -0,0 +1,39 @@
+package addservice
+
+import (
+	"context"
+
+	"ray.vhatt/todo-gokit/pkg/models"
+)
+
+// WatchToDos returns the changes of the todos from now on, until ctx is
+// done or the store stops watching. The todos awaiting review are left out,
+// like from the listings.
+func (s basicService) WatchToDos(ctx context.Context) (<-chan models.ToDoEvent, error) {
+	changes, err := s.dbStore.WatchToDos(ctx)
+	if err != nil {
+		return nil, err
+	}
+	visible := make(chan models.ToDoEvent)
+	go func() {
+		defer close(visible)
+		for e := range changes {
+			if e.Todo != nil {
+				shown, err := s.visible(ctx, []models.ToDoItem{*e.Todo})
+				if err != nil || len(shown) == 0 {
+					continue
+				}
+				if shown, err = s.withStates(ctx, shown); err != nil || len(shown) == 0 {
+					continue
+				}
+				e.Todo = &shown[0]
+			}
+			select {
+			case visible <- e:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return visible, nil
+}
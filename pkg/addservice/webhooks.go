@@ -0,0 +1,148 @@
+package addservice
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kit/kit/log"
+
+	"ray.vhatt/todo-gokit/pkg/models"
+	"ray.vhatt/todo-gokit/pkg/tenant"
+	"ray.vhatt/todo-gokit/pkg/webhooks"
+)
+
+// CreateWebhook subscribes the URL of sub to the events of the tenant of the
+// request, unless its host resolves to addresses internal to the network of
+// the service. The subscription is returned with its ID, and its secret,
+// generated unless given.
+func (s basicService) CreateWebhook(ctx context.Context, sub webhooks.Subscription) (webhooks.Subscription, error) {
+	if err := sub.Validate(); err != nil {
+		return webhooks.Subscription{}, err
+	}
+	if err := sub.CheckAddress(ctx, s.cfg.WebhookNetworks); err != nil {
+		return webhooks.Subscription{}, err
+	}
+	sub.ID = webhooks.NewID()
+	if sub.Secret == "" {
+		sub.Secret = webhooks.NewSecret()
+	}
+	sub.Tenant = tenant.FromContext(ctx)
+	sub.Created = time.Now().UTC()
+	if err := s.webhooks.Save(ctx, sub); err != nil {
+		return webhooks.Subscription{}, err
+	}
+	return sub, nil
+}
+
+// DeleteWebhook unsubscribes the webhook id of the tenant of the request.
+func (s basicService) DeleteWebhook(ctx context.Context, id string) error {
+	return s.webhooks.Delete(ctx, tenant.FromContext(ctx), id)
+}
+
+// WebhooksMiddleware fires the events of the todos created, completed and
+// deleted through next to dispatcher, and of the todos they unblock. Firing
+// never fails a request: the errors are logged to logger.
+func WebhooksMiddleware(dispatcher *webhooks.Dispatcher, logger log.Logger) Middleware {
+	return func(next Service) Service {
+		return webhooksMiddleware{Service: next, dispatcher: dispatcher, logger: logger}
+	}
+}
+
+type webhooksMiddleware struct {
+	Service
+	dispatcher *webhooks.Dispatcher
+	logger     log.Logger
+}
+
+func (mw webhooksMiddleware) AddToDo(ctx context.Context, task models.ToDoItem) (models.TaskID, error) {
+	id, err := mw.Service.AddToDo(ctx, task)
+	if err == nil {
+		mw.fire(ctx, webhooks.ToDoCreated, id)
+	}
+	return id, err
+}
+
+func (mw webhooksMiddleware) CompleteToDo(ctx context.Context, taskID models.TaskID) (models.TaskID, error) {
+	blocking := blockers(ctx, mw.Service, taskID)
+	id, err := mw.Service.CompleteToDo(ctx, taskID)
+	if err == nil {
+		mw.fire(ctx, webhooks.ToDoCompleted, id)
+		mw.unblocked(ctx, blocking)
+	}
+	return id, err
+}
+
+func (mw webhooksMiddleware) UpdateToDo(ctx context.Context, taskID models.TaskID, updates models.ToDoUpdate) (models.TaskID, error) {
+	var blocking []models.TaskID
+	if updates.State != nil || updates.Status != nil {
+		blocking = blockers(ctx, mw.Service, taskID)
+	}
+	id, err := mw.Service.UpdateToDo(ctx, taskID, updates)
+	if err != nil {
+		return id, err
+	}
+	if updates.Status != nil && *updates.Status {
+		mw.fire(ctx, webhooks.ToDoCompleted, id)
+	}
+	mw.unblocked(ctx, blocking)
+	return id, err
+}
+
+func (mw webhooksMiddleware) DeleteToDo(ctx context.Context, taskID models.TaskID) (models.TaskID, error) {
+	id, err := mw.Service.DeleteToDo(ctx, taskID)
+	if err == nil {
+		mw.fire(ctx, webhooks.ToDoDeleted, id)
+	}
+	return id, err
+}
+
+// BatchToDo fires an event for each of the todos the batch completed or
+// deleted, and for those the completions unblock.
+func (mw webhooksMiddleware) BatchToDo(ctx context.Context, action models.BatchAction, ids []models.TaskID) (models.BatchResult, error) {
+	var eventType string
+	switch action {
+	case models.BatchComplete:
+		eventType = webhooks.ToDoCompleted
+	case models.BatchDelete:
+		eventType = webhooks.ToDoDeleted
+	default:
+		return mw.Service.BatchToDo(ctx, action, ids)
+	}
+	found := lookup(ctx, mw.Service, ids...)
+	var blocking []models.TaskID
+	if action == models.BatchComplete {
+		blocking = blockers(ctx, mw.Service, ids...)
+	}
+	result, err := mw.Service.BatchToDo(ctx, action, ids)
+	if err != nil {
+		return result, err
+	}
+	for _, todo := range actedOn(ids, found, result) {
+		if action == models.BatchDelete || !todo.Status {
+			mw.fire(ctx, eventType, todo.ID)
+		}
+	}
+	mw.unblocked(ctx, blocking)
+	return result, err
+}
+
+// unblocked fires an event about each of the todos no longer blocked now
+// that those of blocking done are.
+func (mw webhooksMiddleware) unblocked(ctx context.Context, blocking []models.TaskID) {
+	for _, id := range unblocked(ctx, mw.Service, blocking) {
+		mw.fire(ctx, webhooks.ToDoUnblocked, id)
+	}
+}
+
+func (mw webhooksMiddleware) fire(ctx context.Context, eventType string, taskID models.TaskID) {
+	e := webhooks.Event{
+		ID:     webhooks.NewID(),
+		Type:   eventType,
+		TaskID: taskID,
+		Tenant: tenant.FromContext(ctx),
+		At:     time.Now().UTC(),
+	}
+	if err := mw.dispatcher.Fire(ctx, e); err != nil {
+		mw.logger.Log("webhook", eventType, "taskID", taskID, "err", err)
+	}
+}
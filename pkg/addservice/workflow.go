@@ -0,0 +1,135 @@
+package addservice
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"ray.vhatt/todo-gokit/pkg/models"
+	"ray.vhatt/todo-gokit/pkg/store"
+	"ray.vhatt/todo-gokit/pkg/tenant"
+	"ray.vhatt/todo-gokit/pkg/workflow"
+)
+
+// GetWorkflow returns the workflow of the todos of the tenant of the
+// request.
+func (s basicService) GetWorkflow(ctx context.Context) (workflow.Workflow, error) {
+	return s.workflows.Get(ctx, tenant.FromContext(ctx))
+}
+
+// SaveWorkflow replaces the workflow of the todos of the tenant of the
+// request. The todos in a state it drops fall back to its initial or done
+// state. The workflow is returned as saved.
+func (s basicService) SaveWorkflow(ctx context.Context, w workflow.Workflow) (workflow.Workflow, error) {
+	if err := w.Validate(); err != nil {
+		return workflow.Workflow{}, err
+	}
+	w.Tenant = tenant.FromContext(ctx)
+	w.Updated = time.Now().UTC()
+	if err := s.workflows.Save(ctx, w); err != nil {
+		return workflow.Workflow{}, err
+	}
+	return w, nil
+}
+
+// withStates keeps the todos of the tenant of the request, and sets the
+// state of each as its workflow sees it. The stores scope the todos by
+// tenant already; those of another tenant are dropped rather than shown in
+// the states of a workflow that isn't theirs.
+func (s basicService) withStates(ctx context.Context, todos []models.ToDoItem) ([]models.ToDoItem, error) {
+	if len(todos) == 0 {
+		return todos, nil
+	}
+	w, err := s.GetWorkflow(ctx)
+	if err != nil {
+		return nil, err
+	}
+	t := tenant.FromContext(ctx)
+	own := todos[:0]
+	for _, todo := range todos {
+		if todo.Tenant == t {
+			todo.State = w.StateOf(todo)
+			own = append(own, todo)
+		}
+	}
+	return own, nil
+}
+
+// startState sets the state of task, added, to the one given, or to the
+// initial or done state following its status. Its status then follows its
+// state.
+func (s basicService) startState(ctx context.Context, task *models.ToDoItem) error {
+	w, err := s.GetWorkflow(ctx)
+	if err != nil {
+		return err
+	}
+	if task.State == "" {
+		task.State = w.StateOf(*task)
+	}
+	task.Status, err = w.Done(task.State)
+	return err
+}
+
+// move checks the move of the todo taskID the updates make, and sets both
+// their state and status to where it ends up. A status left as it is keeps
+// the todo in its state. Updates to a missing todo are left to the store.
+func (s basicService) move(ctx context.Context, taskID models.TaskID, updates *models.ToDoUpdate) error {
+	w, err := s.GetWorkflow(ctx)
+	if err != nil {
+		return err
+	}
+	todo, err := s.dbStore.FindByID(ctx, taskID)
+	if errors.Is(err, store.ErrToDoNotFound) || errors.Is(err, models.ErrInvalidTaskID) {
+		if updates.State != nil {
+			_, err := w.Done(*updates.State)
+			return err
+		}
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	from := w.StateOf(todo)
+	to := from
+	switch {
+	case updates.State != nil:
+		to = *updates.State
+	case *updates.Status && !todo.Status:
+		to = w.DoneState()
+	case !*updates.Status && todo.Status:
+		to = w.Initial()
+	}
+	if err := w.Check(from, to); err != nil {
+		return err
+	}
+	done, _ := w.Done(to)
+	updates.State, updates.Status = &to, &done
+	return nil
+}
+
+// disallowed checks that each of the todos ids found may be completed, if
+// done, or reopened otherwise, and returns why for those that may not.
+func (s basicService) disallowed(ctx context.Context, done bool, ids ...models.TaskID) (map[models.TaskID]error, error) {
+	w, err := s.GetWorkflow(ctx)
+	if err != nil {
+		return nil, err
+	}
+	to := w.Initial()
+	if done {
+		to = w.DoneState()
+	}
+	disallowed := make(map[models.TaskID]error)
+	for _, id := range ids {
+		todo, err := s.dbStore.FindByID(ctx, id)
+		if errors.Is(err, store.ErrToDoNotFound) || errors.Is(err, models.ErrInvalidTaskID) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := w.Check(w.StateOf(todo), to); err != nil {
+			disallowed[id] = err
+		}
+	}
+	return disallowed, nil
+}
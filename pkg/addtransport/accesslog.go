@@ -0,0 +1,191 @@
+package addtransport
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// The formats of access logs.
+const (
+	// AccessLogCommon is the Common Log Format of Apache and nginx.
+	AccessLogCommon = "common"
+	// AccessLogCombined is the Common Log Format followed by the quoted
+	// referer and user agent.
+	AccessLogCombined = "combined"
+	// AccessLogJSON writes a JSON object per request, with the request ID and
+	// duration on top of the combined fields.
+	AccessLogJSON = "json"
+)
+
+// AccessLog wraps next so that each request is written to w as a line in
+// format, once its response is complete.
+func AccessLog(w io.Writer, format string, next http.Handler) (http.Handler, error) {
+	var write func(b *strings.Builder, e accessEntry)
+	switch format {
+	case AccessLogCommon:
+		write = writeCommon
+	case AccessLogCombined:
+		write = func(b *strings.Builder, e accessEntry) {
+			writeCommon(b, e)
+			fmt.Fprintf(b, ` "%s" "%s"`, orDash(escapeCLF(e.r.Referer())), orDash(escapeCLF(e.r.UserAgent())))
+		}
+	case AccessLogJSON:
+		write = writeJSON
+	default:
+		return nil, fmt.Errorf("unknown access log format %q", format)
+	}
+
+	var mtx sync.Mutex
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		e := accessEntry{r: r, start: time.Now()}
+		aw := &accessWriter{ResponseWriter: rw, status: http.StatusOK}
+		next.ServeHTTP(aw, r)
+		e.status, e.bytes, e.duration = aw.status, aw.bytes, time.Since(e.start)
+		e.requestID = rw.Header().Get(requestIDHeader)
+
+		var b strings.Builder
+		write(&b, e)
+		b.WriteByte('\n')
+		mtx.Lock()
+		io.WriteString(w, b.String())
+		mtx.Unlock()
+	}), nil
+}
+
+type accessEntry struct {
+	r         *http.Request
+	start     time.Time
+	status    int
+	bytes     int64
+	duration  time.Duration
+	requestID string
+}
+
+// writeCommon writes host ident authuser [date] "request" status bytes.
+func writeCommon(b *strings.Builder, e accessEntry) {
+	var bytes string
+	if e.bytes > 0 {
+		bytes = strconv.FormatInt(e.bytes, 10)
+	}
+	fmt.Fprintf(b, `%s - - [%s] "%s %s %s" %d %s`,
+		remoteHost(e.r), e.start.Format("02/Jan/2006:15:04:05 -0700"),
+		escapeCLF(e.r.Method), escapeCLF(e.r.RequestURI), escapeCLF(e.r.Proto),
+		e.status, orDash(bytes))
+}
+
+// orDash returns s, or - for an empty field.
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func writeJSON(b *strings.Builder, e accessEntry) {
+	line, _ := codec.Marshal(struct {
+		Time       string  `json:"time"`
+		RemoteAddr string  `json:"remote_addr"`
+		Method     string  `json:"method"`
+		URI        string  `json:"uri"`
+		Proto      string  `json:"proto"`
+		Status     int     `json:"status"`
+		Bytes      int64   `json:"bytes"`
+		Referer    string  `json:"referer,omitempty"`
+		UserAgent  string  `json:"user_agent,omitempty"`
+		RequestID  string  `json:"request_id,omitempty"`
+		Duration   float64 `json:"duration_seconds"`
+	}{
+		Time:       e.start.UTC().Format(time.RFC3339Nano),
+		RemoteAddr: remoteHost(e.r),
+		Method:     e.r.Method,
+		URI:        e.r.RequestURI,
+		Proto:      e.r.Proto,
+		Status:     e.status,
+		Bytes:      e.bytes,
+		Referer:    e.r.Referer(),
+		UserAgent:  e.r.UserAgent(),
+		RequestID:  e.requestID,
+		Duration:   e.duration.Seconds(),
+	})
+	b.Write(line)
+}
+
+func remoteHost(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// escapeCLF escapes quotes, backslashes and unprintable bytes the way Apache
+// does, so that a field can't break the line apart.
+func escapeCLF(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '"' || c == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c < 0x20 || c >= 0x7f:
+			fmt.Fprintf(&b, `\x%02x`, c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// accessWriter records the status and size of a response.
+type accessWriter struct {
+	http.ResponseWriter
+	status      int
+	bytes       int64
+	wroteHeader bool
+}
+
+func (w *accessWriter) WriteHeader(code int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		w.status = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *accessWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += int64(n)
+	return n, err
+}
+
+// Flush flushes the response, if it can be, for the streamed responses.
+func (w *accessWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack takes over the connection, for the WebSocket upgrades, which are
+// logged as switching protocols.
+func (w *accessWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("the connection can't be taken over")
+	}
+	w.wroteHeader, w.status = true, http.StatusSwitchingProtocols
+	return h.Hijack()
+}
+
+// Unwrap returns the wrapped ResponseWriter, for http.ResponseController.
+func (w *accessWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
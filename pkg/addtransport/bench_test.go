@@ -0,0 +1,52 @@
+package addtransport
+
+import (
+	"context"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/go-kit/kit/log"
+	"github.com/opentracing/opentracing-go"
+
+	"ray.vhatt/todo-gokit/pkg/addendpoint"
+	"ray.vhatt/todo-gokit/pkg/fixtures"
+	"ray.vhatt/todo-gokit/pkg/models"
+)
+
+// BenchmarkGetAllToDo serves and decodes listings of growing sizes, which
+// is mostly JSON work. Running it with and without the jsoniter tag, e.g.
+//
+//	go test -run NONE -bench GetAllToDo -count 10 ./pkg/addtransport > std.txt
+//	go test -run NONE -bench GetAllToDo -count 10 -tags jsoniter ./pkg/addtransport > jsoniter.txt
+//	benchstat std.txt jsoniter.txt
+//
+// compares the codecs.
+func BenchmarkGetAllToDo(b *testing.B) {
+	for _, size := range []int{10, 100, 1000} {
+		todos := fixtures.Generate(fixtures.Options{Count: size, Completed: 0.5})
+		for i := range todos {
+			todos[i].ID = models.NewTaskID()
+		}
+		list := func(context.Context, interface{}) (interface{}, error) {
+			return addendpoint.GetAllToDoResponse{ToDoPage: models.ToDoPage{Todos: todos}}, nil
+		}
+		handler := NewHTTPHandler(addendpoint.Set{GetAllToDoEndpoint: list}, opentracing.GlobalTracer(), nil, log.NewNopLogger())
+
+		b.Run("todos="+strconv.Itoa(size), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				rec := httptest.NewRecorder()
+				handler.ServeHTTP(rec, httptest.NewRequest("GET", "/getAllToDo", nil))
+				b.SetBytes(int64(rec.Body.Len()))
+				resp, err := decodeHTTPGetAllToDoResponse(context.Background(), rec.Result())
+				if err != nil {
+					b.Fatal(err)
+				}
+				if n := len(resp.(addendpoint.GetAllToDoResponse).Todos); n != size {
+					b.Fatalf("want %d todos, have %d", size, n)
+				}
+			}
+		})
+	}
+}
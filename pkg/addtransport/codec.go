@@ -0,0 +1,25 @@
+package addtransport
+
+import "io"
+
+// jsonCodec encodes and decodes the JSON bodies of requests and responses.
+// It's encoding/json, unless the binary is built with the jsoniter tag:
+//
+//	go build -tags jsoniter ./cmd/addsvc
+//
+// swaps in json-iterator, in its configuration compatible with the standard
+// library. BenchmarkGetAllToDo compares them.
+type jsonCodec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+	NewEncoder(w io.Writer) jsonEncoder
+	NewDecoder(r io.Reader) jsonDecoder
+}
+
+type jsonEncoder interface {
+	Encode(v interface{}) error
+}
+
+type jsonDecoder interface {
+	Decode(v interface{}) error
+}
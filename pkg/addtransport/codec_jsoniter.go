@@ -0,0 +1,19 @@
+//go:build jsoniter
+// +build jsoniter
+
+package addtransport
+
+import (
+	"io"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+var codec jsonCodec = iterCodec{jsoniter.ConfigCompatibleWithStandardLibrary}
+
+type iterCodec struct{ api jsoniter.API }
+
+func (c iterCodec) Marshal(v interface{}) ([]byte, error)      { return c.api.Marshal(v) }
+func (c iterCodec) Unmarshal(data []byte, v interface{}) error { return c.api.Unmarshal(data, v) }
+func (c iterCodec) NewEncoder(w io.Writer) jsonEncoder         { return c.api.NewEncoder(w) }
+func (c iterCodec) NewDecoder(r io.Reader) jsonDecoder         { return c.api.NewDecoder(r) }
@@ -0,0 +1,18 @@
+//go:build !jsoniter
+// +build !jsoniter
+
+package addtransport
+
+import (
+	"encoding/json"
+	"io"
+)
+
+var codec jsonCodec = stdCodec{}
+
+type stdCodec struct{}
+
+func (stdCodec) Marshal(v interface{}) ([]byte, error)      { return json.Marshal(v) }
+func (stdCodec) Unmarshal(data []byte, v interface{}) error { return json.Unmarshal(data, v) }
+func (stdCodec) NewEncoder(w io.Writer) jsonEncoder         { return json.NewEncoder(w) }
+func (stdCodec) NewDecoder(r io.Reader) jsonDecoder         { return json.NewDecoder(r) }
@@ -0,0 +1,81 @@
+package addtransport
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"ray.vhatt/todo-gokit/pkg/errcode"
+)
+
+// jsonBodies makes sure the request bodies next decodes are UTF-8 encoded
+// JSON. A body of another content type is rejected with a 415 response, and
+// one that isn't valid UTF-8 with a 400 response: the JSON decoder would
+// replace the invalid sequences, and persist a task its author didn't write.
+// Requests without a body, like GETs, pass. A body longer than the limit set
+// by MaxBodySize, or DefaultMaxBodySize, is rejected with a 413 response
+// before it is read in full.
+func jsonBodies(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ContentLength == 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+		mediaType, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if err != nil || mediaType != "application/json" || (params["charset"] != "" && !strings.EqualFold(params["charset"], "utf-8")) {
+			writeError(requestIDToContext(r.Context(), r), w, errcode.UnsupportedMediaType, "request bodies must be application/json; charset=utf-8", nil)
+			return
+		}
+		limit := bodyLimit(r.Context())
+		if r.ContentLength > limit {
+			writeError(requestIDToContext(r.Context(), r), w, errcode.RequestTooLarge, fmt.Sprintf("request bodies are limited to %d bytes", limit), nil)
+			return
+		}
+		body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, limit))
+		if err != nil && int64(len(body)) >= limit {
+			writeError(requestIDToContext(r.Context(), r), w, errcode.RequestTooLarge, fmt.Sprintf("request bodies are limited to %d bytes", limit), nil)
+			return
+		}
+		if err != nil {
+			writeError(requestIDToContext(r.Context(), r), w, errcode.Internal, err.Error(), nil)
+			return
+		}
+		if !utf8.Valid(body) {
+			writeError(requestIDToContext(r.Context(), r), w, errcode.InvalidEncoding, "request body isn't valid UTF-8", nil)
+			return
+		}
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+		next.ServeHTTP(w, r)
+	})
+}
+
+// DefaultMaxBodySize is the length in bytes of the longest request body
+// accepted, unless MaxBodySize sets another limit.
+const DefaultMaxBodySize = 1 << 20
+
+type bodyLimitKey struct{}
+
+// MaxBodySize limits the request bodies next decodes to limit bytes, longer
+// ones are rejected with a 413 response. A limit of 0 or less keeps
+// DefaultMaxBodySize.
+func MaxBodySize(limit int64, next http.Handler) http.Handler {
+	if limit <= 0 {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), bodyLimitKey{}, limit)))
+	})
+}
+
+// bodyLimit returns the limit MaxBodySize set in ctx, or DefaultMaxBodySize.
+func bodyLimit(ctx context.Context) int64 {
+	if limit, ok := ctx.Value(bodyLimitKey{}).(int64); ok {
+		return limit
+	}
+	return DefaultMaxBodySize
+}
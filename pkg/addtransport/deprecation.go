@@ -0,0 +1,108 @@
+package addtransport
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/metrics"
+)
+
+// Deprecation is the retirement policy of a route.
+type Deprecation struct {
+	// Since is when the route was deprecated.
+	Since time.Time
+	// Sunset is when the route stops working, zero while undecided.
+	Sunset time.Time
+	// Link documents the migration away from the route, empty if none.
+	Link string
+}
+
+// Deprecated wraps next so that the responses of the routes, keyed by path,
+// carry the Deprecation header, and the Sunset and Link headers when their
+// policy sets them. A path ending in a slash covers the paths below it, like
+// with http.ServeMux. Each request to a deprecated route is counted in used,
+// labelled with the route, and its caller logged, so that the route can go
+// once nobody calls it anymore.
+func Deprecated(routes map[string]Deprecation, used metrics.Counter, logger log.Logger, next http.Handler) http.Handler {
+	if len(routes) == 0 {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route, ok := deprecatedRoute(routes, r.URL.Path)
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+		d := routes[route]
+		h := w.Header()
+		h.Set("Deprecation", "@"+strconv.FormatInt(d.Since.Unix(), 10))
+		if !d.Sunset.IsZero() {
+			h.Set("Sunset", d.Sunset.UTC().Format(http.TimeFormat))
+		}
+		if d.Link != "" {
+			h.Add("Link", "<"+d.Link+`>; rel="deprecation"`)
+		}
+		used.With("route", route).Add(1)
+		logger.Log(
+			"route", route,
+			"method", r.Method,
+			"remote_addr", r.RemoteAddr,
+			"forwarded_for", r.Header.Get("X-Forwarded-For"),
+			"user_agent", r.UserAgent(),
+		)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// deprecatedRoute returns the route of routes path belongs to: path itself,
+// or the longest subtree holding it.
+func deprecatedRoute(routes map[string]Deprecation, path string) (string, bool) {
+	if _, ok := routes[path]; ok {
+		return path, true
+	}
+	var best string
+	for route := range routes {
+		if strings.HasSuffix(route, "/") && strings.HasPrefix(path, route) && len(route) > len(best) {
+			best = route
+		}
+	}
+	return best, best != ""
+}
+
+// ParseDeprecations parses a comma separated list of deprecated routes, each
+// path:since or path:since:sunset, the dates formatted as 2006-01-02, e.g.
+//
+//	/addToDo:2020-06-01:2020-12-31,/getAllToDo:2020-06-01
+//
+// All the routes share link.
+func ParseDeprecations(s, link string) (map[string]Deprecation, error) {
+	routes := make(map[string]Deprecation)
+	if s == "" {
+		return routes, nil
+	}
+	for _, item := range strings.Split(s, ",") {
+		fields := strings.Split(strings.TrimSpace(item), ":")
+		if len(fields) < 2 || len(fields) > 3 || !strings.HasPrefix(fields[0], "/") {
+			return nil, fmt.Errorf("deprecated route %q: want path:since[:sunset]", item)
+		}
+		d := Deprecation{Link: link}
+		var err error
+		if d.Since, err = time.Parse("2006-01-02", fields[1]); err != nil {
+			return nil, fmt.Errorf("deprecated route %q: %v", item, err)
+		}
+		if len(fields) == 3 {
+			if d.Sunset, err = time.Parse("2006-01-02", fields[2]); err != nil {
+				return nil, fmt.Errorf("deprecated route %q: %v", item, err)
+			}
+			if !d.Sunset.After(d.Since) {
+				return nil, fmt.Errorf("deprecated route %q: sunset before deprecation", item)
+			}
+		}
+		routes[fields[0]] = d
+	}
+	return routes, nil
+}
@@ -0,0 +1,110 @@
+package addtransport
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+
+	"ray.vhatt/todo-gokit/pkg/addendpoint"
+	"ray.vhatt/todo-gokit/pkg/errcode"
+	"ray.vhatt/todo-gokit/pkg/models"
+)
+
+// envelope is the body of every response: the data of a success or the error
+// of a failure, and the metadata of the request.
+type envelope struct {
+	Data  interface{} `json:"data,omitempty"`
+	Error *errorBody  `json:"error,omitempty"`
+	Meta  meta        `json:"meta"`
+}
+
+// errorBody reports a failure. Code comes from the errcode registry, Details
+// is specific to the code.
+type errorBody struct {
+	Code    string      `json:"code"`
+	Message string      `json:"message"`
+	Details interface{} `json:"details,omitempty"`
+}
+
+type meta struct {
+	RequestID  string      `json:"requestId,omitempty"`
+	Pagination *pagination `json:"pagination,omitempty"`
+}
+
+type pagination struct {
+	Cursor    string `json:"cursor,omitempty"`
+	Truncated bool   `json:"truncated"`
+	Partial   bool   `json:"partial,omitempty"`
+}
+
+// requestIDHeader carries the ID of a request. Callers may set it to
+// correlate their logs with ours, responses echo it.
+const requestIDHeader = "X-Request-ID"
+
+type requestIDKey struct{}
+
+// requestIDToContext is a transport/http.RequestFunc that stores the ID of
+// the request in the context: the one the caller sent, unless unreasonably
+// long, or a fresh one.
+func requestIDToContext(ctx context.Context, r *http.Request) context.Context {
+	id := r.Header.Get(requestIDHeader)
+	if id == "" || len(id) > 128 {
+		b := make([]byte, 8)
+		rand.Read(b)
+		id = hex.EncodeToString(b)
+	}
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+func requestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// writeEnvelope writes env as the body of a response with status.
+func writeEnvelope(ctx context.Context, w http.ResponseWriter, status int, env envelope) error {
+	env.Meta.RequestID = requestIDFromContext(ctx)
+	if env.Meta.RequestID != "" {
+		w.Header().Set(requestIDHeader, env.Meta.RequestID)
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	return codec.NewEncoder(w).Encode(env)
+}
+
+// writeError writes a response reporting a failure with code.
+func writeError(ctx context.Context, w http.ResponseWriter, code errcode.Code, message string, details interface{}) {
+	writeEnvelope(ctx, w, code.Status, envelope{Error: &errorBody{Code: code.Name, Message: message, Details: details}})
+}
+
+// envelopeOf wraps a successful response. Listings move their paging out of
+// the data into the metadata.
+func envelopeOf(response interface{}) envelope {
+	if resp, ok := response.(addendpoint.GetAllToDoResponse); ok {
+		return envelope{
+			Data: struct {
+				Todos []models.ToDoItem `json:"todos"`
+			}{resp.Todos},
+			Meta: meta{Pagination: &pagination{Cursor: resp.Cursor, Truncated: resp.Truncated, Partial: resp.Partial}},
+		}
+	}
+	return envelope{Data: response}
+}
+
+// decodeData decodes the data of the envelope r carries into v, and returns
+// its metadata. Primarily useful in a client.
+func decodeData(r *http.Response, v interface{}) (meta, error) {
+	var env struct {
+		Data json.RawMessage `json:"data"`
+		Meta meta            `json:"meta"`
+	}
+	if err := codec.NewDecoder(r.Body).Decode(&env); err != nil {
+		return meta{}, err
+	}
+	if len(env.Data) == 0 {
+		return env.Meta, nil
+	}
+	return env.Meta, codec.Unmarshal(env.Data, v)
+}
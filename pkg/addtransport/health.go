@@ -0,0 +1,34 @@
+package addtransport
+
+import (
+	"net/http"
+
+	"ray.vhatt/todo-gokit/pkg/errcode"
+)
+
+// The response of /healthz is serialized once, and its header value shared,
+// so that answering a health check doesn't allocate.
+var (
+	healthzBody        = []byte(`{"data":{"status":"up"}}` + "\n")
+	healthzContentType = []string{"application/json; charset=utf-8"}
+	healthzAllow       = []string{"GET, HEAD"}
+)
+
+// healthz tells load balancers the process is serving. It bypasses the
+// endpoint chain, with its limiters, tracing and logging, and the database:
+// /ping reports whether the database is up, at the cost of a round trip.
+func healthz(w http.ResponseWriter, r *http.Request) {
+	h := w.Header()
+	switch r.Method {
+	case "GET", "HEAD":
+	default:
+		h["Allow"] = healthzAllow
+		writeError(requestIDToContext(r.Context(), r), w, errcode.MethodNotAllowed, http.StatusText(http.StatusMethodNotAllowed), map[string][]string{"allow": {"GET", "HEAD"}})
+		return
+	}
+	h["Content-Type"] = healthzContentType
+	w.WriteHeader(http.StatusOK)
+	if r.Method == "GET" {
+		w.Write(healthzBody)
+	}
+}
@@ -3,11 +3,11 @@ package addtransport
 import (
 	"bytes"
 	"context"
-	"encoding/json"
-	"errors"
+	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"sort"
 	"strings"
 	"time"
 
@@ -28,12 +28,16 @@ import (
 
 	"ray.vhatt/todo-gokit/pkg/addendpoint"
 	"ray.vhatt/todo-gokit/pkg/addservice"
+	"ray.vhatt/todo-gokit/pkg/errcode"
+	"ray.vhatt/todo-gokit/pkg/models"
 )
 
 // NewHTTPHandler returns an HTTP handler that makes a set of endpoints
-// available on predefined paths.
+// available on predefined paths. Each path only accepts the HTTP method used
+// by NewHTTPClient, other methods get a 405 response.
 func NewHTTPHandler(endpoints addendpoint.Set, otTracer stdopentracing.Tracer, zipkinTracer *stdzipkin.Tracer, logger log.Logger) http.Handler {
 	options := []httptransport.ServerOption{
+		httptransport.ServerBefore(requestIDToContext, tenantToContext),
 		httptransport.ServerErrorEncoder(errorEncoder),
 		httptransport.ServerErrorHandler(transport.NewLogErrorHandler(logger)),
 	}
@@ -48,60 +52,274 @@ func NewHTTPHandler(endpoints addendpoint.Set, otTracer stdopentracing.Tracer, z
 	}
 
 	m := http.NewServeMux()
-	m.Handle("/sum", httptransport.NewServer(
+	m.HandleFunc("/healthz", healthz)
+	m.Handle("/sum", allowMethod("POST", rateLimitHeaders(endpoints.Limiters["Sum"], httptransport.NewServer(
 		endpoints.SumEndpoint,
 		decodeHTTPSumRequest,
 		encodeHTTPGenericResponse,
 		append(options, httptransport.ServerBefore(opentracing.HTTPToContext(otTracer, "Sum", logger)))...,
-	))
-	m.Handle("/concat", httptransport.NewServer(
+	))))
+	m.Handle("/concat", allowMethod("POST", rateLimitHeaders(endpoints.Limiters["Concat"], httptransport.NewServer(
 		endpoints.ConcatEndpoint,
 		decodeHTTPConcatRequest,
 		encodeHTTPGenericResponse,
 		append(options, httptransport.ServerBefore(opentracing.HTTPToContext(otTracer, "Concat", logger)))...,
-	))
+	))))
+	m.Handle("/multiply", allowMethod("POST", rateLimitHeaders(endpoints.Limiters["Multiply"], httptransport.NewServer(
+		endpoints.MultiplyEndpoint,
+		decodeHTTPMultiplyRequest,
+		encodeHTTPGenericResponse,
+		append(options, httptransport.ServerBefore(opentracing.HTTPToContext(otTracer, "Multiply", logger)))...,
+	))))
+	m.Handle("/divide", allowMethod("POST", rateLimitHeaders(endpoints.Limiters["Divide"], httptransport.NewServer(
+		endpoints.DivideEndpoint,
+		decodeHTTPDivideRequest,
+		encodeHTTPGenericResponse,
+		append(options, httptransport.ServerBefore(opentracing.HTTPToContext(otTracer, "Divide", logger)))...,
+	))))
 
-	m.Handle("/ping", httptransport.NewServer(
+	m.Handle("/ping", allowMethod("GET", rateLimitHeaders(endpoints.Limiters["Ping"], httptransport.NewServer(
 		endpoints.PingEndpoint,
 		decodeHTTPPingRequest,
 		encodeHTTPGenericResponse,
 		append(options, httptransport.ServerBefore(opentracing.HTTPToContext(otTracer, "Ping", logger)))...,
-	))
+	))))
 
-	m.Handle("/addToDo", httptransport.NewServer(
+	m.Handle("/addToDo", allowMethod("POST", rateLimitHeaders(endpoints.Limiters["AddToDo"], httptransport.NewServer(
 		endpoints.AddToDoEndpoint,
 		decodeHTTPAddToDoRequest,
 		encodeHTTPGenericResponse,
 		append(options, httptransport.ServerBefore(opentracing.HTTPToContext(otTracer, "AddToDo", logger)))...,
-	))
+	))))
 
-	m.Handle("/completeToDo", httptransport.NewServer(
+	m.Handle("/completeToDo", allowMethod("PUT", rateLimitHeaders(endpoints.Limiters["CompleteToDo"], httptransport.NewServer(
 		endpoints.CompleteToDoEndPoint,
 		decodeHTTPCompleteToDoRequest,
 		encodeHTTPGenericResponse,
 		append(options, httptransport.ServerBefore(opentracing.HTTPToContext(otTracer, "CompleteToDo", logger)))...,
-	))
+	))))
 
-	m.Handle("/unDoToDo", httptransport.NewServer(
+	m.Handle("/unDoToDo", allowMethod("PUT", rateLimitHeaders(endpoints.Limiters["UnDoToDo"], httptransport.NewServer(
 		endpoints.UnDoToDoEndpoint,
 		decodeHTTPUnDoToDoRequest,
 		encodeHTTPGenericResponse,
 		append(options, httptransport.ServerBefore(opentracing.HTTPToContext(otTracer, "UnDoToDo", logger)))...,
-	))
+	))))
+
+	m.Handle("/updateToDo", allowMethod("PATCH", rateLimitHeaders(endpoints.Limiters["UpdateToDo"], httptransport.NewServer(
+		endpoints.UpdateToDoEndpoint,
+		decodeHTTPUpdateToDoRequest,
+		encodeHTTPGenericResponse,
+		append(options, httptransport.ServerBefore(opentracing.HTTPToContext(otTracer, "UpdateToDo", logger)))...,
+	))))
 
-	m.Handle("/deleteToDo", httptransport.NewServer(
+	m.Handle("/deleteToDo", allowMethod("DELETE", rateLimitHeaders(endpoints.Limiters["DeleteToDo"], httptransport.NewServer(
 		endpoints.DeleteToDoEndpoint,
 		decodeHTTPDeleteToDoRequest,
 		encodeHTTPGenericResponse,
 		append(options, httptransport.ServerBefore(opentracing.HTTPToContext(otTracer, "DeleteToDo", logger)))...,
-	))
+	))))
+
+	// A batch answers 200 even when some of its todos failed, they're listed
+	// in the response.
+	m.Handle("/todos/batch", allowMethod("POST", rateLimitHeaders(endpoints.Limiters["BatchToDo"], httptransport.NewServer(
+		endpoints.BatchToDoEndpoint,
+		decodeHTTPBatchToDoRequest,
+		encodeHTTPGenericResponse,
+		append(options, httptransport.ServerBefore(opentracing.HTTPToContext(otTracer, "BatchToDo", logger)))...,
+	))))
+
+	// A todo is blocked by another until that one is done, see
+	// /getToDoByID for the links of a todo.
+	m.Handle("/todos/dependencies", allowMethods(map[string]http.Handler{
+		"POST": rateLimitHeaders(endpoints.Limiters["AddDependency"], httptransport.NewServer(
+			endpoints.AddDependencyEndpoint,
+			decodeHTTPAddDependencyRequest,
+			encodeHTTPGenericResponse,
+			append(options, httptransport.ServerBefore(opentracing.HTTPToContext(otTracer, "AddDependency", logger)))...,
+		)),
+		"DELETE": rateLimitHeaders(endpoints.Limiters["RemoveDependency"], httptransport.NewServer(
+			endpoints.RemoveDependencyEndpoint,
+			decodeHTTPRemoveDependencyRequest,
+			encodeHTTPGenericResponse,
+			append(options, httptransport.ServerBefore(opentracing.HTTPToContext(otTracer, "RemoveDependency", logger)))...,
+		)),
+	}))
+
+	m.Handle("/getToDoByID", allowMethod("GET", rateLimitHeaders(endpoints.Limiters["GetToDoByID"], httptransport.NewServer(
+		endpoints.GetToDoByIDEndpoint,
+		decodeHTTPGetToDoByIDRequest,
+		encodeHTTPGenericResponse,
+		append(options, httptransport.ServerBefore(opentracing.HTTPToContext(otTracer, "GetToDoByID", logger)))...,
+	))))
 
-	m.Handle("/getAllToDo", httptransport.NewServer(
+	m.Handle("/getAllToDo", allowMethod("GET", rateLimitHeaders(endpoints.Limiters["GetAllToDo"], httptransport.NewServer(
 		endpoints.GetAllToDoEndpoint,
 		decodeHTTPGetAllToDoRequest,
 		encodeHTTPGenericResponse,
 		append(options, httptransport.ServerBefore(opentracing.HTTPToContext(otTracer, "GetAllToDo", logger)))...,
-	))
+	))))
+
+	m.Handle("/todos/overdue", allowMethod("GET", rateLimitHeaders(endpoints.Limiters["GetAllToDo"], httptransport.NewServer(
+		endpoints.GetAllToDoEndpoint,
+		decodeHTTPOverdueToDoRequest,
+		encodeHTTPGenericResponse,
+		append(options, httptransport.ServerBefore(opentracing.HTTPToContext(otTracer, "GetAllToDo", logger)))...,
+	))))
+
+	m.Handle("/similarToDo", allowMethod("POST", rateLimitHeaders(endpoints.Limiters["SimilarToDo"], httptransport.NewServer(
+		endpoints.SimilarToDoEndpoint,
+		decodeHTTPSimilarToDoRequest,
+		encodeHTTPGenericResponse,
+		append(options, httptransport.ServerBefore(opentracing.HTTPToContext(otTracer, "SimilarToDo", logger)))...,
+	))))
+
+	m.Handle("/todos/search", allowMethod("GET", rateLimitHeaders(endpoints.Limiters["SearchToDo"], httptransport.NewServer(
+		endpoints.SearchToDoEndpoint,
+		decodeHTTPSearchToDoRequest,
+		encodeHTTPGenericResponse,
+		append(options, httptransport.ServerBefore(opentracing.HTTPToContext(otTracer, "SearchToDo", logger)))...,
+	))))
+
+	m.Handle("/todos/watch", allowMethod("GET", rateLimitHeaders(endpoints.Limiters["WatchToDos"], httptransport.NewServer(
+		endpoints.WatchToDosEndpoint,
+		decodeHTTPWatchToDosRequest,
+		encodeHTTPWatchToDosResponse,
+		append(options, httptransport.ServerBefore(opentracing.HTTPToContext(otTracer, "WatchToDos", logger)))...,
+	))))
+
+	m.Handle("/stats/completion", allowMethod("GET", rateLimitHeaders(endpoints.Limiters["CompletionStats"], httptransport.NewServer(
+		endpoints.CompletionStatsEndpoint,
+		decodeHTTPCompletionStatsRequest,
+		encodeHTTPGenericResponse,
+		append(options, httptransport.ServerBefore(opentracing.HTTPToContext(otTracer, "CompletionStats", logger)))...,
+	))))
+
+	// Views are built in, like /views/today, or saved under /views.
+	m.Handle("/views", allowMethods(map[string]http.Handler{
+		"GET": rateLimitHeaders(endpoints.Limiters["ListViews"], httptransport.NewServer(
+			endpoints.ListViewsEndpoint,
+			decodeHTTPListViewsRequest,
+			encodeHTTPGenericResponse,
+			append(options, httptransport.ServerBefore(opentracing.HTTPToContext(otTracer, "ListViews", logger)))...,
+		)),
+		"POST": rateLimitHeaders(endpoints.Limiters["SaveView"], httptransport.NewServer(
+			endpoints.SaveViewEndpoint,
+			decodeHTTPSaveViewRequest,
+			encodeHTTPGenericResponse,
+			append(options, httptransport.ServerBefore(opentracing.HTTPToContext(otTracer, "SaveView", logger)))...,
+		)),
+	}))
+	m.Handle("/views/", allowMethods(map[string]http.Handler{
+		"GET": rateLimitHeaders(endpoints.Limiters["View"], httptransport.NewServer(
+			endpoints.ViewEndpoint,
+			decodeHTTPViewRequest,
+			encodeHTTPGenericResponse,
+			append(options, httptransport.ServerBefore(opentracing.HTTPToContext(otTracer, "View", logger)))...,
+		)),
+		"DELETE": rateLimitHeaders(endpoints.Limiters["DeleteView"], httptransport.NewServer(
+			endpoints.DeleteViewEndpoint,
+			decodeHTTPDeleteViewRequest,
+			encodeHTTPGenericResponse,
+			append(options, httptransport.ServerBefore(opentracing.HTTPToContext(otTracer, "DeleteView", logger)))...,
+		)),
+	}))
+
+	// Webhooks are subscribed under /webhooks, and unsubscribed by ID.
+	m.Handle("/webhooks", allowMethod("POST", rateLimitHeaders(endpoints.Limiters["CreateWebhook"], httptransport.NewServer(
+		endpoints.CreateWebhookEndpoint,
+		decodeHTTPCreateWebhookRequest,
+		encodeHTTPGenericResponse,
+		append(options, httptransport.ServerBefore(opentracing.HTTPToContext(otTracer, "CreateWebhook", logger)))...,
+	))))
+	m.Handle("/webhooks/", allowMethod("DELETE", rateLimitHeaders(endpoints.Limiters["DeleteWebhook"], httptransport.NewServer(
+		endpoints.DeleteWebhookEndpoint,
+		decodeHTTPDeleteWebhookRequest,
+		encodeHTTPGenericResponse,
+		append(options, httptransport.ServerBefore(opentracing.HTTPToContext(otTracer, "DeleteWebhook", logger)))...,
+	))))
+
+	// The custom fields of the todos of a tenant are defined by the schema
+	// under /fields.
+	m.Handle("/fields", allowMethods(map[string]http.Handler{
+		"GET": rateLimitHeaders(endpoints.Limiters["GetFieldSchema"], httptransport.NewServer(
+			endpoints.GetFieldSchemaEndpoint,
+			decodeHTTPGetFieldSchemaRequest,
+			encodeHTTPGenericResponse,
+			append(options, httptransport.ServerBefore(opentracing.HTTPToContext(otTracer, "GetFieldSchema", logger)))...,
+		)),
+		"PUT": rateLimitHeaders(endpoints.Limiters["SaveFieldSchema"], httptransport.NewServer(
+			endpoints.SaveFieldSchemaEndpoint,
+			decodeHTTPSaveFieldSchemaRequest,
+			encodeHTTPGenericResponse,
+			append(options, httptransport.ServerBefore(opentracing.HTTPToContext(otTracer, "SaveFieldSchema", logger)))...,
+		)),
+	}))
+
+	// The states of the todos of a tenant, and the moves allowed between
+	// them, are defined by the workflow under /workflow.
+	m.Handle("/workflow", allowMethods(map[string]http.Handler{
+		"GET": rateLimitHeaders(endpoints.Limiters["GetWorkflow"], httptransport.NewServer(
+			endpoints.GetWorkflowEndpoint,
+			decodeHTTPGetWorkflowRequest,
+			encodeHTTPGenericResponse,
+			append(options, httptransport.ServerBefore(opentracing.HTTPToContext(otTracer, "GetWorkflow", logger)))...,
+		)),
+		"PUT": rateLimitHeaders(endpoints.Limiters["SaveWorkflow"], httptransport.NewServer(
+			endpoints.SaveWorkflowEndpoint,
+			decodeHTTPSaveWorkflowRequest,
+			encodeHTTPGenericResponse,
+			append(options, httptransport.ServerBefore(opentracing.HTTPToContext(otTracer, "SaveWorkflow", logger)))...,
+		)),
+	}))
+
+	// Interactive clients watch the changes and make theirs over a
+	// WebSocket, see newWebSocketHandler.
+	m.Handle("/ws", allowMethod("GET", newWebSocketHandler(endpoints, logger)))
+
+	// Long-running operations answer 202 with the job doing the work, whose
+	// resource under /jobs/ can then be polled or deleted to cancel it.
+	m.Handle("/factorize", allowMethod("POST", rateLimitHeaders(endpoints.Limiters["Factorize"], httptransport.NewServer(
+		endpoints.FactorizeEndpoint,
+		decodeHTTPFactorizeRequest,
+		encodeHTTPJobResponse,
+		append(options, httptransport.ServerBefore(opentracing.HTTPToContext(otTracer, "Factorize", logger)))...,
+	))))
+	m.Handle("/jobs/", allowMethods(map[string]http.Handler{
+		"GET": rateLimitHeaders(endpoints.Limiters["JobStatus"], httptransport.NewServer(
+			endpoints.JobStatusEndpoint,
+			decodeHTTPJobStatusRequest,
+			encodeHTTPGenericResponse,
+			append(options, httptransport.ServerBefore(opentracing.HTTPToContext(otTracer, "JobStatus", logger)))...,
+		)),
+		"DELETE": rateLimitHeaders(endpoints.Limiters["CancelJob"], httptransport.NewServer(
+			endpoints.CancelJobEndpoint,
+			decodeHTTPCancelJobRequest,
+			encodeHTTPGenericResponse,
+			append(options, httptransport.ServerBefore(opentracing.HTTPToContext(otTracer, "CancelJob", logger)))...,
+		)),
+	}))
+
+	// Flagged todos await review under /moderation, where they're approved
+	// or rejected, see ModerationAdmin.
+	m.Handle("/moderation", allowMethod("GET", rateLimitHeaders(endpoints.Limiters["ListFlagged"], httptransport.NewServer(
+		endpoints.ListFlaggedEndpoint,
+		decodeHTTPListFlaggedRequest,
+		encodeHTTPGenericResponse,
+		append(options, httptransport.ServerBefore(opentracing.HTTPToContext(otTracer, "ListFlagged", logger)))...,
+	))))
+	m.Handle("/moderation/approve/", allowMethod("POST", rateLimitHeaders(endpoints.Limiters["ApproveFlagged"], httptransport.NewServer(
+		endpoints.ApproveFlaggedEndpoint,
+		decodeHTTPApproveFlaggedRequest,
+		encodeHTTPGenericResponse,
+		append(options, httptransport.ServerBefore(opentracing.HTTPToContext(otTracer, "ApproveFlagged", logger)))...,
+	))))
+	m.Handle("/moderation/reject/", allowMethod("POST", rateLimitHeaders(endpoints.Limiters["RejectFlagged"], httptransport.NewServer(
+		endpoints.RejectFlaggedEndpoint,
+		decodeHTTPRejectFlaggedRequest,
+		encodeHTTPGenericResponse,
+		append(options, httptransport.ServerBefore(opentracing.HTTPToContext(otTracer, "RejectFlagged", logger)))...,
+	))))
 
 	return m
 }
@@ -128,7 +346,9 @@ func NewHTTPClient(instance string, otTracer stdopentracing.Tracer, zipkinTracer
 	limiter := ratelimit.NewErroringLimiter(rate.NewLimiter(rate.Every(time.Second), 100))
 
 	// global client middlewares
-	var options []httptransport.ClientOption
+	options := []httptransport.ClientOption{
+		httptransport.ClientBefore(tenantToHTTP),
+	}
 
 	if zipkinTracer != nil {
 		// Zipkin HTTP Client Trace can either be instantiated per endpoint with a
@@ -184,6 +404,47 @@ func NewHTTPClient(instance string, otTracer stdopentracing.Tracer, zipkinTracer
 		}))(concatEndpoint)
 	}
 
+	// The Multiply and Divide endpoints are the same thing.
+	var multiplyEndpoint endpoint.Endpoint
+	{
+		multiplyEndpoint = httptransport.NewClient(
+			"POST",
+			copyURL(u, "/multiply"),
+			encodeHTTPGenericRequest,
+			decodeHTTPMultiplyResponse,
+			append(options, httptransport.ClientBefore(opentracing.ContextToHTTP(otTracer, logger)))...,
+		).Endpoint()
+		multiplyEndpoint = opentracing.TraceClient(otTracer, "Multiply")(multiplyEndpoint)
+		if zipkinTracer != nil {
+			multiplyEndpoint = zipkin.TraceEndpoint(zipkinTracer, "Multiply")(multiplyEndpoint)
+		}
+		multiplyEndpoint = limiter(multiplyEndpoint)
+		multiplyEndpoint = circuitbreaker.Gobreaker(gobreaker.NewCircuitBreaker(gobreaker.Settings{
+			Name:    "Multiply",
+			Timeout: 10 * time.Second,
+		}))(multiplyEndpoint)
+	}
+
+	var divideEndpoint endpoint.Endpoint
+	{
+		divideEndpoint = httptransport.NewClient(
+			"POST",
+			copyURL(u, "/divide"),
+			encodeHTTPGenericRequest,
+			decodeHTTPDivideResponse,
+			append(options, httptransport.ClientBefore(opentracing.ContextToHTTP(otTracer, logger)))...,
+		).Endpoint()
+		divideEndpoint = opentracing.TraceClient(otTracer, "Divide")(divideEndpoint)
+		if zipkinTracer != nil {
+			divideEndpoint = zipkin.TraceEndpoint(zipkinTracer, "Divide")(divideEndpoint)
+		}
+		divideEndpoint = limiter(divideEndpoint)
+		divideEndpoint = circuitbreaker.Gobreaker(gobreaker.NewCircuitBreaker(gobreaker.Settings{
+			Name:    "Divide",
+			Timeout: 10 * time.Second,
+		}))(divideEndpoint)
+	}
+
 	// The Ping endpoint is the same thing, with slightly different
 	// middlewares to demonstrate how to specialize per-endpoint.
 	var pingEndpoint endpoint.Endpoint
@@ -217,9 +478,9 @@ func NewHTTPClient(instance string, otTracer stdopentracing.Tracer, zipkinTracer
 			decodeHTTPAddToDoResponse,
 			append(options, httptransport.ClientBefore(opentracing.ContextToHTTP(otTracer, logger)))...,
 		).Endpoint()
-		addToDoEndpoint = opentracing.TraceClient(otTracer, "AddToDo")(pingEndpoint)
+		addToDoEndpoint = opentracing.TraceClient(otTracer, "AddToDo")(addToDoEndpoint)
 		if zipkinTracer != nil {
-			pingEndpoint = zipkin.TraceEndpoint(zipkinTracer, "AddToDo")(pingEndpoint)
+			addToDoEndpoint = zipkin.TraceEndpoint(zipkinTracer, "AddToDo")(addToDoEndpoint)
 		}
 		addToDoEndpoint = limiter(addToDoEndpoint)
 		addToDoEndpoint = circuitbreaker.Gobreaker(gobreaker.NewCircuitBreaker(gobreaker.Settings{
@@ -239,9 +500,9 @@ func NewHTTPClient(instance string, otTracer stdopentracing.Tracer, zipkinTracer
 			decodeHTTPCompleteToDoResponse,
 			append(options, httptransport.ClientBefore(opentracing.ContextToHTTP(otTracer, logger)))...,
 		).Endpoint()
-		completeToDoEndpoint = opentracing.TraceClient(otTracer, "CompleteToDo")(pingEndpoint)
+		completeToDoEndpoint = opentracing.TraceClient(otTracer, "CompleteToDo")(completeToDoEndpoint)
 		if zipkinTracer != nil {
-			pingEndpoint = zipkin.TraceEndpoint(zipkinTracer, "CompleteToDo")(pingEndpoint)
+			completeToDoEndpoint = zipkin.TraceEndpoint(zipkinTracer, "CompleteToDo")(completeToDoEndpoint)
 		}
 		completeToDoEndpoint = limiter(completeToDoEndpoint)
 		completeToDoEndpoint = circuitbreaker.Gobreaker(gobreaker.NewCircuitBreaker(gobreaker.Settings{
@@ -261,9 +522,9 @@ func NewHTTPClient(instance string, otTracer stdopentracing.Tracer, zipkinTracer
 			decodeHTTPUnDoToDoResponse,
 			append(options, httptransport.ClientBefore(opentracing.ContextToHTTP(otTracer, logger)))...,
 		).Endpoint()
-		unDoToDoEndpoint = opentracing.TraceClient(otTracer, "UnDoToDo")(pingEndpoint)
+		unDoToDoEndpoint = opentracing.TraceClient(otTracer, "UnDoToDo")(unDoToDoEndpoint)
 		if zipkinTracer != nil {
-			pingEndpoint = zipkin.TraceEndpoint(zipkinTracer, "UnDoToDo")(pingEndpoint)
+			unDoToDoEndpoint = zipkin.TraceEndpoint(zipkinTracer, "UnDoToDo")(unDoToDoEndpoint)
 		}
 		unDoToDoEndpoint = limiter(unDoToDoEndpoint)
 		unDoToDoEndpoint = circuitbreaker.Gobreaker(gobreaker.NewCircuitBreaker(gobreaker.Settings{
@@ -272,6 +533,28 @@ func NewHTTPClient(instance string, otTracer stdopentracing.Tracer, zipkinTracer
 		}))(unDoToDoEndpoint)
 	}
 
+	// The UpdateToDo endpoint is the same thing, with slightly different
+	// middlewares to demonstrate how to specialize per-endpoint.
+	var updateToDoEndpoint endpoint.Endpoint
+	{
+		updateToDoEndpoint = httptransport.NewClient(
+			"PATCH",
+			copyURL(u, "/updateToDo"),
+			encodeHTTPGenericRequest,
+			decodeHTTPUpdateToDoResponse,
+			append(options, httptransport.ClientBefore(opentracing.ContextToHTTP(otTracer, logger)))...,
+		).Endpoint()
+		updateToDoEndpoint = opentracing.TraceClient(otTracer, "UpdateToDo")(updateToDoEndpoint)
+		if zipkinTracer != nil {
+			updateToDoEndpoint = zipkin.TraceEndpoint(zipkinTracer, "UpdateToDo")(updateToDoEndpoint)
+		}
+		updateToDoEndpoint = limiter(updateToDoEndpoint)
+		updateToDoEndpoint = circuitbreaker.Gobreaker(gobreaker.NewCircuitBreaker(gobreaker.Settings{
+			Name:    "UpdateToDo",
+			Timeout: 10 * time.Second,
+		}))(updateToDoEndpoint)
+	}
+
 	// The DeleteToDo endpoint is the same thing, with slightly different
 	// middlewares to demonstrate how to specialize per-endpoint.
 	var deleteToDoEndpoint endpoint.Endpoint
@@ -283,9 +566,9 @@ func NewHTTPClient(instance string, otTracer stdopentracing.Tracer, zipkinTracer
 			decodeHTTPDeleteToDoResponse,
 			append(options, httptransport.ClientBefore(opentracing.ContextToHTTP(otTracer, logger)))...,
 		).Endpoint()
-		deleteToDoEndpoint = opentracing.TraceClient(otTracer, "DeleteToDo")(pingEndpoint)
+		deleteToDoEndpoint = opentracing.TraceClient(otTracer, "DeleteToDo")(deleteToDoEndpoint)
 		if zipkinTracer != nil {
-			pingEndpoint = zipkin.TraceEndpoint(zipkinTracer, "DeleteToDo")(pingEndpoint)
+			deleteToDoEndpoint = zipkin.TraceEndpoint(zipkinTracer, "DeleteToDo")(deleteToDoEndpoint)
 		}
 		deleteToDoEndpoint = limiter(deleteToDoEndpoint)
 		deleteToDoEndpoint = circuitbreaker.Gobreaker(gobreaker.NewCircuitBreaker(gobreaker.Settings{
@@ -294,6 +577,48 @@ func NewHTTPClient(instance string, otTracer stdopentracing.Tracer, zipkinTracer
 		}))(deleteToDoEndpoint)
 	}
 
+	var batchToDoEndpoint endpoint.Endpoint
+	{
+		batchToDoEndpoint = httptransport.NewClient(
+			"POST",
+			copyURL(u, "/todos/batch"),
+			encodeHTTPGenericRequest,
+			decodeHTTPBatchToDoResponse,
+			append(options, httptransport.ClientBefore(opentracing.ContextToHTTP(otTracer, logger)))...,
+		).Endpoint()
+		batchToDoEndpoint = opentracing.TraceClient(otTracer, "BatchToDo")(batchToDoEndpoint)
+		if zipkinTracer != nil {
+			batchToDoEndpoint = zipkin.TraceEndpoint(zipkinTracer, "BatchToDo")(batchToDoEndpoint)
+		}
+		batchToDoEndpoint = limiter(batchToDoEndpoint)
+		batchToDoEndpoint = circuitbreaker.Gobreaker(gobreaker.NewCircuitBreaker(gobreaker.Settings{
+			Name:    "BatchToDo",
+			Timeout: 10 * time.Second,
+		}))(batchToDoEndpoint)
+	}
+
+	// The GetToDoByID endpoint is the same thing, with slightly different
+	// middlewares to demonstrate how to specialize per-endpoint.
+	var getToDoByIDEndpoint endpoint.Endpoint
+	{
+		getToDoByIDEndpoint = httptransport.NewClient(
+			"GET",
+			copyURL(u, "/getToDoByID"),
+			encodeHTTPGetToDoByIDRequest,
+			decodeHTTPGetToDoByIDResponse,
+			append(options, httptransport.ClientBefore(opentracing.ContextToHTTP(otTracer, logger)))...,
+		).Endpoint()
+		getToDoByIDEndpoint = opentracing.TraceClient(otTracer, "GetToDoByID")(getToDoByIDEndpoint)
+		if zipkinTracer != nil {
+			getToDoByIDEndpoint = zipkin.TraceEndpoint(zipkinTracer, "GetToDoByID")(getToDoByIDEndpoint)
+		}
+		getToDoByIDEndpoint = limiter(getToDoByIDEndpoint)
+		getToDoByIDEndpoint = circuitbreaker.Gobreaker(gobreaker.NewCircuitBreaker(gobreaker.Settings{
+			Name:    "GetToDoByID",
+			Timeout: 10 * time.Second,
+		}))(getToDoByIDEndpoint)
+	}
+
 	// The GetAllToDo endpoint is the same thing, with slightly different
 	// middlewares to demonstrate how to specialize per-endpoint.
 	var getAllToDoEndpoint endpoint.Endpoint
@@ -301,133 +626,879 @@ func NewHTTPClient(instance string, otTracer stdopentracing.Tracer, zipkinTracer
 		getAllToDoEndpoint = httptransport.NewClient(
 			"GET",
 			copyURL(u, "/getAllToDo"),
-			encodeHTTPGenericRequest,
+			encodeHTTPGetAllToDoRequest,
 			decodeHTTPGetAllToDoResponse,
 			append(options, httptransport.ClientBefore(opentracing.ContextToHTTP(otTracer, logger)))...,
 		).Endpoint()
-		getAllToDoEndpoint = opentracing.TraceClient(otTracer, "GetAllToDo")(pingEndpoint)
+		getAllToDoEndpoint = opentracing.TraceClient(otTracer, "GetAllToDo")(getAllToDoEndpoint)
 		if zipkinTracer != nil {
-			pingEndpoint = zipkin.TraceEndpoint(zipkinTracer, "GetAllToDo")(pingEndpoint)
+			getAllToDoEndpoint = zipkin.TraceEndpoint(zipkinTracer, "GetAllToDo")(getAllToDoEndpoint)
 		}
-		getAllToDoEndpoint = limiter(deleteToDoEndpoint)
+		getAllToDoEndpoint = limiter(getAllToDoEndpoint)
 		getAllToDoEndpoint = circuitbreaker.Gobreaker(gobreaker.NewCircuitBreaker(gobreaker.Settings{
 			Name:    "GetAllToDo",
 			Timeout: 10 * time.Second,
 		}))(getAllToDoEndpoint)
 	}
 
-	// Returning the endpoint.Set as a service.Service relies on the
-	// endpoint.Set implementing the Service methods. That's just a simple bit
-	// of glue code.
-	return addendpoint.Set{
-		SumEndpoint:          sumEndpoint,
-		ConcatEndpoint:       concatEndpoint,
-		PingEndpoint:         pingEndpoint,
-		AddToDoEndpoint:      addToDoEndpoint,
-		CompleteToDoEndPoint: completeToDoEndpoint,
-		UnDoToDoEndpoint:     unDoToDoEndpoint,
-		DeleteToDoEndpoint:   deleteToDoEndpoint,
-		GetAllToDoEndpoint:   getAllToDoEndpoint,
-	}, nil
-}
-
-func copyURL(base *url.URL, path string) *url.URL {
-	next := *base
-	next.Path = path
-	return &next
-}
+	// The SimilarToDo endpoint is the same thing, with slightly different
+	// middlewares to demonstrate how to specialize per-endpoint.
+	var similarToDoEndpoint endpoint.Endpoint
+	{
+		similarToDoEndpoint = httptransport.NewClient(
+			"POST",
+			copyURL(u, "/similarToDo"),
+			encodeHTTPGenericRequest,
+			decodeHTTPSimilarToDoResponse,
+			append(options, httptransport.ClientBefore(opentracing.ContextToHTTP(otTracer, logger)))...,
+		).Endpoint()
+		similarToDoEndpoint = opentracing.TraceClient(otTracer, "SimilarToDo")(similarToDoEndpoint)
+		if zipkinTracer != nil {
+			similarToDoEndpoint = zipkin.TraceEndpoint(zipkinTracer, "SimilarToDo")(similarToDoEndpoint)
+		}
+		similarToDoEndpoint = limiter(similarToDoEndpoint)
+		similarToDoEndpoint = circuitbreaker.Gobreaker(gobreaker.NewCircuitBreaker(gobreaker.Settings{
+			Name:    "SimilarToDo",
+			Timeout: 10 * time.Second,
+		}))(similarToDoEndpoint)
+	}
 
-func errorEncoder(_ context.Context, err error, w http.ResponseWriter) {
-	w.WriteHeader(err2code(err))
-	json.NewEncoder(w).Encode(errorWrapper{Error: err.Error()})
-}
+	var searchToDoEndpoint endpoint.Endpoint
+	{
+		searchToDoEndpoint = httptransport.NewClient(
+			"GET",
+			copyURL(u, "/todos/search"),
+			encodeHTTPSearchToDoRequest,
+			decodeHTTPSearchToDoResponse,
+			append(options, httptransport.ClientBefore(opentracing.ContextToHTTP(otTracer, logger)))...,
+		).Endpoint()
+		searchToDoEndpoint = opentracing.TraceClient(otTracer, "SearchToDo")(searchToDoEndpoint)
+		if zipkinTracer != nil {
+			searchToDoEndpoint = zipkin.TraceEndpoint(zipkinTracer, "SearchToDo")(searchToDoEndpoint)
+		}
+		searchToDoEndpoint = limiter(searchToDoEndpoint)
+		searchToDoEndpoint = circuitbreaker.Gobreaker(gobreaker.NewCircuitBreaker(gobreaker.Settings{
+			Name:    "SearchToDo",
+			Timeout: 10 * time.Second,
+		}))(searchToDoEndpoint)
+	}
 
-func err2code(err error) int {
-	switch err {
-	case addservice.ErrTwoZeroes, addservice.ErrMaxSizeExceeded, addservice.ErrIntOverflow:
-		return http.StatusBadRequest
+	var completionStatsEndpoint endpoint.Endpoint
+	{
+		completionStatsEndpoint = httptransport.NewClient(
+			"GET",
+			copyURL(u, "/stats/completion"),
+			encodeHTTPGenericRequest,
+			decodeHTTPCompletionStatsResponse,
+			append(options, httptransport.ClientBefore(opentracing.ContextToHTTP(otTracer, logger)))...,
+		).Endpoint()
+		completionStatsEndpoint = opentracing.TraceClient(otTracer, "CompletionStats")(completionStatsEndpoint)
+		if zipkinTracer != nil {
+			completionStatsEndpoint = zipkin.TraceEndpoint(zipkinTracer, "CompletionStats")(completionStatsEndpoint)
+		}
+		completionStatsEndpoint = limiter(completionStatsEndpoint)
+		completionStatsEndpoint = circuitbreaker.Gobreaker(gobreaker.NewCircuitBreaker(gobreaker.Settings{
+			Name:    "CompletionStats",
+			Timeout: 10 * time.Second,
+		}))(completionStatsEndpoint)
 	}
-	return http.StatusInternalServerError
-}
 
-func errorDecoder(r *http.Response) error {
-	var w errorWrapper
-	if err := json.NewDecoder(r.Body).Decode(&w); err != nil {
-		return err
+	var viewEndpoint endpoint.Endpoint
+	{
+		viewEndpoint = httptransport.NewClient(
+			"GET",
+			copyURL(u, "/views/"),
+			encodeHTTPViewRequest,
+			decodeHTTPViewResponse,
+			append(options, httptransport.ClientBefore(opentracing.ContextToHTTP(otTracer, logger)))...,
+		).Endpoint()
+		viewEndpoint = opentracing.TraceClient(otTracer, "View")(viewEndpoint)
+		if zipkinTracer != nil {
+			viewEndpoint = zipkin.TraceEndpoint(zipkinTracer, "View")(viewEndpoint)
+		}
+		viewEndpoint = limiter(viewEndpoint)
+		viewEndpoint = circuitbreaker.Gobreaker(gobreaker.NewCircuitBreaker(gobreaker.Settings{
+			Name:    "View",
+			Timeout: 10 * time.Second,
+		}))(viewEndpoint)
 	}
-	return errors.New(w.Error)
-}
 
-type errorWrapper struct {
-	Error string `json:"error"`
-}
+	var saveViewEndpoint endpoint.Endpoint
+	{
+		saveViewEndpoint = httptransport.NewClient(
+			"POST",
+			copyURL(u, "/views"),
+			encodeHTTPGenericRequest,
+			decodeHTTPSaveViewResponse,
+			append(options, httptransport.ClientBefore(opentracing.ContextToHTTP(otTracer, logger)))...,
+		).Endpoint()
+		saveViewEndpoint = opentracing.TraceClient(otTracer, "SaveView")(saveViewEndpoint)
+		if zipkinTracer != nil {
+			saveViewEndpoint = zipkin.TraceEndpoint(zipkinTracer, "SaveView")(saveViewEndpoint)
+		}
+		saveViewEndpoint = limiter(saveViewEndpoint)
+		saveViewEndpoint = circuitbreaker.Gobreaker(gobreaker.NewCircuitBreaker(gobreaker.Settings{
+			Name:    "SaveView",
+			Timeout: 10 * time.Second,
+		}))(saveViewEndpoint)
+	}
 
-// decodeHTTPSumRequest is a transport/http.DecodeRequestFunc that decodes a
-// JSON-encoded sum request from the HTTP request body. Primarily useful in a
-// server.
-func decodeHTTPSumRequest(_ context.Context, r *http.Request) (interface{}, error) {
-	var req addendpoint.SumRequest
-	err := json.NewDecoder(r.Body).Decode(&req)
-	return req, err
-}
+	var listViewsEndpoint endpoint.Endpoint
+	{
+		listViewsEndpoint = httptransport.NewClient(
+			"GET",
+			copyURL(u, "/views"),
+			encodeHTTPGenericRequest,
+			decodeHTTPListViewsResponse,
+			append(options, httptransport.ClientBefore(opentracing.ContextToHTTP(otTracer, logger)))...,
+		).Endpoint()
+		listViewsEndpoint = opentracing.TraceClient(otTracer, "ListViews")(listViewsEndpoint)
+		if zipkinTracer != nil {
+			listViewsEndpoint = zipkin.TraceEndpoint(zipkinTracer, "ListViews")(listViewsEndpoint)
+		}
+		listViewsEndpoint = limiter(listViewsEndpoint)
+		listViewsEndpoint = circuitbreaker.Gobreaker(gobreaker.NewCircuitBreaker(gobreaker.Settings{
+			Name:    "ListViews",
+			Timeout: 10 * time.Second,
+		}))(listViewsEndpoint)
+	}
 
-// decodeHTTPConcatRequest is a transport/http.DecodeRequestFunc that decodes a
-// JSON-encoded concat request from the HTTP request body. Primarily useful in a
-// server.
-func decodeHTTPConcatRequest(_ context.Context, r *http.Request) (interface{}, error) {
-	var req addendpoint.ConcatRequest
-	err := json.NewDecoder(r.Body).Decode(&req)
-	return req, err
-}
+	var deleteViewEndpoint endpoint.Endpoint
+	{
+		deleteViewEndpoint = httptransport.NewClient(
+			"DELETE",
+			copyURL(u, "/views/"),
+			encodeHTTPViewRequest,
+			decodeHTTPDeleteViewResponse,
+			append(options, httptransport.ClientBefore(opentracing.ContextToHTTP(otTracer, logger)))...,
+		).Endpoint()
+		deleteViewEndpoint = opentracing.TraceClient(otTracer, "DeleteView")(deleteViewEndpoint)
+		if zipkinTracer != nil {
+			deleteViewEndpoint = zipkin.TraceEndpoint(zipkinTracer, "DeleteView")(deleteViewEndpoint)
+		}
+		deleteViewEndpoint = limiter(deleteViewEndpoint)
+		deleteViewEndpoint = circuitbreaker.Gobreaker(gobreaker.NewCircuitBreaker(gobreaker.Settings{
+			Name:    "DeleteView",
+			Timeout: 10 * time.Second,
+		}))(deleteViewEndpoint)
+	}
 
-// decodeHTTPPingRequest is a transport/http.DecodeRequestFunc that decodes a
-// JSON-encoded ping request from the HTTP request body. Primarily useful in a
-// server.
-func decodeHTTPPingRequest(_ context.Context, r *http.Request) (interface{}, error) {
-	return addendpoint.PingRequest{}, nil
-}
+	var createWebhookEndpoint endpoint.Endpoint
+	{
+		createWebhookEndpoint = httptransport.NewClient(
+			"POST",
+			copyURL(u, "/webhooks"),
+			encodeHTTPGenericRequest,
+			decodeHTTPCreateWebhookResponse,
+			append(options, httptransport.ClientBefore(opentracing.ContextToHTTP(otTracer, logger)))...,
+		).Endpoint()
+		createWebhookEndpoint = opentracing.TraceClient(otTracer, "CreateWebhook")(createWebhookEndpoint)
+		if zipkinTracer != nil {
+			createWebhookEndpoint = zipkin.TraceEndpoint(zipkinTracer, "CreateWebhook")(createWebhookEndpoint)
+		}
+		createWebhookEndpoint = limiter(createWebhookEndpoint)
+		createWebhookEndpoint = circuitbreaker.Gobreaker(gobreaker.NewCircuitBreaker(gobreaker.Settings{
+			Name:    "CreateWebhook",
+			Timeout: 10 * time.Second,
+		}))(createWebhookEndpoint)
+	}
 
-// decodeHTTPAddToDoRequest is a transport/http.DecodeRequestFunc that decodes a
-// JSON-encoded addToDo request from the HTTP request body. Primarily useful in a
-// server.
-func decodeHTTPAddToDoRequest(_ context.Context, r *http.Request) (interface{}, error) {
-	var req addendpoint.AddToDoRequest
-	err := json.NewDecoder(r.Body).Decode(&req)
-	return req, err
-}
+	var deleteWebhookEndpoint endpoint.Endpoint
+	{
+		deleteWebhookEndpoint = httptransport.NewClient(
+			"DELETE",
+			copyURL(u, "/webhooks/"),
+			encodeHTTPWebhookRequest,
+			decodeHTTPDeleteWebhookResponse,
+			append(options, httptransport.ClientBefore(opentracing.ContextToHTTP(otTracer, logger)))...,
+		).Endpoint()
+		deleteWebhookEndpoint = opentracing.TraceClient(otTracer, "DeleteWebhook")(deleteWebhookEndpoint)
+		if zipkinTracer != nil {
+			deleteWebhookEndpoint = zipkin.TraceEndpoint(zipkinTracer, "DeleteWebhook")(deleteWebhookEndpoint)
+		}
+		deleteWebhookEndpoint = limiter(deleteWebhookEndpoint)
+		deleteWebhookEndpoint = circuitbreaker.Gobreaker(gobreaker.NewCircuitBreaker(gobreaker.Settings{
+			Name:    "DeleteWebhook",
+			Timeout: 10 * time.Second,
+		}))(deleteWebhookEndpoint)
+	}
 
-// decodeHTTPCompleteToDoRequest is a transport/http.DecodeRequestFunc that decodes a
-// JSON-encoded completeToDo request from the HTTP request body. Primarily useful in a
-// server.
-func decodeHTTPCompleteToDoRequest(_ context.Context, r *http.Request) (interface{}, error) {
-	var req addendpoint.CompleteToDoRequest
-	err := json.NewDecoder(r.Body).Decode(&req)
-	return req, err
-}
+	var getFieldSchemaEndpoint endpoint.Endpoint
+	{
+		getFieldSchemaEndpoint = httptransport.NewClient(
+			"GET",
+			copyURL(u, "/fields"),
+			encodeHTTPGenericRequest,
+			decodeHTTPGetFieldSchemaResponse,
+			append(options, httptransport.ClientBefore(opentracing.ContextToHTTP(otTracer, logger)))...,
+		).Endpoint()
+		getFieldSchemaEndpoint = opentracing.TraceClient(otTracer, "GetFieldSchema")(getFieldSchemaEndpoint)
+		if zipkinTracer != nil {
+			getFieldSchemaEndpoint = zipkin.TraceEndpoint(zipkinTracer, "GetFieldSchema")(getFieldSchemaEndpoint)
+		}
+		getFieldSchemaEndpoint = limiter(getFieldSchemaEndpoint)
+		getFieldSchemaEndpoint = circuitbreaker.Gobreaker(gobreaker.NewCircuitBreaker(gobreaker.Settings{
+			Name:    "GetFieldSchema",
+			Timeout: 10 * time.Second,
+		}))(getFieldSchemaEndpoint)
+	}
 
-// decodeHTTPUnDoToDoRequest is a transport/http.DecodeRequestFunc that decodes a
-// JSON-encoded unDoToDo request from the HTTP request body. Primarily useful in a
-// server.
-func decodeHTTPUnDoToDoRequest(_ context.Context, r *http.Request) (interface{}, error) {
-	var req addendpoint.UnDoToDoRequest
-	err := json.NewDecoder(r.Body).Decode(&req)
-	return req, err
-}
+	var saveFieldSchemaEndpoint endpoint.Endpoint
+	{
+		saveFieldSchemaEndpoint = httptransport.NewClient(
+			"PUT",
+			copyURL(u, "/fields"),
+			encodeHTTPGenericRequest,
+			decodeHTTPSaveFieldSchemaResponse,
+			append(options, httptransport.ClientBefore(opentracing.ContextToHTTP(otTracer, logger)))...,
+		).Endpoint()
+		saveFieldSchemaEndpoint = opentracing.TraceClient(otTracer, "SaveFieldSchema")(saveFieldSchemaEndpoint)
+		if zipkinTracer != nil {
+			saveFieldSchemaEndpoint = zipkin.TraceEndpoint(zipkinTracer, "SaveFieldSchema")(saveFieldSchemaEndpoint)
+		}
+		saveFieldSchemaEndpoint = limiter(saveFieldSchemaEndpoint)
+		saveFieldSchemaEndpoint = circuitbreaker.Gobreaker(gobreaker.NewCircuitBreaker(gobreaker.Settings{
+			Name:    "SaveFieldSchema",
+			Timeout: 10 * time.Second,
+		}))(saveFieldSchemaEndpoint)
+	}
 
-// decodeHTTPDeleteToDoRequest is a transport/http.DecodeRequestFunc that decodes a
-// JSON-encoded deleteToDo request from the HTTP request body. Primarily useful in a
-// server.
-func decodeHTTPDeleteToDoRequest(_ context.Context, r *http.Request) (interface{}, error) {
-	var req addendpoint.DeleteToDoRequest
-	err := json.NewDecoder(r.Body).Decode(&req)
-	return req, err
-}
+	var getWorkflowEndpoint endpoint.Endpoint
+	{
+		getWorkflowEndpoint = httptransport.NewClient(
+			"GET",
+			copyURL(u, "/workflow"),
+			encodeHTTPGenericRequest,
+			decodeHTTPGetWorkflowResponse,
+			append(options, httptransport.ClientBefore(opentracing.ContextToHTTP(otTracer, logger)))...,
+		).Endpoint()
+		getWorkflowEndpoint = opentracing.TraceClient(otTracer, "GetWorkflow")(getWorkflowEndpoint)
+		if zipkinTracer != nil {
+			getWorkflowEndpoint = zipkin.TraceEndpoint(zipkinTracer, "GetWorkflow")(getWorkflowEndpoint)
+		}
+		getWorkflowEndpoint = limiter(getWorkflowEndpoint)
+		getWorkflowEndpoint = circuitbreaker.Gobreaker(gobreaker.NewCircuitBreaker(gobreaker.Settings{
+			Name:    "GetWorkflow",
+			Timeout: 10 * time.Second,
+		}))(getWorkflowEndpoint)
+	}
 
-// decodeHTTPGetAllToDoRequest is a transport/http.DecodeRequestFunc that decodes a
-// JSON-encoded getAllToDo request from the HTTP request body. Primarily useful in a
-// server.
-func decodeHTTPGetAllToDoRequest(_ context.Context, r *http.Request) (interface{}, error) {
-	return addendpoint.GetAllToDoRequest{}, nil
+	var saveWorkflowEndpoint endpoint.Endpoint
+	{
+		saveWorkflowEndpoint = httptransport.NewClient(
+			"PUT",
+			copyURL(u, "/workflow"),
+			encodeHTTPGenericRequest,
+			decodeHTTPSaveWorkflowResponse,
+			append(options, httptransport.ClientBefore(opentracing.ContextToHTTP(otTracer, logger)))...,
+		).Endpoint()
+		saveWorkflowEndpoint = opentracing.TraceClient(otTracer, "SaveWorkflow")(saveWorkflowEndpoint)
+		if zipkinTracer != nil {
+			saveWorkflowEndpoint = zipkin.TraceEndpoint(zipkinTracer, "SaveWorkflow")(saveWorkflowEndpoint)
+		}
+		saveWorkflowEndpoint = limiter(saveWorkflowEndpoint)
+		saveWorkflowEndpoint = circuitbreaker.Gobreaker(gobreaker.NewCircuitBreaker(gobreaker.Settings{
+			Name:    "SaveWorkflow",
+			Timeout: 10 * time.Second,
+		}))(saveWorkflowEndpoint)
+	}
+
+	var addDependencyEndpoint endpoint.Endpoint
+	{
+		addDependencyEndpoint = httptransport.NewClient(
+			"POST",
+			copyURL(u, "/todos/dependencies"),
+			encodeHTTPGenericRequest,
+			decodeHTTPAddDependencyResponse,
+			append(options, httptransport.ClientBefore(opentracing.ContextToHTTP(otTracer, logger)))...,
+		).Endpoint()
+		addDependencyEndpoint = opentracing.TraceClient(otTracer, "AddDependency")(addDependencyEndpoint)
+		if zipkinTracer != nil {
+			addDependencyEndpoint = zipkin.TraceEndpoint(zipkinTracer, "AddDependency")(addDependencyEndpoint)
+		}
+		addDependencyEndpoint = limiter(addDependencyEndpoint)
+		addDependencyEndpoint = circuitbreaker.Gobreaker(gobreaker.NewCircuitBreaker(gobreaker.Settings{
+			Name:    "AddDependency",
+			Timeout: 10 * time.Second,
+		}))(addDependencyEndpoint)
+	}
+
+	var removeDependencyEndpoint endpoint.Endpoint
+	{
+		removeDependencyEndpoint = httptransport.NewClient(
+			"DELETE",
+			copyURL(u, "/todos/dependencies"),
+			encodeHTTPGenericRequest,
+			decodeHTTPRemoveDependencyResponse,
+			append(options, httptransport.ClientBefore(opentracing.ContextToHTTP(otTracer, logger)))...,
+		).Endpoint()
+		removeDependencyEndpoint = opentracing.TraceClient(otTracer, "RemoveDependency")(removeDependencyEndpoint)
+		if zipkinTracer != nil {
+			removeDependencyEndpoint = zipkin.TraceEndpoint(zipkinTracer, "RemoveDependency")(removeDependencyEndpoint)
+		}
+		removeDependencyEndpoint = limiter(removeDependencyEndpoint)
+		removeDependencyEndpoint = circuitbreaker.Gobreaker(gobreaker.NewCircuitBreaker(gobreaker.Settings{
+			Name:    "RemoveDependency",
+			Timeout: 10 * time.Second,
+		}))(removeDependencyEndpoint)
+	}
+
+	var factorizeEndpoint endpoint.Endpoint
+	{
+		factorizeEndpoint = httptransport.NewClient(
+			"POST",
+			copyURL(u, "/factorize"),
+			encodeHTTPGenericRequest,
+			decodeHTTPFactorizeResponse,
+			append(options, httptransport.ClientBefore(opentracing.ContextToHTTP(otTracer, logger)))...,
+		).Endpoint()
+		factorizeEndpoint = opentracing.TraceClient(otTracer, "Factorize")(factorizeEndpoint)
+		if zipkinTracer != nil {
+			factorizeEndpoint = zipkin.TraceEndpoint(zipkinTracer, "Factorize")(factorizeEndpoint)
+		}
+		factorizeEndpoint = limiter(factorizeEndpoint)
+		factorizeEndpoint = circuitbreaker.Gobreaker(gobreaker.NewCircuitBreaker(gobreaker.Settings{
+			Name:    "Factorize",
+			Timeout: 10 * time.Second,
+		}))(factorizeEndpoint)
+	}
+
+	var jobStatusEndpoint endpoint.Endpoint
+	{
+		jobStatusEndpoint = httptransport.NewClient(
+			"GET",
+			copyURL(u, "/jobs/"),
+			encodeHTTPJobRequest,
+			decodeHTTPJobStatusResponse,
+			append(options, httptransport.ClientBefore(opentracing.ContextToHTTP(otTracer, logger)))...,
+		).Endpoint()
+		jobStatusEndpoint = opentracing.TraceClient(otTracer, "JobStatus")(jobStatusEndpoint)
+		if zipkinTracer != nil {
+			jobStatusEndpoint = zipkin.TraceEndpoint(zipkinTracer, "JobStatus")(jobStatusEndpoint)
+		}
+		jobStatusEndpoint = limiter(jobStatusEndpoint)
+		jobStatusEndpoint = circuitbreaker.Gobreaker(gobreaker.NewCircuitBreaker(gobreaker.Settings{
+			Name:    "JobStatus",
+			Timeout: 10 * time.Second,
+		}))(jobStatusEndpoint)
+	}
+
+	var cancelJobEndpoint endpoint.Endpoint
+	{
+		cancelJobEndpoint = httptransport.NewClient(
+			"DELETE",
+			copyURL(u, "/jobs/"),
+			encodeHTTPJobRequest,
+			decodeHTTPCancelJobResponse,
+			append(options, httptransport.ClientBefore(opentracing.ContextToHTTP(otTracer, logger)))...,
+		).Endpoint()
+		cancelJobEndpoint = opentracing.TraceClient(otTracer, "CancelJob")(cancelJobEndpoint)
+		if zipkinTracer != nil {
+			cancelJobEndpoint = zipkin.TraceEndpoint(zipkinTracer, "CancelJob")(cancelJobEndpoint)
+		}
+		cancelJobEndpoint = limiter(cancelJobEndpoint)
+		cancelJobEndpoint = circuitbreaker.Gobreaker(gobreaker.NewCircuitBreaker(gobreaker.Settings{
+			Name:    "CancelJob",
+			Timeout: 10 * time.Second,
+		}))(cancelJobEndpoint)
+	}
+
+	var listFlaggedEndpoint endpoint.Endpoint
+	{
+		listFlaggedEndpoint = httptransport.NewClient(
+			"GET",
+			copyURL(u, "/moderation"),
+			encodeHTTPGenericRequest,
+			decodeHTTPListFlaggedResponse,
+			append(options, httptransport.ClientBefore(opentracing.ContextToHTTP(otTracer, logger)))...,
+		).Endpoint()
+		listFlaggedEndpoint = opentracing.TraceClient(otTracer, "ListFlagged")(listFlaggedEndpoint)
+		if zipkinTracer != nil {
+			listFlaggedEndpoint = zipkin.TraceEndpoint(zipkinTracer, "ListFlagged")(listFlaggedEndpoint)
+		}
+		listFlaggedEndpoint = limiter(listFlaggedEndpoint)
+		listFlaggedEndpoint = circuitbreaker.Gobreaker(gobreaker.NewCircuitBreaker(gobreaker.Settings{
+			Name:    "ListFlagged",
+			Timeout: 10 * time.Second,
+		}))(listFlaggedEndpoint)
+	}
+
+	var approveFlaggedEndpoint endpoint.Endpoint
+	{
+		approveFlaggedEndpoint = httptransport.NewClient(
+			"POST",
+			copyURL(u, "/moderation/approve/"),
+			encodeHTTPFlaggedRequest,
+			decodeHTTPApproveFlaggedResponse,
+			append(options, httptransport.ClientBefore(opentracing.ContextToHTTP(otTracer, logger)))...,
+		).Endpoint()
+		approveFlaggedEndpoint = opentracing.TraceClient(otTracer, "ApproveFlagged")(approveFlaggedEndpoint)
+		if zipkinTracer != nil {
+			approveFlaggedEndpoint = zipkin.TraceEndpoint(zipkinTracer, "ApproveFlagged")(approveFlaggedEndpoint)
+		}
+		approveFlaggedEndpoint = limiter(approveFlaggedEndpoint)
+		approveFlaggedEndpoint = circuitbreaker.Gobreaker(gobreaker.NewCircuitBreaker(gobreaker.Settings{
+			Name:    "ApproveFlagged",
+			Timeout: 10 * time.Second,
+		}))(approveFlaggedEndpoint)
+	}
+
+	var rejectFlaggedEndpoint endpoint.Endpoint
+	{
+		rejectFlaggedEndpoint = httptransport.NewClient(
+			"POST",
+			copyURL(u, "/moderation/reject/"),
+			encodeHTTPFlaggedRequest,
+			decodeHTTPRejectFlaggedResponse,
+			append(options, httptransport.ClientBefore(opentracing.ContextToHTTP(otTracer, logger)))...,
+		).Endpoint()
+		rejectFlaggedEndpoint = opentracing.TraceClient(otTracer, "RejectFlagged")(rejectFlaggedEndpoint)
+		if zipkinTracer != nil {
+			rejectFlaggedEndpoint = zipkin.TraceEndpoint(zipkinTracer, "RejectFlagged")(rejectFlaggedEndpoint)
+		}
+		rejectFlaggedEndpoint = limiter(rejectFlaggedEndpoint)
+		rejectFlaggedEndpoint = circuitbreaker.Gobreaker(gobreaker.NewCircuitBreaker(gobreaker.Settings{
+			Name:    "RejectFlagged",
+			Timeout: 10 * time.Second,
+		}))(rejectFlaggedEndpoint)
+	}
+
+	// Returning the endpoint.Set as a service.Service relies on the
+	// endpoint.Set implementing the Service methods. That's just a simple bit
+	// of glue code.
+	return addendpoint.Set{
+		SumEndpoint:              sumEndpoint,
+		MultiplyEndpoint:         multiplyEndpoint,
+		DivideEndpoint:           divideEndpoint,
+		ConcatEndpoint:           concatEndpoint,
+		PingEndpoint:             pingEndpoint,
+		AddToDoEndpoint:          addToDoEndpoint,
+		CompleteToDoEndPoint:     completeToDoEndpoint,
+		UnDoToDoEndpoint:         unDoToDoEndpoint,
+		UpdateToDoEndpoint:       updateToDoEndpoint,
+		DeleteToDoEndpoint:       deleteToDoEndpoint,
+		BatchToDoEndpoint:        batchToDoEndpoint,
+		GetToDoByIDEndpoint:      getToDoByIDEndpoint,
+		GetAllToDoEndpoint:       getAllToDoEndpoint,
+		SimilarToDoEndpoint:      similarToDoEndpoint,
+		SearchToDoEndpoint:       searchToDoEndpoint,
+		CompletionStatsEndpoint:  completionStatsEndpoint,
+		ViewEndpoint:             viewEndpoint,
+		SaveViewEndpoint:         saveViewEndpoint,
+		ListViewsEndpoint:        listViewsEndpoint,
+		DeleteViewEndpoint:       deleteViewEndpoint,
+		CreateWebhookEndpoint:    createWebhookEndpoint,
+		DeleteWebhookEndpoint:    deleteWebhookEndpoint,
+		GetFieldSchemaEndpoint:   getFieldSchemaEndpoint,
+		SaveFieldSchemaEndpoint:  saveFieldSchemaEndpoint,
+		GetWorkflowEndpoint:      getWorkflowEndpoint,
+		SaveWorkflowEndpoint:     saveWorkflowEndpoint,
+		AddDependencyEndpoint:    addDependencyEndpoint,
+		RemoveDependencyEndpoint: removeDependencyEndpoint,
+		FactorizeEndpoint:        factorizeEndpoint,
+		JobStatusEndpoint:        jobStatusEndpoint,
+		CancelJobEndpoint:        cancelJobEndpoint,
+		ListFlaggedEndpoint:      listFlaggedEndpoint,
+		ApproveFlaggedEndpoint:   approveFlaggedEndpoint,
+		RejectFlaggedEndpoint:    rejectFlaggedEndpoint,
+	}, nil
+}
+
+func copyURL(base *url.URL, path string) *url.URL {
+	next := *base
+	next.Path = path
+	return &next
+}
+
+// allowMethod wraps next so that requests using any HTTP method other than
+// method are rejected with a 405 response carrying the Allow header.
+func allowMethod(method string, next http.Handler) http.Handler {
+	return allowMethods(map[string]http.Handler{method: next})
+}
+
+// allowMethods routes requests to the handler of their HTTP method, once
+// jsonBodies checked their body. Requests using any other method are rejected
+// with a 405 response carrying the Allow header.
+func allowMethods(handlers map[string]http.Handler) http.Handler {
+	allowed := make([]string, 0, len(handlers))
+	checked := make(map[string]http.Handler, len(handlers))
+	for method, next := range handlers {
+		allowed = append(allowed, method)
+		checked[method] = jsonBodies(next)
+	}
+	sort.Strings(allowed)
+	allow := strings.Join(allowed, ", ")
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next, ok := checked[r.Method]
+		if !ok {
+			w.Header().Set("Allow", allow)
+			writeError(requestIDToContext(r.Context(), r), w, errcode.MethodNotAllowed, http.StatusText(http.StatusMethodNotAllowed), map[string][]string{"allow": allowed})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func errorEncoder(ctx context.Context, err error, w http.ResponseWriter) {
+	writeError(ctx, w, errcode.Of(err), err.Error(), nil)
+}
+
+// StatusError is the error clients return for a response with an unexpected
+// status code. Code and Message are the error the service reported, Message
+// falls back to the status when the body doesn't carry one.
+type StatusError struct {
+	StatusCode int
+	Code       string
+	Message    string
+}
+
+func (e *StatusError) Error() string { return e.Message }
+
+func errorDecoder(r *http.Response) error {
+	var env envelope
+	if err := codec.NewDecoder(r.Body).Decode(&env); err != nil || env.Error == nil {
+		return &StatusError{StatusCode: r.StatusCode, Message: r.Status}
+	}
+	return &StatusError{StatusCode: r.StatusCode, Code: env.Error.Code, Message: env.Error.Message}
+}
+
+// decodeHTTPSumRequest is a transport/http.DecodeRequestFunc that decodes a
+// JSON-encoded sum request from the HTTP request body. Primarily useful in a
+// server.
+func decodeHTTPSumRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var req addendpoint.SumRequest
+	err := codec.NewDecoder(r.Body).Decode(&req)
+	return req, err
+}
+
+// decodeHTTPMultiplyRequest is a transport/http.DecodeRequestFunc that decodes
+// a JSON-encoded multiply request from the HTTP request body. Primarily useful
+// in a server.
+func decodeHTTPMultiplyRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var req addendpoint.MultiplyRequest
+	err := codec.NewDecoder(r.Body).Decode(&req)
+	return req, err
+}
+
+// decodeHTTPDivideRequest is a transport/http.DecodeRequestFunc that decodes a
+// JSON-encoded divide request from the HTTP request body. Primarily useful in
+// a server.
+func decodeHTTPDivideRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var req addendpoint.DivideRequest
+	err := codec.NewDecoder(r.Body).Decode(&req)
+	return req, err
+}
+
+// decodeHTTPConcatRequest is a transport/http.DecodeRequestFunc that decodes a
+// JSON-encoded concat request from the HTTP request body. Primarily useful in a
+// server.
+func decodeHTTPConcatRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var req addendpoint.ConcatRequest
+	err := codec.NewDecoder(r.Body).Decode(&req)
+	return req, err
+}
+
+// decodeHTTPPingRequest is a transport/http.DecodeRequestFunc that decodes a
+// JSON-encoded ping request from the HTTP request body. Primarily useful in a
+// server.
+func decodeHTTPPingRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	return addendpoint.PingRequest{}, nil
+}
+
+// decodeHTTPAddToDoRequest is a transport/http.DecodeRequestFunc that decodes a
+// JSON-encoded addToDo request from the HTTP request body. Primarily useful in a
+// server.
+func decodeHTTPAddToDoRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var req addendpoint.AddToDoRequest
+	err := codec.NewDecoder(r.Body).Decode(&req)
+	return req, err
+}
+
+// decodeHTTPCompleteToDoRequest is a transport/http.DecodeRequestFunc that decodes a
+// JSON-encoded completeToDo request from the HTTP request body. Primarily useful in a
+// server.
+func decodeHTTPCompleteToDoRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var req addendpoint.CompleteToDoRequest
+	err := codec.NewDecoder(r.Body).Decode(&req)
+	return req, err
+}
+
+// decodeHTTPUnDoToDoRequest is a transport/http.DecodeRequestFunc that decodes a
+// JSON-encoded unDoToDo request from the HTTP request body. Primarily useful in a
+// server.
+func decodeHTTPUnDoToDoRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var req addendpoint.UnDoToDoRequest
+	err := codec.NewDecoder(r.Body).Decode(&req)
+	return req, err
+}
+
+// decodeHTTPUpdateToDoRequest is a transport/http.DecodeRequestFunc that decodes a
+// JSON-encoded updateToDo request from the HTTP request body. Primarily useful in a
+// server.
+func decodeHTTPUpdateToDoRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var req addendpoint.UpdateToDoRequest
+	err := codec.NewDecoder(r.Body).Decode(&req)
+	return req, err
+}
+
+// decodeHTTPDeleteToDoRequest is a transport/http.DecodeRequestFunc that decodes a
+// JSON-encoded deleteToDo request from the HTTP request body. Primarily useful in a
+// server.
+func decodeHTTPDeleteToDoRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var req addendpoint.DeleteToDoRequest
+	err := codec.NewDecoder(r.Body).Decode(&req)
+	return req, err
+}
+
+// decodeHTTPBatchToDoRequest is a transport/http.DecodeRequestFunc that decodes
+// a JSON-encoded batchToDo request from the HTTP request body. Primarily
+// useful in a server.
+func decodeHTTPBatchToDoRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var req addendpoint.BatchToDoRequest
+	err := codec.NewDecoder(r.Body).Decode(&req)
+	return req, err
+}
+
+// decodeHTTPGetToDoByIDRequest is a transport/http.DecodeRequestFunc that
+// decodes a getToDoByID request from the taskID query parameter. Primarily
+// useful in a server.
+func decodeHTTPGetToDoByIDRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	return addendpoint.GetToDoByIDRequest{TaskID: models.TaskID(r.URL.Query().Get("taskID"))}, nil
+}
+
+// decodeHTTPGetAllToDoRequest is a transport/http.DecodeRequestFunc that decodes a
+// getAllToDo request from the cursor, scheduled, query, status, sort and
+// order parameters of the HTTP request. Primarily useful in a server.
+func decodeHTTPGetAllToDoRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	params := r.URL.Query()
+	opts := models.ListOptions{
+		Cursor:    params.Get("cursor"),
+		Scheduled: params.Get("scheduled") == "true",
+		Query:     params.Get("query"),
+		Status:    params.Get("status"),
+		Sort:      params.Get("sort"),
+		Order:     params.Get("order"),
+		Overdue:   params.Get("overdue") == "true",
+		Priority:  models.Priority(params.Get("priority")),
+	}
+	var err error
+	if opts.DueBefore, err = timeParam(params, "dueBefore"); err != nil {
+		return nil, err
+	}
+	if opts.DueAfter, err = timeParam(params, "dueAfter"); err != nil {
+		return nil, err
+	}
+	return addendpoint.GetAllToDoRequest{ListOptions: opts}, nil
+}
+
+// decodeHTTPOverdueToDoRequest is a transport/http.DecodeRequestFunc that
+// decodes a getAllToDo request for the overdue todos from the query
+// parameters of the HTTP request. Primarily useful in a server.
+func decodeHTTPOverdueToDoRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	request, err := decodeHTTPGetAllToDoRequest(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	req := request.(addendpoint.GetAllToDoRequest)
+	req.Overdue = true
+	return req, nil
+}
+
+// timeParam returns the RFC 3339 time of the query parameter name, nil when
+// it's missing.
+func timeParam(params url.Values, name string) (*time.Time, error) {
+	v := params.Get(name)
+	if v == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s %q", models.ErrInvalidListOptions, name, v)
+	}
+	return &t, nil
+}
+
+// decodeHTTPSimilarToDoRequest is a transport/http.DecodeRequestFunc that decodes a
+// JSON-encoded similarToDo request from the HTTP request body. Primarily useful in a
+// server.
+func decodeHTTPSimilarToDoRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var req addendpoint.SimilarToDoRequest
+	err := codec.NewDecoder(r.Body).Decode(&req)
+	return req, err
+}
+
+// decodeHTTPSearchToDoRequest is a transport/http.DecodeRequestFunc that
+// decodes a searchToDo request from the q parameter of the HTTP request.
+// Primarily useful in a server.
+func decodeHTTPSearchToDoRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	return addendpoint.SearchToDoRequest{Query: r.URL.Query().Get("q")}, nil
+}
+
+// decodeHTTPCompletionStatsRequest is a transport/http.DecodeRequestFunc
+// that decodes a completionStats request, which has no parameters.
+// Primarily useful in a server.
+func decodeHTTPCompletionStatsRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	return addendpoint.CompletionStatsRequest{}, nil
+}
+
+// decodeHTTPWatchToDosRequest is a transport/http.DecodeRequestFunc that
+// decodes a watchToDos request, which has no parameters. Primarily useful in
+// a server.
+func decodeHTTPWatchToDosRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	return addendpoint.WatchToDosRequest{}, nil
+}
+
+// decodeHTTPViewRequest is a transport/http.DecodeRequestFunc that decodes a
+// view request from the /views/{name} path of the HTTP request. Primarily
+// useful in a server.
+func decodeHTTPViewRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	return addendpoint.ViewRequest{Name: viewNameFromPath(r)}, nil
+}
+
+// decodeHTTPSaveViewRequest is a transport/http.DecodeRequestFunc that decodes
+// a JSON-encoded saveView request from the HTTP request body. Primarily useful
+// in a server.
+func decodeHTTPSaveViewRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var req addendpoint.SaveViewRequest
+	err := codec.NewDecoder(r.Body).Decode(&req)
+	return req, err
+}
+
+// decodeHTTPListViewsRequest is a transport/http.DecodeRequestFunc that
+// decodes a listViews request, which has no parameters. Primarily useful in a
+// server.
+func decodeHTTPListViewsRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	return addendpoint.ListViewsRequest{}, nil
+}
+
+// decodeHTTPDeleteViewRequest is a transport/http.DecodeRequestFunc that
+// decodes a deleteView request from the /views/{name} path of the HTTP
+// request. Primarily useful in a server.
+func decodeHTTPDeleteViewRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	return addendpoint.DeleteViewRequest{Name: viewNameFromPath(r)}, nil
+}
+
+// viewNameFromPath returns the view name of a /views/{name} request.
+func viewNameFromPath(r *http.Request) string {
+	return strings.TrimPrefix(r.URL.Path, "/views/")
+}
+
+// decodeHTTPCreateWebhookRequest is a transport/http.DecodeRequestFunc that
+// decodes a JSON-encoded createWebhook request from the HTTP request body.
+// Primarily useful in a server.
+func decodeHTTPCreateWebhookRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var req addendpoint.CreateWebhookRequest
+	err := codec.NewDecoder(r.Body).Decode(&req)
+	return req, err
+}
+
+// decodeHTTPDeleteWebhookRequest is a transport/http.DecodeRequestFunc that
+// decodes a deleteWebhook request from the /webhooks/{id} path of the HTTP
+// request. Primarily useful in a server.
+func decodeHTTPDeleteWebhookRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	return addendpoint.DeleteWebhookRequest{ID: strings.TrimPrefix(r.URL.Path, "/webhooks/")}, nil
+}
+
+// decodeHTTPGetFieldSchemaRequest is a transport/http.DecodeRequestFunc that
+// decodes a getFieldSchema request, which has no parameters. Primarily useful
+// in a server.
+func decodeHTTPGetFieldSchemaRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	return addendpoint.GetFieldSchemaRequest{}, nil
+}
+
+// decodeHTTPSaveFieldSchemaRequest is a transport/http.DecodeRequestFunc that
+// decodes a JSON-encoded saveFieldSchema request from the HTTP request body.
+// Primarily useful in a server.
+func decodeHTTPSaveFieldSchemaRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var req addendpoint.SaveFieldSchemaRequest
+	err := codec.NewDecoder(r.Body).Decode(&req)
+	return req, err
+}
+
+// decodeHTTPGetWorkflowRequest is a transport/http.DecodeRequestFunc that
+// decodes a getWorkflow request, which has no parameters. Primarily useful
+// in a server.
+func decodeHTTPGetWorkflowRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	return addendpoint.GetWorkflowRequest{}, nil
+}
+
+// decodeHTTPSaveWorkflowRequest is a transport/http.DecodeRequestFunc that
+// decodes a JSON-encoded saveWorkflow request from the HTTP request body.
+// Primarily useful in a server.
+func decodeHTTPSaveWorkflowRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var req addendpoint.SaveWorkflowRequest
+	err := codec.NewDecoder(r.Body).Decode(&req)
+	return req, err
+}
+
+// decodeHTTPAddDependencyRequest is a transport/http.DecodeRequestFunc that
+// decodes a JSON-encoded addDependency request from the HTTP request body.
+// Primarily useful in a server.
+func decodeHTTPAddDependencyRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var req addendpoint.AddDependencyRequest
+	err := codec.NewDecoder(r.Body).Decode(&req)
+	return req, err
+}
+
+// decodeHTTPRemoveDependencyRequest is a transport/http.DecodeRequestFunc that
+// decodes a JSON-encoded removeDependency request from the HTTP request body.
+// Primarily useful in a server.
+func decodeHTTPRemoveDependencyRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var req addendpoint.RemoveDependencyRequest
+	err := codec.NewDecoder(r.Body).Decode(&req)
+	return req, err
+}
+
+// decodeHTTPFactorizeRequest is a transport/http.DecodeRequestFunc that decodes
+// a JSON-encoded factorize request from the HTTP request body. Primarily useful
+// in a server.
+func decodeHTTPFactorizeRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var req addendpoint.FactorizeRequest
+	err := codec.NewDecoder(r.Body).Decode(&req)
+	return req, err
+}
+
+// decodeHTTPJobStatusRequest is a transport/http.DecodeRequestFunc that decodes
+// a jobStatus request from the /jobs/{id} path of the HTTP request. Primarily
+// useful in a server.
+func decodeHTTPJobStatusRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	return addendpoint.JobStatusRequest{JobID: jobIDFromPath(r)}, nil
+}
+
+// decodeHTTPCancelJobRequest is a transport/http.DecodeRequestFunc that decodes
+// a cancelJob request from the /jobs/{id} path of the HTTP request. Primarily
+// useful in a server.
+func decodeHTTPCancelJobRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	return addendpoint.CancelJobRequest{JobID: jobIDFromPath(r)}, nil
+}
+
+// jobIDFromPath returns the job ID of a /jobs/{id} request.
+func jobIDFromPath(r *http.Request) string {
+	return strings.TrimPrefix(r.URL.Path, "/jobs/")
+}
+
+// decodeHTTPListFlaggedRequest is a transport/http.DecodeRequestFunc that
+// decodes a listFlagged request, which has no parameters. Primarily useful in
+// a server.
+func decodeHTTPListFlaggedRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	return addendpoint.ListFlaggedRequest{}, nil
+}
+
+// decodeHTTPApproveFlaggedRequest is a transport/http.DecodeRequestFunc that
+// decodes an approveFlagged request from the /moderation/approve/{taskID}
+// path of the HTTP request. Primarily useful in a server.
+func decodeHTTPApproveFlaggedRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	id, err := models.ParseTaskID(strings.TrimPrefix(r.URL.Path, "/moderation/approve/"))
+	return addendpoint.ApproveFlaggedRequest{TaskID: id}, err
+}
+
+// decodeHTTPRejectFlaggedRequest is a transport/http.DecodeRequestFunc that
+// decodes a rejectFlagged request from the /moderation/reject/{taskID} path
+// of the HTTP request. Primarily useful in a server.
+func decodeHTTPRejectFlaggedRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	id, err := models.ParseTaskID(strings.TrimPrefix(r.URL.Path, "/moderation/reject/"))
+	return addendpoint.RejectFlaggedRequest{TaskID: id}, err
 }
 
 // decodeHTTPSumResponse is a transport/http.DecodeResponseFunc that decodes a
@@ -437,10 +1508,38 @@ func decodeHTTPGetAllToDoRequest(_ context.Context, r *http.Request) (interface{
 // client.
 func decodeHTTPSumResponse(_ context.Context, r *http.Response) (interface{}, error) {
 	if r.StatusCode != http.StatusOK {
-		return nil, errors.New(r.Status)
+		return nil, errorDecoder(r)
 	}
 	var resp addendpoint.SumResponse
-	err := json.NewDecoder(r.Body).Decode(&resp)
+	_, err := decodeData(r, &resp)
+	return resp, err
+}
+
+// decodeHTTPMultiplyResponse is a transport/http.DecodeResponseFunc that
+// decodes a JSON-encoded multiply response from the HTTP response body. If the
+// response has a non-200 status code, we will interpret that as an error and
+// attempt to decode the specific error message from the response body.
+// Primarily useful in a client.
+func decodeHTTPMultiplyResponse(_ context.Context, r *http.Response) (interface{}, error) {
+	if r.StatusCode != http.StatusOK {
+		return nil, errorDecoder(r)
+	}
+	var resp addendpoint.MultiplyResponse
+	_, err := decodeData(r, &resp)
+	return resp, err
+}
+
+// decodeHTTPDivideResponse is a transport/http.DecodeResponseFunc that decodes
+// a JSON-encoded divide response from the HTTP response body. If the response
+// has a non-200 status code, we will interpret that as an error and attempt to
+// decode the specific error message from the response body. Primarily useful in
+// a client.
+func decodeHTTPDivideResponse(_ context.Context, r *http.Response) (interface{}, error) {
+	if r.StatusCode != http.StatusOK {
+		return nil, errorDecoder(r)
+	}
+	var resp addendpoint.DivideResponse
+	_, err := decodeData(r, &resp)
 	return resp, err
 }
 
@@ -451,10 +1550,10 @@ func decodeHTTPSumResponse(_ context.Context, r *http.Response) (interface{}, er
 // a client.
 func decodeHTTPConcatResponse(_ context.Context, r *http.Response) (interface{}, error) {
 	if r.StatusCode != http.StatusOK {
-		return nil, errors.New(r.Status)
+		return nil, errorDecoder(r)
 	}
 	var resp addendpoint.ConcatResponse
-	err := json.NewDecoder(r.Body).Decode(&resp)
+	_, err := decodeData(r, &resp)
 	return resp, err
 }
 
@@ -465,10 +1564,10 @@ func decodeHTTPConcatResponse(_ context.Context, r *http.Response) (interface{},
 // a client.
 func decodeHTTPPingResponse(_ context.Context, r *http.Response) (interface{}, error) {
 	if r.StatusCode != http.StatusOK {
-		return nil, errors.New(r.Status)
+		return nil, errorDecoder(r)
 	}
 	var resp addendpoint.PingResponse
-	err := json.NewDecoder(r.Body).Decode(&resp)
+	_, err := decodeData(r, &resp)
 	return resp, err
 }
 
@@ -479,10 +1578,10 @@ func decodeHTTPPingResponse(_ context.Context, r *http.Response) (interface{}, e
 // a client.
 func decodeHTTPAddToDoResponse(_ context.Context, r *http.Response) (interface{}, error) {
 	if r.StatusCode != http.StatusOK {
-		return nil, errors.New(r.Status)
+		return nil, errorDecoder(r)
 	}
 	var resp addendpoint.AddToDoResponse
-	err := json.NewDecoder(r.Body).Decode(&resp)
+	_, err := decodeData(r, &resp)
 	return resp, err
 }
 
@@ -493,10 +1592,10 @@ func decodeHTTPAddToDoResponse(_ context.Context, r *http.Response) (interface{}
 // a client.
 func decodeHTTPCompleteToDoResponse(_ context.Context, r *http.Response) (interface{}, error) {
 	if r.StatusCode != http.StatusOK {
-		return nil, errors.New(r.Status)
+		return nil, errorDecoder(r)
 	}
 	var resp addendpoint.CompleteToDoResponse
-	err := json.NewDecoder(r.Body).Decode(&resp)
+	_, err := decodeData(r, &resp)
 	return resp, err
 }
 
@@ -507,10 +1606,24 @@ func decodeHTTPCompleteToDoResponse(_ context.Context, r *http.Response) (interf
 // a client.
 func decodeHTTPUnDoToDoResponse(_ context.Context, r *http.Response) (interface{}, error) {
 	if r.StatusCode != http.StatusOK {
-		return nil, errors.New(r.Status)
+		return nil, errorDecoder(r)
 	}
 	var resp addendpoint.UnDoToDoResponse
-	err := json.NewDecoder(r.Body).Decode(&resp)
+	_, err := decodeData(r, &resp)
+	return resp, err
+}
+
+// decodeHTTPUpdateToDoResponse is a transport/http.DecodeResponseFunc that decodes
+// a JSON-encoded concat response from the HTTP response body. If the response
+// has a non-200 status code, we will interpret that as an error and attempt to
+// decode the specific error message from the response body. Primarily useful in
+// a client.
+func decodeHTTPUpdateToDoResponse(_ context.Context, r *http.Response) (interface{}, error) {
+	if r.StatusCode != http.StatusOK {
+		return nil, errorDecoder(r)
+	}
+	var resp addendpoint.UpdateToDoResponse
+	_, err := decodeData(r, &resp)
 	return resp, err
 }
 
@@ -521,10 +1634,38 @@ func decodeHTTPUnDoToDoResponse(_ context.Context, r *http.Response) (interface{
 // a client.
 func decodeHTTPDeleteToDoResponse(_ context.Context, r *http.Response) (interface{}, error) {
 	if r.StatusCode != http.StatusOK {
-		return nil, errors.New(r.Status)
+		return nil, errorDecoder(r)
 	}
 	var resp addendpoint.DeleteToDoResponse
-	err := json.NewDecoder(r.Body).Decode(&resp)
+	_, err := decodeData(r, &resp)
+	return resp, err
+}
+
+// decodeHTTPBatchToDoResponse is a transport/http.DecodeResponseFunc that
+// decodes a JSON-encoded batchToDo response from the HTTP response body. If
+// the response has a non-200 status code, we will interpret that as an error
+// and attempt to decode the specific error message from the response body.
+// Primarily useful in a client.
+func decodeHTTPBatchToDoResponse(_ context.Context, r *http.Response) (interface{}, error) {
+	if r.StatusCode != http.StatusOK {
+		return nil, errorDecoder(r)
+	}
+	var resp addendpoint.BatchToDoResponse
+	_, err := decodeData(r, &resp)
+	return resp, err
+}
+
+// decodeHTTPGetToDoByIDResponse is a transport/http.DecodeResponseFunc that
+// decodes a JSON-encoded getToDoByID response from the HTTP response body. If
+// the response has a non-200 status code, we will interpret that as an error
+// and attempt to decode the specific error message from the response body.
+// Primarily useful in a client.
+func decodeHTTPGetToDoByIDResponse(_ context.Context, r *http.Response) (interface{}, error) {
+	if r.StatusCode != http.StatusOK {
+		return nil, errorDecoder(r)
+	}
+	var resp addendpoint.GetToDoByIDResponse
+	_, err := decodeData(r, &resp)
 	return resp, err
 }
 
@@ -535,10 +1676,307 @@ func decodeHTTPDeleteToDoResponse(_ context.Context, r *http.Response) (interfac
 // a client.
 func decodeHTTPGetAllToDoResponse(_ context.Context, r *http.Response) (interface{}, error) {
 	if r.StatusCode != http.StatusOK {
-		return nil, errors.New(r.Status)
+		return nil, errorDecoder(r)
 	}
 	var resp addendpoint.GetAllToDoResponse
-	err := json.NewDecoder(r.Body).Decode(&resp)
+	m, err := decodeData(r, &resp.ToDoPage)
+	if m.Pagination != nil {
+		resp.Cursor, resp.Truncated, resp.Partial = m.Pagination.Cursor, m.Pagination.Truncated, m.Pagination.Partial
+	}
+	return resp, err
+}
+
+// decodeHTTPSimilarToDoResponse is a transport/http.DecodeResponseFunc that decodes
+// a JSON-encoded similarToDo response from the HTTP response body. If the response
+// has a non-200 status code, we will interpret that as an error and attempt to
+// decode the specific error message from the response body. Primarily useful in
+// a client.
+func decodeHTTPSimilarToDoResponse(_ context.Context, r *http.Response) (interface{}, error) {
+	if r.StatusCode != http.StatusOK {
+		return nil, errorDecoder(r)
+	}
+	var resp addendpoint.SimilarToDoResponse
+	_, err := decodeData(r, &resp)
+	return resp, err
+}
+
+// decodeHTTPSearchToDoResponse is a transport/http.DecodeResponseFunc that
+// decodes a JSON-encoded searchToDo response from the HTTP response body. If
+// the response has a non-200 status code, we will interpret that as an error
+// and attempt to decode the specific error message from the response body.
+// Primarily useful in a client.
+func decodeHTTPSearchToDoResponse(_ context.Context, r *http.Response) (interface{}, error) {
+	if r.StatusCode != http.StatusOK {
+		return nil, errorDecoder(r)
+	}
+	var resp addendpoint.SearchToDoResponse
+	_, err := decodeData(r, &resp)
+	return resp, err
+}
+
+// decodeHTTPCompletionStatsResponse is a transport/http.DecodeResponseFunc
+// that decodes a JSON-encoded completionStats response from the HTTP
+// response body. If the response has a non-200 status code, we will
+// interpret that as an error and attempt to decode the specific error
+// message from the response body. Primarily useful in a client.
+func decodeHTTPCompletionStatsResponse(_ context.Context, r *http.Response) (interface{}, error) {
+	if r.StatusCode != http.StatusOK {
+		return nil, errorDecoder(r)
+	}
+	var resp addendpoint.CompletionStatsResponse
+	_, err := decodeData(r, &resp)
+	return resp, err
+}
+
+// decodeHTTPViewResponse is a transport/http.DecodeResponseFunc that decodes
+// a JSON-encoded view response from the HTTP response body. If the response
+// has a non-200 status code, we will interpret that as an error and attempt to
+// decode the specific error message from the response body. Primarily useful in
+// a client.
+func decodeHTTPViewResponse(_ context.Context, r *http.Response) (interface{}, error) {
+	if r.StatusCode != http.StatusOK {
+		return nil, errorDecoder(r)
+	}
+	var resp addendpoint.ViewResponse
+	_, err := decodeData(r, &resp)
+	return resp, err
+}
+
+// decodeHTTPSaveViewResponse is a transport/http.DecodeResponseFunc that decodes
+// a JSON-encoded saveView response from the HTTP response body. If the response
+// has a non-200 status code, we will interpret that as an error and attempt to
+// decode the specific error message from the response body. Primarily useful in
+// a client.
+func decodeHTTPSaveViewResponse(_ context.Context, r *http.Response) (interface{}, error) {
+	if r.StatusCode != http.StatusOK {
+		return nil, errorDecoder(r)
+	}
+	var resp addendpoint.SaveViewResponse
+	_, err := decodeData(r, &resp)
+	return resp, err
+}
+
+// decodeHTTPListViewsResponse is a transport/http.DecodeResponseFunc that decodes
+// a JSON-encoded listViews response from the HTTP response body. If the response
+// has a non-200 status code, we will interpret that as an error and attempt to
+// decode the specific error message from the response body. Primarily useful in
+// a client.
+func decodeHTTPListViewsResponse(_ context.Context, r *http.Response) (interface{}, error) {
+	if r.StatusCode != http.StatusOK {
+		return nil, errorDecoder(r)
+	}
+	var resp addendpoint.ListViewsResponse
+	_, err := decodeData(r, &resp)
+	return resp, err
+}
+
+// decodeHTTPDeleteViewResponse is a transport/http.DecodeResponseFunc that decodes
+// a JSON-encoded deleteView response from the HTTP response body. If the response
+// has a non-200 status code, we will interpret that as an error and attempt to
+// decode the specific error message from the response body. Primarily useful in
+// a client.
+func decodeHTTPDeleteViewResponse(_ context.Context, r *http.Response) (interface{}, error) {
+	if r.StatusCode != http.StatusOK {
+		return nil, errorDecoder(r)
+	}
+	var resp addendpoint.DeleteViewResponse
+	_, err := decodeData(r, &resp)
+	return resp, err
+}
+
+// decodeHTTPCreateWebhookResponse is a transport/http.DecodeResponseFunc that
+// decodes a JSON-encoded createWebhook response from the HTTP response body.
+// If the response has a non-200 status code, we will interpret that as an
+// error and attempt to decode the specific error message from the response
+// body. Primarily useful in a client.
+func decodeHTTPCreateWebhookResponse(_ context.Context, r *http.Response) (interface{}, error) {
+	if r.StatusCode != http.StatusOK {
+		return nil, errorDecoder(r)
+	}
+	var resp addendpoint.CreateWebhookResponse
+	_, err := decodeData(r, &resp)
+	return resp, err
+}
+
+// decodeHTTPDeleteWebhookResponse is a transport/http.DecodeResponseFunc that
+// decodes a JSON-encoded deleteWebhook response from the HTTP response body.
+// If the response has a non-200 status code, we will interpret that as an
+// error and attempt to decode the specific error message from the response
+// body. Primarily useful in a client.
+func decodeHTTPDeleteWebhookResponse(_ context.Context, r *http.Response) (interface{}, error) {
+	if r.StatusCode != http.StatusOK {
+		return nil, errorDecoder(r)
+	}
+	var resp addendpoint.DeleteWebhookResponse
+	_, err := decodeData(r, &resp)
+	return resp, err
+}
+
+// decodeHTTPGetFieldSchemaResponse is a transport/http.DecodeResponseFunc that
+// decodes a JSON-encoded getFieldSchema response from the HTTP response body.
+// If the response has a non-200 status code, we will interpret that as an
+// error and attempt to decode the specific error message from the response
+// body. Primarily useful in a client.
+func decodeHTTPGetFieldSchemaResponse(_ context.Context, r *http.Response) (interface{}, error) {
+	if r.StatusCode != http.StatusOK {
+		return nil, errorDecoder(r)
+	}
+	var resp addendpoint.GetFieldSchemaResponse
+	_, err := decodeData(r, &resp)
+	return resp, err
+}
+
+// decodeHTTPSaveFieldSchemaResponse is a transport/http.DecodeResponseFunc that
+// decodes a JSON-encoded saveFieldSchema response from the HTTP response body.
+// If the response has a non-200 status code, we will interpret that as an
+// error and attempt to decode the specific error message from the response
+// body. Primarily useful in a client.
+func decodeHTTPSaveFieldSchemaResponse(_ context.Context, r *http.Response) (interface{}, error) {
+	if r.StatusCode != http.StatusOK {
+		return nil, errorDecoder(r)
+	}
+	var resp addendpoint.SaveFieldSchemaResponse
+	_, err := decodeData(r, &resp)
+	return resp, err
+}
+
+// decodeHTTPGetWorkflowResponse is a transport/http.DecodeResponseFunc that
+// decodes a JSON-encoded getWorkflow response from the HTTP response body.
+// If the response has a non-200 status code, we will interpret that as an
+// error and attempt to decode the specific error message from the response
+// body. Primarily useful in a client.
+func decodeHTTPGetWorkflowResponse(_ context.Context, r *http.Response) (interface{}, error) {
+	if r.StatusCode != http.StatusOK {
+		return nil, errorDecoder(r)
+	}
+	var resp addendpoint.GetWorkflowResponse
+	_, err := decodeData(r, &resp)
+	return resp, err
+}
+
+// decodeHTTPSaveWorkflowResponse is a transport/http.DecodeResponseFunc that
+// decodes a JSON-encoded saveWorkflow response from the HTTP response body.
+// If the response has a non-200 status code, we will interpret that as an
+// error and attempt to decode the specific error message from the response
+// body. Primarily useful in a client.
+func decodeHTTPSaveWorkflowResponse(_ context.Context, r *http.Response) (interface{}, error) {
+	if r.StatusCode != http.StatusOK {
+		return nil, errorDecoder(r)
+	}
+	var resp addendpoint.SaveWorkflowResponse
+	_, err := decodeData(r, &resp)
+	return resp, err
+}
+
+// decodeHTTPAddDependencyResponse is a transport/http.DecodeResponseFunc that
+// decodes a JSON-encoded addDependency response from the HTTP response body.
+// If the response has a non-200 status code, we will interpret that as an
+// error and attempt to decode the specific error message from the response
+// body. Primarily useful in a client.
+func decodeHTTPAddDependencyResponse(_ context.Context, r *http.Response) (interface{}, error) {
+	if r.StatusCode != http.StatusOK {
+		return nil, errorDecoder(r)
+	}
+	var resp addendpoint.AddDependencyResponse
+	_, err := decodeData(r, &resp)
+	return resp, err
+}
+
+// decodeHTTPRemoveDependencyResponse is a transport/http.DecodeResponseFunc that
+// decodes a JSON-encoded removeDependency response from the HTTP response body.
+// If the response has a non-200 status code, we will interpret that as an
+// error and attempt to decode the specific error message from the response
+// body. Primarily useful in a client.
+func decodeHTTPRemoveDependencyResponse(_ context.Context, r *http.Response) (interface{}, error) {
+	if r.StatusCode != http.StatusOK {
+		return nil, errorDecoder(r)
+	}
+	var resp addendpoint.RemoveDependencyResponse
+	_, err := decodeData(r, &resp)
+	return resp, err
+}
+
+// decodeHTTPFactorizeResponse is a transport/http.DecodeResponseFunc that
+// decodes a JSON-encoded factorize response from the HTTP response body. If the
+// response has a non-200 status code, we will interpret that as an error and
+// attempt to decode the specific error message from the response body.
+// Primarily useful in a client.
+func decodeHTTPFactorizeResponse(_ context.Context, r *http.Response) (interface{}, error) {
+	if r.StatusCode != http.StatusAccepted {
+		return nil, errorDecoder(r)
+	}
+	var resp addendpoint.FactorizeResponse
+	_, err := decodeData(r, &resp)
+	return resp, err
+}
+
+// decodeHTTPJobStatusResponse is a transport/http.DecodeResponseFunc that
+// decodes a JSON-encoded jobStatus response from the HTTP response body. If the
+// response has a non-200 status code, we will interpret that as an error and
+// attempt to decode the specific error message from the response body.
+// Primarily useful in a client.
+func decodeHTTPJobStatusResponse(_ context.Context, r *http.Response) (interface{}, error) {
+	if r.StatusCode != http.StatusOK {
+		return nil, errorDecoder(r)
+	}
+	var resp addendpoint.JobStatusResponse
+	_, err := decodeData(r, &resp)
+	return resp, err
+}
+
+// decodeHTTPCancelJobResponse is a transport/http.DecodeResponseFunc that
+// decodes a JSON-encoded cancelJob response from the HTTP response body. If the
+// response has a non-200 status code, we will interpret that as an error and
+// attempt to decode the specific error message from the response body.
+// Primarily useful in a client.
+func decodeHTTPCancelJobResponse(_ context.Context, r *http.Response) (interface{}, error) {
+	if r.StatusCode != http.StatusOK {
+		return nil, errorDecoder(r)
+	}
+	var resp addendpoint.CancelJobResponse
+	_, err := decodeData(r, &resp)
+	return resp, err
+}
+
+// decodeHTTPListFlaggedResponse is a transport/http.DecodeResponseFunc that
+// decodes a JSON-encoded listFlagged response from the HTTP response body. If
+// the response has a non-200 status code, we will interpret that as an error
+// and attempt to decode the specific error message from the response body.
+// Primarily useful in a client.
+func decodeHTTPListFlaggedResponse(_ context.Context, r *http.Response) (interface{}, error) {
+	if r.StatusCode != http.StatusOK {
+		return nil, errorDecoder(r)
+	}
+	var resp addendpoint.ListFlaggedResponse
+	_, err := decodeData(r, &resp)
+	return resp, err
+}
+
+// decodeHTTPApproveFlaggedResponse is a transport/http.DecodeResponseFunc
+// that decodes a JSON-encoded approveFlagged response from the HTTP response
+// body. If the response has a non-200 status code, we will interpret that as
+// an error and attempt to decode the specific error message from the response
+// body. Primarily useful in a client.
+func decodeHTTPApproveFlaggedResponse(_ context.Context, r *http.Response) (interface{}, error) {
+	if r.StatusCode != http.StatusOK {
+		return nil, errorDecoder(r)
+	}
+	var resp addendpoint.ApproveFlaggedResponse
+	_, err := decodeData(r, &resp)
+	return resp, err
+}
+
+// decodeHTTPRejectFlaggedResponse is a transport/http.DecodeResponseFunc that
+// decodes a JSON-encoded rejectFlagged response from the HTTP response body.
+// If the response has a non-200 status code, we will interpret that as an
+// error and attempt to decode the specific error message from the response
+// body. Primarily useful in a client.
+func decodeHTTPRejectFlaggedResponse(_ context.Context, r *http.Response) (interface{}, error) {
+	if r.StatusCode != http.StatusOK {
+		return nil, errorDecoder(r)
+	}
+	var resp addendpoint.RejectFlaggedResponse
+	_, err := decodeData(r, &resp)
 	return resp, err
 }
 
@@ -546,13 +1984,138 @@ func decodeHTTPGetAllToDoResponse(_ context.Context, r *http.Response) (interfac
 // JSON-encodes any request to the request body. Primarily useful in a client.
 func encodeHTTPGenericRequest(_ context.Context, r *http.Request, request interface{}) error {
 	var buf bytes.Buffer
-	if err := json.NewEncoder(&buf).Encode(request); err != nil {
+	if err := codec.NewEncoder(&buf).Encode(request); err != nil {
 		return err
 	}
+	r.Header.Set("Content-Type", "application/json; charset=utf-8")
 	r.Body = ioutil.NopCloser(&buf)
 	return nil
 }
 
+// encodeHTTPGetToDoByIDRequest is a transport/http.EncodeRequestFunc that
+// encodes a getToDoByID request as the taskID query parameter. Primarily
+// useful in a client.
+func encodeHTTPGetToDoByIDRequest(_ context.Context, r *http.Request, request interface{}) error {
+	req := request.(addendpoint.GetToDoByIDRequest)
+	r.URL.RawQuery = url.Values{"taskID": {req.TaskID.String()}}.Encode()
+	return nil
+}
+
+// encodeHTTPGetAllToDoRequest is a transport/http.EncodeRequestFunc that
+// encodes a getAllToDo request as query parameters. Primarily useful in a
+// client.
+func encodeHTTPGetAllToDoRequest(_ context.Context, r *http.Request, request interface{}) error {
+	req := request.(addendpoint.GetAllToDoRequest)
+	params := url.Values{}
+	if req.Cursor != "" {
+		params.Set("cursor", req.Cursor)
+	}
+	if req.Scheduled {
+		params.Set("scheduled", "true")
+	}
+	if req.Query != "" {
+		params.Set("query", req.Query)
+	}
+	if req.Status != "" {
+		params.Set("status", req.Status)
+	}
+	if req.Sort != "" {
+		params.Set("sort", req.Sort)
+	}
+	if req.Order != "" {
+		params.Set("order", req.Order)
+	}
+	if req.Overdue {
+		params.Set("overdue", "true")
+	}
+	if req.Priority != "" {
+		params.Set("priority", string(req.Priority))
+	}
+	if req.DueBefore != nil {
+		params.Set("dueBefore", req.DueBefore.Format(time.RFC3339Nano))
+	}
+	if req.DueAfter != nil {
+		params.Set("dueAfter", req.DueAfter.Format(time.RFC3339Nano))
+	}
+	r.URL.RawQuery = params.Encode()
+	return nil
+}
+
+// encodeHTTPSearchToDoRequest is a transport/http.EncodeRequestFunc that
+// encodes a searchToDo request as the q parameter. Primarily useful in a
+// client.
+func encodeHTTPSearchToDoRequest(_ context.Context, r *http.Request, request interface{}) error {
+	req := request.(addendpoint.SearchToDoRequest)
+	r.URL.RawQuery = url.Values{"q": {req.Query}}.Encode()
+	return nil
+}
+
+// encodeHTTPViewRequest is a transport/http.EncodeRequestFunc that encodes a
+// view or deleteView request as the /views/{name} path. Primarily useful in a
+// client.
+func encodeHTTPViewRequest(_ context.Context, r *http.Request, request interface{}) error {
+	var name string
+	switch req := request.(type) {
+	case addendpoint.ViewRequest:
+		name = req.Name
+	case addendpoint.DeleteViewRequest:
+		name = req.Name
+	}
+	r.URL.Path += url.PathEscape(name)
+	return nil
+}
+
+// encodeHTTPWebhookRequest is a transport/http.EncodeRequestFunc that encodes
+// a deleteWebhook request as the /webhooks/{id} path. Primarily useful in a
+// client.
+func encodeHTTPWebhookRequest(_ context.Context, r *http.Request, request interface{}) error {
+	r.URL.Path += url.PathEscape(request.(addendpoint.DeleteWebhookRequest).ID)
+	return nil
+}
+
+// encodeHTTPJobRequest is a transport/http.EncodeRequestFunc that encodes a
+// jobStatus or cancelJob request as the /jobs/{id} path. Primarily useful in
+// a client.
+func encodeHTTPJobRequest(_ context.Context, r *http.Request, request interface{}) error {
+	var id string
+	switch req := request.(type) {
+	case addendpoint.JobStatusRequest:
+		id = req.JobID
+	case addendpoint.CancelJobRequest:
+		id = req.JobID
+	}
+	r.URL.Path += url.PathEscape(id)
+	return nil
+}
+
+// encodeHTTPFlaggedRequest is a transport/http.EncodeRequestFunc that
+// encodes an approveFlagged or rejectFlagged request as the
+// /moderation/{approve,reject}/{taskID} path. Primarily useful in a client.
+func encodeHTTPFlaggedRequest(_ context.Context, r *http.Request, request interface{}) error {
+	var id models.TaskID
+	switch req := request.(type) {
+	case addendpoint.ApproveFlaggedRequest:
+		id = req.TaskID
+	case addendpoint.RejectFlaggedRequest:
+		id = req.TaskID
+	}
+	r.URL.Path += url.PathEscape(id.String())
+	return nil
+}
+
+// encodeHTTPJobResponse is a transport/http.EncodeResponseFunc that encodes
+// the job started by a request as a 202 response, locating the job resource.
+// Primarily useful in a server.
+func encodeHTTPJobResponse(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+	if f, ok := response.(endpoint.Failer); ok && f.Failed() != nil {
+		errorEncoder(ctx, f.Failed(), w)
+		return nil
+	}
+	status := response.(addendpoint.FactorizeResponse).Status
+	w.Header().Set("Location", "/jobs/"+url.PathEscape(status.ID))
+	return writeEnvelope(ctx, w, http.StatusAccepted, envelopeOf(response))
+}
+
 // encodeHTTPGenericResponse is a transport/http.EncodeResponseFunc that encodes
 // the response as JSON to the response writer. Primarily useful in a server.
 func encodeHTTPGenericResponse(ctx context.Context, w http.ResponseWriter, response interface{}) error {
@@ -560,6 +2123,5 @@ func encodeHTTPGenericResponse(ctx context.Context, w http.ResponseWriter, respo
 		errorEncoder(ctx, f.Failed(), w)
 		return nil
 	}
-	w.Header().Set("Content-Type", "application/json; charset=utf-8")
-	return json.NewEncoder(w).Encode(response)
+	return writeEnvelope(ctx, w, http.StatusOK, envelopeOf(response))
 }
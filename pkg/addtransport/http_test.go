@@ -0,0 +1,686 @@
+package addtransport
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/metrics"
+	"github.com/go-kit/kit/metrics/discard"
+	"github.com/go-kit/kit/metrics/generic"
+	"github.com/gorilla/websocket"
+
+	"ray.vhatt/todo-gokit/pkg/addendpoint"
+	"ray.vhatt/todo-gokit/pkg/addservice"
+	"ray.vhatt/todo-gokit/pkg/errcode"
+	"ray.vhatt/todo-gokit/pkg/models"
+	"ray.vhatt/todo-gokit/pkg/store"
+	"ray.vhatt/todo-gokit/pkg/tenant"
+	"ray.vhatt/todo-gokit/pkg/views"
+)
+
+func TestHTTPMethodRouting(t *testing.T) {
+	nop := func(context.Context, interface{}) (interface{}, error) { return struct{}{}, nil }
+	job := func(context.Context, interface{}) (interface{}, error) { return addendpoint.FactorizeResponse{}, nil }
+	watch := func(context.Context, interface{}) (interface{}, error) {
+		changes := make(chan models.ToDoEvent)
+		close(changes)
+		return addendpoint.WatchToDosResponse{Changes: changes}, nil
+	}
+	eps := addendpoint.Set{
+		SumEndpoint:              nop,
+		ConcatEndpoint:           nop,
+		MultiplyEndpoint:         nop,
+		DivideEndpoint:           nop,
+		PingEndpoint:             nop,
+		AddToDoEndpoint:          nop,
+		CompleteToDoEndPoint:     nop,
+		UnDoToDoEndpoint:         nop,
+		UpdateToDoEndpoint:       nop,
+		DeleteToDoEndpoint:       nop,
+		BatchToDoEndpoint:        nop,
+		GetToDoByIDEndpoint:      nop,
+		GetAllToDoEndpoint:       nop,
+		SimilarToDoEndpoint:      nop,
+		SearchToDoEndpoint:       nop,
+		CompletionStatsEndpoint:  nop,
+		WatchToDosEndpoint:       watch,
+		ViewEndpoint:             nop,
+		SaveViewEndpoint:         nop,
+		ListViewsEndpoint:        nop,
+		DeleteViewEndpoint:       nop,
+		CreateWebhookEndpoint:    nop,
+		DeleteWebhookEndpoint:    nop,
+		GetFieldSchemaEndpoint:   nop,
+		SaveFieldSchemaEndpoint:  nop,
+		GetWorkflowEndpoint:      nop,
+		SaveWorkflowEndpoint:     nop,
+		AddDependencyEndpoint:    nop,
+		RemoveDependencyEndpoint: nop,
+		FactorizeEndpoint:        job,
+		JobStatusEndpoint:        nop,
+		CancelJobEndpoint:        nop,
+		ListFlaggedEndpoint:      nop,
+		ApproveFlaggedEndpoint:   nop,
+		RejectFlaggedEndpoint:    nop,
+	}
+	srv := httptest.NewServer(NewHTTPHandler(eps, opentracing.GlobalTracer(), nil, log.NewNopLogger()))
+	defer srv.Close()
+
+	methods := []string{"GET", "POST", "PUT", "PATCH", "DELETE"}
+	for _, route := range []struct {
+		path, allow string
+		status      int // defaults to 200
+	}{
+		{path: "/sum", allow: "POST"},
+		{path: "/concat", allow: "POST"},
+		{path: "/multiply", allow: "POST"},
+		{path: "/divide", allow: "POST"},
+		{path: "/ping", allow: "GET"},
+		{path: "/healthz", allow: "GET, HEAD"},
+		{path: "/addToDo", allow: "POST"},
+		{path: "/completeToDo", allow: "PUT"},
+		{path: "/unDoToDo", allow: "PUT"},
+		{path: "/updateToDo", allow: "PATCH"},
+		{path: "/deleteToDo", allow: "DELETE"},
+		{path: "/todos/batch", allow: "POST"},
+		{path: "/getToDoByID", allow: "GET"},
+		{path: "/getAllToDo", allow: "GET"},
+		{path: "/similarToDo", allow: "POST"},
+		{path: "/todos/search", allow: "GET"},
+		{path: "/todos/overdue", allow: "GET"},
+		{path: "/todos/watch", allow: "GET"},
+		{path: "/stats/completion", allow: "GET"},
+		{path: "/views", allow: "GET, POST"},
+		{path: "/views/today", allow: "DELETE, GET"},
+		{path: "/webhooks", allow: "POST"},
+		{path: "/webhooks/abc", allow: "DELETE"},
+		{path: "/fields", allow: "GET, PUT"},
+		{path: "/workflow", allow: "GET, PUT"},
+		{path: "/todos/dependencies", allow: "DELETE, POST"},
+		{path: "/ws", allow: "GET", status: http.StatusUpgradeRequired},
+		{path: "/factorize", allow: "POST", status: http.StatusAccepted},
+		{path: "/jobs/abc", allow: "DELETE, GET"},
+		{path: "/moderation", allow: "GET"},
+		{path: "/moderation/approve/000000000000000000000001", allow: "POST"},
+		{path: "/moderation/reject/000000000000000000000001", allow: "POST"},
+	} {
+		for _, method := range methods {
+			req, _ := http.NewRequest(method, srv.URL+route.path, strings.NewReader(`{}`))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("%s %s: %v", method, route.path, err)
+			}
+			resp.Body.Close()
+
+			if strings.Contains(route.allow, method) {
+				want := route.status
+				if want == 0 {
+					want = http.StatusOK
+				}
+				if have := resp.StatusCode; want != have {
+					t.Errorf("%s %s: want %d, have %d", method, route.path, want, have)
+				}
+				continue
+			}
+			if want, have := http.StatusMethodNotAllowed, resp.StatusCode; want != have {
+				t.Errorf("%s %s: want %d, have %d", method, route.path, want, have)
+			}
+			if want, have := route.allow, resp.Header.Get("Allow"); want != have {
+				t.Errorf("%s %s: want Allow %q, have %q", method, route.path, want, have)
+			}
+		}
+	}
+}
+
+func TestHealthz(t *testing.T) {
+	rec := httptest.NewRecorder()
+	healthz(rec, httptest.NewRequest("GET", "/healthz", nil))
+	if want, have := `{"data":{"status":"up"}}`+"\n", rec.Body.String(); want != have {
+		t.Errorf("want %s, have %s", want, have)
+	}
+
+	w := &discardWriter{header: make(http.Header)}
+	r := httptest.NewRequest("GET", "/healthz", nil)
+	if allocs := testing.AllocsPerRun(100, func() { healthz(w, r) }); allocs != 0 {
+		t.Errorf("want no allocations, have %v", allocs)
+	}
+}
+
+func TestJSONBodies(t *testing.T) {
+	handler := jsonBodies(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		w.Write(body)
+	}))
+	for _, testcase := range []struct {
+		contentType, body string
+		status            int
+		code              string
+	}{
+		{contentType: "application/json", body: `{"task":"café"}`, status: http.StatusOK},
+		{contentType: "application/json; charset=UTF-8", body: `{}`, status: http.StatusOK},
+		{contentType: "", body: "", status: http.StatusOK},
+		{contentType: "", body: `{}`, status: http.StatusUnsupportedMediaType, code: "unsupported_media_type"},
+		{contentType: "text/plain", body: `{}`, status: http.StatusUnsupportedMediaType, code: "unsupported_media_type"},
+		{contentType: "application/json; charset=latin1", body: `{}`, status: http.StatusUnsupportedMediaType, code: "unsupported_media_type"},
+		{contentType: "application/json", body: "{\"task\":\"caf\xe9\"}", status: http.StatusBadRequest, code: "invalid_encoding"},
+	} {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/addToDo", strings.NewReader(testcase.body))
+		req.Header.Set("Content-Type", testcase.contentType)
+		handler.ServeHTTP(rec, req)
+		if want, have := testcase.status, rec.Code; want != have {
+			t.Errorf("%q %q: want %d, have %d", testcase.contentType, testcase.body, want, have)
+		}
+		if testcase.code == "" {
+			if want, have := testcase.body, rec.Body.String(); want != have {
+				t.Errorf("%q: want the body %q passed on, have %q", testcase.contentType, want, have)
+			}
+		} else if !strings.Contains(rec.Body.String(), `"code":"`+testcase.code+`"`) {
+			t.Errorf("%q %q: want code %s, have %s", testcase.contentType, testcase.body, testcase.code, rec.Body.String())
+		}
+	}
+}
+
+func TestMaxBodySize(t *testing.T) {
+	handler := MaxBodySize(16, jsonBodies(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		w.Write(body)
+	})))
+	for _, testcase := range []struct {
+		body    string
+		chunked bool
+		status  int
+	}{
+		{body: `{"task":"write"}`, status: http.StatusOK},
+		{body: `{"task":"write it"}`, status: http.StatusRequestEntityTooLarge},
+		{body: `{"task":"write it"}`, chunked: true, status: http.StatusRequestEntityTooLarge},
+	} {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/addToDo", strings.NewReader(testcase.body))
+		req.Header.Set("Content-Type", "application/json")
+		if testcase.chunked {
+			req.ContentLength = -1
+		}
+		handler.ServeHTTP(rec, req)
+		if want, have := testcase.status, rec.Code; want != have {
+			t.Errorf("%q (chunked %v): want %d, have %d", testcase.body, testcase.chunked, want, have)
+		}
+		if testcase.status != http.StatusOK && !strings.Contains(rec.Body.String(), `"code":"request_too_large"`) {
+			t.Errorf("%q: want code request_too_large, have %s", testcase.body, rec.Body.String())
+		}
+	}
+}
+
+// discardWriter is a ResponseWriter that doesn't allocate.
+type discardWriter struct{ header http.Header }
+
+func (w *discardWriter) Header() http.Header         { return w.header }
+func (w *discardWriter) Write(b []byte) (int, error) { return len(b), nil }
+func (w *discardWriter) WriteHeader(int)             {}
+
+func TestHTTPCancelledRequest(t *testing.T) {
+	cancelled := generic.NewCounter("cancelled")
+	block := func(ctx context.Context, _ interface{}) (interface{}, error) {
+		<-ctx.Done()
+		return addendpoint.GetAllToDoResponse{Err: errors.New("store: operation aborted")}, nil
+	}
+	eps := addendpoint.Set{GetAllToDoEndpoint: addendpoint.CancellationMiddleware(cancelled)(block)}
+	handler := NewHTTPHandler(eps, opentracing.GlobalTracer(), nil, log.NewNopLogger())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+	req := httptest.NewRequest("GET", "/getAllToDo", nil).WithContext(ctx)
+	rec := httptest.NewRecorder()
+
+	done := make(chan struct{})
+	go func() {
+		handler.ServeHTTP(rec, req)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("request still running a second after it was cancelled")
+	}
+
+	if want, have := errcode.StatusClientClosedRequest, rec.Code; want != have {
+		t.Errorf("want %d, have %d", want, have)
+	}
+	if want, have := 1.0, cancelled.Value(); want != have {
+		t.Errorf("want %v cancellation counted, have %v", want, have)
+	}
+}
+
+func TestHTTPEnvelope(t *testing.T) {
+	page := models.ToDoPage{Todos: []models.ToDoItem{{Task: "a"}}, Truncated: true, Cursor: "next"}
+	eps := addendpoint.Set{
+		GetAllToDoEndpoint: func(context.Context, interface{}) (interface{}, error) {
+			return addendpoint.GetAllToDoResponse{ToDoPage: page}, nil
+		},
+		ViewEndpoint: func(context.Context, interface{}) (interface{}, error) {
+			return addendpoint.ViewResponse{Err: views.ErrViewNotFound}, nil
+		},
+	}
+	srv := httptest.NewServer(NewHTTPHandler(eps, opentracing.GlobalTracer(), nil, log.NewNopLogger()))
+	defer srv.Close()
+
+	for _, test := range []struct {
+		method, path string
+		status       int
+		body         string
+	}{
+		{"GET", "/getAllToDo", http.StatusOK, `{"data":{"todos":[{"task":"a","status":false}]},"meta":{"requestId":"r1","pagination":{"cursor":"next","truncated":true}}}`},
+		{"GET", "/views/gone", http.StatusNotFound, `{"error":{"code":"view_not_found","message":"view not found"},"meta":{"requestId":"r1"}}`},
+		{"PUT", "/views", http.StatusMethodNotAllowed, `{"error":{"code":"method_not_allowed","message":"Method Not Allowed","details":{"allow":["GET","POST"]}},"meta":{"requestId":"r1"}}`},
+	} {
+		req, _ := http.NewRequest(test.method, srv.URL+test.path, nil)
+		req.Header.Set("X-Request-ID", "r1")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != test.status || strings.TrimSpace(string(body)) != test.body {
+			t.Errorf("%s %s: want %d %s, have %d %s", test.method, test.path, test.status, test.body, resp.StatusCode, body)
+		}
+		if want, have := "r1", resp.Header.Get("X-Request-ID"); want != have {
+			t.Errorf("%s %s: want request ID %q, have %q", test.method, test.path, want, have)
+		}
+	}
+
+	// The client unwraps the envelope.
+	svc, err := NewHTTPClient(srv.URL, opentracing.GlobalTracer(), nil, log.NewNopLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if have, err := svc.GetAllToDo(context.Background(), models.ListOptions{}); err != nil || !reflect.DeepEqual(have, page) {
+		t.Errorf("want %v, have %v, %v", page, have, err)
+	}
+	var statusErr *StatusError
+	if _, err := svc.View(context.Background(), "gone"); !errors.As(err, &statusErr) || statusErr.Code != "view_not_found" {
+		t.Errorf("want a view_not_found error, have %v", err)
+	}
+}
+
+func TestHTTPWatchToDos(t *testing.T) {
+	changes := make(chan models.ToDoEvent, 2)
+	changes <- models.ToDoEvent{Type: models.ToDoCreated, TaskID: "000000000000000000000001", Todo: &models.ToDoItem{ID: "000000000000000000000001", Task: "a"}}
+	changes <- models.ToDoEvent{Type: models.ToDoDeleted, TaskID: "000000000000000000000001"}
+	close(changes)
+	eps := addendpoint.Set{
+		WatchToDosEndpoint: func(context.Context, interface{}) (interface{}, error) {
+			return addendpoint.WatchToDosResponse{Changes: changes}, nil
+		},
+	}
+	srv := httptest.NewServer(NewHTTPHandler(eps, opentracing.GlobalTracer(), nil, log.NewNopLogger()))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/todos/watch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if want, have := "text/event-stream", resp.Header.Get("Content-Type"); want != have {
+		t.Errorf("want %s, have %s", want, have)
+	}
+	want := "event: created\ndata: {\"type\":\"created\",\"taskID\":\"000000000000000000000001\",\"todo\":{\"_id\":\"000000000000000000000001\",\"task\":\"a\",\"status\":false}}\n\n" +
+		"event: deleted\ndata: {\"type\":\"deleted\",\"taskID\":\"000000000000000000000001\"}\n\n"
+	if have := string(body); want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+}
+
+// TestHTTPWatchToDosRateLimited streams through the endpoints addendpoint.New
+// builds, whose rate limit headers wrap the response writer.
+func TestHTTPWatchToDosRateLimited(t *testing.T) {
+	svc, err := addservice.New(store.NewInMemoryStore(), log.NewNopLogger(), discard.NewCounter(), discard.NewCounter(), discard.NewHistogram(), discard.NewHistogram(), discard.NewHistogram(), addservice.DefaultConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	eps := addendpoint.New(svc, log.NewNopLogger(), discard.NewHistogram(), discard.NewCounter(), opentracing.GlobalTracer(), nil)
+	srv := httptest.NewServer(NewHTTPHandler(eps, opentracing.GlobalTracer(), nil, log.NewNopLogger()))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, _ := http.NewRequest("GET", srv.URL+"/todos/watch", nil)
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if want, have := http.StatusOK, resp.StatusCode; want != have {
+		body, _ := ioutil.ReadAll(resp.Body)
+		t.Fatalf("want %d, have %d: %s", want, have, body)
+	}
+	if resp.Header.Get("X-RateLimit-Remaining") == "" {
+		t.Error("missing rate limit headers")
+	}
+	if _, err := svc.AddToDo(context.Background(), models.ToDoItem{Task: "water the plants"}); err != nil {
+		t.Fatal(err)
+	}
+	line, err := bufio.NewReader(resp.Body).ReadString('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, have := "event: created\n", line; want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+}
+
+func TestHTTPWebSocket(t *testing.T) {
+	changes := make(chan models.ToDoEvent, 1)
+	changes <- models.ToDoEvent{Type: models.ToDoDeleted, TaskID: "000000000000000000000001"}
+	eps := addendpoint.Set{
+		WatchToDosEndpoint: func(context.Context, interface{}) (interface{}, error) {
+			return addendpoint.WatchToDosResponse{Changes: changes}, nil
+		},
+		AddToDoEndpoint: func(ctx context.Context, request interface{}) (interface{}, error) {
+			req := request.(addendpoint.AddToDoRequest)
+			if req.Task != "water the plants" || tenant.FromContext(ctx) != "acme" {
+				return nil, fmt.Errorf("unexpected request %+v", req)
+			}
+			return addendpoint.AddToDoResponse{TaskID: "000000000000000000000002"}, nil
+		},
+		CompleteToDoEndPoint: func(context.Context, interface{}) (interface{}, error) {
+			return addendpoint.CompleteToDoResponse{Err: store.ErrToDoNotFound}, nil
+		},
+	}
+	srv := httptest.NewServer(NewHTTPHandler(eps, opentracing.GlobalTracer(), nil, log.NewNopLogger()))
+	defer srv.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws://"+srv.Listener.Addr().String()+"/ws", http.Header{"X-Tenant-ID": {"acme"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	for _, cmd := range []string{
+		`{"id":"1","method":"addToDo","params":{"task":"water the plants"}}`,
+		`{"id":"2","method":"completeToDo","params":{"taskID":"000000000000000000000003"}}`,
+		`{"id":"3","method":"sum","params":{"a":1,"b":2}}`,
+	} {
+		if err := conn.WriteMessage(websocket.TextMessage, []byte(cmd)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	messages := make(map[string]string)
+	for len(messages) < 4 {
+		_, payload, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var m struct {
+			Type string `json:"type"`
+			ID   string `json:"id"`
+		}
+		json.Unmarshal(payload, &m)
+		messages[m.Type+m.ID] = string(payload)
+	}
+	for key, want := range map[string]string{
+		"change": `{"type":"change","data":{"type":"deleted","taskID":"000000000000000000000001"}}`,
+		"reply1": `{"type":"reply","id":"1","data":{"taskID":"000000000000000000000002"}}`,
+		"reply2": `{"type":"reply","id":"2","error":{"code":"todo_not_found","message":"` + store.ErrToDoNotFound.Error() + `"}}`,
+		"reply3": `{"type":"reply","id":"3","error":{"code":"invalid_command","message":"unknown method sum"}}`,
+	} {
+		if have := messages[key]; want != have {
+			t.Errorf("%s: want %s, have %s", key, want, have)
+		}
+	}
+
+	if err := conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")); err != nil {
+		t.Fatal(err)
+	}
+	_, _, err = conn.ReadMessage()
+	if !websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+		t.Errorf("want the close echoed, have %v", err)
+	}
+}
+
+func TestHTTPWebSocketOrigin(t *testing.T) {
+	eps := addendpoint.Set{
+		WatchToDosEndpoint: func(context.Context, interface{}) (interface{}, error) {
+			return addendpoint.WatchToDosResponse{Changes: make(chan models.ToDoEvent)}, nil
+		},
+	}
+	handler := NewHTTPHandler(eps, opentracing.GlobalTracer(), nil, log.NewNopLogger())
+	for _, testcase := range []struct {
+		name       string
+		origins    []string
+		origin     string
+		wantStatus int
+	}{
+		{"no origin", nil, "", http.StatusSwitchingProtocols},
+		{"own origin", nil, "http://{host}", http.StatusSwitchingProtocols},
+		{"unknown origin", nil, "https://evil.example.com", http.StatusForbidden},
+		{"allowed origin", []string{"https://app.example.com"}, "https://app.example.com", http.StatusSwitchingProtocols},
+		{"other origin", []string{"https://app.example.com"}, "https://evil.example.com", http.StatusForbidden},
+	} {
+		t.Run(testcase.name, func(t *testing.T) {
+			srv := httptest.NewServer(WebSocketOrigins(testcase.origins, handler))
+			defer srv.Close()
+			header := http.Header{}
+			if testcase.origin != "" {
+				header.Set("Origin", strings.Replace(testcase.origin, "{host}", srv.Listener.Addr().String(), 1))
+			}
+			conn, resp, err := websocket.DefaultDialer.Dial("ws://"+srv.Listener.Addr().String()+"/ws", header)
+			if conn != nil {
+				conn.Close()
+			}
+			if resp == nil {
+				t.Fatal(err)
+			}
+			if want, have := testcase.wantStatus, resp.StatusCode; want != have {
+				t.Errorf("want %d, have %d (%v)", want, have, err)
+			}
+		})
+	}
+}
+
+func TestParseOrigins(t *testing.T) {
+	origins, err := ParseOrigins("https://app.example.com, http://localhost:3000/")
+	if want := []string{"https://app.example.com", "http://localhost:3000"}; err != nil || !reflect.DeepEqual(want, origins) {
+		t.Errorf("want %v, have %v, %v", want, origins, err)
+	}
+	for _, s := range []string{"app.example.com", "ftp://app.example.com", "https://app.example.com/page", "https://"} {
+		if _, err := ParseOrigins(s); err == nil {
+			t.Errorf("%s: want an error", s)
+		}
+	}
+}
+
+func TestStoreTargetOverride(t *testing.T) {
+	var have store.Target
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		have, _ = store.TargetFrom(r.Context())
+	})
+	handler := StoreTargetOverride("s3cret", next)
+
+	for _, testcase := range []struct {
+		token      string
+		wantStatus int
+	}{
+		{"", http.StatusForbidden},
+		{"guess", http.StatusForbidden},
+		{"s3cret", http.StatusOK},
+	} {
+		have = store.Target{}
+		req := httptest.NewRequest("GET", "/getAllToDo", nil)
+		req.Header.Set("X-Store-Database", "preview")
+		req.Header.Set("X-Admin-Token", testcase.token)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		if want, have := testcase.wantStatus, rec.Code; want != have {
+			t.Errorf("token %q: want %d, have %d", testcase.token, want, have)
+		}
+		if testcase.wantStatus == http.StatusOK && have.Database != "preview" {
+			t.Errorf("token %q: want the request routed to preview, have %+v", testcase.token, have)
+		}
+	}
+}
+
+func TestModerationAdmin(t *testing.T) {
+	handler := ModerationAdmin("s3cret", http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	for _, testcase := range []struct {
+		path, token string
+		wantStatus  int
+	}{
+		{"/moderation", "", http.StatusForbidden},
+		{"/moderation/approve/000000000000000000000001", "guess", http.StatusForbidden},
+		{"/moderation/reject/000000000000000000000001", "s3cret", http.StatusOK},
+		{"/moderationish", "", http.StatusOK},
+		{"/getAllToDo", "", http.StatusOK},
+	} {
+		req := httptest.NewRequest("GET", testcase.path, nil)
+		req.Header.Set("X-Admin-Token", testcase.token)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if want, have := testcase.wantStatus, rec.Code; want != have {
+			t.Errorf("%s with token %q: want %d, have %d", testcase.path, testcase.token, want, have)
+		}
+	}
+}
+
+func TestTrustedCallers(t *testing.T) {
+	networks, err := ParseNetworks("10.0.0.0/8, 192.0.2.7,2001:db8::/32")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var trusted bool
+	handler := TrustedCallers(networks, "s3cret", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		trusted = addendpoint.TrustedCaller(r.Context())
+	}))
+	for _, c := range []struct {
+		remoteAddr, token string
+		want              bool
+	}{
+		{"10.1.2.3:4567", "", true},
+		{"192.0.2.7:4567", "", true},
+		{"192.0.2.8:4567", "", false},
+		{"[2001:db8::1]:4567", "", true},
+		{"203.0.113.1:4567", "s3cret", true},
+		{"203.0.113.1:4567", "guess", false},
+	} {
+		r := httptest.NewRequest("GET", "/ping", nil)
+		r.RemoteAddr = c.remoteAddr
+		if c.token != "" {
+			r.Header.Set("X-Trusted-Caller", c.token)
+		}
+		handler.ServeHTTP(httptest.NewRecorder(), r)
+		if trusted != c.want {
+			t.Errorf("%s with token %q: want trusted %v, have %v", c.remoteAddr, c.token, c.want, trusted)
+		}
+	}
+
+	if _, err := ParseNetworks("10.0.0.0/33"); err == nil {
+		t.Error("want an error for an invalid network")
+	}
+}
+
+func TestDeprecated(t *testing.T) {
+	routes, err := ParseDeprecations("/addToDo:2020-06-01:2020-12-31,/jobs/:2020-06-01", "https://example.com/migrate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	used := labelCounter{counts: make(map[string]float64)}
+	handler := Deprecated(routes, used, log.NewNopLogger(), http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+
+	for _, testcase := range []struct {
+		path                string
+		deprecation, sunset string
+	}{
+		{"/addToDo", "@1590969600", "Thu, 31 Dec 2020 00:00:00 GMT"},
+		{"/jobs/abc", "@1590969600", ""},
+		{"/getAllToDo", "", ""},
+	} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest("GET", testcase.path, nil))
+		if want, have := testcase.deprecation, rec.Header().Get("Deprecation"); want != have {
+			t.Errorf("%s: want Deprecation %q, have %q", testcase.path, want, have)
+		}
+		if want, have := testcase.sunset, rec.Header().Get("Sunset"); want != have {
+			t.Errorf("%s: want Sunset %q, have %q", testcase.path, want, have)
+		}
+		if testcase.deprecation != "" && rec.Header().Get("Link") != `<https://example.com/migrate>; rel="deprecation"` {
+			t.Errorf("%s: want the migration linked, have %q", testcase.path, rec.Header().Get("Link"))
+		}
+	}
+	if want, have := map[string]float64{"/addToDo": 1, "/jobs/": 1}, used.counts; !reflect.DeepEqual(want, have) {
+		t.Errorf("want deprecated requests counted %v, have %v", want, have)
+	}
+
+	for _, s := range []string{"addToDo:2020-06-01", "/addToDo", "/addToDo:June", "/addToDo:2020-06-01:2020-01-01"} {
+		if _, err := ParseDeprecations(s, ""); err == nil {
+			t.Errorf("%q: want an error", s)
+		}
+	}
+}
+
+func TestAccessLog(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-ID", "r1")
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte("gone"))
+	})
+	for format, want := range map[string]string{
+		AccessLogCommon:   `192.0.2.1 - - [01/Jun/2020:10:00:00 +0000] "GET /views/a\"b HTTP/1.1" 404 4`,
+		AccessLogCombined: `192.0.2.1 - - [01/Jun/2020:10:00:00 +0000] "GET /views/a\"b HTTP/1.1" 404 4 "-" "cli \"1.0\""`,
+		AccessLogJSON:     `{"time":"2020-06-01T10:00:00Z","remote_addr":"192.0.2.1","method":"GET","uri":"/views/a\"b","proto":"HTTP/1.1","status":404,"bytes":4,"user_agent":"cli \"1.0\"","request_id":"r1","duration_seconds":0}`,
+	} {
+		var buf strings.Builder
+		handler, err := AccessLog(&buf, format, next)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req := httptest.NewRequest("GET", "/views/a%22b", nil)
+		req.RequestURI = "/views/a\"b"
+		req.Header.Set("User-Agent", `cli "1.0"`)
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+
+		// Pin the time and duration, which vary between runs.
+		have := buf.String()
+		if format == AccessLogJSON {
+			have = regexp.MustCompile(`"time":"[^"]*"`).ReplaceAllString(have, `"time":"2020-06-01T10:00:00Z"`)
+			have = regexp.MustCompile(`"duration_seconds":[0-9.e-]+`).ReplaceAllString(have, `"duration_seconds":0`)
+		} else {
+			have = regexp.MustCompile(`\[[^]]*\]`).ReplaceAllString(have, "[01/Jun/2020:10:00:00 +0000]")
+		}
+		if have != want+"\n" {
+			t.Errorf("%s: want\n%s\nhave\n%s", format, want, have)
+		}
+	}
+	if _, err := AccessLog(ioutil.Discard, "apache", next); err == nil {
+		t.Error("want an unknown format rejected")
+	}
+}
+
+// labelCounter counts by the value of its single label.
+type labelCounter struct {
+	counts map[string]float64
+	value  string
+}
+
+func (c labelCounter) With(labelValues ...string) metrics.Counter {
+	return labelCounter{counts: c.counts, value: labelValues[1]}
+}
+
+func (c labelCounter) Add(delta float64) { c.counts[c.value] += delta }
@@ -0,0 +1,27 @@
+package addtransport
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"ray.vhatt/todo-gokit/pkg/errcode"
+)
+
+// ModerationAdmin wraps next so that the review queue under /moderation is
+// only reachable with adminToken in the X-Admin-Token header, other requests
+// to it are refused with 403. An empty adminToken refuses them all.
+func ModerationAdmin(adminToken string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/moderation" && !strings.HasPrefix(r.URL.Path, "/moderation/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+		token := r.Header.Get(adminTokenHeader)
+		if adminToken == "" || subtle.ConstantTimeCompare([]byte(token), []byte(adminToken)) != 1 {
+			writeError(requestIDToContext(r.Context(), r), w, errcode.Forbidden, "the review queue requires an admin token", nil)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
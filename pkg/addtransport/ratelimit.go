@@ -0,0 +1,63 @@
+package addtransport
+
+import (
+	"net/http"
+	"strconv"
+
+	"ray.vhatt/todo-gokit/pkg/addendpoint"
+)
+
+// rateLimitHeaders wraps next so that every response carries the
+// X-RateLimit-Limit, X-RateLimit-Remaining and X-RateLimit-Reset headers of
+// limiter, letting well-behaved clients throttle themselves. A nil limiter
+// leaves next untouched.
+func rateLimitHeaders(limiter addendpoint.Limiter, next http.Handler) http.Handler {
+	if limiter == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(&rateLimitWriter{ResponseWriter: w, limiter: limiter}, r)
+	})
+}
+
+// rateLimitWriter stamps the rate limit headers just before the response
+// header is written, so they reflect the tokens consumed by the request.
+type rateLimitWriter struct {
+	http.ResponseWriter
+	limiter     addendpoint.Limiter
+	wroteHeader bool
+}
+
+func (w *rateLimitWriter) WriteHeader(code int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		limit, remaining, reset := w.limiter.State()
+		h := w.Header()
+		h.Set("X-RateLimit-Limit", strconv.Itoa(limit))
+		h.Set("X-RateLimit-Remaining", strconv.Itoa(remaining))
+		h.Set("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *rateLimitWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush flushes the response, if it can be, for the streamed responses.
+func (w *rateLimitWriter) Flush() {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap returns the wrapped ResponseWriter, for http.ResponseController.
+func (w *rateLimitWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,41 @@
+package addtransport
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"ray.vhatt/todo-gokit/pkg/errcode"
+	"ray.vhatt/todo-gokit/pkg/store"
+)
+
+// Headers selecting an alternate store target for a request.
+const (
+	adminTokenHeader      = "X-Admin-Token"
+	storeDatabaseHeader   = "X-Store-Database"
+	storeCollectionHeader = "X-Store-Collection"
+)
+
+// StoreTargetOverride wraps next so that requests carrying the
+// X-Store-Database or X-Store-Collection headers have their store operations
+// routed there, see store.WithTarget. Such requests must present adminToken
+// in the X-Admin-Token header, or are refused with 403. An empty adminToken
+// refuses all overrides.
+func StoreTargetOverride(adminToken string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		target := store.Target{
+			Database:   r.Header.Get(storeDatabaseHeader),
+			Collection: r.Header.Get(storeCollectionHeader),
+		}
+		if target.Database == "" && target.Collection == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		token := r.Header.Get(adminTokenHeader)
+		if adminToken == "" || subtle.ConstantTimeCompare([]byte(token), []byte(adminToken)) != 1 {
+			writeError(requestIDToContext(r.Context(), r), w, errcode.Forbidden, "store target override requires an admin token", nil)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(store.WithTarget(r.Context(), target)))
+	})
+}
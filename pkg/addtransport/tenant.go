@@ -0,0 +1,31 @@
+package addtransport
+
+import (
+	"context"
+	"net/http"
+
+	"ray.vhatt/todo-gokit/pkg/tenant"
+)
+
+// tenantHeader carries the tenant a request is made for. The edge proxy sets
+// it from the caller's credentials, and must strip it from what callers
+// send.
+const tenantHeader = "X-Tenant-ID"
+
+// tenantToContext is a transport/http.RequestFunc that stores the tenant of
+// the request in the context, see tenant.FromContext.
+func tenantToContext(ctx context.Context, r *http.Request) context.Context {
+	if id := r.Header.Get(tenantHeader); id != "" {
+		return tenant.NewContext(ctx, id)
+	}
+	return ctx
+}
+
+// tenantToHTTP is a transport/http.RequestFunc that forwards the tenant of
+// the context in the request.
+func tenantToHTTP(ctx context.Context, r *http.Request) context.Context {
+	if id := tenant.FromContext(ctx); id != "" {
+		r.Header.Set(tenantHeader, id)
+	}
+	return ctx
+}
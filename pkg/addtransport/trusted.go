@@ -0,0 +1,79 @@
+package addtransport
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"ray.vhatt/todo-gokit/pkg/addendpoint"
+)
+
+// trustedCallerHeader carries the token of trusted callers. The edge proxy
+// sets it on internal monitoring traffic, and must strip it from everything
+// else.
+const trustedCallerHeader = "X-Trusted-Caller"
+
+// TrustedCallers wraps next so that requests from networks, or presenting
+// token in the X-Trusted-Caller header, are marked with
+// addendpoint.WithTrustedCaller and bypass the rate limiters and circuit
+// breakers. An empty token trusts no header.
+func TrustedCallers(networks []*net.IPNet, token string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if trustedNetwork(networks, r.RemoteAddr) || trustedToken(token, r.Header.Get(trustedCallerHeader)) {
+			r = r.WithContext(addendpoint.WithTrustedCaller(r.Context()))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func trustedNetwork(networks []*net.IPNet, remoteAddr string) bool {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	for _, network := range networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func trustedToken(token, presented string) bool {
+	return token != "" && subtle.ConstantTimeCompare([]byte(presented), []byte(token)) == 1
+}
+
+// ParseNetworks parses a list of CIDR blocks separated by commas, like
+// "10.0.0.0/8,fd00::/8". A bare IP address is a block of its own.
+func ParseNetworks(s string) ([]*net.IPNet, error) {
+	var networks []*net.IPNet
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		if !strings.Contains(item, "/") {
+			ip := net.ParseIP(item)
+			if ip == nil {
+				return nil, fmt.Errorf("network %q: invalid IP address", item)
+			}
+			bits := 8 * len(ip.To4())
+			if bits == 0 {
+				bits = 8 * net.IPv6len
+			}
+			item = fmt.Sprintf("%s/%d", item, bits)
+		}
+		_, network, err := net.ParseCIDR(item)
+		if err != nil {
+			return nil, fmt.Errorf("network %q: %v", item, err)
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
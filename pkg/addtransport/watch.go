@@ -0,0 +1,63 @@
+package addtransport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"ray.vhatt/todo-gokit/pkg/addendpoint"
+)
+
+// watchHeartbeat is how often a watch stream without changes gets a comment,
+// so that proxies don't close it as idle.
+const watchHeartbeat = 15 * time.Second
+
+// encodeHTTPWatchToDosResponse is a transport/http.EncodeResponseFunc that
+// streams the changes of a watchToDos response as server-sent events, one
+// per change, named after its type, with the change as JSON data. The
+// stream lasts until the client goes away or the changes end.
+func encodeHTTPWatchToDosResponse(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+	resp := response.(addendpoint.WatchToDosResponse)
+	if resp.Err != nil {
+		errorEncoder(ctx, resp.Err, w)
+		return nil
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return errors.New("the response can't be streamed")
+	}
+	if id := requestIDFromContext(ctx); id != "" {
+		w.Header().Set(requestIDHeader, id)
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(watchHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case e, ok := <-resp.Changes:
+			if !ok {
+				return nil
+			}
+			data, err := codec.Marshal(e)
+			if err != nil {
+				return err
+			}
+			if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", e.Type, data); err != nil {
+				return err
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return err
+			}
+		case <-ctx.Done():
+			return nil
+		}
+		flusher.Flush()
+	}
+}
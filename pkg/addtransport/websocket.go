@@ -0,0 +1,159 @@
+package addtransport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// wsMaxMessage is the largest message a client may send, in bytes.
+	wsMaxMessage = 1 << 20
+	// wsWriteTimeout is how long a message may take to be written, before
+	// the client is given up on.
+	wsWriteTimeout = 10 * time.Second
+)
+
+var (
+	errNotWebSocket  = errors.New("the request isn't a WebSocket handshake")
+	errWSInvalidUTF8 = errors.New("WebSocket text message isn't valid UTF-8")
+)
+
+type wsOriginsKey struct{}
+
+// WebSocketOrigins lets the pages of origins, like https://app.example.com,
+// open the WebSocket of /ws in the requests next serves. Browsers tell the
+// origin of the page opening a WebSocket, and the others are refused: only
+// the pages of the service's own origin, and the clients that aren't
+// browsers, which tell none, may open it otherwise.
+func WebSocketOrigins(origins []string, next http.Handler) http.Handler {
+	if len(origins) == 0 {
+		return next
+	}
+	allowed := make(map[string]bool, len(origins))
+	for _, origin := range origins {
+		allowed[strings.ToLower(origin)] = true
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), wsOriginsKey{}, allowed)))
+	})
+}
+
+// ParseOrigins parses origins separated by commas, as scheme://host[:port].
+func ParseOrigins(s string) ([]string, error) {
+	var origins []string
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		u, err := url.Parse(item)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || strings.TrimSuffix(u.Path, "/") != "" || u.RawQuery != "" {
+			return nil, fmt.Errorf("origin %q isn't like https://host[:port]", item)
+		}
+		origins = append(origins, u.Scheme+"://"+u.Host)
+	}
+	return origins, nil
+}
+
+// checkOrigin reports whether the page r comes from may open a WebSocket:
+// the requests without an Origin, those of the origin of the service and
+// those WebSocketOrigins allows may.
+func checkOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	if u, err := url.Parse(origin); err == nil && strings.EqualFold(u.Host, r.Host) {
+		return true
+	}
+	allowed, _ := r.Context().Value(wsOriginsKey{}).(map[string]bool)
+	return allowed[strings.ToLower(origin)]
+}
+
+// wsConn is the server side of a WebSocket connection. One goroutine at a
+// time may read messages, any may write them.
+type wsConn struct {
+	conn *websocket.Conn
+	wmtx sync.Mutex
+}
+
+// upgradeWebSocket answers the opening handshake of r, and takes over its
+// connection. A handshake refused is answered with fail, given the status
+// of the refusal and why.
+func upgradeWebSocket(w http.ResponseWriter, r *http.Request, fail func(status int, err error)) (*wsConn, error) {
+	upgrader := websocket.Upgrader{
+		CheckOrigin: checkOrigin,
+		Error: func(_ http.ResponseWriter, _ *http.Request, status int, reason error) {
+			fail(status, reason)
+		},
+	}
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return nil, err
+	}
+	conn.SetReadLimit(wsMaxMessage)
+	return &wsConn{conn: conn}, nil
+}
+
+// ReadMessage returns the next message of the client. Its pings are
+// answered on the way, and its close is answered and returned as io.EOF.
+func (c *wsConn) ReadMessage() ([]byte, error) {
+	messageType, message, err := c.conn.ReadMessage()
+	var closed *websocket.CloseError
+	if errors.As(err, &closed) {
+		return nil, io.EOF
+	}
+	if err != nil {
+		return nil, err
+	}
+	if messageType == websocket.TextMessage && !utf8.Valid(message) {
+		return nil, errWSInvalidUTF8
+	}
+	return message, nil
+}
+
+// WriteMessage writes a text message to the client.
+func (c *wsConn) WriteMessage(message []byte) error {
+	c.wmtx.Lock()
+	defer c.wmtx.Unlock()
+	c.conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+	return c.conn.WriteMessage(websocket.TextMessage, message)
+}
+
+// Ping pings the client, keeping the connection from looking idle.
+func (c *wsConn) Ping() error {
+	return c.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteTimeout))
+}
+
+// Close closes the connection, telling the client why with status and
+// reason, unless a close was sent already.
+func (c *wsConn) Close(status int, reason string) error {
+	if len(reason) > 123 {
+		reason = reason[:123]
+	}
+	c.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(status, reason), time.Now().Add(wsWriteTimeout))
+	return c.conn.Close()
+}
+
+// closeStatus returns the status a connection failed reading with err is
+// closed with.
+func closeStatus(err error) int {
+	switch err {
+	case errWSInvalidUTF8:
+		return websocket.CloseInvalidFramePayloadData
+	case websocket.ErrReadLimit:
+		return websocket.CloseMessageTooBig
+	}
+	// The protocol errors were closed with their status already.
+	return websocket.CloseInternalServerErr
+}
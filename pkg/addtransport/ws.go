@@ -0,0 +1,245 @@
+package addtransport
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/go-kit/kit/log"
+	"github.com/gorilla/websocket"
+
+	"ray.vhatt/todo-gokit/pkg/addendpoint"
+	"ray.vhatt/todo-gokit/pkg/errcode"
+	"ray.vhatt/todo-gokit/pkg/models"
+)
+
+// wsCommand is a message of the client of /ws, calling the endpoint Method
+// with Params, the body of its HTTP request. The reply carries its ID.
+type wsCommand struct {
+	ID     string          `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+}
+
+// wsMessage is a message to the client of /ws: the reply to a command, with
+// the data or the error of its response, or a change of the todos.
+type wsMessage struct {
+	Type  string      `json:"type"`
+	ID    string      `json:"id,omitempty"`
+	Data  interface{} `json:"data,omitempty"`
+	Error *errorBody  `json:"error,omitempty"`
+}
+
+// The types of the messages to the client of /ws.
+const (
+	wsReply  = "reply"
+	wsChange = "change"
+)
+
+// wsMethod is a command the client of /ws may send: the endpoint it calls,
+// and the decoder of the request its params are.
+type wsMethod struct {
+	endpoint endpoint.Endpoint
+	decode   func(params []byte) (interface{}, error)
+}
+
+// wsMethods returns the commands of /ws, named after the paths of their
+// endpoints over HTTP.
+func wsMethods(endpoints addendpoint.Set) map[string]wsMethod {
+	return map[string]wsMethod{
+		"addToDo": {endpoints.AddToDoEndpoint, func(params []byte) (interface{}, error) {
+			var req addendpoint.AddToDoRequest
+			err := decodeParams(params, &req)
+			return req, err
+		}},
+		"completeToDo": {endpoints.CompleteToDoEndPoint, func(params []byte) (interface{}, error) {
+			var req addendpoint.CompleteToDoRequest
+			err := decodeParams(params, &req)
+			return req, err
+		}},
+		"unDoToDo": {endpoints.UnDoToDoEndpoint, func(params []byte) (interface{}, error) {
+			var req addendpoint.UnDoToDoRequest
+			err := decodeParams(params, &req)
+			return req, err
+		}},
+		"updateToDo": {endpoints.UpdateToDoEndpoint, func(params []byte) (interface{}, error) {
+			var req addendpoint.UpdateToDoRequest
+			err := decodeParams(params, &req)
+			return req, err
+		}},
+		"deleteToDo": {endpoints.DeleteToDoEndpoint, func(params []byte) (interface{}, error) {
+			var req addendpoint.DeleteToDoRequest
+			err := decodeParams(params, &req)
+			return req, err
+		}},
+		"batchToDo": {endpoints.BatchToDoEndpoint, func(params []byte) (interface{}, error) {
+			var req addendpoint.BatchToDoRequest
+			err := decodeParams(params, &req)
+			return req, err
+		}},
+		"getToDoByID": {endpoints.GetToDoByIDEndpoint, func(params []byte) (interface{}, error) {
+			var req addendpoint.GetToDoByIDRequest
+			err := decodeParams(params, &req)
+			return req, err
+		}},
+	}
+}
+
+// decodeParams decodes the params of a command into req, leaving it empty
+// without params.
+func decodeParams(params []byte, req interface{}) error {
+	if len(params) == 0 {
+		return nil
+	}
+	return codec.Unmarshal(params, req)
+}
+
+// newWebSocketHandler returns the handler of /ws. It upgrades the request
+// to a WebSocket streaming the changes of the todos, like /todos/watch, and
+// taking commands calling the endpoints, like their HTTP routes. Commands
+// are run in order, on behalf of the tenant of the upgrade request, and go
+// through the same rate limits as over HTTP. The pages of other origins
+// than those WebSocketOrigins allows are refused, so that they can't use
+// the credentials the browser sends along.
+func newWebSocketHandler(endpoints addendpoint.Set, logger log.Logger) http.Handler {
+	methods := wsMethods(endpoints)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := tenantToContext(requestIDToContext(r.Context(), r), r)
+		if !websocket.IsWebSocketUpgrade(r) {
+			writeError(ctx, w, errcode.UpgradeRequired, errNotWebSocket.Error(), nil)
+			return
+		}
+		if !checkOrigin(r) {
+			writeError(ctx, w, errcode.Forbidden, "WebSocket connections from "+r.Header.Get("Origin")+" aren't allowed", nil)
+			return
+		}
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		resp, err := endpoints.WatchToDosEndpoint(ctx, addendpoint.WatchToDosRequest{})
+		if err == nil {
+			err = resp.(addendpoint.WatchToDosResponse).Err
+		}
+		if err != nil {
+			errorEncoder(ctx, err, w)
+			return
+		}
+		conn, err := upgradeWebSocket(w, r, func(status int, err error) {
+			code := errcode.Internal
+			switch status {
+			case http.StatusBadRequest:
+				// Like an unsupported version, see RFC 6455, section 4.4.
+				code = errcode.UpgradeRequired
+			case http.StatusForbidden:
+				code = errcode.Forbidden
+			}
+			writeError(ctx, w, code, err.Error(), nil)
+		})
+		if err != nil {
+			return
+		}
+
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			streamChanges(ctx, conn, resp.(addendpoint.WatchToDosResponse).Changes, logger)
+		}()
+		status, reason := serveCommands(ctx, conn, methods, logger)
+		cancel()
+		conn.Close(status, reason)
+		wg.Wait()
+	})
+}
+
+// streamChanges writes changes to conn until ctx is done. The connection
+// is closed when the changes end or can't be written.
+func streamChanges(ctx context.Context, conn *wsConn, changes <-chan models.ToDoEvent, logger log.Logger) {
+	heartbeat := time.NewTicker(watchHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case e, ok := <-changes:
+			if !ok {
+				if ctx.Err() == nil {
+					conn.Close(websocket.CloseGoingAway, "the changes ended")
+				}
+				return
+			}
+			if err := writeWSMessage(conn, wsMessage{Type: wsChange, Data: e}); err != nil {
+				logger.Log("during", "WebSocket", "err", err)
+				conn.Close(websocket.CloseInternalServerErr, "")
+				return
+			}
+		case <-heartbeat.C:
+			if err := conn.Ping(); err != nil {
+				conn.Close(websocket.CloseGoingAway, "")
+				return
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// serveCommands runs the commands read from conn until it fails or the
+// client closes it, and returns the status and reason to close it with.
+func serveCommands(ctx context.Context, conn *wsConn, methods map[string]wsMethod, logger log.Logger) (int, string) {
+	for {
+		message, err := conn.ReadMessage()
+		if err == io.EOF {
+			return websocket.CloseNormalClosure, ""
+		}
+		if err != nil {
+			return closeStatus(err), err.Error()
+		}
+		reply := runCommand(ctx, message, methods)
+		if err := writeWSMessage(conn, reply); err != nil {
+			logger.Log("during", "WebSocket", "err", err)
+			return websocket.CloseInternalServerErr, ""
+		}
+	}
+}
+
+// runCommand runs the command message, and returns its reply.
+func runCommand(ctx context.Context, message []byte, methods map[string]wsMethod) wsMessage {
+	var cmd wsCommand
+	if err := codec.Unmarshal(message, &cmd); err != nil {
+		return wsError("", errcode.InvalidCommand, err.Error())
+	}
+	method, ok := methods[cmd.Method]
+	if !ok {
+		return wsError(cmd.ID, errcode.InvalidCommand, "unknown method "+cmd.Method)
+	}
+	req, err := method.decode(cmd.Params)
+	if err != nil {
+		return wsError(cmd.ID, errcode.InvalidCommand, err.Error())
+	}
+	resp, err := method.endpoint(ctx, req)
+	if err == nil {
+		if f, ok := resp.(endpoint.Failer); ok {
+			err = f.Failed()
+		}
+	}
+	if err != nil {
+		return wsError(cmd.ID, errcode.Of(err), err.Error())
+	}
+	return wsMessage{Type: wsReply, ID: cmd.ID, Data: resp}
+}
+
+// wsError returns the reply to the command id, failed with code.
+func wsError(id string, code errcode.Code, message string) wsMessage {
+	return wsMessage{Type: wsReply, ID: id, Error: &errorBody{Code: code.Name, Message: message}}
+}
+
+// writeWSMessage writes m to conn as JSON.
+func writeWSMessage(conn *wsConn, m wsMessage) error {
+	data, err := codec.Marshal(m)
+	if err != nil {
+		return err
+	}
+	return conn.WriteMessage(data)
+}
@@ -0,0 +1,135 @@
+// Package analytics emits product events, like the todos created and
+// completed, to a pluggable sink. Events carry IDs and measures only, never
+// the text of a todo, and tenants can opt out of them.
+package analytics
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-kit/kit/log"
+
+	"ray.vhatt/todo-gokit/pkg/models"
+	"ray.vhatt/todo-gokit/pkg/tenant"
+)
+
+// The names of the events.
+const (
+	TaskCreated   = "task_created"
+	TaskCompleted = "task_completed"
+)
+
+// Event is something that happened to a todo, segment-style: a name, who it
+// happened for and when, and properties depending on the name.
+type Event struct {
+	Name       string                 `json:"event"`
+	Tenant     string                 `json:"tenant,omitempty"`
+	TaskID     models.TaskID          `json:"taskID"`
+	At         time.Time              `json:"timestamp"`
+	Properties map[string]interface{} `json:"properties,omitempty"`
+}
+
+// Sink receives the events.
+type Sink interface {
+	Track(ctx context.Context, event Event) error
+}
+
+type logSink struct {
+	logger log.Logger
+}
+
+// NewLogSink returns a Sink logging the events to logger.
+func NewLogSink(logger log.Logger) Sink {
+	return logSink{logger: logger}
+}
+
+func (s logSink) Track(_ context.Context, e Event) error {
+	keyvals := []interface{}{"event", e.Name, "taskID", e.TaskID, "tenant", e.Tenant}
+	for k, v := range e.Properties {
+		keyvals = append(keyvals, k, v)
+	}
+	return s.logger.Log(keyvals...)
+}
+
+type httpSink struct {
+	url    string
+	client *http.Client
+}
+
+// NewHTTPSink returns a Sink POSTing each event as JSON to the collector at
+// url. A nil client uses http.DefaultClient.
+func NewHTTPSink(url string, client *http.Client) Sink {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return httpSink{url: url, client: client}
+}
+
+func (s httpSink) Track(ctx context.Context, e Event) error {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("POST", s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("analytics collector: %s", resp.Status)
+	}
+	return nil
+}
+
+// Tracker sends the events of the tenants who didn't opt out to a Sink.
+type Tracker struct {
+	sink   Sink
+	optOut map[string]bool
+}
+
+// NewTracker returns a Tracker sending to sink the events of every tenant
+// but those of optOut.
+func NewTracker(sink Sink, optOut ...string) *Tracker {
+	t := &Tracker{sink: sink, optOut: make(map[string]bool)}
+	for _, name := range optOut {
+		t.optOut[name] = true
+	}
+	return t
+}
+
+// ParseOptOut parses tenants separated by commas.
+func ParseOptOut(s string) []string {
+	var tenants []string
+	for _, name := range strings.Split(s, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			tenants = append(tenants, name)
+		}
+	}
+	return tenants
+}
+
+// Track sends the event name of the todo taskID, for the tenant of ctx,
+// see tenant.FromContext, unless it opted out.
+func (t *Tracker) Track(ctx context.Context, name string, taskID models.TaskID, properties map[string]interface{}) error {
+	id := tenant.FromContext(ctx)
+	if t.optOut[id] {
+		return nil
+	}
+	return t.sink.Track(ctx, Event{
+		Name:       name,
+		Tenant:     id,
+		TaskID:     taskID,
+		At:         time.Now(),
+		Properties: properties,
+	})
+}
@@ -0,0 +1,43 @@
+package analytics
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"ray.vhatt/todo-gokit/pkg/tenant"
+)
+
+func TestTracker(t *testing.T) {
+	var events []Event
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var e Event
+		if err := json.NewDecoder(r.Body).Decode(&e); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		events = append(events, e)
+	}))
+	defer srv.Close()
+
+	tracker := NewTracker(NewHTTPSink(srv.URL, nil), ParseOptOut(" acme, ,internal")...)
+	for _, name := range []string{"", "acme", "globex"} {
+		ctx := tenant.NewContext(context.Background(), name)
+		if err := tracker.Track(ctx, TaskCompleted, "000000000000000000000001", map[string]interface{}{"secondsToComplete": 60}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(events) != 2 || events[0].Tenant != "" || events[1].Tenant != "globex" {
+		t.Fatalf("want the events of the tenants who didn't opt out, have %+v", events)
+	}
+	if e := events[1]; e.Name != TaskCompleted || e.TaskID != "000000000000000000000001" || e.Properties["secondsToComplete"] != 60.0 {
+		t.Errorf("want the event sent as is, have %+v", e)
+	}
+
+	srv.Close()
+	if err := tracker.Track(context.Background(), TaskCreated, "000000000000000000000001", nil); err == nil {
+		t.Error("want an error with the collector down")
+	}
+}
@@ -0,0 +1,277 @@
+// Package client is the Go SDK of the todo service. It wraps the HTTP
+// transport behind a Client with one method per operation, and translates
+// the failures the service reports into the error values below, so that
+// callers don't deal with endpoints, request types or status codes.
+//
+//	c, err := client.New("localhost:8081", client.WithTimeout(5*time.Second))
+//	...
+//	id, err := c.Add(ctx, models.ToDoItem{Task: "water the plants"})
+//	if errors.Is(err, client.ErrInvalid) {
+//		...
+//	}
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	stdopentracing "github.com/opentracing/opentracing-go"
+	stdzipkin "github.com/openzipkin/zipkin-go"
+	"github.com/sony/gobreaker"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/ratelimit"
+
+	"ray.vhatt/todo-gokit/pkg/addservice"
+	"ray.vhatt/todo-gokit/pkg/addtransport"
+	"ray.vhatt/todo-gokit/pkg/models"
+	"ray.vhatt/todo-gokit/pkg/views"
+)
+
+// The kinds of failure, matched with errors.Is against the errors the Client
+// methods return.
+var (
+	// ErrInvalid means the service rejected the request as malformed.
+	ErrInvalid = errors.New("invalid request")
+	// ErrNotFound means the todo or view doesn't exist.
+	ErrNotFound = errors.New("not found")
+	// ErrConflict means the request conflicts with the current state.
+	ErrConflict = errors.New("conflict")
+	// ErrRateLimited means the client or the service limited the request;
+	// it is worth retrying later.
+	ErrRateLimited = errors.New("rate limited")
+	// ErrUnavailable means the service, or a circuit breaker toward it,
+	// refused the request; it is worth retrying later.
+	ErrUnavailable = errors.New("service unavailable")
+)
+
+// Error is a request the service failed. It matches the kind of failure its
+// status code reports with errors.Is, and carries the service's error code,
+// from the errcode registry, and message.
+type Error struct {
+	StatusCode int
+	Code       string
+	Message    string
+	kind       error
+}
+
+func (e *Error) Error() string { return e.Message }
+
+// Unwrap returns the kind of failure, or nil for unexpected failures.
+func (e *Error) Unwrap() error { return e.kind }
+
+// Client talks to a todo service. Its methods are safe for concurrent use.
+type Client struct {
+	svc     addservice.Service
+	timeout time.Duration
+}
+
+// Option tunes the Client built by New.
+type Option func(*options)
+
+type options struct {
+	logger       log.Logger
+	otTracer     stdopentracing.Tracer
+	zipkinTracer *stdzipkin.Tracer
+	timeout      time.Duration
+}
+
+// WithLogger makes the Client log to logger, instead of discarding its logs.
+func WithLogger(logger log.Logger) Option {
+	return func(o *options) {
+		o.logger = logger
+	}
+}
+
+// WithTracer makes the Client trace its requests with the OpenTracing tracer.
+func WithTracer(tracer stdopentracing.Tracer) Option {
+	return func(o *options) {
+		o.otTracer = tracer
+	}
+}
+
+// WithZipkinTracer makes the Client trace its requests with the native Zipkin
+// tracer.
+func WithZipkinTracer(tracer *stdzipkin.Tracer) Option {
+	return func(o *options) {
+		o.zipkinTracer = tracer
+	}
+}
+
+// WithTimeout bounds each call, on top of the deadline of its context. Zero,
+// the default, leaves calls bounded by their context only.
+func WithTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.timeout = d
+	}
+}
+
+// New returns a Client of the service at instance, a host:port or a URL.
+func New(instance string, opts ...Option) (*Client, error) {
+	o := options{logger: log.NewNopLogger(), otTracer: stdopentracing.NoopTracer{}}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	svc, err := addtransport.NewHTTPClient(instance, o.otTracer, o.zipkinTracer, o.logger)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{svc: svc, timeout: o.timeout}, nil
+}
+
+// Ping checks that the service is up.
+func (c *Client) Ping(ctx context.Context) error {
+	ctx, cancel := c.context(ctx)
+	defer cancel()
+	_, err := c.svc.Ping(ctx)
+	return translate(err)
+}
+
+// Add adds todo, and returns its ID.
+func (c *Client) Add(ctx context.Context, todo models.ToDoItem) (models.TaskID, error) {
+	ctx, cancel := c.context(ctx)
+	defer cancel()
+	id, err := c.svc.AddToDo(ctx, todo)
+	return id, translate(err)
+}
+
+// Get returns the todo with id.
+func (c *Client) Get(ctx context.Context, id models.TaskID) (models.ToDoItem, error) {
+	ctx, cancel := c.context(ctx)
+	defer cancel()
+	todo, err := c.svc.GetToDoByID(ctx, id)
+	return todo, translate(err)
+}
+
+// List returns a page of the todos opts selects. Pass the cursor of a
+// truncated page in opts to get the next one.
+func (c *Client) List(ctx context.Context, opts models.ListOptions) (models.ToDoPage, error) {
+	ctx, cancel := c.context(ctx)
+	defer cancel()
+	page, err := c.svc.GetAllToDo(ctx, opts)
+	return page, translate(err)
+}
+
+// ListAll returns all the todos opts selects, following the pages of the
+// listing.
+func (c *Client) ListAll(ctx context.Context, opts models.ListOptions) ([]models.ToDoItem, error) {
+	var todos []models.ToDoItem
+	for {
+		page, err := c.List(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		todos = append(todos, page.Todos...)
+		if !page.Truncated || page.Cursor == "" {
+			return todos, nil
+		}
+		opts.Cursor = page.Cursor
+	}
+}
+
+// Complete marks the todo done.
+func (c *Client) Complete(ctx context.Context, id models.TaskID) error {
+	ctx, cancel := c.context(ctx)
+	defer cancel()
+	_, err := c.svc.CompleteToDo(ctx, id)
+	return translate(err)
+}
+
+// Undo marks the todo open again.
+func (c *Client) Undo(ctx context.Context, id models.TaskID) error {
+	ctx, cancel := c.context(ctx)
+	defer cancel()
+	_, err := c.svc.UnDoToDo(ctx, id)
+	return translate(err)
+}
+
+// Update sets the fields of the todo that updates holds, leaving the others.
+func (c *Client) Update(ctx context.Context, id models.TaskID, updates models.ToDoUpdate) error {
+	ctx, cancel := c.context(ctx)
+	defer cancel()
+	_, err := c.svc.UpdateToDo(ctx, id, updates)
+	return translate(err)
+}
+
+// Delete deletes the todo.
+func (c *Client) Delete(ctx context.Context, id models.TaskID) error {
+	ctx, cancel := c.context(ctx)
+	defer cancel()
+	_, err := c.svc.DeleteToDo(ctx, id)
+	return translate(err)
+}
+
+// Similar returns the todos whose task resembles task.
+func (c *Client) Similar(ctx context.Context, task string) ([]models.ToDoItem, error) {
+	ctx, cancel := c.context(ctx)
+	defer cancel()
+	todos, err := c.svc.SimilarToDo(ctx, task)
+	return todos, translate(err)
+}
+
+// View returns the todos of the named view, built-in or saved.
+func (c *Client) View(ctx context.Context, name string) ([]models.ToDoItem, error) {
+	ctx, cancel := c.context(ctx)
+	defer cancel()
+	todos, err := c.svc.View(ctx, name)
+	return todos, translate(err)
+}
+
+// SaveView saves v, replacing the view of the same name.
+func (c *Client) SaveView(ctx context.Context, v views.View) error {
+	ctx, cancel := c.context(ctx)
+	defer cancel()
+	return translate(c.svc.SaveView(ctx, v))
+}
+
+// ListViews returns the saved views.
+func (c *Client) ListViews(ctx context.Context) ([]views.View, error) {
+	ctx, cancel := c.context(ctx)
+	defer cancel()
+	vs, err := c.svc.ListViews(ctx)
+	return vs, translate(err)
+}
+
+// DeleteView deletes the saved view.
+func (c *Client) DeleteView(ctx context.Context, name string) error {
+	ctx, cancel := c.context(ctx)
+	defer cancel()
+	return translate(c.svc.DeleteView(ctx, name))
+}
+
+func (c *Client) context(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, c.timeout)
+}
+
+// translate turns the errors of the transport into the errors of the
+// package.
+func translate(err error) error {
+	var statusErr *addtransport.StatusError
+	switch {
+	case err == nil:
+		return nil
+	case errors.As(err, &statusErr):
+		return &Error{StatusCode: statusErr.StatusCode, Code: statusErr.Code, Message: statusErr.Message, kind: kinds[statusErr.StatusCode]}
+	case errors.Is(err, ratelimit.ErrLimited):
+		return fmt.Errorf("%w: %v", ErrRateLimited, err)
+	case errors.Is(err, gobreaker.ErrOpenState), errors.Is(err, gobreaker.ErrTooManyRequests):
+		return fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	return err
+}
+
+// kinds maps the status codes of the service to the kinds of failure.
+var kinds = map[int]error{
+	http.StatusBadRequest:          ErrInvalid,
+	http.StatusUnprocessableEntity: ErrInvalid,
+	http.StatusNotFound:            ErrNotFound,
+	http.StatusConflict:            ErrConflict,
+	http.StatusTooManyRequests:     ErrRateLimited,
+	http.StatusServiceUnavailable:  ErrUnavailable,
+}
@@ -0,0 +1,73 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"ray.vhatt/todo-gokit/pkg/models"
+)
+
+func TestClient(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/getAllToDo":
+			if r.URL.Query().Get("cursor") == "next" {
+				w.Write([]byte(`{"data":{"todos":[{"task":"b"}]},"meta":{"pagination":{"truncated":false}}}`))
+				return
+			}
+			w.Write([]byte(`{"data":{"todos":[{"task":"a"}]},"meta":{"pagination":{"cursor":"next","truncated":true}}}`))
+		case "/completeToDo":
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":{"code":"invalid_task_id","message":"invalid task id"},"meta":{}}`))
+		case "/getToDoByID":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":{"code":"todo_not_found","message":"todo not found"},"meta":{}}`))
+		case "/views/gone":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":{"code":"view_not_found","message":"view not found"},"meta":{}}`))
+		default:
+			w.WriteHeader(http.StatusTeapot)
+		}
+	}))
+	defer srv.Close()
+
+	c, err := New(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	todos, err := c.ListAll(ctx, models.ListOptions{})
+	if err != nil || len(todos) != 2 || todos[0].Task != "a" || todos[1].Task != "b" {
+		t.Errorf("want todos a and b, have %v, %v", todos, err)
+	}
+
+	for _, test := range []struct {
+		call    func() error
+		kind    error
+		status  int
+		code    string
+		message string
+	}{
+		{func() error { return c.Complete(ctx, "x") }, ErrInvalid, http.StatusBadRequest, "invalid_task_id", "invalid task id"},
+		{func() error { _, err := c.Get(ctx, models.NewTaskID()); return err }, ErrNotFound, http.StatusNotFound, "todo_not_found", "todo not found"},
+		{func() error { _, err := c.View(ctx, "gone"); return err }, ErrNotFound, http.StatusNotFound, "view_not_found", "view not found"},
+		{func() error { return c.Ping(ctx) }, nil, http.StatusTeapot, "", "418 I'm a teapot"},
+	} {
+		err := test.call()
+		var e *Error
+		if !errors.As(err, &e) || e.StatusCode != test.status || e.Code != test.code || e.Message != test.message {
+			t.Errorf("want a %d %s error %q, have %#v", test.status, test.code, test.message, err)
+			continue
+		}
+		if test.kind != nil && !errors.Is(err, test.kind) {
+			t.Errorf("%v: want %v", err, test.kind)
+		}
+		if errors.Unwrap(err) != test.kind {
+			t.Errorf("%v: want kind %v, have %v", err, test.kind, errors.Unwrap(err))
+		}
+	}
+}
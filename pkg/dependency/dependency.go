@@ -0,0 +1,70 @@
+// Package dependency links the todos blocked by others, keeps the links
+// from going round in circles, and where the links are kept.
+package dependency
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"ray.vhatt/todo-gokit/pkg/models"
+)
+
+var (
+	// ErrCycle is returned, wrapped with the cycle, for a link that would
+	// make a todo block itself, directly or through others.
+	ErrCycle = errors.New("dependency cycle")
+
+	// ErrLinkNotFound is returned when removing a link that wasn't added,
+	// or was removed.
+	ErrLinkNotFound = errors.New("dependency not found")
+)
+
+// Link makes the todo Blocked blocked by the todo Blocker, until that one
+// is done.
+type Link struct {
+	Tenant  string        `json:"-" bson:"tenant"`
+	Blocked models.TaskID `json:"blocked" bson:"blocked"`
+	Blocker models.TaskID `json:"blocker" bson:"blocker"`
+	Created time.Time     `json:"created" bson:"created"`
+}
+
+// CheckCycle returns ErrCycle if adding l to links would make a cycle: if
+// the blocker of l is blocked by its blocked todo, or is it.
+func CheckCycle(links []Link, l Link) error {
+	blockers := make(map[models.TaskID][]models.TaskID, len(links))
+	for _, link := range links {
+		blockers[link.Blocked] = append(blockers[link.Blocked], link.Blocker)
+	}
+	// The path from the blocker of l to its blocked todo, following the
+	// blockers, is the rest of the cycle.
+	path := []models.TaskID{l.Blocked, l.Blocker}
+	seen := make(map[models.TaskID]bool)
+	var reaches func(id models.TaskID) bool
+	reaches = func(id models.TaskID) bool {
+		if id == l.Blocked {
+			return true
+		}
+		if seen[id] {
+			return false
+		}
+		seen[id] = true
+		for _, blocker := range blockers[id] {
+			path = append(path, blocker)
+			if reaches(blocker) {
+				return true
+			}
+			path = path[:len(path)-1]
+		}
+		return false
+	}
+	if !reaches(l.Blocker) {
+		return nil
+	}
+	names := make([]string, len(path))
+	for i, id := range path {
+		names[i] = id.String()
+	}
+	return fmt.Errorf("%w: %s", ErrCycle, strings.Join(names, " → "))
+}
@@ -0,0 +1,80 @@
+package dependency
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"ray.vhatt/todo-gokit/pkg/models"
+	"ray.vhatt/todo-gokit/pkg/store/mongotest"
+)
+
+func TestCheckCycle(t *testing.T) {
+	// c is blocked by b, blocked by a.
+	links := []Link{{Blocked: "b", Blocker: "a"}, {Blocked: "c", Blocker: "b"}}
+	for _, test := range []struct {
+		link Link
+		want error
+	}{
+		{Link{Blocked: "c", Blocker: "a"}, nil},
+		{Link{Blocked: "d", Blocker: "c"}, nil},
+		{Link{Blocked: "a", Blocker: "c"}, ErrCycle},
+		{Link{Blocked: "b", Blocker: "c"}, ErrCycle},
+		{Link{Blocked: "a", Blocker: "a"}, ErrCycle},
+	} {
+		if err := CheckCycle(links, test.link); !errors.Is(err, test.want) {
+			t.Errorf("%s blocked by %s: want %v, have %v", test.link.Blocked, test.link.Blocker, test.want, err)
+		}
+	}
+	if err := CheckCycle(links, Link{Blocked: "a", Blocker: "c"}); err == nil || err.Error() != "dependency cycle: a → c → b → a" {
+		t.Errorf("want the cycle in the error, have %v", err)
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, NewMemoryStore())
+}
+
+func TestMongoStore(t *testing.T) {
+	s, err := NewMongoStore(mongotest.DB(t), "dependencies")
+	if err != nil {
+		t.Fatal(err)
+	}
+	testStore(t, s)
+}
+
+// testStore runs the checks every Store passes.
+func testStore(t *testing.T, s Store) {
+	ctx := context.Background()
+	for _, l := range []Link{
+		{Tenant: "acme", Blocked: "b", Blocker: "a"},
+		{Tenant: "acme", Blocked: "b", Blocker: "a"},
+		{Tenant: "acme", Blocked: "c", Blocker: "b"},
+		{Tenant: "globex", Blocked: "b", Blocker: "a"},
+	} {
+		if err := s.Add(ctx, l); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if links, err := s.List(ctx, "acme"); err != nil || len(links) != 2 {
+		t.Errorf("want the links of the tenant, once each, have %+v, %v", links, err)
+	}
+	if links, err := s.Of(ctx, "acme", "b"); err != nil || len(links) != 2 {
+		t.Errorf("want the links of the todo both ways, have %+v, %v", links, err)
+	}
+	if err := s.Remove(ctx, "acme", "c", "b"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Remove(ctx, "acme", "c", "b"); !errors.Is(err, ErrLinkNotFound) {
+		t.Errorf("want %v, have %v", ErrLinkNotFound, err)
+	}
+	if err := s.RemoveAll(ctx, "acme", models.TaskID("a")); err != nil {
+		t.Fatal(err)
+	}
+	if links, err := s.List(ctx, "acme"); err != nil || len(links) != 0 {
+		t.Errorf("want no links left, have %+v, %v", links, err)
+	}
+	if links, err := s.List(ctx, "globex"); err != nil || len(links) != 1 {
+		t.Errorf("want the links of another tenant kept, have %+v, %v", links, err)
+	}
+}
@@ -0,0 +1,83 @@
+package dependency
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"ray.vhatt/todo-gokit/pkg/models"
+	"ray.vhatt/todo-gokit/pkg/store"
+)
+
+type mongoStore struct {
+	collection store.Collection
+}
+
+// linkDocument is a Link, identified by its tenant and todos so that it's
+// only added once.
+type linkDocument struct {
+	ID   string `bson:"_id"`
+	Link `bson:",inline"`
+}
+
+// NewMongoStore returns a Store keeping the links in the collection
+// collectionName of db, so that they survive restarts and are shared by the
+// replicas.
+func NewMongoStore(db *store.MongoDB, collectionName string) (Store, error) {
+	collection := db.Collection(collectionName)
+	_, err := collection.Indexes().CreateMany(context.TODO(), []mongo.IndexModel{
+		{Keys: bson.D{{Key: "tenant", Value: 1}, {Key: "blocked", Value: 1}}},
+		{Keys: bson.D{{Key: "tenant", Value: 1}, {Key: "blocker", Value: 1}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return mongoStore{collection: collection}, nil
+}
+
+// linkID identifies the link of the todo blocked to its blocker.
+func linkID(tenant string, blocked, blocker models.TaskID) string {
+	return tenant + "/" + blocked.String() + "/" + blocker.String()
+}
+
+func (s mongoStore) Add(ctx context.Context, l Link) error {
+	doc := linkDocument{ID: linkID(l.Tenant, l.Blocked, l.Blocker), Link: l}
+	_, err := s.collection.UpdateOne(ctx, bson.M{"_id": doc.ID}, bson.M{"$setOnInsert": doc}, options.Update().SetUpsert(true))
+	return err
+}
+
+func (s mongoStore) Remove(ctx context.Context, tenant string, blocked, blocker models.TaskID) error {
+	found, err := s.collection.Delete(ctx, linkID(tenant, blocked, blocker))
+	if err == nil && !found {
+		return ErrLinkNotFound
+	}
+	return err
+}
+
+func (s mongoStore) RemoveAll(ctx context.Context, tenant string, id models.TaskID) error {
+	_, err := s.collection.DeleteMany(ctx, bson.M{"tenant": tenant, "$or": bson.A{bson.M{"blocked": id}, bson.M{"blocker": id}}})
+	return err
+}
+
+func (s mongoStore) Of(ctx context.Context, tenant string, id models.TaskID) ([]Link, error) {
+	return s.find(ctx, bson.M{"tenant": tenant, "$or": bson.A{bson.M{"blocked": id}, bson.M{"blocker": id}}})
+}
+
+func (s mongoStore) List(ctx context.Context, tenant string) ([]Link, error) {
+	return s.find(ctx, bson.M{"tenant": tenant})
+}
+
+// find returns the links matching filter, oldest first.
+func (s mongoStore) find(ctx context.Context, filter bson.M) ([]Link, error) {
+	var docs []linkDocument
+	if err := s.collection.All(ctx, filter, "created", &docs); err != nil {
+		return nil, err
+	}
+	var links []Link
+	for _, doc := range docs {
+		links = append(links, doc.Link)
+	}
+	return links, nil
+}
@@ -0,0 +1,96 @@
+package dependency
+
+import (
+	"context"
+	"sync"
+
+	"ray.vhatt/todo-gokit/pkg/models"
+)
+
+// Store persists the links between the todos of the tenants.
+type Store interface {
+	// Add adds l, unless it was already.
+	Add(ctx context.Context, l Link) error
+	// Remove removes the link of the todo blocked to its blocker, or
+	// returns ErrLinkNotFound.
+	Remove(ctx context.Context, tenant string, blocked, blocker models.TaskID) error
+	// RemoveAll removes the links of the todo id, blocked or blocking.
+	RemoveAll(ctx context.Context, tenant string, id models.TaskID) error
+	// Of returns the links of the todo id, blocked or blocking, oldest
+	// first.
+	Of(ctx context.Context, tenant string, id models.TaskID) ([]Link, error)
+	// List returns every link of tenant.
+	List(ctx context.Context, tenant string) ([]Link, error)
+}
+
+type memoryStore struct {
+	mtx   sync.Mutex
+	links []Link
+}
+
+// NewMemoryStore returns a Store keeping the links in memory, they don't
+// survive restarts.
+func NewMemoryStore() Store {
+	return &memoryStore{}
+}
+
+func (s *memoryStore) Add(_ context.Context, l Link) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	for _, link := range s.links {
+		if link.Tenant == l.Tenant && link.Blocked == l.Blocked && link.Blocker == l.Blocker {
+			return nil
+		}
+	}
+	s.links = append(s.links, l)
+	return nil
+}
+
+func (s *memoryStore) Remove(_ context.Context, tenant string, blocked, blocker models.TaskID) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	for i, link := range s.links {
+		if link.Tenant == tenant && link.Blocked == blocked && link.Blocker == blocker {
+			s.links = append(s.links[:i], s.links[i+1:]...)
+			return nil
+		}
+	}
+	return ErrLinkNotFound
+}
+
+func (s *memoryStore) RemoveAll(_ context.Context, tenant string, id models.TaskID) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	kept := s.links[:0]
+	for _, link := range s.links {
+		if link.Tenant != tenant || (link.Blocked != id && link.Blocker != id) {
+			kept = append(kept, link)
+		}
+	}
+	s.links = kept
+	return nil
+}
+
+func (s *memoryStore) Of(_ context.Context, tenant string, id models.TaskID) ([]Link, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	var links []Link
+	for _, link := range s.links {
+		if link.Tenant == tenant && (link.Blocked == id || link.Blocker == id) {
+			links = append(links, link)
+		}
+	}
+	return links, nil
+}
+
+func (s *memoryStore) List(_ context.Context, tenant string) ([]Link, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	var links []Link
+	for _, link := range s.links {
+		if link.Tenant == tenant {
+			links = append(links, link)
+		}
+	}
+	return links, nil
+}
@@ -0,0 +1,113 @@
+// Package errcode is the registry of the codes the service reports errors
+// with. A code names a kind of failure for good: clients branch on it, not on
+// messages, which may change, nor on statuses, which several codes share.
+package errcode
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/go-kit/kit/ratelimit"
+	"github.com/sony/gobreaker"
+
+	"ray.vhatt/todo-gokit/pkg/addservice"
+	"ray.vhatt/todo-gokit/pkg/dependency"
+	"ray.vhatt/todo-gokit/pkg/fields"
+	"ray.vhatt/todo-gokit/pkg/jobs"
+	"ray.vhatt/todo-gokit/pkg/models"
+	"ray.vhatt/todo-gokit/pkg/moderation"
+	"ray.vhatt/todo-gokit/pkg/query"
+	"ray.vhatt/todo-gokit/pkg/store"
+	"ray.vhatt/todo-gokit/pkg/views"
+	"ray.vhatt/todo-gokit/pkg/webhooks"
+	"ray.vhatt/todo-gokit/pkg/workflow"
+)
+
+// StatusClientClosedRequest is the non-standard status, borrowed from nginx,
+// reported when the client cancelled the request before it completed.
+const StatusClientClosedRequest = 499
+
+// Code is an error code, and the HTTP status it's reported with.
+type Code struct {
+	Name   string `json:"code"`
+	Status int    `json:"status"`
+}
+
+// The codes of the failures that aren't errors of the service.
+var (
+	Internal             = Code{"internal", http.StatusInternalServerError}
+	MethodNotAllowed     = Code{"method_not_allowed", http.StatusMethodNotAllowed}
+	Forbidden            = Code{"forbidden", http.StatusForbidden}
+	UnsupportedMediaType = Code{"unsupported_media_type", http.StatusUnsupportedMediaType}
+	InvalidEncoding      = Code{"invalid_encoding", http.StatusBadRequest}
+	UpgradeRequired      = Code{"upgrade_required", http.StatusUpgradeRequired}
+	InvalidCommand       = Code{"invalid_command", http.StatusBadRequest}
+	RequestTooLarge      = Code{"request_too_large", http.StatusRequestEntityTooLarge}
+)
+
+// registry maps the errors of the service to their codes. Names are never
+// reused nor renamed; add new errors at the end.
+var registry = []struct {
+	err  error
+	code Code
+}{
+	{context.Canceled, Code{"cancelled", StatusClientClosedRequest}},
+	{addservice.ErrTwoZeroes, Code{"two_zeroes", http.StatusBadRequest}},
+	{addservice.ErrMaxSizeExceeded, Code{"max_size_exceeded", http.StatusBadRequest}},
+	{addservice.ErrIntOverflow, Code{"int_overflow", http.StatusBadRequest}},
+	{addservice.ErrDivideByZero, Code{"divide_by_zero", http.StatusBadRequest}},
+	{addservice.ErrNotFactorizable, Code{"not_factorizable", http.StatusBadRequest}},
+	{addservice.ErrUnknownView, Code{"unknown_view", http.StatusNotFound}},
+	{models.ErrInvalidTaskID, Code{"invalid_task_id", http.StatusBadRequest}},
+	{query.ErrInvalidQuery, Code{"invalid_query", http.StatusBadRequest}},
+	{views.ErrInvalidView, Code{"invalid_view", http.StatusBadRequest}},
+	{views.ErrViewNotFound, Code{"view_not_found", http.StatusNotFound}},
+	{jobs.ErrJobNotFound, Code{"job_not_found", http.StatusNotFound}},
+	{jobs.ErrJobFinished, Code{"job_finished", http.StatusConflict}},
+	{store.ErrInvalidCursor, Code{"invalid_cursor", http.StatusBadRequest}},
+	{store.ErrQueryTooExpensive, Code{"query_too_expensive", http.StatusUnprocessableEntity}},
+	{ratelimit.ErrLimited, Code{"rate_limited", http.StatusTooManyRequests}},
+	{gobreaker.ErrOpenState, Code{"unavailable", http.StatusServiceUnavailable}},
+	{addservice.ErrEmptyUpdate, Code{"empty_update", http.StatusBadRequest}},
+	{store.ErrToDoNotFound, Code{"todo_not_found", http.StatusNotFound}},
+	{store.ErrConflict, Code{"todo_conflict", http.StatusConflict}},
+	{addservice.ErrTaskTooLong, Code{"task_too_long", http.StatusBadRequest}},
+	{moderation.ErrRejected, Code{"content_rejected", http.StatusUnprocessableEntity}},
+	{moderation.ErrNotQueued, Code{"not_flagged", http.StatusNotFound}},
+	{models.ErrInvalidListOptions, Code{"invalid_list_options", http.StatusBadRequest}},
+	{addservice.ErrEmptySearch, Code{"empty_search", http.StatusBadRequest}},
+	{models.ErrInvalidBatchAction, Code{"invalid_batch_action", http.StatusBadRequest}},
+	{addservice.ErrBatchTooLarge, Code{"batch_too_large", http.StatusBadRequest}},
+	{models.ErrInvalidPriority, Code{"invalid_priority", http.StatusBadRequest}},
+	{models.ErrInvalidRecurrence, Code{"invalid_recurrence", http.StatusBadRequest}},
+	{webhooks.ErrInvalidSubscription, Code{"invalid_webhook", http.StatusBadRequest}},
+	{webhooks.ErrSubscriptionNotFound, Code{"webhook_not_found", http.StatusNotFound}},
+	{fields.ErrInvalidSchema, Code{"invalid_field_schema", http.StatusBadRequest}},
+	{fields.ErrInvalidCustomFields, Code{"invalid_custom_fields", http.StatusBadRequest}},
+	{workflow.ErrInvalidWorkflow, Code{"invalid_workflow", http.StatusBadRequest}},
+	{workflow.ErrUnknownState, Code{"unknown_state", http.StatusBadRequest}},
+	{workflow.ErrTransitionNotAllowed, Code{"transition_not_allowed", http.StatusConflict}},
+	{dependency.ErrCycle, Code{"dependency_cycle", http.StatusConflict}},
+	{dependency.ErrLinkNotFound, Code{"dependency_not_found", http.StatusNotFound}},
+}
+
+// Of returns the code of err: that of the first registered error err wraps,
+// or Internal.
+func Of(err error) Code {
+	for _, e := range registry {
+		if errors.Is(err, e.err) {
+			return e.code
+		}
+	}
+	return Internal
+}
+
+// All returns every code, for documentation.
+func All() []Code {
+	codes := []Code{Internal, MethodNotAllowed, Forbidden, UnsupportedMediaType, InvalidEncoding, UpgradeRequired, InvalidCommand, RequestTooLarge}
+	for _, e := range registry {
+		codes = append(codes, e.code)
+	}
+	return codes
+}
@@ -0,0 +1,36 @@
+package errcode
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"ray.vhatt/todo-gokit/pkg/query"
+	"ray.vhatt/todo-gokit/pkg/store"
+	"ray.vhatt/todo-gokit/pkg/views"
+)
+
+func TestOf(t *testing.T) {
+	for err, want := range map[error]Code{
+		views.ErrViewNotFound:                                {"view_not_found", http.StatusNotFound},
+		fmt.Errorf("%w: at offset 3", query.ErrInvalidQuery): {"invalid_query", http.StatusBadRequest},
+		fmt.Errorf("%w: E11000", store.ErrConflict):          {"todo_conflict", http.StatusConflict},
+		store.ErrInvalidID:                                   {"invalid_task_id", http.StatusBadRequest},
+		errors.New("boom"):                                   Internal,
+	} {
+		if have := Of(err); have != want {
+			t.Errorf("%v: want %v, have %v", err, want, have)
+		}
+	}
+}
+
+func TestAllUnique(t *testing.T) {
+	seen := make(map[string]bool)
+	for _, code := range All() {
+		if seen[code.Name] {
+			t.Errorf("code %s registered twice", code.Name)
+		}
+		seen[code.Name] = true
+	}
+}
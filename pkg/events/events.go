@@ -0,0 +1,123 @@
+// Package events publishes the domain events of the service, a typed event
+// per change of a todo, to a message broker through a pluggable Publisher.
+package events
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/go-kit/kit/log"
+
+	"ray.vhatt/todo-gokit/pkg/models"
+)
+
+// The types of the events.
+const (
+	ToDoCreated   = "todo.created"
+	ToDoUpdated   = "todo.updated"
+	ToDoCompleted = "todo.completed"
+	ToDoReopened  = "todo.reopened"
+	ToDoDeleted   = "todo.deleted"
+
+	ToDoTransitioned = "todo.transitioned"
+	ToDoUnblocked    = "todo.unblocked"
+)
+
+// Event is a change of a todo. Todo is the todo as created or updated, it's
+// nil for the other types. Transition is the move of a todo transitioned
+// between the states of its workflow.
+type Event struct {
+	ID         string           `json:"id"`
+	Type       string           `json:"type"`
+	TaskID     models.TaskID    `json:"taskID"`
+	Tenant     string           `json:"tenant,omitempty"`
+	At         time.Time        `json:"timestamp"`
+	Todo       *models.ToDoItem `json:"todo,omitempty"`
+	Transition *Transition      `json:"transition,omitempty"`
+}
+
+// Transition is the move of a todo from a state to another.
+type Transition struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// New returns an event of type about the todo taskID, identified at random.
+func New(eventType string, taskID models.TaskID, tenant string) Event {
+	id := make([]byte, 12)
+	if _, err := rand.Read(id); err != nil {
+		panic(err)
+	}
+	return Event{ID: hex.EncodeToString(id), Type: eventType, TaskID: taskID, Tenant: tenant, At: time.Now().UTC()}
+}
+
+// Publisher publishes the events to a broker.
+type Publisher interface {
+	Publish(ctx context.Context, e Event) error
+}
+
+// PublisherFunc is an adapter allowing a function to be used as a
+// Publisher.
+type PublisherFunc func(ctx context.Context, e Event) error
+
+// Publish calls f(ctx, e).
+func (f PublisherFunc) Publish(ctx context.Context, e Event) error { return f(ctx, e) }
+
+type logPublisher struct {
+	logger log.Logger
+}
+
+// NewLogPublisher returns a Publisher logging the events to logger, in
+// development.
+func NewLogPublisher(logger log.Logger) Publisher {
+	return logPublisher{logger: logger}
+}
+
+func (p logPublisher) Publish(_ context.Context, e Event) error {
+	return p.logger.Log("event", e.Type, "id", e.ID, "taskID", e.TaskID, "tenant", e.Tenant)
+}
+
+// Open returns the Publisher spec names: log, writing to logger,
+// nats://[user:password@]host:port[?prefix=P] for the JetStream of a NATS
+// server, or kafka+http://host:port?topic=T, kafka+https likewise, for a
+// Kafka REST proxy. The NATS connection takes the credentials file of its
+// user as creds=FILE, and goes over TLS with tls=true, or given the CAs to
+// verify the server with as ca=FILE, or a client certificate as cert=FILE
+// and key=FILE.
+func Open(spec string, logger log.Logger) (Publisher, error) {
+	if spec == "log" {
+		return NewLogPublisher(logger), nil
+	}
+	u, err := url.Parse(spec)
+	if err != nil {
+		return nil, err
+	}
+	switch u.Scheme {
+	case "nats":
+		q := u.Query()
+		tls, _ := strconv.ParseBool(q.Get("tls"))
+		opts := NATSOptions{
+			Prefix:      q.Get("prefix"),
+			Credentials: q.Get("creds"),
+			TLS:         tls,
+			CACert:      q.Get("ca"),
+			Cert:        q.Get("cert"),
+			Key:         q.Get("key"),
+		}
+		server := url.URL{Scheme: u.Scheme, User: u.User, Host: u.Host}
+		return NewNATSPublisher(server.String(), opts, logger)
+	case "kafka+http", "kafka+https":
+		topic := u.Query().Get("topic")
+		if topic == "" {
+			return nil, fmt.Errorf("no topic for the Kafka REST proxy %s", u.Host)
+		}
+		proxy := url.URL{Scheme: u.Scheme[len("kafka+"):], Host: u.Host, Path: u.Path}
+		return NewKafkaRESTPublisher(proxy.String(), topic, nil), nil
+	}
+	return nil, fmt.Errorf("unknown event publisher %q", spec)
+}
@@ -0,0 +1,74 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+type kafkaRESTPublisher struct {
+	url    string
+	client *http.Client
+}
+
+// NewKafkaRESTPublisher returns a Publisher producing the events to topic,
+// through the Kafka REST proxy at proxy. Events are keyed by their task
+// ID, so that the events of a todo land on the same partition, in order.
+// A nil client times requests out after 5 seconds.
+func NewKafkaRESTPublisher(proxy, topic string, client *http.Client) Publisher {
+	if client == nil {
+		client = &http.Client{Timeout: 5 * time.Second}
+	}
+	return kafkaRESTPublisher{url: strings.TrimSuffix(proxy, "/") + "/topics/" + url.PathEscape(topic), client: client}
+}
+
+// kafkaRecords is the body of a produce request of the v2 REST API.
+type kafkaRecords struct {
+	Records []kafkaRecord `json:"records"`
+}
+
+type kafkaRecord struct {
+	Key   string `json:"key"`
+	Value Event  `json:"value"`
+}
+
+func (p kafkaRESTPublisher) Publish(ctx context.Context, e Event) error {
+	body, err := json.Marshal(kafkaRecords{Records: []kafkaRecord{{Key: e.TaskID.String(), Value: e}}})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("POST", p.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/vnd.kafka.json.v2+json")
+	req.Header.Set("Accept", "application/vnd.kafka.v2+json")
+	resp, err := p.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("kafka rest proxy: %s", resp.Status)
+	}
+	// The proxy answers 200 with the offset, or the error, of each record.
+	var produced struct {
+		Offsets []struct {
+			Error string `json:"error"`
+		} `json:"offsets"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&produced); err != nil {
+		return fmt.Errorf("kafka rest proxy: %w", err)
+	}
+	for _, o := range produced.Offsets {
+		if o.Error != "" {
+			return fmt.Errorf("kafka rest proxy: %s", o.Error)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/nats-io/nats.go"
+)
+
+// natsTimeout bounds connecting to the NATS server, and waiting for the ack
+// of each event published without a deadline.
+const natsTimeout = 5 * time.Second
+
+// NATSOptions configure a NATS publisher. The zero value publishes without a
+// prefix, TLS or credentials other than those of the URL.
+type NATSOptions struct {
+	// Prefix is prepended to the subjects with a dot, unless it's empty.
+	Prefix string
+	// Credentials is the file of the user JWT and NKey seed to connect
+	// with, as made by nsc, empty for none.
+	Credentials string
+	// TLS connects over TLS, verifying the server with the system roots
+	// unless CACert is set.
+	TLS bool
+	// CACert is the PEM file of the CAs to verify the server with. It
+	// implies TLS.
+	CACert string
+	// Cert and Key are the PEM files of the client certificate, for the
+	// servers verifying their clients. They imply TLS.
+	Cert, Key string
+}
+
+type natsPublisher struct {
+	url     string
+	prefix  string
+	options []nats.Option
+
+	mtx sync.Mutex
+	nc  *nats.Conn
+}
+
+// NewNATSPublisher returns a Publisher publishing each event to the NATS
+// server at url, nats://[user:password@]host:port, on the subject of its
+// type, like todo.created, prefixed as opts says. The subjects must be
+// captured by a JetStream stream: an event is published once the stream
+// acknowledges storing it, and fails otherwise, so that the Relay keeps it
+// in the outbox. The connection is made on the first event, and reconnects
+// on its own afterwards. The errors the server reports asynchronously are
+// logged to logger.
+func NewNATSPublisher(url string, opts NATSOptions, logger log.Logger) (Publisher, error) {
+	if (opts.Cert == "") != (opts.Key == "") {
+		return nil, errors.New("nats: a client certificate needs both its certificate and key files")
+	}
+	options := []nats.Option{
+		nats.Name("addsvc"),
+		nats.Timeout(natsTimeout),
+		nats.MaxReconnects(-1),
+		nats.ErrorHandler(func(_ *nats.Conn, _ *nats.Subscription, err error) {
+			logger.Log("nats", url, "err", err)
+		}),
+	}
+	if opts.Credentials != "" {
+		options = append(options, nats.UserCredentials(opts.Credentials))
+	}
+	if opts.TLS {
+		options = append(options, nats.Secure())
+	}
+	if opts.CACert != "" {
+		options = append(options, nats.RootCAs(opts.CACert))
+	}
+	if opts.Cert != "" {
+		options = append(options, nats.ClientCert(opts.Cert, opts.Key))
+	}
+	return &natsPublisher{url: url, prefix: opts.Prefix, options: options}, nil
+}
+
+// natsAck is the answer of JetStream to a message published to a stream.
+type natsAck struct {
+	Stream string `json:"stream"`
+	Seq    uint64 `json:"seq"`
+	Error  *struct {
+		Code        int    `json:"code"`
+		Description string `json:"description"`
+	} `json:"error"`
+}
+
+func (p *natsPublisher) Publish(ctx context.Context, e Event) error {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	subject := e.Type
+	if p.prefix != "" {
+		subject = p.prefix + "." + subject
+	}
+	nc, err := p.conn()
+	if err != nil {
+		return fmt.Errorf("nats: %w", err)
+	}
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, natsTimeout)
+		defer cancel()
+	}
+	// Publishing as a request has JetStream answer with its ack.
+	msg, err := nc.RequestWithContext(ctx, subject, body)
+	if err != nil {
+		return fmt.Errorf("nats: no ack for %s: %w", subject, err)
+	}
+	var ack natsAck
+	if err := json.Unmarshal(msg.Data, &ack); err != nil {
+		return fmt.Errorf("nats: invalid ack for %s: %w", subject, err)
+	}
+	switch {
+	case ack.Error != nil:
+		return fmt.Errorf("nats: %s not stored: %s", subject, ack.Error.Description)
+	case ack.Stream == "":
+		return fmt.Errorf("nats: invalid ack for %s: %s", subject, msg.Data)
+	}
+	return nil
+}
+
+// conn returns the connection to the server, made on the first call, and
+// again once closed.
+func (p *natsPublisher) conn() (*nats.Conn, error) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	if p.nc != nil && !p.nc.IsClosed() {
+		return p.nc, nil
+	}
+	nc, err := nats.Connect(p.url, p.options...)
+	if err != nil {
+		return nil, err
+	}
+	p.nc = nc
+	return nc, nil
+}
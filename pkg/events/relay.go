@@ -0,0 +1,78 @@
+package events
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kit/kit/log"
+)
+
+// Outbox holds the events written along with the changes of the todos,
+// until they're published.
+type Outbox interface {
+	// Claim returns up to limit events awaiting publishing at now, oldest
+	// first, and hides them from the other claims for a while: the events
+	// not acknowledged by then are claimed again.
+	Claim(ctx context.Context, now time.Time, limit int) ([]Event, error)
+	// Ack removes the event id, published, from the outbox.
+	Ack(ctx context.Context, id string) error
+}
+
+// Relay publishes the events of an Outbox. An event is acknowledged once
+// published, and published again if that fails, so events are delivered at
+// least once; consumers tell the duplicates apart by their ID.
+type Relay struct {
+	outbox    Outbox
+	publisher Publisher
+	interval  time.Duration
+	batch     int
+	logger    log.Logger
+}
+
+// DefaultBatch is the number of events a Relay claims at once.
+const DefaultBatch = 100
+
+// NewRelay returns a Relay polling outbox every interval.
+func NewRelay(outbox Outbox, publisher Publisher, interval time.Duration, logger log.Logger) *Relay {
+	return &Relay{outbox: outbox, publisher: publisher, interval: interval, batch: DefaultBatch, logger: logger}
+}
+
+// Run publishes the events of the outbox until ctx is done.
+func (r *Relay) Run(ctx context.Context) error {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		r.Poll(ctx)
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Poll publishes the events awaiting in the outbox, and returns how many
+// were published. Errors are logged.
+func (r *Relay) Poll(ctx context.Context) int {
+	published := 0
+	for ctx.Err() == nil {
+		claimed, err := r.outbox.Claim(ctx, time.Now(), r.batch)
+		if err != nil {
+			r.logger.Log("during", "Claim", "err", err)
+		}
+		for _, e := range claimed {
+			if err := r.publisher.Publish(ctx, e); err != nil {
+				r.logger.Log("event", e.ID, "type", e.Type, "err", err)
+				continue
+			}
+			if err := r.outbox.Ack(ctx, e.ID); err != nil {
+				r.logger.Log("event", e.ID, "during", "Ack", "err", err)
+			}
+			published++
+		}
+		if err != nil || len(claimed) < r.batch {
+			break
+		}
+	}
+	return published
+}
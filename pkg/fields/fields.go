@@ -0,0 +1,215 @@
+// Package fields defines the custom fields tenants add to their todos, and
+// where their schemas are kept.
+package fields
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"ray.vhatt/todo-gokit/pkg/models"
+)
+
+var (
+	// ErrInvalidSchema is returned, wrapped with the reason, when saving a
+	// schema whose fields can't be checked.
+	ErrInvalidSchema = errors.New("invalid custom field schema")
+
+	// ErrInvalidCustomFields is returned, wrapped with the reason, for the
+	// custom fields of a todo that don't pass the schema of its tenant.
+	ErrInvalidCustomFields = errors.New("invalid custom fields")
+)
+
+// The types of the custom fields.
+const (
+	// String fields hold text, matching Pattern if set.
+	String = "string"
+	// Number fields hold a number between Min and Max, if set.
+	Number = "number"
+	// Bool fields hold true or false.
+	Bool = "bool"
+	// Date fields hold an RFC 3339 time, kept in UTC.
+	Date = "date"
+	// Enum fields hold one of Values.
+	Enum = "enum"
+)
+
+// MaxFields is the largest number of custom fields a schema defines.
+const MaxFields = 50
+
+// Field defines a custom field.
+type Field struct {
+	Name string `json:"name" bson:"name"`
+	Type string `json:"type" bson:"type"`
+	// Required fields must be set on every todo added, and can't be
+	// removed.
+	Required bool `json:"required,omitempty" bson:"required,omitempty"`
+	// Values are those an Enum field accepts.
+	Values []string `json:"values,omitempty" bson:"values,omitempty"`
+	// Pattern is a regular expression a String field must match.
+	Pattern string `json:"pattern,omitempty" bson:"pattern,omitempty"`
+	// Min and Max bound a Number field, inclusive.
+	Min *float64 `json:"min,omitempty" bson:"min,omitempty"`
+	Max *float64 `json:"max,omitempty" bson:"max,omitempty"`
+}
+
+// Schema is the custom fields of the todos of a tenant. Changing it doesn't
+// check the todos again: they keep the fields they have until updated.
+type Schema struct {
+	Tenant  string    `json:"-" bson:"_id"`
+	Fields  []Field   `json:"fields" bson:"fields"`
+	Updated time.Time `json:"updated,omitempty" bson:"updated"`
+}
+
+// Validate returns ErrInvalidSchema unless s can be saved.
+func (s Schema) Validate() error {
+	if len(s.Fields) > MaxFields {
+		return fmt.Errorf("%w: %d fields, the limit is %d", ErrInvalidSchema, len(s.Fields), MaxFields)
+	}
+	seen := make(map[string]bool, len(s.Fields))
+	for _, f := range s.Fields {
+		if err := f.validate(); err != nil {
+			return err
+		}
+		if seen[f.Name] {
+			return fmt.Errorf("%w: field %q defined twice", ErrInvalidSchema, f.Name)
+		}
+		seen[f.Name] = true
+	}
+	return nil
+}
+
+func (f Field) validate() error {
+	if err := models.ValidateCustomFieldName(f.Name); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSchema, err)
+	}
+	switch f.Type {
+	case String, Number, Bool, Date, Enum:
+	default:
+		return fmt.Errorf("%w: field %q has unknown type %q", ErrInvalidSchema, f.Name, f.Type)
+	}
+	if (f.Type == Enum) != (len(f.Values) > 0) {
+		return fmt.Errorf("%w: field %q: values are given for, and only for, enums", ErrInvalidSchema, f.Name)
+	}
+	if f.Pattern != "" {
+		if f.Type != String {
+			return fmt.Errorf("%w: field %q: only strings have a pattern", ErrInvalidSchema, f.Name)
+		}
+		if _, err := regexp.Compile(f.Pattern); err != nil {
+			return fmt.Errorf("%w: field %q: %v", ErrInvalidSchema, f.Name, err)
+		}
+	}
+	if (f.Min != nil || f.Max != nil) && f.Type != Number {
+		return fmt.Errorf("%w: field %q: only numbers have bounds", ErrInvalidSchema, f.Name)
+	}
+	if f.Min != nil && f.Max != nil && *f.Min > *f.Max {
+		return fmt.Errorf("%w: field %q: min is above max", ErrInvalidSchema, f.Name)
+	}
+	return nil
+}
+
+// Check returns ErrInvalidCustomFields unless values pass the schema, else
+// values normalized: numbers as float64, dates as RFC 3339 strings in UTC.
+// Partial values are those of an update, where nil removes a field and the
+// required fields may be left out.
+func (s Schema) Check(values models.CustomFields, partial bool) (models.CustomFields, error) {
+	defined := make(map[string]Field, len(s.Fields))
+	for _, f := range s.Fields {
+		defined[f.Name] = f
+	}
+	checked := make(models.CustomFields, len(values))
+	for name, v := range values {
+		f, ok := defined[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown field %q", ErrInvalidCustomFields, name)
+		}
+		if v == nil {
+			if !partial || f.Required {
+				return nil, fmt.Errorf("%w: field %q is required", ErrInvalidCustomFields, name)
+			}
+			checked[name] = nil
+			continue
+		}
+		var err error
+		if checked[name], err = f.check(v); err != nil {
+			return nil, fmt.Errorf("%w: field %q: %v", ErrInvalidCustomFields, name, err)
+		}
+	}
+	if !partial {
+		for _, f := range s.Fields {
+			if _, ok := values[f.Name]; f.Required && !ok {
+				return nil, fmt.Errorf("%w: field %q is required", ErrInvalidCustomFields, f.Name)
+			}
+		}
+	}
+	return checked, nil
+}
+
+// check returns v normalized, or the reason it isn't a value of f.
+func (f Field) check(v interface{}) (interface{}, error) {
+	switch f.Type {
+	case String:
+		s, ok := v.(string)
+		if !ok {
+			return nil, errors.New("want a string")
+		}
+		if f.Pattern != "" && !regexp.MustCompile(f.Pattern).MatchString(s) {
+			return nil, fmt.Errorf("%q doesn't match %s", s, f.Pattern)
+		}
+		return s, nil
+	case Number:
+		n, ok := number(v)
+		if !ok {
+			return nil, errors.New("want a number")
+		}
+		if f.Min != nil && n < *f.Min {
+			return nil, fmt.Errorf("%v is below %v", n, *f.Min)
+		}
+		if f.Max != nil && n > *f.Max {
+			return nil, fmt.Errorf("%v is above %v", n, *f.Max)
+		}
+		return n, nil
+	case Bool:
+		b, ok := v.(bool)
+		if !ok {
+			return nil, errors.New("want true or false")
+		}
+		return b, nil
+	case Date:
+		s, ok := v.(string)
+		if !ok {
+			return nil, errors.New("want an RFC 3339 time")
+		}
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return nil, errors.New("want an RFC 3339 time")
+		}
+		return t.UTC().Format(time.RFC3339), nil
+	case Enum:
+		s, _ := v.(string)
+		for _, value := range f.Values {
+			if s == value {
+				return s, nil
+			}
+		}
+		return nil, fmt.Errorf("want one of %q", f.Values)
+	}
+	return nil, fmt.Errorf("unknown type %q", f.Type)
+}
+
+// number returns v as a float64, v being any of the numbers JSON and BSON
+// decode to.
+func number(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	}
+	return 0, false
+}
@@ -0,0 +1,112 @@
+package fields
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"ray.vhatt/todo-gokit/pkg/models"
+	"ray.vhatt/todo-gokit/pkg/store/mongotest"
+)
+
+func TestValidate(t *testing.T) {
+	zero, ten := 0.0, 10.0
+	valid := Schema{Fields: []Field{
+		{Name: "points", Type: Number, Min: &zero, Max: &ten},
+		{Name: "team", Type: Enum, Values: []string{"backend", "frontend"}, Required: true},
+		{Name: "ticket", Type: String, Pattern: `^[A-Z]+-[0-9]+$`},
+		{Name: "billable", Type: Bool},
+		{Name: "signed_off", Type: Date},
+	}}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("want valid, have %v", err)
+	}
+	for _, f := range []Field{
+		{Name: "", Type: String},
+		{Name: "a.b", Type: String},
+		{Name: "$where", Type: String},
+		{Name: "points", Type: "integer"},
+		{Name: "team", Type: Enum},
+		{Name: "team", Type: String, Values: []string{"backend"}},
+		{Name: "ticket", Type: String, Pattern: "("},
+		{Name: "ticket", Type: Number, Pattern: "x"},
+		{Name: "points", Type: String, Min: &zero},
+		{Name: "points", Type: Number, Min: &ten, Max: &zero},
+	} {
+		if err := (Schema{Fields: []Field{f}}).Validate(); !errors.Is(err, ErrInvalidSchema) {
+			t.Errorf("%+v: want %v, have %v", f, ErrInvalidSchema, err)
+		}
+	}
+	twice := Schema{Fields: []Field{{Name: "a", Type: Bool}, {Name: "a", Type: String}}}
+	if err := twice.Validate(); !errors.Is(err, ErrInvalidSchema) {
+		t.Errorf("want %v for a field defined twice, have %v", ErrInvalidSchema, err)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	ten := 10.0
+	schema := Schema{Fields: []Field{
+		{Name: "points", Type: Number, Max: &ten},
+		{Name: "team", Type: Enum, Values: []string{"backend", "frontend"}, Required: true},
+		{Name: "ticket", Type: String, Pattern: `^[A-Z]+-[0-9]+$`},
+		{Name: "signed_off", Type: Date},
+	}}
+
+	checked, err := schema.Check(models.CustomFields{"team": "backend", "points": int32(3), "signed_off": "2020-06-01T12:00:00+02:00"}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, have := "map[points:3 signed_off:2020-06-01T10:00:00Z team:backend]", fmt.Sprint(checked); want != have {
+		t.Errorf("want %s, have %s", want, have)
+	}
+	if _, err := schema.Check(models.CustomFields{"ticket": nil}, true); err != nil {
+		t.Errorf("want an optional field removed, have %v", err)
+	}
+	for _, test := range []struct {
+		values  models.CustomFields
+		partial bool
+	}{
+		{models.CustomFields{}, false},
+		{models.CustomFields{"team": "ops"}, false},
+		{models.CustomFields{"team": "backend", "points": 11.0}, false},
+		{models.CustomFields{"team": "backend", "points": "3"}, false},
+		{models.CustomFields{"team": "backend", "ticket": "gh-1"}, false},
+		{models.CustomFields{"team": "backend", "signed_off": "yesterday"}, false},
+		{models.CustomFields{"team": "backend", "color": "red"}, false},
+		{models.CustomFields{"team": nil}, true},
+	} {
+		if _, err := schema.Check(test.values, test.partial); !errors.Is(err, ErrInvalidCustomFields) {
+			t.Errorf("%v: want %v, have %v", test.values, ErrInvalidCustomFields, err)
+		}
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, NewMemoryStore())
+}
+
+func TestMongoStore(t *testing.T) {
+	s, err := NewMongoStore(mongotest.DB(t), "fields")
+	if err != nil {
+		t.Fatal(err)
+	}
+	testStore(t, s)
+}
+
+// testStore runs the checks every Store passes.
+func testStore(t *testing.T, s Store) {
+	ctx := context.Background()
+	if schema, err := s.Get(ctx, "acme"); err != nil || schema.Tenant != "acme" || len(schema.Fields) != 0 {
+		t.Errorf("want no fields, have %+v, %v", schema, err)
+	}
+	if err := s.Save(ctx, Schema{Tenant: "acme", Fields: []Field{{Name: "points", Type: Number}}}); err != nil {
+		t.Fatal(err)
+	}
+	if schema, err := s.Get(ctx, "globex"); err != nil || len(schema.Fields) != 0 {
+		t.Errorf("want the schemas kept per tenant, have %+v, %v", schema, err)
+	}
+	if schema, err := s.Get(ctx, "acme"); err != nil || len(schema.Fields) != 1 {
+		t.Errorf("want the schema saved, have %+v, %v", schema, err)
+	}
+}
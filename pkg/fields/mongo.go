@@ -0,0 +1,33 @@
+package fields
+
+import (
+	"context"
+
+	"ray.vhatt/todo-gokit/pkg/store"
+)
+
+type mongoStore struct {
+	collection store.Collection
+}
+
+// NewMongoStore returns a Store keeping the schemas in the collection
+// collectionName of db, one document per tenant, so that they survive
+// restarts.
+func NewMongoStore(db *store.MongoDB, collectionName string) (Store, error) {
+	return mongoStore{collection: db.Collection(collectionName)}, nil
+}
+
+func (s mongoStore) Save(ctx context.Context, schema Schema) error {
+	return s.collection.Put(ctx, schema.Tenant, schema)
+}
+
+func (s mongoStore) Get(ctx context.Context, tenant string) (Schema, error) {
+	schema := Schema{Tenant: tenant}
+	if _, err := s.collection.Get(ctx, tenant, &schema); err != nil {
+		return Schema{}, err
+	}
+	if schema.Fields == nil {
+		schema.Fields = []Field{}
+	}
+	return schema, nil
+}
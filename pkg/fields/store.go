@@ -0,0 +1,42 @@
+package fields
+
+import (
+	"context"
+	"sync"
+)
+
+// Store persists the schemas of the tenants.
+type Store interface {
+	// Save creates or replaces the schema of schema.Tenant.
+	Save(ctx context.Context, schema Schema) error
+	// Get returns the schema of tenant, without fields if none was saved.
+	Get(ctx context.Context, tenant string) (Schema, error)
+}
+
+type memoryStore struct {
+	mtx     sync.Mutex
+	schemas map[string]Schema
+}
+
+// NewMemoryStore returns a Store keeping the schemas in memory, they don't
+// survive restarts.
+func NewMemoryStore() Store {
+	return &memoryStore{schemas: make(map[string]Schema)}
+}
+
+func (s *memoryStore) Save(_ context.Context, schema Schema) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.schemas[schema.Tenant] = schema
+	return nil
+}
+
+func (s *memoryStore) Get(_ context.Context, tenant string) (Schema, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	schema, ok := s.schemas[tenant]
+	if !ok {
+		return Schema{Tenant: tenant, Fields: []Field{}}, nil
+	}
+	return schema, nil
+}
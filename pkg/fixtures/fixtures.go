@@ -0,0 +1,102 @@
+// Package fixtures generates synthetic todos and loads them into a store,
+// for demos, load tests and store benchmark comparisons.
+package fixtures
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"ray.vhatt/todo-gokit/pkg/models"
+	"ray.vhatt/todo-gokit/pkg/store"
+	"ray.vhatt/todo-gokit/pkg/workflow"
+)
+
+// Options set the volume and shape of the generated todos.
+type Options struct {
+	// Count is the number of todos to generate.
+	Count int
+	// Completed is the fraction, between 0 and 1, of todos marked done.
+	Completed float64
+	// Seed makes the generated todos reproducible, the same seed always
+	// yields the same todos.
+	Seed int64
+	// Now is when the due dates are around, from a week before to a month
+	// after. Zero is the start of the current day.
+	Now time.Time
+}
+
+var (
+	verbs   = []string{"buy", "call", "email", "fix", "review", "plan", "book", "clean", "write", "read"}
+	objects = []string{"groceries", "the dentist", "the landlord", "the bike", "the quarterly report", "the team offsite", "train tickets", "the garage", "a birthday card", "the release notes"}
+	whens   = []string{"today", "tomorrow", "this week", "before friday", "next month", "asap"}
+
+	priorities  = []models.Priority{models.PriorityLow, models.PriorityNormal, models.PriorityHigh, models.PriorityUrgent}
+	recurrences = []models.Recurrence{"daily", "weekly", "monthly", "every 2 weeks", "yearly"}
+	labels      = []string{"home", "work", "errand", "finance", "health"}
+)
+
+// Generate returns opts.Count synthetic todos, without IDs. Their state is
+// one of the default workflow, done for the completed todos. About half are
+// due, a tenth repeat, and a third carry the estimate and label custom
+// fields.
+func Generate(opts Options) []models.ToDoItem {
+	r := rand.New(rand.NewSource(opts.Seed))
+	now := opts.Now
+	if now.IsZero() {
+		now = time.Now().Truncate(24 * time.Hour)
+	}
+	var pending, done []string
+	for _, state := range workflow.Default.States {
+		if state.Done {
+			done = append(done, state.Name)
+		} else {
+			pending = append(pending, state.Name)
+		}
+	}
+
+	todos := make([]models.ToDoItem, opts.Count)
+	for i := range todos {
+		todo := models.ToDoItem{
+			Task: fmt.Sprintf("%s %s %s",
+				verbs[r.Intn(len(verbs))],
+				objects[r.Intn(len(objects))],
+				whens[r.Intn(len(whens))],
+			),
+			Status:   r.Float64() < opts.Completed,
+			Priority: priorities[r.Intn(len(priorities))],
+		}
+		if todo.Status {
+			todo.State = done[r.Intn(len(done))]
+		} else {
+			todo.State = pending[r.Intn(len(pending))]
+		}
+		if r.Float64() < 0.5 {
+			due := now.Add(time.Duration(r.Intn(38*24)-7*24) * time.Hour)
+			todo.DueDate = &due
+		}
+		if r.Float64() < 0.1 {
+			todo.Recurrence = recurrences[r.Intn(len(recurrences))]
+		}
+		if r.Float64() < 0.3 {
+			todo.CustomFields = models.CustomFields{
+				"estimate": float64(1 + r.Intn(8)),
+				"label":    labels[r.Intn(len(labels))],
+			}
+		}
+		todos[i] = todo
+	}
+	return todos
+}
+
+// Load inserts the todos generated for opts into s. It returns the number of
+// todos inserted, which is short of opts.Count only on error.
+func Load(ctx context.Context, s store.Store, opts Options) (int, error) {
+	for i, todo := range Generate(opts) {
+		if _, err := s.InsertToDo(ctx, todo); err != nil {
+			return i, fmt.Errorf("inserting todo %d: %w", i, err)
+		}
+	}
+	return opts.Count, nil
+}
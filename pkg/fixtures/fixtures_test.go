@@ -0,0 +1,62 @@
+package fixtures
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestGenerateIsReproducible(t *testing.T) {
+	opts := Options{Count: 200, Completed: 0.25, Seed: 42, Now: time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)}
+	a, b := Generate(opts), Generate(opts)
+	if !reflect.DeepEqual(a, b) {
+		t.Fatal("same seed generated different todos")
+	}
+
+	done, due, recurring, custom := 0, 0, 0, 0
+	priorities := map[string]bool{}
+	for _, todo := range a {
+		if todo.Task == "" || todo.ID != "" {
+			t.Fatalf("want a task and no ID, have %v", todo)
+		}
+		if err := todo.Priority.Validate(); err != nil || todo.Priority == "" {
+			t.Fatalf("want a priority, have %q", todo.Priority)
+		}
+		priorities[string(todo.Priority)] = true
+		if want, have := todo.Status, todo.State == "done"; want != have {
+			t.Fatalf("want the state of %v to follow its status, have %q", todo.Status, todo.State)
+		}
+		if todo.Status {
+			done++
+		}
+		if todo.DueDate != nil {
+			due++
+			if todo.DueDate.Before(opts.Now.AddDate(0, 0, -7)) || todo.DueDate.After(opts.Now.AddDate(0, 1, 0)) {
+				t.Errorf("want a due date around %s, have %s", opts.Now, todo.DueDate)
+			}
+		}
+		if todo.Recurrence != "" {
+			recurring++
+			if err := todo.Recurrence.Validate(); err != nil {
+				t.Error(err)
+			}
+		}
+		if len(todo.CustomFields) > 0 {
+			custom++
+		}
+	}
+	if done == 0 || done == len(a) {
+		t.Errorf("want about a quarter of the todos done, have %d of %d", done, len(a))
+	}
+	if len(priorities) != 4 {
+		t.Errorf("want every priority, have %v", priorities)
+	}
+	if due == 0 || recurring == 0 || custom == 0 {
+		t.Errorf("want some todos due, repeating and with custom fields, have %d, %d and %d of %d", due, recurring, custom, len(a))
+	}
+
+	opts.Seed++
+	if reflect.DeepEqual(a, Generate(opts)) {
+		t.Error("another seed generated the same todos")
+	}
+}
@@ -0,0 +1,275 @@
+// Package jobs runs long-running operations in the background, so that
+// requests can submit them, poll their progress and cancel them instead of
+// waiting. Jobs are persisted in a Store, and those interrupted by a restart
+// are run again by Resume.
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrJobNotFound is returned for a job ID the manager doesn't know, or
+	// forgot after its retention.
+	ErrJobNotFound = errors.New("job not found")
+
+	// ErrJobFinished is returned when cancelling a job that already ended.
+	ErrJobFinished = errors.New("job already finished")
+
+	// ErrUnknownKind is returned when submitting a job of a kind that wasn't
+	// registered.
+	ErrUnknownKind = errors.New("unknown job kind")
+)
+
+// State is the lifecycle stage of a job.
+type State string
+
+// The states of a job. Running jobs end up in one of the last three.
+const (
+	Running   State = "running"
+	Succeeded State = "succeeded"
+	Failed    State = "failed"
+	Cancelled State = "cancelled"
+)
+
+// Status is a snapshot of a job, as reported to clients.
+type Status struct {
+	ID    string `json:"id"`
+	Kind  string `json:"kind"`
+	State State  `json:"state"`
+	// Done and Total measure the progress of the job, in units of its
+	// choosing. Total is 0 while unknown.
+	Done   int64       `json:"done"`
+	Total  int64       `json:"total"`
+	Result interface{} `json:"result,omitempty"`
+	Error  string      `json:"error,omitempty"`
+}
+
+// Record is a job as persisted by a Store: its status, plus what's needed to
+// run it again.
+type Record struct {
+	Status
+	Params  json.RawMessage
+	Created time.Time
+	// Ended is zero while the job is running.
+	Ended time.Time
+}
+
+// Func is the work of a kind of job, given the parameters it was submitted
+// with. It should report its progress and return promptly once ctx is done.
+// It may be run again from the start after a restart.
+type Func func(ctx context.Context, params json.RawMessage, progress func(done, total int64)) (interface{}, error)
+
+// saveEvery throttles the progress saved to the store while a job runs.
+const saveEvery = time.Second
+
+// Manager runs jobs and keeps their records in a Store. Finished jobs are
+// forgotten after the retention given to NewManager.
+type Manager struct {
+	store     Store
+	retention time.Duration
+
+	mtx     sync.Mutex
+	kinds   map[string]Func
+	running map[string]*running
+}
+
+// running is the live state of a job run by this manager.
+type running struct {
+	record Record
+	cancel context.CancelFunc
+	saved  time.Time
+}
+
+// NewManager returns a Manager persisting jobs to store, and keeping finished
+// jobs for retention.
+func NewManager(store Store, retention time.Duration) *Manager {
+	return &Manager{
+		store:     store,
+		retention: retention,
+		kinds:     make(map[string]Func),
+		running:   make(map[string]*running),
+	}
+}
+
+// Register makes jobs of kind run fn. It must be called before jobs of kind
+// are submitted or resumed.
+func (m *Manager) Register(kind string, fn Func) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.kinds[kind] = fn
+}
+
+// Submit starts a job of kind with params, encoded as JSON, in the
+// background. The job outlives the caller's request, it only ends with its
+// function or Cancel.
+func (m *Manager) Submit(ctx context.Context, kind string, params interface{}) (Status, error) {
+	raw, err := json.Marshal(params)
+	if err != nil {
+		return Status{}, err
+	}
+	id, err := newID()
+	if err != nil {
+		return Status{}, err
+	}
+	if err := m.store.DeleteEndedBefore(ctx, time.Now().Add(-m.retention)); err != nil {
+		return Status{}, err
+	}
+
+	record := Record{
+		Status:  Status{ID: id, Kind: kind, State: Running},
+		Params:  raw,
+		Created: time.Now(),
+	}
+	if err := m.start(ctx, record); err != nil {
+		return Status{}, err
+	}
+	return record.Status, nil
+}
+
+// Resume runs again the jobs left running in the store, e.g. by a crash or
+// a restart. Jobs of a kind not registered fail.
+func (m *Manager) Resume(ctx context.Context) error {
+	records, err := m.store.Unfinished(ctx)
+	if err != nil {
+		return err
+	}
+	for _, record := range records {
+		record.Done, record.Total = 0, 0
+		if err := m.start(ctx, record); err == ErrUnknownKind {
+			record.State = Failed
+			record.Error = fmt.Sprintf("%v %q, can't resume", err, record.Kind)
+			record.Ended = time.Now()
+			err = m.store.Save(ctx, record)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// start saves record and runs it in the background.
+func (m *Manager) start(ctx context.Context, record Record) error {
+	m.mtx.Lock()
+	fn, ok := m.kinds[record.Kind]
+	m.mtx.Unlock()
+	if !ok {
+		return ErrUnknownKind
+	}
+	if err := m.store.Save(ctx, record); err != nil {
+		return err
+	}
+
+	jobCtx, cancel := context.WithCancel(context.Background())
+	r := &running{record: record, cancel: cancel, saved: time.Now()}
+	m.mtx.Lock()
+	m.running[record.ID] = r
+	m.mtx.Unlock()
+
+	go func() {
+		defer cancel()
+		result, err := fn(jobCtx, record.Params, func(done, total int64) {
+			m.mtx.Lock()
+			r.record.Done, r.record.Total = done, total
+			save := time.Since(r.saved) >= saveEvery
+			if save {
+				r.saved = time.Now()
+			}
+			snapshot := r.record
+			m.mtx.Unlock()
+			if save {
+				m.store.Save(context.Background(), snapshot)
+			}
+		})
+
+		m.mtx.Lock()
+		r.record.Ended = time.Now()
+		switch {
+		case err != nil && jobCtx.Err() == context.Canceled:
+			r.record.State = Cancelled
+		case err != nil:
+			r.record.State = Failed
+			r.record.Error = err.Error()
+		default:
+			r.record.State = Succeeded
+			r.record.Result = result
+		}
+		final := r.record
+		m.mtx.Unlock()
+
+		// The record is saved before the job stops being served from memory,
+		// so that Status never goes back in time.
+		m.store.Save(context.Background(), final)
+		m.mtx.Lock()
+		delete(m.running, final.ID)
+		m.mtx.Unlock()
+	}()
+	return nil
+}
+
+// Status returns a snapshot of the job id.
+func (m *Manager) Status(ctx context.Context, id string) (Status, error) {
+	m.mtx.Lock()
+	r, ok := m.running[id]
+	var status Status
+	if ok {
+		status = r.record.Status
+	}
+	m.mtx.Unlock()
+	if ok {
+		return status, nil
+	}
+
+	record, err := m.store.Get(ctx, id)
+	if err != nil {
+		return Status{}, err
+	}
+	if !record.Ended.IsZero() && time.Since(record.Ended) > m.retention {
+		return Status{}, ErrJobNotFound
+	}
+	return record.Status, nil
+}
+
+// Cancel asks the job id to stop. Its state turns to Cancelled once its
+// function returned.
+func (m *Manager) Cancel(ctx context.Context, id string) error {
+	m.mtx.Lock()
+	r, ok := m.running[id]
+	finished := ok && r.record.State != Running
+	m.mtx.Unlock()
+	if finished {
+		return ErrJobFinished
+	}
+	if ok {
+		r.cancel()
+		return nil
+	}
+
+	record, err := m.store.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if record.State != Running {
+		return ErrJobFinished
+	}
+	// The job was left running by another process and not resumed here.
+	record.State = Cancelled
+	record.Ended = time.Now()
+	return m.store.Save(ctx, record)
+}
+
+func newID() (string, error) {
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b[:]), nil
+}
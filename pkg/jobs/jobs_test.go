@@ -0,0 +1,133 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"ray.vhatt/todo-gokit/pkg/store/mongotest"
+)
+
+// wait polls the job id until it's no longer running.
+func wait(t *testing.T, m *Manager, id string) Status {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		status, err := m.Status(context.Background(), id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if status.State != Running {
+			return status
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("job %s still running after a second", id)
+	return Status{}
+}
+
+func newTestManager(store Store, retention time.Duration) *Manager {
+	m := NewManager(store, retention)
+	m.Register("answer", func(_ context.Context, _ json.RawMessage, progress func(done, total int64)) (interface{}, error) {
+		progress(1, 1)
+		return 42, nil
+	})
+	m.Register("fail", func(context.Context, json.RawMessage, func(done, total int64)) (interface{}, error) {
+		return nil, errors.New("boom")
+	})
+	m.Register("block", func(ctx context.Context, _ json.RawMessage, _ func(done, total int64)) (interface{}, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	return m
+}
+
+func TestJobLifecycle(t *testing.T) {
+	ctx := context.Background()
+	m := newTestManager(NewMemoryStore(), time.Minute)
+
+	job, _ := m.Submit(ctx, "answer", nil)
+	if status := wait(t, m, job.ID); status.State != Succeeded || status.Result != 42 || status.Done != 1 {
+		t.Errorf("want success with 42, have %+v", status)
+	}
+	if err := m.Cancel(ctx, job.ID); err != ErrJobFinished {
+		t.Errorf("want %v, have %v", ErrJobFinished, err)
+	}
+
+	job, _ = m.Submit(ctx, "fail", nil)
+	if status := wait(t, m, job.ID); status.State != Failed || status.Error != "boom" {
+		t.Errorf("want failure with boom, have %+v", status)
+	}
+
+	job, _ = m.Submit(ctx, "block", nil)
+	if err := m.Cancel(ctx, job.ID); err != nil {
+		t.Fatal(err)
+	}
+	if status := wait(t, m, job.ID); status.State != Cancelled {
+		t.Errorf("want cancelled, have %+v", status)
+	}
+
+	if _, err := m.Submit(ctx, "nope", nil); err != ErrUnknownKind {
+		t.Errorf("want %v, have %v", ErrUnknownKind, err)
+	}
+	if _, err := m.Status(ctx, "nope"); err != ErrJobNotFound {
+		t.Errorf("want %v, have %v", ErrJobNotFound, err)
+	}
+}
+
+func TestJobRetention(t *testing.T) {
+	ctx := context.Background()
+	m := newTestManager(NewMemoryStore(), 0)
+	job, _ := m.Submit(ctx, "answer", nil)
+	for {
+		m.mtx.Lock()
+		_, running := m.running[job.ID]
+		m.mtx.Unlock()
+		if !running {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(time.Millisecond)
+	if _, err := m.Status(ctx, job.ID); err != ErrJobNotFound {
+		t.Errorf("want finished job forgotten, have %v", err)
+	}
+}
+
+func TestJobResume(t *testing.T) {
+	testResume(t, NewMemoryStore())
+}
+
+func TestMongoStore(t *testing.T) {
+	s, err := NewMongoStore(mongotest.DB(t), "jobs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	testResume(t, s)
+}
+
+// testResume checks that the jobs left running in store are resumed, which
+// every Store allows.
+func testResume(t *testing.T, store Store) {
+	ctx := context.Background()
+	for _, r := range []Record{
+		{Status: Status{ID: "interrupted", Kind: "answer", State: Running, Done: 1, Total: 2}},
+		{Status: Status{ID: "obsolete", Kind: "nope", State: Running}},
+	} {
+		store.Save(ctx, r)
+	}
+
+	// A new manager picks up the jobs left running by the previous one.
+	m := newTestManager(store, time.Minute)
+	if err := m.Resume(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if status := wait(t, m, "interrupted"); status.State != Succeeded || status.Result != 42 {
+		t.Errorf("want resumed job to succeed with 42, have %+v", status)
+	}
+	if status := wait(t, m, "obsolete"); status.State != Failed {
+		t.Errorf("want job of unknown kind failed, have %+v", status)
+	}
+}
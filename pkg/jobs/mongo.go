@@ -0,0 +1,100 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"ray.vhatt/todo-gokit/pkg/store"
+)
+
+type mongoStore struct {
+	collection store.Collection
+}
+
+// NewMongoStore returns a Store keeping the jobs in the collection
+// collectionName of db, so that they survive restarts.
+func NewMongoStore(db *store.MongoDB, collectionName string) (Store, error) {
+	return mongoStore{collection: db.Collection(collectionName)}, nil
+}
+
+// recordDocument is the shape of a Record in the collection.
+type recordDocument struct {
+	ID      string      `bson:"_id"`
+	Kind    string      `bson:"kind"`
+	State   State       `bson:"state"`
+	Done    int64       `bson:"done"`
+	Total   int64       `bson:"total"`
+	Result  interface{} `bson:"result,omitempty"`
+	Error   string      `bson:"error,omitempty"`
+	Params  string      `bson:"params"`
+	Created time.Time   `bson:"created"`
+	Ended   time.Time   `bson:"ended,omitempty"`
+}
+
+func toDocument(r Record) recordDocument {
+	return recordDocument{
+		ID:      r.ID,
+		Kind:    r.Kind,
+		State:   r.State,
+		Done:    r.Done,
+		Total:   r.Total,
+		Result:  r.Result,
+		Error:   r.Error,
+		Params:  string(r.Params),
+		Created: r.Created,
+		Ended:   r.Ended,
+	}
+}
+
+func (d recordDocument) toRecord() Record {
+	return Record{
+		Status: Status{
+			ID:     d.ID,
+			Kind:   d.Kind,
+			State:  d.State,
+			Done:   d.Done,
+			Total:  d.Total,
+			Result: d.Result,
+			Error:  d.Error,
+		},
+		Params:  json.RawMessage(d.Params),
+		Created: d.Created,
+		Ended:   d.Ended,
+	}
+}
+
+func (s mongoStore) Save(ctx context.Context, record Record) error {
+	return s.collection.Put(ctx, record.ID, toDocument(record))
+}
+
+func (s mongoStore) Get(ctx context.Context, id string) (Record, error) {
+	var doc recordDocument
+	found, err := s.collection.Get(ctx, id, &doc)
+	if err != nil {
+		return Record{}, err
+	}
+	if !found {
+		return Record{}, ErrJobNotFound
+	}
+	return doc.toRecord(), nil
+}
+
+func (s mongoStore) Unfinished(ctx context.Context) ([]Record, error) {
+	var docs []recordDocument
+	if err := s.collection.All(ctx, bson.M{"state": Running}, "created", &docs); err != nil {
+		return nil, err
+	}
+	var records []Record
+	for _, doc := range docs {
+		records = append(records, doc.toRecord())
+	}
+	return records, nil
+}
+
+func (s mongoStore) DeleteEndedBefore(ctx context.Context, t time.Time) error {
+	_, err := s.collection.DeleteMany(ctx, bson.M{"state": bson.M{"$ne": Running}, "ended": bson.M{"$lt": t}})
+	return err
+}
@@ -0,0 +1,70 @@
+package jobs
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Store persists job records.
+type Store interface {
+	// Save creates or replaces the record of a job.
+	Save(ctx context.Context, record Record) error
+	// Get returns the record of the job id, or ErrJobNotFound.
+	Get(ctx context.Context, id string) (Record, error)
+	// Unfinished returns the records of the jobs still running.
+	Unfinished(ctx context.Context) ([]Record, error)
+	// DeleteEndedBefore forgets the jobs that ended before t.
+	DeleteEndedBefore(ctx context.Context, t time.Time) error
+}
+
+type memoryStore struct {
+	mtx     sync.Mutex
+	records map[string]Record
+}
+
+// NewMemoryStore returns a Store keeping the jobs in memory, they don't
+// survive restarts.
+func NewMemoryStore() Store {
+	return &memoryStore{records: make(map[string]Record)}
+}
+
+func (s *memoryStore) Save(_ context.Context, record Record) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.records[record.ID] = record
+	return nil
+}
+
+func (s *memoryStore) Get(_ context.Context, id string) (Record, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	record, ok := s.records[id]
+	if !ok {
+		return Record{}, ErrJobNotFound
+	}
+	return record, nil
+}
+
+func (s *memoryStore) Unfinished(context.Context) ([]Record, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	var records []Record
+	for _, record := range s.records {
+		if record.Ended.IsZero() {
+			records = append(records, record)
+		}
+	}
+	return records, nil
+}
+
+func (s *memoryStore) DeleteEndedBefore(_ context.Context, t time.Time) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	for id, record := range s.records {
+		if !record.Ended.IsZero() && record.Ended.Before(t) {
+			delete(s.records, id)
+		}
+	}
+	return nil
+}
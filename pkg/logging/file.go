@@ -0,0 +1,20 @@
+// Package logging provides the sinks the service writes its logs to.
+package logging
+
+import (
+	"io"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// RotatingFile returns a writer appending to the file at path. Once the file
+// grows past maxSizeMB megabytes, it's renamed with a timestamp and
+// compressed, keeping at most maxBackups of those, or all of them for 0.
+func RotatingFile(path string, maxSizeMB, maxBackups int) io.WriteCloser {
+	return &lumberjack.Logger{
+		Filename:   path,
+		MaxSize:    maxSizeMB,
+		MaxBackups: maxBackups,
+		Compress:   true,
+	}
+}
@@ -0,0 +1,103 @@
+package logging
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// The rotation of the files Open opens.
+const (
+	DefaultMaxSizeMB  = 100
+	DefaultMaxBackups = 10
+)
+
+// Open returns the sink spec describes:
+//
+//	stderr, stdout       the standard streams
+//	file:PATH            a file rotated like RotatingFile, with the defaults
+//	syslog[:TAG]         the local syslog daemon
+//	syslog://HOST:PORT   a remote syslog daemon, over UDP
+//	tcp://HOST:PORT      a log collector, one connection redialled as needed
+//	udp://HOST:PORT      a log collector, one datagram per write
+//
+// Closing the standard streams is a no-op.
+func Open(spec string) (io.WriteCloser, error) {
+	switch {
+	case spec == "stderr":
+		return nopCloser{os.Stderr}, nil
+	case spec == "stdout":
+		return nopCloser{os.Stdout}, nil
+	case strings.HasPrefix(spec, "file:"):
+		path := strings.TrimPrefix(spec, "file:")
+		if path == "" {
+			return nil, fmt.Errorf("log sink %q: missing path", spec)
+		}
+		return RotatingFile(path, DefaultMaxSizeMB, DefaultMaxBackups), nil
+	case spec == "syslog" || strings.HasPrefix(spec, "syslog:"):
+		if addr := strings.TrimPrefix(spec, "syslog://"); addr != spec {
+			return openSyslog("udp", addr, "")
+		}
+		return openSyslog("", "", strings.TrimPrefix(strings.TrimPrefix(spec, "syslog"), ":"))
+	case strings.HasPrefix(spec, "tcp://"), strings.HasPrefix(spec, "udp://"):
+		network, addr := spec[:3], spec[len("tcp://"):]
+		if _, _, err := net.SplitHostPort(addr); err != nil {
+			return nil, fmt.Errorf("log sink %q: %v", spec, err)
+		}
+		return &networkWriter{network: network, addr: addr}, nil
+	}
+	return nil, fmt.Errorf("unknown log sink %q", spec)
+}
+
+type nopCloser struct{ io.Writer }
+
+func (nopCloser) Close() error { return nil }
+
+// networkWriter writes to a connection it dials on first use, and redials
+// after a failure, so that a collector restarting loses as few lines as
+// possible. Writes failing twice in a row are dropped.
+type networkWriter struct {
+	network, addr string
+
+	mtx  sync.Mutex
+	conn net.Conn
+}
+
+// dialTimeout bounds how long a write waits for the collector.
+const dialTimeout = 5 * time.Second
+
+func (w *networkWriter) Write(p []byte) (int, error) {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	var err error
+	for attempt := 0; attempt < 2; attempt++ {
+		if w.conn == nil {
+			if w.conn, err = net.DialTimeout(w.network, w.addr, dialTimeout); err != nil {
+				w.conn = nil
+				continue
+			}
+		}
+		var n int
+		if n, err = w.conn.Write(p); err == nil {
+			return n, nil
+		}
+		w.conn.Close()
+		w.conn = nil
+	}
+	return 0, err
+}
+
+func (w *networkWriter) Close() error {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	if w.conn == nil {
+		return nil
+	}
+	err := w.conn.Close()
+	w.conn = nil
+	return err
+}
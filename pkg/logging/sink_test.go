@@ -0,0 +1,66 @@
+package logging
+
+import (
+	"bufio"
+	"io/ioutil"
+	"net"
+	"path/filepath"
+	"testing"
+)
+
+func TestOpenFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "logging")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "svc.log")
+	w, err := Open("file:" + path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte("hello\n"))
+	w.Close()
+	if b, err := ioutil.ReadFile(path); err != nil || string(b) != "hello\n" {
+		t.Errorf("want hello logged, have %q, %v", b, err)
+	}
+}
+
+func TestOpenTCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	lines := make(chan string, 2)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			line, _ := bufio.NewReader(conn).ReadString('\n')
+			lines <- line
+			conn.Close()
+		}
+	}()
+
+	w, err := Open("tcp://" + ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	if _, err := w.Write([]byte("one\n")); err != nil {
+		t.Fatal(err)
+	}
+	if have := <-lines; have != "one\n" {
+		t.Errorf("want one, have %q", have)
+	}
+}
+
+func TestOpenErrors(t *testing.T) {
+	for _, spec := range []string{"", "stdin", "file:", "tcp://nowhere", "http://localhost:80"} {
+		if _, err := Open(spec); err == nil {
+			t.Errorf("%q: want an error", spec)
+		}
+	}
+}
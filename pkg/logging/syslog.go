@@ -0,0 +1,16 @@
+//go:build !windows && !plan9
+// +build !windows,!plan9
+
+package logging
+
+import (
+	"io"
+	"log/syslog"
+)
+
+// openSyslog connects to the syslog daemon at addr over network, or to the
+// local one when both are empty. Lines are sent with the info priority of
+// the daemon facility, tagged with tag, or the program name when empty.
+func openSyslog(network, addr, tag string) (io.WriteCloser, error) {
+	return syslog.Dial(network, addr, syslog.LOG_INFO|syslog.LOG_DAEMON, tag)
+}
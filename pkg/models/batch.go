@@ -0,0 +1,43 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+)
+
+// BatchAction is what a batch does to each of its todos.
+type BatchAction string
+
+// The actions of a batch.
+const (
+	BatchComplete BatchAction = "complete"
+	BatchUndo     BatchAction = "undo"
+	BatchDelete   BatchAction = "delete"
+)
+
+// ErrInvalidBatchAction is returned for a batch with an unknown action.
+var ErrInvalidBatchAction = errors.New("invalid batch action")
+
+// Validate returns ErrInvalidBatchAction, wrapped with a, unless a is known.
+func (a BatchAction) Validate() error {
+	switch a {
+	case BatchComplete, BatchUndo, BatchDelete:
+		return nil
+	}
+	return fmt.Errorf("%w: %q", ErrInvalidBatchAction, a)
+}
+
+// BatchFailure is a todo of a batch that couldn't be acted on, and why.
+type BatchFailure struct {
+	ID    TaskID `json:"id"`
+	Error string `json:"error"`
+}
+
+// BatchResult reports a batch. Matched counts the todos found, Modified
+// those changed: completing a todo already done matches it, but doesn't
+// modify it. Missing todos are no failure, they're left out of Matched.
+type BatchResult struct {
+	Matched  int64          `json:"matched"`
+	Modified int64          `json:"modified"`
+	Failures []BatchFailure `json:"failures,omitempty"`
+}
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+// CustomFields are the values of the fields a tenant defines on its todos,
+// by field name: strings, numbers, booleans, and dates as RFC 3339 strings.
+// In an update, a nil value removes the field.
+type CustomFields map[string]interface{}
+
+// ErrInvalidCustomFieldName is returned for a custom field named otherwise
+// than with letters, digits, - and _.
+var ErrInvalidCustomFieldName = errors.New("invalid custom field name")
+
+// customFieldName is what a custom field name is made of. It never holds a
+// dot or a $, which Mongo would read as a path or an operator.
+var customFieldName = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_-]{0,63}$`)
+
+// ValidateCustomFieldName returns ErrInvalidCustomFieldName, wrapped with
+// name, unless name is a valid custom field name.
+func ValidateCustomFieldName(name string) error {
+	if !customFieldName.MatchString(name) {
+		return fmt.Errorf("%w: %q", ErrInvalidCustomFieldName, name)
+	}
+	return nil
+}
+
+// Merge returns a copy of f with the fields of updates set, or removed for
+// those set to nil. The copy is nil when no field is left.
+func (f CustomFields) Merge(updates CustomFields) CustomFields {
+	merged := make(CustomFields, len(f)+len(updates))
+	for name, v := range f {
+		merged[name] = v
+	}
+	for name, v := range updates {
+		if v == nil {
+			delete(merged, name)
+		} else {
+			merged[name] = v
+		}
+	}
+	if len(merged) == 0 {
+		return nil
+	}
+	return merged
+}
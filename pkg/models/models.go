@@ -1,17 +1,229 @@
 package models
 
 import (
+	"errors"
 	"fmt"
-
-	"go.mongodb.org/mongo-driver/bson/primitive"
+	"strings"
+	"time"
 )
 
+// ToDoItem is a todo as seen by the service and its clients. It's
+// independent of how stores persist it.
 type ToDoItem struct {
-	ID     primitive.ObjectID `json:"_id,omitempty" bson:"_id,omitempty"`
-	Task   string             `json:"task,omitempty"`
-	Status bool               `json:"status"`
+	ID   TaskID `json:"_id,omitempty"`
+	Task string `json:"task,omitempty"`
+	// Status is whether the todo is done, following from its State.
+	Status bool `json:"status"`
+	// State is where the todo is in the workflow of its tenant. Stores
+	// keep it as set, the service computes it.
+	State string `json:"state,omitempty"`
+	// ScheduleAt defers the todo: it's left out of listings until then.
+	ScheduleAt *time.Time `json:"scheduleAt,omitempty"`
+	// DueDate is when the todo should be done by. Past it, the todo is
+	// overdue until done.
+	DueDate *time.Time `json:"dueDate,omitempty"`
+	// Priority is PriorityNormal unless set.
+	Priority Priority `json:"priority,omitempty"`
+	// CompletedAt is when the todo was done, nil while it's pending. Stores
+	// set it, completing a todo already done keeps it.
+	CompletedAt *time.Time `json:"completedAt,omitempty"`
+	// Recurrence adds the next occurrence of the todo once it's done.
+	Recurrence Recurrence `json:"recurrence,omitempty"`
+	// RemindAt is when to notify about the todo, if it's still pending.
+	// It's cleared once the reminder is sent.
+	RemindAt *time.Time `json:"remindAt,omitempty"`
+	// CustomFields are checked against the schema of the tenant.
+	CustomFields CustomFields `json:"customFields,omitempty"`
+	// Dependencies are the links of the todo to the others it's blocked by
+	// or blocks. Only GetToDoByID sets them, stores don't keep them.
+	Dependencies *Dependencies `json:"dependencies,omitempty"`
+	// Tenant is who the todo belongs to, empty for no tenant in
+	// particular. Stores set it from the context of the insert, and only
+	// let the same tenant see and change the todo.
+	Tenant string `json:"-"`
+}
+
+// Dependencies are the todos a todo is blocked by, and those it blocks.
+type Dependencies struct {
+	BlockedBy []TaskID `json:"blockedBy"`
+	Blocks    []TaskID `json:"blocks"`
+	// Blocked is whether any of the todos the todo is blocked by is
+	// pending.
+	Blocked bool `json:"blocked"`
 }
 
 func (t ToDoItem) String() string {
 	return fmt.Sprintf("%#v", t)
 }
+
+// SearchResult is a todo matching a search, scored by relevance: the higher
+// the better.
+type SearchResult struct {
+	ToDoItem
+	Score float64 `json:"score"`
+}
+
+// ToDoUpdate is a partial update of a todo: only the fields set change.
+type ToDoUpdate struct {
+	Task   *string `json:"task,omitempty"`
+	Status *bool   `json:"status,omitempty"`
+	// State moves the todo in the workflow of its tenant. Stores reset the
+	// state of a todo whose status is set without it.
+	State      *string    `json:"state,omitempty"`
+	ScheduleAt *time.Time `json:"scheduleAt,omitempty"`
+	DueDate    *time.Time `json:"dueDate,omitempty"`
+	Priority   *Priority  `json:"priority,omitempty"`
+	// Recurrence set to empty stops the todo repeating.
+	Recurrence *Recurrence `json:"recurrence,omitempty"`
+	RemindAt   *time.Time  `json:"remindAt,omitempty"`
+	// CustomFields sets the custom fields given, and removes those set to
+	// null, leaving the others alone.
+	CustomFields CustomFields `json:"customFields,omitempty"`
+}
+
+// Empty reports whether u changes nothing.
+func (u ToDoUpdate) Empty() bool {
+	return u.Task == nil && u.Status == nil && u.State == nil && u.ScheduleAt == nil && u.DueDate == nil && u.Priority == nil && u.Recurrence == nil && u.RemindAt == nil && len(u.CustomFields) == 0
+}
+
+func (u ToDoUpdate) String() string {
+	var fields []string
+	if u.Task != nil {
+		fields = append(fields, fmt.Sprintf("task=%q", *u.Task))
+	}
+	if u.Status != nil {
+		fields = append(fields, fmt.Sprintf("status=%t", *u.Status))
+	}
+	if u.State != nil {
+		fields = append(fields, "state="+*u.State)
+	}
+	if u.ScheduleAt != nil {
+		fields = append(fields, "scheduleAt="+u.ScheduleAt.Format(time.RFC3339))
+	}
+	if u.DueDate != nil {
+		fields = append(fields, "dueDate="+u.DueDate.Format(time.RFC3339))
+	}
+	if u.Priority != nil {
+		fields = append(fields, "priority="+string(*u.Priority))
+	}
+	if u.Recurrence != nil {
+		fields = append(fields, fmt.Sprintf("recurrence=%q", *u.Recurrence))
+	}
+	if u.RemindAt != nil {
+		fields = append(fields, "remindAt="+u.RemindAt.Format(time.RFC3339))
+	}
+	for name, v := range u.CustomFields {
+		fields = append(fields, fmt.Sprintf("customFields.%s=%v", name, v))
+	}
+	return strings.Join(fields, " ")
+}
+
+// ListOptions narrow a todo listing.
+type ListOptions struct {
+	// Cursor resumes a truncated listing.
+	Cursor string `json:"cursor,omitempty"`
+	// Scheduled includes the todos scheduled for later.
+	Scheduled bool `json:"scheduled,omitempty"`
+	// Query only lists the todos passing this filter expression, see the
+	// query package.
+	Query string `json:"query,omitempty"`
+	// Status only lists the done todos, or the pending ones. Empty lists
+	// both.
+	Status string `json:"status,omitempty"`
+	// Sort orders the listing by SortCreatedAt, the default, SortTask or
+	// SortPriority, from PriorityLow up. Todos with the same task, or
+	// priority, are in the order they were created.
+	Sort string `json:"sort,omitempty"`
+	// Order is OrderAsc, the default, or OrderDesc.
+	Order string `json:"order,omitempty"`
+	// DueBefore and DueAfter only list the todos due before, or after,
+	// these times. Todos without a due date are left out.
+	DueBefore *time.Time `json:"dueBefore,omitempty"`
+	DueAfter  *time.Time `json:"dueAfter,omitempty"`
+	// Overdue only lists the pending todos due before the listing started.
+	Overdue bool `json:"overdue,omitempty"`
+	// Priority only lists the todos of this priority. Empty lists all.
+	Priority Priority `json:"priority,omitempty"`
+}
+
+// The values of the fields of ListOptions.
+const (
+	StatusDone    = "done"
+	StatusPending = "pending"
+	SortCreatedAt = "createdAt"
+	SortTask      = "task"
+	SortPriority  = "priority"
+	OrderAsc      = "asc"
+	OrderDesc     = "desc"
+)
+
+// ErrInvalidListOptions is returned for a listing with an unknown status,
+// sort, order or priority, or asking for overdue todos that are done.
+var ErrInvalidListOptions = errors.New("invalid list options")
+
+// Validate returns ErrInvalidListOptions, wrapped with the culprit, unless
+// the status, sort, order and priority of o are known or empty, and agree
+// with Overdue.
+func (o ListOptions) Validate() error {
+	switch o.Status {
+	case "", StatusDone, StatusPending:
+	default:
+		return fmt.Errorf("%w: status %q", ErrInvalidListOptions, o.Status)
+	}
+	switch o.Sort {
+	case "", SortCreatedAt, SortTask, SortPriority:
+	default:
+		return fmt.Errorf("%w: sort %q", ErrInvalidListOptions, o.Sort)
+	}
+	switch o.Order {
+	case "", OrderAsc, OrderDesc:
+	default:
+		return fmt.Errorf("%w: order %q", ErrInvalidListOptions, o.Order)
+	}
+	if o.Priority != "" && o.Priority.Validate() != nil {
+		return fmt.Errorf("%w: priority %q", ErrInvalidListOptions, o.Priority)
+	}
+	if o.Overdue && o.Status == StatusDone {
+		return fmt.Errorf("%w: overdue todos are pending", ErrInvalidListOptions)
+	}
+	return nil
+}
+
+// ToDoPage is a bounded part of a todo listing. When Truncated is set more
+// todos follow, and Cursor resumes the listing after the last one. Partial
+// is set when some todos couldn't be fetched, e.g. a shard was down, or the
+// listing ran out of time and the page holds the todos read until then.
+type ToDoPage struct {
+	Todos     []ToDoItem `json:"todos"`
+	Truncated bool       `json:"truncated"`
+	Cursor    string     `json:"cursor,omitempty"`
+	Partial   bool       `json:"partial,omitempty"`
+}
+
+// CompletionStats is the distribution of the time todos took to complete,
+// from their creation, in seconds, over the Count todos done most recently.
+type CompletionStats struct {
+	Count int     `json:"count"`
+	Mean  float64 `json:"mean"`
+	P50   float64 `json:"p50"`
+	P90   float64 `json:"p90"`
+	P99   float64 `json:"p99"`
+	Max   float64 `json:"max"`
+}
+
+// The kinds of ToDoEvent.
+const (
+	ToDoCreated = "created"
+	ToDoUpdated = "updated"
+	ToDoDeleted = "deleted"
+)
+
+// ToDoEvent is a change of a todo, as watched. Todo is the todo once
+// changed, nil once deleted.
+type ToDoEvent struct {
+	Type   string    `json:"type"`
+	TaskID TaskID    `json:"taskID"`
+	Todo   *ToDoItem `json:"todo,omitempty"`
+	// Tenant is the tenant of the todo, only its watchers see the event.
+	Tenant string `json:"-"`
+}
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Priority is how pressing a todo is. Empty is PriorityNormal.
+type Priority string
+
+// The priorities, from the least pressing.
+const (
+	PriorityLow    Priority = "low"
+	PriorityNormal Priority = "normal"
+	PriorityHigh   Priority = "high"
+	PriorityUrgent Priority = "urgent"
+)
+
+// ErrInvalidPriority is returned for a todo with an unknown priority.
+var ErrInvalidPriority = errors.New("invalid priority")
+
+// Validate returns ErrInvalidPriority, wrapped with p, unless p is known or
+// empty.
+func (p Priority) Validate() error {
+	if p.Rank() == 0 {
+		return fmt.Errorf("%w: %q", ErrInvalidPriority, p)
+	}
+	return nil
+}
+
+// Rank orders the priorities, from 1 for PriorityLow to 4 for
+// PriorityUrgent. Unknown priorities rank 0. Stores persist the rank, to
+// sort on it.
+func (p Priority) Rank() int {
+	switch p {
+	case PriorityLow:
+		return 1
+	case "", PriorityNormal:
+		return 2
+	case PriorityHigh:
+		return 3
+	case PriorityUrgent:
+		return 4
+	}
+	return 0
+}
+
+// PriorityOfRank returns the priority of rank, PriorityNormal for a rank
+// out of range, like that of a todo stored before priorities.
+func PriorityOfRank(rank int) Priority {
+	switch rank {
+	case 1:
+		return PriorityLow
+	case 3:
+		return PriorityHigh
+	case 4:
+		return PriorityUrgent
+	}
+	return PriorityNormal
+}
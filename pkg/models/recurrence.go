@@ -0,0 +1,66 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Recurrence repeats a todo: once it's done, its next occurrence is added.
+// It's daily, weekly, monthly or yearly, or every N days, weeks, months or
+// years, like "every 2 weeks". Empty doesn't repeat.
+type Recurrence string
+
+// ErrInvalidRecurrence is returned for a todo with a recurrence that can't
+// be parsed.
+var ErrInvalidRecurrence = errors.New("invalid recurrence")
+
+// Validate returns ErrInvalidRecurrence, wrapped with r, unless r is empty
+// or can be parsed.
+func (r Recurrence) Validate() error {
+	if r == "" {
+		return nil
+	}
+	_, _, err := r.period()
+	return err
+}
+
+// Next returns the occurrence n periods after t, t itself for an empty or
+// invalid recurrence.
+func (r Recurrence) Next(t time.Time, n int) time.Time {
+	months, days, err := r.period()
+	if err != nil {
+		return t
+	}
+	return t.AddDate(0, n*months, n*days)
+}
+
+// period returns the months and days between two occurrences.
+func (r Recurrence) period() (months, days int, err error) {
+	fields := strings.Fields(strings.ToLower(string(r)))
+	n, unit := 1, ""
+	switch {
+	case len(fields) == 1:
+		unit = map[string]string{"daily": "day", "weekly": "week", "monthly": "month", "yearly": "year"}[fields[0]]
+	case len(fields) == 2 && fields[0] == "every":
+		unit = fields[1]
+	case len(fields) == 3 && fields[0] == "every":
+		if n, err = strconv.Atoi(fields[1]); err != nil || n < 1 {
+			return 0, 0, fmt.Errorf("%w: %q", ErrInvalidRecurrence, r)
+		}
+		unit = strings.TrimSuffix(fields[2], "s")
+	}
+	switch unit {
+	case "day":
+		return 0, n, nil
+	case "week":
+		return 0, 7 * n, nil
+	case "month":
+		return n, 0, nil
+	case "year":
+		return 12 * n, 0, nil
+	}
+	return 0, 0, fmt.Errorf("%w: %q", ErrInvalidRecurrence, r)
+}
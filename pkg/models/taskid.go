@@ -0,0 +1,103 @@
+package models
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// ErrInvalidTaskID is returned when a string isn't a well-formed TaskID.
+var ErrInvalidTaskID = errors.New("invalid task ID")
+
+// TaskID identifies a todo. It's the hex encoding of a 12 byte ID. The empty
+// TaskID means no ID was assigned yet.
+type TaskID string
+
+var (
+	// processUnique and idCounter make the IDs generated by this process
+	// unique, as the timestamp alone can't.
+	processUnique [5]byte
+	idCounter     uint32
+)
+
+func init() {
+	var b [4]byte
+	if _, err := rand.Read(processUnique[:]); err != nil {
+		panic(err)
+	}
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(err)
+	}
+	idCounter = binary.BigEndian.Uint32(b[:])
+}
+
+// NewTaskID returns a new, unique TaskID. Like MongoDB ObjectIDs, it's made
+// of a timestamp in seconds, a per-process random value and a counter, so
+// IDs sort roughly by creation time.
+func NewTaskID() TaskID {
+	var b [12]byte
+	binary.BigEndian.PutUint32(b[0:4], uint32(time.Now().Unix()))
+	copy(b[4:9], processUnique[:])
+	c := atomic.AddUint32(&idCounter, 1)
+	b[9], b[10], b[11] = byte(c>>16), byte(c>>8), byte(c)
+	return TaskID(hex.EncodeToString(b[:]))
+}
+
+// ParseTaskID validates s and returns it as a TaskID.
+func ParseTaskID(s string) (TaskID, error) {
+	id := TaskID(strings.ToLower(s))
+	if err := id.Validate(); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// Validate returns ErrInvalidTaskID unless id is 24 hex characters.
+func (id TaskID) Validate() error {
+	if len(id) != 24 {
+		return ErrInvalidTaskID
+	}
+	if _, err := hex.DecodeString(string(id)); err != nil {
+		return ErrInvalidTaskID
+	}
+	return nil
+}
+
+// Time returns when the todo id was created, to the second, or the zero
+// time for a malformed id.
+func (id TaskID) Time() time.Time {
+	b, err := hex.DecodeString(string(id))
+	if err != nil || len(b) != 12 {
+		return time.Time{}
+	}
+	return time.Unix(int64(binary.BigEndian.Uint32(b[0:4])), 0)
+}
+
+func (id TaskID) String() string {
+	return string(id)
+}
+
+// UnmarshalJSON implements json.Unmarshaler. Malformed IDs are rejected, an
+// empty string is accepted as no ID.
+func (id *TaskID) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	if s == "" {
+		*id = ""
+		return nil
+	}
+
+	parsed, err := ParseTaskID(s)
+	if err != nil {
+		return err
+	}
+	*id = parsed
+	return nil
+}
@@ -0,0 +1,109 @@
+package moderation
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+type regexFilter []*regexp.Regexp
+
+// NewRegexFilter returns a Filter flagging the text any of patterns
+// matches. The reason is the pattern.
+func NewRegexFilter(patterns []*regexp.Regexp) Filter {
+	return regexFilter(patterns)
+}
+
+func (f regexFilter) Check(_ context.Context, text string) (Verdict, error) {
+	for _, re := range f {
+		if re.MatchString(text) {
+			return Verdict{Flagged: true, Reason: "matches " + re.String()}, nil
+		}
+	}
+	return Verdict{}, nil
+}
+
+// ParseRegexList reads a list of patterns, one per line. Blank lines and
+// lines starting with # are skipped. Patterns match ignoring case.
+func ParseRegexList(r io.Reader) ([]*regexp.Regexp, error) {
+	var patterns []*regexp.Regexp
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		pattern := strings.TrimSpace(scanner.Text())
+		if pattern == "" || strings.HasPrefix(pattern, "#") {
+			continue
+		}
+		re, err := regexp.Compile("(?i)" + pattern)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		patterns = append(patterns, re)
+	}
+	return patterns, scanner.Err()
+}
+
+type apiFilter struct {
+	url    string
+	client *http.Client
+}
+
+// NewAPIFilter returns a Filter asking an external moderation API at url.
+// The text is POSTed as {"text":...}, and the API answers with a Verdict,
+// {"flagged":true,"reason":...}. A nil client uses http.DefaultClient.
+func NewAPIFilter(url string, client *http.Client) Filter {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return apiFilter{url: url, client: client}
+}
+
+func (f apiFilter) Check(ctx context.Context, text string) (Verdict, error) {
+	body, err := json.Marshal(struct {
+		Text string `json:"text"`
+	}{text})
+	if err != nil {
+		return Verdict{}, err
+	}
+	req, err := http.NewRequest("POST", f.url, bytes.NewReader(body))
+	if err != nil {
+		return Verdict{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := f.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return Verdict{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return Verdict{}, fmt.Errorf("moderation API: %s", resp.Status)
+	}
+	var v Verdict
+	if err := json.NewDecoder(resp.Body).Decode(&v); err != nil {
+		return Verdict{}, fmt.Errorf("moderation API: %v", err)
+	}
+	return v, nil
+}
+
+type filters []Filter
+
+// Filters returns a Filter checking text with each of fs in turn, until one
+// flags it.
+func Filters(fs ...Filter) Filter {
+	return filters(fs)
+}
+
+func (fs filters) Check(ctx context.Context, text string) (Verdict, error) {
+	for _, f := range fs {
+		v, err := f.Check(ctx, text)
+		if err != nil || v.Flagged {
+			return v, err
+		}
+	}
+	return Verdict{}, nil
+}
@@ -0,0 +1,181 @@
+// Package moderation screens the text of todos with pluggable content
+// filters, applies the action configured for the tenant to what they flag,
+// and queues the flagged todos for review.
+package moderation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-kit/kit/log"
+
+	"ray.vhatt/todo-gokit/pkg/models"
+	"ray.vhatt/todo-gokit/pkg/tenant"
+)
+
+// ErrRejected is returned, wrapped with the reason, for text flagged for a
+// tenant whose action is Reject.
+var ErrRejected = errors.New("content rejected by moderation")
+
+// Verdict is what a Filter made of a text.
+type Verdict struct {
+	Flagged bool   `json:"flagged"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+// Filter screens text.
+type Filter interface {
+	Check(ctx context.Context, text string) (Verdict, error)
+}
+
+// Action is what happens to a todo whose text was flagged.
+type Action string
+
+const (
+	// Reject refuses the todo with ErrRejected.
+	Reject Action = "reject"
+	// Review stores the todo and flags it for review.
+	Review Action = "review"
+	// Allow stores the todo and records it for audit.
+	Allow Action = "allow"
+)
+
+// Policy is the action taken for each tenant.
+type Policy struct {
+	// Default is the action for the tenants not listed.
+	Default Action
+	// Tenants overrides the action per tenant.
+	Tenants map[string]Action
+}
+
+// For returns the action of tenant.
+func (p Policy) For(tenant string) Action {
+	if action, ok := p.Tenants[tenant]; ok {
+		return action
+	}
+	return p.Default
+}
+
+// ParsePolicy parses a policy given as the default action, then the
+// overrides as tenant=action, separated by commas, e.g.
+// "reject,acme=review,internal=allow".
+func ParsePolicy(s string) (Policy, error) {
+	p := Policy{Default: Reject, Tenants: make(map[string]Action)}
+	for i, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		name, action := "", item
+		if eq := strings.IndexByte(item, '='); eq >= 0 {
+			name, action = strings.TrimSpace(item[:eq]), strings.TrimSpace(item[eq+1:])
+		} else if i > 0 {
+			return Policy{}, fmt.Errorf("policy %q: want tenant=action", item)
+		}
+		switch Action(action) {
+		case Reject, Review, Allow:
+		default:
+			return Policy{}, fmt.Errorf("policy %q: unknown action %q", item, action)
+		}
+		if name == "" {
+			p.Default = Action(action)
+		} else {
+			p.Tenants[name] = Action(action)
+		}
+	}
+	return p, nil
+}
+
+// Event records a flagged todo that was stored anyway.
+type Event struct {
+	TaskID models.TaskID `json:"taskID" bson:"_id"`
+	Tenant string        `json:"tenant,omitempty" bson:"tenant,omitempty"`
+	Text   string        `json:"text" bson:"text"`
+	Reason string        `json:"reason,omitempty" bson:"reason,omitempty"`
+	Action Action        `json:"action" bson:"action"`
+	At     time.Time     `json:"at" bson:"at"`
+}
+
+// Sink receives the events of flagged todos: the ones to review, and the
+// ones allowed, for audit.
+type Sink interface {
+	Record(ctx context.Context, event Event) error
+}
+
+type sinks []Sink
+
+// Sinks returns a Sink recording the events to each of ss, until one fails.
+func Sinks(ss ...Sink) Sink {
+	return sinks(ss)
+}
+
+func (ss sinks) Record(ctx context.Context, e Event) error {
+	for _, s := range ss {
+		if err := s.Record(ctx, e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type logSink struct {
+	logger log.Logger
+}
+
+// NewLogSink returns a Sink logging the events to logger.
+func NewLogSink(logger log.Logger) Sink {
+	return logSink{logger: logger}
+}
+
+func (s logSink) Record(_ context.Context, e Event) error {
+	return s.logger.Log("moderation", e.Action, "taskID", e.TaskID, "tenant", e.Tenant, "reason", e.Reason, "text", e.Text)
+}
+
+// Moderator screens the text of todos with a Filter, and applies the action
+// its Policy sets for the tenant of the request, see tenant.FromContext.
+type Moderator struct {
+	filter Filter
+	policy Policy
+	sink   Sink
+}
+
+// New returns a Moderator. The events of the todos stored although flagged
+// go to sink.
+func New(filter Filter, policy Policy, sink Sink) *Moderator {
+	return &Moderator{filter: filter, policy: policy, sink: sink}
+}
+
+// Screen checks text before it's stored. It returns ErrRejected if it's
+// flagged and the tenant rejects flagged text, otherwise the verdict to
+// Record once the todo is stored. A failing filter fails the screening:
+// text isn't let through unchecked.
+func (m *Moderator) Screen(ctx context.Context, text string) (Verdict, error) {
+	v, err := m.filter.Check(ctx, text)
+	if err != nil {
+		return Verdict{}, fmt.Errorf("moderation: %w", err)
+	}
+	if v.Flagged && m.policy.For(tenant.FromContext(ctx)) == Reject {
+		return Verdict{}, fmt.Errorf("%w: %s", ErrRejected, v.Reason)
+	}
+	return v, nil
+}
+
+// Record sends the event of the todo taskID, stored with text, to the sink
+// when v flagged it.
+func (m *Moderator) Record(ctx context.Context, taskID models.TaskID, text string, v Verdict) error {
+	if !v.Flagged {
+		return nil
+	}
+	t := tenant.FromContext(ctx)
+	return m.sink.Record(ctx, Event{
+		TaskID: taskID,
+		Tenant: t,
+		Text:   text,
+		Reason: v.Reason,
+		Action: m.policy.For(t),
+		At:     time.Now(),
+	})
+}
@@ -0,0 +1,131 @@
+package moderation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"ray.vhatt/todo-gokit/pkg/models"
+	"ray.vhatt/todo-gokit/pkg/store/mongotest"
+	"ray.vhatt/todo-gokit/pkg/tenant"
+)
+
+type sinkFunc func(Event) error
+
+func (f sinkFunc) Record(_ context.Context, e Event) error { return f(e) }
+
+func TestModerator(t *testing.T) {
+	patterns, err := ParseRegexList(strings.NewReader("# swearing\n\nd[a4]rn\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	policy, err := ParsePolicy("reject, acme=review, internal=allow")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var events []Event
+	m := New(NewRegexFilter(patterns), policy, sinkFunc(func(e Event) error {
+		events = append(events, e)
+		return nil
+	}))
+
+	if _, err := m.Screen(context.Background(), "fix the DARN sink"); !errors.Is(err, ErrRejected) {
+		t.Errorf("want %v, have %v", ErrRejected, err)
+	}
+	for _, name := range []string{"acme", "internal"} {
+		ctx := tenant.NewContext(context.Background(), name)
+		v, err := m.Screen(ctx, "fix the d4rn sink")
+		if err != nil || !v.Flagged {
+			t.Fatalf("%s: want the text flagged, have %+v, %v", name, v, err)
+		}
+		if err := m.Record(ctx, "000000000000000000000001", "fix the d4rn sink", v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if v, err := m.Screen(context.Background(), "fix the sink"); err != nil || v.Flagged {
+		t.Errorf("want the text let through, have %+v, %v", v, err)
+	}
+	if len(events) != 2 || events[0].Action != Review || events[1].Action != Allow || events[1].Tenant != "internal" {
+		t.Errorf("want a review and an audit event, have %+v", events)
+	}
+}
+
+func TestParsePolicy(t *testing.T) {
+	for _, s := range []string{"block", "reject,acme", "reject,acme=ignore"} {
+		if _, err := ParsePolicy(s); err == nil {
+			t.Errorf("%q: want an error", s)
+		}
+	}
+	p, err := ParsePolicy("")
+	if err != nil || p.For("acme") != Reject {
+		t.Errorf("want flagged text rejected by default, have %+v, %v", p, err)
+	}
+}
+
+func TestAPIFilter(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct{ Text string }
+		json.NewDecoder(r.Body).Decode(&req)
+		json.NewEncoder(w).Encode(Verdict{Flagged: req.Text == "spam", Reason: "spam"})
+	}))
+	defer srv.Close()
+
+	f := Filters(NewRegexFilter(nil), NewAPIFilter(srv.URL, nil))
+	if v, err := f.Check(context.Background(), "spam"); err != nil || !v.Flagged || v.Reason != "spam" {
+		t.Errorf("want the text flagged, have %+v, %v", v, err)
+	}
+	if v, err := f.Check(context.Background(), "ham"); err != nil || v.Flagged {
+		t.Errorf("want the text let through, have %+v, %v", v, err)
+	}
+
+	srv.Close()
+	if _, err := f.Check(context.Background(), "ham"); err == nil {
+		t.Error("want an error with the API down")
+	}
+}
+
+func TestMemoryQueue(t *testing.T) {
+	testQueue(t, NewMemoryQueue())
+}
+
+func TestMongoQueue(t *testing.T) {
+	q, err := NewMongoQueue(mongotest.DB(t), "review_queue")
+	if err != nil {
+		t.Fatal(err)
+	}
+	testQueue(t, q)
+}
+
+// testQueue runs the checks every Queue passes.
+func testQueue(t *testing.T, q Queue) {
+	ctx := context.Background()
+	at := time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)
+	for i, e := range []Event{
+		{TaskID: "000000000000000000000002", Text: "darn", Action: Review, At: at.Add(time.Minute)},
+		{TaskID: "000000000000000000000001", Text: "d4rn", Action: Review, At: at},
+		{TaskID: "000000000000000000000003", Text: "ham", Action: Allow, At: at},
+	} {
+		if err := q.Record(ctx, e); err != nil {
+			t.Fatalf("event %d: %v", i, err)
+		}
+	}
+	events, err := q.List(ctx)
+	if err != nil || len(events) != 2 || events[0].TaskID != "000000000000000000000001" {
+		t.Errorf("want the todos to review, oldest first, have %+v, %v", events, err)
+	}
+	pending, err := q.Pending(ctx, []models.TaskID{"000000000000000000000001", "000000000000000000000003"})
+	if err != nil || !pending["000000000000000000000001"] || pending["000000000000000000000003"] {
+		t.Errorf("want only the todo to review pending, have %v, %v", pending, err)
+	}
+	if err := q.Remove(ctx, "000000000000000000000001"); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Remove(ctx, "000000000000000000000001"); !errors.Is(err, ErrNotQueued) {
+		t.Errorf("want %v, have %v", ErrNotQueued, err)
+	}
+}
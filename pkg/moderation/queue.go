@@ -0,0 +1,145 @@
+package moderation
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"ray.vhatt/todo-gokit/pkg/models"
+	"ray.vhatt/todo-gokit/pkg/store"
+)
+
+// ErrNotQueued is returned for a todo that isn't awaiting review.
+var ErrNotQueued = errors.New("todo not awaiting review")
+
+// Queue holds the flagged todos awaiting review. As a Sink, it queues the
+// events of the Review action and ignores the others; a todo flagged again
+// keeps its place with its latest text.
+type Queue interface {
+	Sink
+	// List returns the todos awaiting review, oldest first.
+	List(ctx context.Context) ([]Event, error)
+	// Pending reports which of ids are awaiting review.
+	Pending(ctx context.Context, ids []models.TaskID) (map[models.TaskID]bool, error)
+	// Remove takes the todo taskID off the queue, or returns ErrNotQueued.
+	Remove(ctx context.Context, taskID models.TaskID) error
+}
+
+type memoryQueue struct {
+	mtx    sync.Mutex
+	events map[models.TaskID]Event
+}
+
+// NewMemoryQueue returns a Queue kept in memory, it doesn't survive
+// restarts.
+func NewMemoryQueue() Queue {
+	return &memoryQueue{events: make(map[models.TaskID]Event)}
+}
+
+func (q *memoryQueue) Record(_ context.Context, e Event) error {
+	if e.Action != Review {
+		return nil
+	}
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+	if queued, ok := q.events[e.TaskID]; ok {
+		e.At = queued.At
+	}
+	q.events[e.TaskID] = e
+	return nil
+}
+
+func (q *memoryQueue) List(context.Context) ([]Event, error) {
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+	events := make([]Event, 0, len(q.events))
+	for _, e := range q.events {
+		events = append(events, e)
+	}
+	sort.Slice(events, func(i, j int) bool { return events[i].At.Before(events[j].At) })
+	return events, nil
+}
+
+func (q *memoryQueue) Pending(_ context.Context, ids []models.TaskID) (map[models.TaskID]bool, error) {
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+	pending := make(map[models.TaskID]bool)
+	for _, id := range ids {
+		if _, ok := q.events[id]; ok {
+			pending[id] = true
+		}
+	}
+	return pending, nil
+}
+
+func (q *memoryQueue) Remove(_ context.Context, taskID models.TaskID) error {
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+	if _, ok := q.events[taskID]; !ok {
+		return ErrNotQueued
+	}
+	delete(q.events, taskID)
+	return nil
+}
+
+type mongoQueue struct {
+	collection store.Collection
+}
+
+// NewMongoQueue returns a Queue kept in the collection collectionName of db,
+// so that the todos awaiting review survive restarts.
+func NewMongoQueue(db *store.MongoDB, collectionName string) (Queue, error) {
+	return mongoQueue{collection: db.Collection(collectionName)}, nil
+}
+
+func (q mongoQueue) Record(ctx context.Context, e Event) error {
+	if e.Action != Review {
+		return nil
+	}
+	update := bson.M{
+		"$set":         bson.M{"tenant": e.Tenant, "text": e.Text, "reason": e.Reason, "action": e.Action},
+		"$setOnInsert": bson.M{"at": e.At},
+	}
+	_, err := q.collection.UpdateOne(ctx, bson.M{"_id": e.TaskID}, update, options.Update().SetUpsert(true))
+	return err
+}
+
+func (q mongoQueue) List(ctx context.Context) ([]Event, error) {
+	events := []Event{}
+	err := q.collection.All(ctx, bson.M{}, "at", &events)
+	return events, err
+}
+
+func (q mongoQueue) Pending(ctx context.Context, ids []models.TaskID) (map[models.TaskID]bool, error) {
+	pending := make(map[models.TaskID]bool)
+	if len(ids) == 0 {
+		return pending, nil
+	}
+	cur, err := q.collection.Find(ctx, bson.M{"_id": bson.M{"$in": ids}}, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+	for cur.Next(ctx) {
+		var doc struct {
+			ID models.TaskID `bson:"_id"`
+		}
+		if err := cur.Decode(&doc); err != nil {
+			return nil, err
+		}
+		pending[doc.ID] = true
+	}
+	return pending, cur.Err()
+}
+
+func (q mongoQueue) Remove(ctx context.Context, taskID models.TaskID) error {
+	found, err := q.collection.Delete(ctx, taskID)
+	if err == nil && !found {
+		return ErrNotQueued
+	}
+	return err
+}
@@ -0,0 +1,84 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+
+	"ray.vhatt/todo-gokit/pkg/models"
+	"ray.vhatt/todo-gokit/pkg/store"
+)
+
+func TestNotifier(t *testing.T) {
+	var received []Notification
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var n Notification
+		if err := json.NewDecoder(r.Body).Decode(&n); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		received = append(received, n)
+	}))
+	defer srv.Close()
+
+	s := store.NewInMemoryStore()
+	ctx := context.Background()
+	past, later := time.Now().Add(-time.Minute), time.Now().Add(time.Hour)
+	var ids []models.TaskID
+	for _, todo := range []models.ToDoItem{
+		{Task: "call mom", RemindAt: &past, DueDate: &later},
+		{Task: "pay rent", RemindAt: &later},
+		{Task: "buy milk", RemindAt: &past, Status: true},
+		{Task: "walk the dog"},
+	} {
+		id, err := s.InsertToDo(ctx, todo)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, id)
+	}
+
+	n := New(s, NewWebhookSink(srv.URL, nil), time.Minute, log.NewNopLogger())
+	n.batch = 1
+	if sent := n.Poll(ctx); sent != 1 {
+		t.Errorf("want 1 reminder sent, have %d", sent)
+	}
+	if len(received) != 1 || received[0].TaskID != ids[0] || received[0].Task != "call mom" || received[0].DueDate == nil {
+		t.Errorf("want the reminder of the pending todo due, have %+v", received)
+	}
+	if todo, err := s.FindByID(ctx, ids[0]); err != nil || todo.RemindAt != nil {
+		t.Errorf("want the reminder cleared, have %+v, %v", todo, err)
+	}
+	if sent := n.Poll(ctx); sent != 0 {
+		t.Errorf("want a reminder sent once, have %d sent again", sent)
+	}
+
+	srv.Close()
+	remind := time.Now()
+	if _, err := s.UpdateToDo(ctx, ids[3], models.ToDoUpdate{RemindAt: &remind}); err != nil {
+		t.Fatal(err)
+	}
+	if sent := n.Poll(ctx); sent != 0 {
+		t.Errorf("want no reminder sent with the webhook down, have %d", sent)
+	}
+}
+
+func TestOpen(t *testing.T) {
+	for spec, ok := range map[string]bool{
+		"log":                          true,
+		"https://hooks.example.com/r":  true,
+		"smtp://mail:25?from=a@b&to=c": true,
+		"smtp://u:p@mail:587?from=a@b&to=c@d,e@f": true,
+		"smtp://mail:25?to=c":                     false,
+		"ftp://example.com":                       false,
+	} {
+		if _, err := Open(spec, log.NewNopLogger()); (err == nil) != ok {
+			t.Errorf("%s: want ok %v, have %v", spec, ok, err)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+// Package profiling collects the profiles of the running process at regular
+// intervals and ships them to a continuous profiler, to diagnose CPU and
+// allocation regressions in production.
+package profiling
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"runtime/pprof"
+	"time"
+
+	"github.com/go-kit/kit/log"
+)
+
+// The types of the profiles a Profiler collects.
+const (
+	CPU  = "cpu"
+	Heap = "heap"
+)
+
+// Profile is one profile, in the gzipped protobuf format of pprof.
+type Profile struct {
+	Type       string
+	Start, End time.Time
+	Data       []byte
+}
+
+// Uploader ships profiles to a continuous profiler.
+type Uploader interface {
+	Upload(ctx context.Context, p Profile) error
+}
+
+// UploaderFunc is an adapter allowing a function to be used as an Uploader.
+type UploaderFunc func(ctx context.Context, p Profile) error
+
+// Upload calls f(ctx, p).
+func (f UploaderFunc) Upload(ctx context.Context, p Profile) error { return f(ctx, p) }
+
+// Profiler profiles the CPU for a while, then takes a heap profile, every
+// interval.
+type Profiler struct {
+	uploader    Uploader
+	interval    time.Duration
+	cpuDuration time.Duration
+	logger      log.Logger
+}
+
+// New returns a Profiler uploading to uploader. The CPU is profiled for
+// cpuDuration at the start of every interval, which must be longer.
+func New(uploader Uploader, interval, cpuDuration time.Duration, logger log.Logger) (*Profiler, error) {
+	if cpuDuration <= 0 || cpuDuration >= interval {
+		return nil, fmt.Errorf("CPU profiling duration %v isn't shorter than the interval %v", cpuDuration, interval)
+	}
+	return &Profiler{uploader: uploader, interval: interval, cpuDuration: cpuDuration, logger: logger}, nil
+}
+
+// Run profiles until ctx is done. Failing profiles and uploads are logged and
+// skipped: the CPU profiler is busy while someone profiles the process from
+// /debug/pprof, for instance.
+func (p *Profiler) Run(ctx context.Context) error {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		p.collect(ctx)
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (p *Profiler) collect(ctx context.Context) {
+	if profile, err := p.cpu(ctx); err != nil {
+		p.logger.Log("profile", CPU, "err", err)
+	} else {
+		p.upload(ctx, profile)
+	}
+	if profile, err := heap(); err != nil {
+		p.logger.Log("profile", Heap, "err", err)
+	} else {
+		p.upload(ctx, profile)
+	}
+}
+
+func (p *Profiler) cpu(ctx context.Context) (Profile, error) {
+	var buf bytes.Buffer
+	start := time.Now()
+	if err := pprof.StartCPUProfile(&buf); err != nil {
+		return Profile{}, err
+	}
+	timer := time.NewTimer(p.cpuDuration)
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+		timer.Stop()
+	}
+	pprof.StopCPUProfile()
+	return Profile{Type: CPU, Start: start, End: time.Now(), Data: buf.Bytes()}, nil
+}
+
+// heap returns the allocations since the process started, and the memory
+// in use at the last garbage collection.
+func heap() (Profile, error) {
+	var buf bytes.Buffer
+	now := time.Now()
+	if err := pprof.Lookup("heap").WriteTo(&buf, 0); err != nil {
+		return Profile{}, err
+	}
+	return Profile{Type: Heap, Start: now, End: now, Data: buf.Bytes()}, nil
+}
+
+func (p *Profiler) upload(ctx context.Context, profile Profile) {
+	if err := p.uploader.Upload(ctx, profile); err != nil && ctx.Err() == nil {
+		p.logger.Log("profile", profile.Type, "during", "Upload", "err", err)
+	}
+}
@@ -0,0 +1,96 @@
+package profiling
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+)
+
+func TestProfiler(t *testing.T) {
+	var (
+		mtx      sync.Mutex
+		profiles []Profile
+	)
+	uploader := UploaderFunc(func(_ context.Context, p Profile) error {
+		mtx.Lock()
+		defer mtx.Unlock()
+		profiles = append(profiles, p)
+		return nil
+	})
+	p, err := New(uploader, 50*time.Millisecond, 10*time.Millisecond, log.NewNopLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Millisecond)
+	defer cancel()
+	if err := p.Run(ctx); err != context.DeadlineExceeded {
+		t.Errorf("want %v, have %v", context.DeadlineExceeded, err)
+	}
+
+	mtx.Lock()
+	defer mtx.Unlock()
+	if len(profiles) < 4 {
+		t.Fatalf("want at least 2 rounds of profiles, have %d profiles", len(profiles))
+	}
+	for i, profile := range profiles {
+		if want := []string{CPU, Heap}[i%2]; profile.Type != want {
+			t.Errorf("profile %d: want %s, have %s", i, want, profile.Type)
+		}
+		// pprof profiles are gzipped.
+		if len(profile.Data) < 2 || profile.Data[0] != 0x1f || profile.Data[1] != 0x8b {
+			t.Errorf("profile %d: not a pprof profile", i)
+		}
+	}
+	if _, err := New(uploader, time.Second, time.Second, log.NewNopLogger()); err == nil {
+		t.Error("want an error for a CPU profile as long as the interval")
+	}
+}
+
+func TestPyroscopeUploader(t *testing.T) {
+	var query, profile string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/pyroscope/ingest" {
+			http.NotFound(w, r)
+			return
+		}
+		query = r.URL.RawQuery
+		f, _, err := r.FormFile("profile")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		b, _ := ioutil.ReadAll(f)
+		profile = string(b)
+	}))
+	defer srv.Close()
+
+	uploader, err := NewPyroscopeUploader(srv.URL+"/pyroscope/", "addsvc", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Unix(1600000000, 0)
+	err = uploader.Upload(context.Background(), Profile{Type: CPU, Start: start, End: start.Add(10 * time.Second), Data: []byte("pprof")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "format=pprof&from=1600000000&name=addsvc.cpu&spyName=gospy&until=1600000010"; query != want {
+		t.Errorf("want query %s, have %s", want, query)
+	}
+	if profile != "pprof" {
+		t.Errorf("want the profile uploaded, have %q", profile)
+	}
+
+	failing, _ := NewPyroscopeUploader(srv.URL, "addsvc", nil)
+	if err := failing.Upload(context.Background(), Profile{Type: Heap}); err == nil {
+		t.Error("want an error from a failed upload")
+	}
+	if _, err := NewPyroscopeUploader("localhost:4040", "addsvc", nil); err == nil {
+		t.Error("want an error for a URL without a scheme")
+	}
+}
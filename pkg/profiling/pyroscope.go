@@ -0,0 +1,74 @@
+package profiling
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// NewPyroscopeUploader returns an Uploader posting profiles to the ingestion
+// API of the Pyroscope server at serverURL, under the application name.
+func NewPyroscopeUploader(serverURL, name string, client *http.Client) (Uploader, error) {
+	u, err := url.Parse(serverURL)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("Pyroscope URL %q isn't http or https", serverURL)
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/ingest"
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &pyroscope{ingest: u, name: name, client: client}, nil
+}
+
+type pyroscope struct {
+	ingest *url.URL
+	name   string
+	client *http.Client
+}
+
+func (p *pyroscope) Upload(ctx context.Context, profile Profile) error {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("profile", "profile.pprof")
+	if err != nil {
+		return err
+	}
+	part.Write(profile.Data)
+	if err := form.Close(); err != nil {
+		return err
+	}
+
+	u := *p.ingest
+	u.RawQuery = url.Values{
+		"name":    {p.name + "." + profile.Type},
+		"from":    {strconv.FormatInt(profile.Start.Unix(), 10)},
+		"until":   {strconv.FormatInt(profile.End.Unix(), 10)},
+		"format":  {"pprof"},
+		"spyName": {"gospy"},
+	}.Encode()
+	req, err := http.NewRequest("POST", u.String(), &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	resp, err := p.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, resp.Body)
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("Pyroscope answered %s", resp.Status)
+	}
+	return nil
+}
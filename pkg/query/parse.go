@@ -0,0 +1,327 @@
+package query
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"ray.vhatt/todo-gokit/pkg/models"
+)
+
+// maxDepth bounds the nesting of parentheses and NOTs, so that a hostile
+// expression can't exhaust the stack.
+const maxDepth = 32
+
+// ops lists the operators each field accepts.
+var ops = map[string]map[string]bool{
+	Status:     {"=": true, "!=": true, "IN": true},
+	Task:       {"=": true, "!=": true, "~": true, "IN": true},
+	ScheduleAt: {"=": true, "!=": true, "<": true, "<=": true, ">": true, ">=": true, "IN": true},
+}
+
+// customOps lists the operators the custom fields accept.
+var customOps = map[string]bool{"=": true, "!=": true, "<": true, "<=": true, ">": true, ">=": true, "~": true, "IN": true}
+
+// Parse parses the expression s.
+func Parse(s string) (Expr, error) {
+	tokens, err := lex(s)
+	if err != nil {
+		return nil, err
+	}
+	p := parser{tokens: tokens}
+	e, err := p.or(0)
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != eof {
+		return nil, p.errorf(t, "unexpected %s", t)
+	}
+	return e, nil
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token { return p.tokens[p.pos] }
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != eof {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) errorf(t token, format string, args ...interface{}) error {
+	return fmt.Errorf("%w: at offset %d: %s", ErrInvalidQuery, t.offset, fmt.Sprintf(format, args...))
+}
+
+// or parses: and { OR and }.
+func (p *parser) or(depth int) (Expr, error) {
+	e, err := p.and(depth)
+	if err != nil {
+		return nil, err
+	}
+	operands := Or{e}
+	for p.peek().keyword("OR") {
+		p.next()
+		e, err := p.and(depth)
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, e)
+	}
+	if len(operands) == 1 {
+		return e, nil
+	}
+	return operands, nil
+}
+
+// and parses: unary { AND unary }.
+func (p *parser) and(depth int) (Expr, error) {
+	e, err := p.unary(depth)
+	if err != nil {
+		return nil, err
+	}
+	operands := And{e}
+	for p.peek().keyword("AND") {
+		p.next()
+		e, err := p.unary(depth)
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, e)
+	}
+	if len(operands) == 1 {
+		return e, nil
+	}
+	return operands, nil
+}
+
+// unary parses: NOT unary | ( or ) | comparison.
+func (p *parser) unary(depth int) (Expr, error) {
+	t := p.peek()
+	if depth > maxDepth {
+		return nil, p.errorf(t, "nested deeper than %d", maxDepth)
+	}
+	switch {
+	case t.keyword("NOT"):
+		p.next()
+		e, err := p.unary(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		return Not{e}, nil
+	case t.kind == punct && t.text == "(":
+		p.next()
+		e, err := p.or(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		if t := p.next(); t.kind != punct || t.text != ")" {
+			return nil, p.errorf(t, "want ), have %s", t)
+		}
+		return e, nil
+	}
+	return p.comparison()
+}
+
+// comparison parses: field op value | field IN ( value { , value } ).
+func (p *parser) comparison() (Expr, error) {
+	t := p.next()
+	if t.kind != word {
+		return nil, p.errorf(t, "want a field, have %s", t)
+	}
+	field, ok := fieldNamed(t.text)
+	if !ok {
+		return nil, p.errorf(t, "unknown field %q", t.text)
+	}
+	_, custom := CustomField(field)
+
+	t = p.next()
+	var op string
+	switch {
+	case t.kind == operator:
+		op = t.text
+	case t.keyword("IN"):
+		op = "IN"
+	default:
+		return nil, p.errorf(t, "want an operator, have %s", t)
+	}
+	if !ops[field][op] && !(custom && customOps[op]) {
+		return nil, p.errorf(t, "%s doesn't support %s", field, op)
+	}
+
+	if op != "IN" {
+		v, err := p.value(field)
+		if err != nil {
+			return nil, err
+		}
+		return Compare{Field: field, Op: op, Values: []interface{}{v}}, nil
+	}
+
+	if t := p.next(); t.kind != punct || t.text != "(" {
+		return nil, p.errorf(t, "want ( after IN, have %s", t)
+	}
+	var values []interface{}
+	for {
+		v, err := p.value(field)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+		t := p.next()
+		if t.kind == punct && t.text == ")" {
+			return Compare{Field: field, Op: op, Values: values}, nil
+		}
+		if t.kind != punct || t.text != "," {
+			return nil, p.errorf(t, "want , or ), have %s", t)
+		}
+	}
+}
+
+// value parses a value of field.
+func (p *parser) value(field string) (interface{}, error) {
+	t := p.next()
+	if t.kind != word && t.kind != quoted {
+		return nil, p.errorf(t, "want a value, have %s", t)
+	}
+	switch field {
+	case Status:
+		switch t.text {
+		case "open":
+			return false, nil
+		case "done":
+			return true, nil
+		}
+		return nil, p.errorf(t, "status is open or done, not %q", t.text)
+	case ScheduleAt:
+		if d, err := time.Parse("2006-01-02", t.text); err == nil {
+			return d, nil
+		}
+		if d, err := time.Parse(time.RFC3339, t.text); err == nil {
+			return d, nil
+		}
+		return nil, p.errorf(t, "%q is neither a date nor an RFC 3339 time", t.text)
+	}
+	if _, custom := CustomField(field); custom && t.kind == word {
+		if t.text == "true" || t.text == "false" {
+			return t.text == "true", nil
+		}
+		if n, err := strconv.ParseFloat(t.text, 64); err == nil {
+			return n, nil
+		}
+	}
+	return t.text, nil
+}
+
+// fieldNamed returns the field name refers to, ignoring case but for the
+// name of a custom field.
+func fieldNamed(name string) (string, bool) {
+	if len(name) > len(CustomFields) && strings.EqualFold(name[:len(CustomFields)], CustomFields) {
+		custom := name[len(CustomFields):]
+		if models.ValidateCustomFieldName(custom) != nil {
+			return "", false
+		}
+		return CustomFields + custom, true
+	}
+	for field := range ops {
+		if strings.EqualFold(field, name) {
+			return field, true
+		}
+	}
+	return "", false
+}
+
+type tokenKind int
+
+const (
+	eof tokenKind = iota
+	word
+	quoted
+	operator
+	punct
+)
+
+type token struct {
+	kind   tokenKind
+	text   string
+	offset int
+}
+
+// keyword reports whether t is the keyword kw, ignoring case.
+func (t token) keyword(kw string) bool {
+	return t.kind == word && strings.EqualFold(t.text, kw)
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case eof:
+		return "end of query"
+	case quoted:
+		return fmt.Sprintf("%q", t.text)
+	}
+	return t.text
+}
+
+// lex splits s into tokens, ending with an eof token.
+func lex(s string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(' || c == ')' || c == ',':
+			tokens = append(tokens, token{punct, string(c), i})
+			i++
+		case strings.HasPrefix(s[i:], "!=") || strings.HasPrefix(s[i:], "<=") || strings.HasPrefix(s[i:], ">="):
+			tokens = append(tokens, token{operator, s[i : i+2], i})
+			i += 2
+		case c == '=' || c == '<' || c == '>' || c == '~':
+			tokens = append(tokens, token{operator, string(c), i})
+			i++
+		case c == '"':
+			text, n, err := unquote(s[i:])
+			if err != nil {
+				return nil, fmt.Errorf("%w: at offset %d: %v", ErrInvalidQuery, i, err)
+			}
+			tokens = append(tokens, token{quoted, text, i})
+			i += n
+		default:
+			start := i
+			for i < len(s) && !strings.ContainsRune(" \t\n\r(),=!<>~\"", rune(s[i])) {
+				i++
+			}
+			if i == start {
+				return nil, fmt.Errorf("%w: at offset %d: unexpected %q", ErrInvalidQuery, i, c)
+			}
+			tokens = append(tokens, token{word, s[start:i], start})
+		}
+	}
+	return append(tokens, token{eof, "", len(s)}), nil
+}
+
+// unquote returns the double quoted string s starts with, and its length in
+// s. Backslashes escape the next character.
+func unquote(s string) (string, int, error) {
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '"':
+			return b.String(), i + 1, nil
+		case '\\':
+			i++
+			if i == len(s) {
+				break
+			}
+			b.WriteByte(s[i])
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated string")
+}
@@ -0,0 +1,237 @@
+// Package query parses the filter expressions todo listings accept, e.g.
+//
+//	status=open AND (task~garden OR scheduleAt<2020-06-01)
+//
+// Comparisons are combined with AND, OR and NOT, AND binding tighter than
+// OR. The fields are status (open or done), task, scheduleAt (a date or an
+// RFC 3339 time) and the custom fields of the todos, as customFields.name.
+// The operators are =, !=, <, <=, >, >=, IN (a parenthesized list of
+// values) and ~ (task or custom field contains, ignoring case). Values
+// containing spaces or operators are double quoted.
+//
+// The values of custom fields are numbers, true and false unless quoted,
+// strings otherwise; dates are compared as RFC 3339 strings.
+//
+// Stores compile the parsed expression to their own queries, Match
+// evaluates it in memory with the same semantics: a todo without a schedule
+// only passes != comparisons of scheduleAt, likewise for a todo without a
+// custom field, or whose custom field has a value of another type.
+package query
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"ray.vhatt/todo-gokit/pkg/models"
+)
+
+// ErrInvalidQuery is returned, wrapped with the reason, for an expression
+// that doesn't parse.
+var ErrInvalidQuery = errors.New("invalid query")
+
+// Expr is a parsed filter expression: And, Or, Not or Compare.
+type Expr interface {
+	// Match reports whether todo passes the expression.
+	Match(todo models.ToDoItem) bool
+}
+
+// And passes the todos passing all of its operands.
+type And []Expr
+
+// Or passes the todos passing any of its operands.
+type Or []Expr
+
+// Not passes the todos its operand rejects.
+type Not struct{ Expr Expr }
+
+// The fields an expression can compare.
+const (
+	Status     = "status"
+	Task       = "task"
+	ScheduleAt = "scheduleAt"
+	// CustomFields prefixes the name of a custom field.
+	CustomFields = "customFields."
+)
+
+// CustomField returns the name of the custom field field compares, if it
+// compares one.
+func CustomField(field string) (string, bool) {
+	if !strings.HasPrefix(field, CustomFields) {
+		return "", false
+	}
+	return field[len(CustomFields):], true
+}
+
+// Compare compares a field with values. Values hold a bool for Status (true
+// when done), a string for Task, a time.Time for ScheduleAt and a float64,
+// bool or string for a custom field. All operators but IN have a single
+// value.
+type Compare struct {
+	Field  string
+	Op     string
+	Values []interface{}
+}
+
+// Match implements Expr.
+func (e And) Match(todo models.ToDoItem) bool {
+	for _, operand := range e {
+		if !operand.Match(todo) {
+			return false
+		}
+	}
+	return true
+}
+
+// Match implements Expr.
+func (e Or) Match(todo models.ToDoItem) bool {
+	for _, operand := range e {
+		if operand.Match(todo) {
+			return true
+		}
+	}
+	return false
+}
+
+// Match implements Expr.
+func (e Not) Match(todo models.ToDoItem) bool { return !e.Expr.Match(todo) }
+
+// Match implements Expr.
+func (e Compare) Match(todo models.ToDoItem) bool {
+	if name, ok := CustomField(e.Field); ok {
+		return e.matchCustom(todo.CustomFields[name])
+	}
+	var cmp func(v interface{}) int
+	switch e.Field {
+	case Status:
+		cmp = func(v interface{}) int {
+			if todo.Status == v.(bool) {
+				return 0
+			}
+			return 1
+		}
+	case Task:
+		if e.Op == "~" {
+			return strings.Contains(strings.ToLower(todo.Task), strings.ToLower(e.Values[0].(string)))
+		}
+		cmp = func(v interface{}) int { return strings.Compare(todo.Task, v.(string)) }
+	case ScheduleAt:
+		if todo.ScheduleAt == nil {
+			return e.Op == "!="
+		}
+		cmp = func(v interface{}) int {
+			t := v.(time.Time)
+			switch {
+			case todo.ScheduleAt.Before(t):
+				return -1
+			case todo.ScheduleAt.After(t):
+				return 1
+			}
+			return 0
+		}
+	}
+
+	switch e.Op {
+	case "IN":
+		for _, v := range e.Values {
+			if cmp(v) == 0 {
+				return true
+			}
+		}
+		return false
+	case "=":
+		return cmp(e.Values[0]) == 0
+	case "!=":
+		return cmp(e.Values[0]) != 0
+	case "<":
+		return cmp(e.Values[0]) < 0
+	case "<=":
+		return cmp(e.Values[0]) <= 0
+	case ">":
+		return cmp(e.Values[0]) > 0
+	case ">=":
+		return cmp(e.Values[0]) >= 0
+	}
+	return false
+}
+
+// matchCustom reports whether the custom field of value passes e. Values
+// of different types are unequal, and not ordered.
+func (e Compare) matchCustom(value interface{}) bool {
+	if value == nil {
+		return e.Op == "!="
+	}
+	if e.Op == "~" {
+		s, ok := value.(string)
+		return ok && strings.Contains(strings.ToLower(s), strings.ToLower(e.Values[0].(string)))
+	}
+	cmp := func(v interface{}) (int, bool) {
+		switch v := v.(type) {
+		case string:
+			if s, ok := value.(string); ok {
+				return strings.Compare(s, v), true
+			}
+		case float64:
+			if n, ok := number(value); ok {
+				switch {
+				case n < v:
+					return -1, true
+				case n > v:
+					return 1, true
+				}
+				return 0, true
+			}
+		case bool:
+			if b, ok := value.(bool); ok {
+				switch {
+				case b == v:
+					return 0, true
+				case v:
+					return -1, true
+				}
+				return 1, true
+			}
+		}
+		return 0, false
+	}
+	if e.Op == "IN" {
+		for _, v := range e.Values {
+			if c, ok := cmp(v); ok && c == 0 {
+				return true
+			}
+		}
+		return false
+	}
+	c, ok := cmp(e.Values[0])
+	switch e.Op {
+	case "=":
+		return ok && c == 0
+	case "!=":
+		return !ok || c != 0
+	case "<":
+		return ok && c < 0
+	case "<=":
+		return ok && c <= 0
+	case ">":
+		return ok && c > 0
+	case ">=":
+		return ok && c >= 0
+	}
+	return false
+}
+
+// number returns v as a float64, v being any of the numbers JSON and BSON
+// decode to.
+func number(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	}
+	return 0, false
+}
@@ -0,0 +1,169 @@
+package query
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/quick"
+	"time"
+
+	"ray.vhatt/todo-gokit/pkg/models"
+)
+
+func TestParse(t *testing.T) {
+	june := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	may, july := june.AddDate(0, -1, 0), june.AddDate(0, 1, 0)
+	todos := []models.ToDoItem{
+		{Task: "water the garden", ScheduleAt: &may},
+		{Task: "Water the plants", Status: true},
+		{Task: "plant seeds", ScheduleAt: &july},
+		{Task: "call mum"},
+	}
+	for q, want := range map[string]string{
+		"status=open":                                     "[water the garden plant seeds call mum]",
+		"status != open":                                  "[Water the plants]",
+		"task~WATER":                                      "[water the garden Water the plants]",
+		`task="call mum"`:                                 "[call mum]",
+		`task IN ("call mum", "plant seeds")`:             "[plant seeds call mum]",
+		"scheduleAt<2020-06-01":                           "[water the garden]",
+		"scheduleAt>=2020-06-01T00:00:00Z":                "[plant seeds]",
+		"scheduleAt!=2020-05-01":                          "[Water the plants plant seeds call mum]",
+		"NOT scheduleAt<2020-06-01":                       "[Water the plants plant seeds call mum]",
+		"status=open AND task~water OR task~seeds":        "[water the garden plant seeds]",
+		"status=open and (task~water or task~mum)":        "[water the garden call mum]",
+		"not (status=done or scheduleAt in (2020-05-01))": "[plant seeds call mum]",
+		"Status = open AND NOT NOT task ~ plant":          "[plant seeds]",
+	} {
+		e, err := Parse(q)
+		if err != nil {
+			t.Errorf("%s: %v", q, err)
+			continue
+		}
+		var have []string
+		for _, todo := range todos {
+			if e.Match(todo) {
+				have = append(have, todo.Task)
+			}
+		}
+		if fmt.Sprint(have) != want {
+			t.Errorf("%s: want %s, have %v", q, want, have)
+		}
+	}
+}
+
+func TestParseCustomFields(t *testing.T) {
+	todos := []models.ToDoItem{
+		{Task: "ship", CustomFields: models.CustomFields{"points": 3.0, "team": "Backend", "billable": true}},
+		{Task: "fix", CustomFields: models.CustomFields{"points": int32(8), "team": "frontend"}},
+		{Task: "plan", CustomFields: models.CustomFields{"points": "many"}},
+		{Task: "rest"},
+	}
+	for q, want := range map[string]string{
+		"customFields.points=3":                    "[ship]",
+		"customFields.points>=3":                   "[ship fix]",
+		"customFields.points<5.5":                  "[ship]",
+		"customFields.points!=3":                   "[fix plan rest]",
+		`customFields.points="many"`:               "[plan]",
+		"customFields.points IN (8, many)":         "[fix plan]",
+		"CustomFields.team~END":                    "[ship fix]",
+		"customFields.team>b":                      "[fix]",
+		"customFields.billable=true":               "[ship]",
+		"NOT customFields.billable=true":           "[fix plan rest]",
+		`customFields.billable="true"`:             "[]",
+		"customFields.team=frontend OR task=ship":  "[ship fix]",
+		"customFields.missing=1 OR task~r":         "[rest]",
+		"customFields.points>1 AND status=open":    "[ship fix]",
+		"customFields.team!=backend AND task!=fix": "[ship plan rest]",
+	} {
+		e, err := Parse(q)
+		if err != nil {
+			t.Errorf("%s: %v", q, err)
+			continue
+		}
+		have := []string{}
+		for _, todo := range todos {
+			if e.Match(todo) {
+				have = append(have, todo.Task)
+			}
+		}
+		if fmt.Sprint(have) != want {
+			t.Errorf("%s: want %s, have %v", q, want, have)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, q := range []string{
+		"",
+		"status",
+		"status=",
+		"status=maybe",
+		"tag=work",
+		"task<b",
+		"scheduleAt=tomorrow",
+		"status=open AND",
+		"status=open OR OR status=done",
+		"(status=open",
+		"status=open)",
+		"status IN open",
+		"status IN (open",
+		"status IN (open done)",
+		`task="unterminated`,
+		"task=a!b",
+		"customFields.=1",
+		"customFields.a.b=1",
+		"customFields.$where=1",
+		strings.Repeat("(", 100) + "status=open" + strings.Repeat(")", 100),
+		strings.Repeat("NOT ", 100) + "status=open",
+	} {
+		if _, err := Parse(q); !errors.Is(err, ErrInvalidQuery) {
+			t.Errorf("%q: want %v, have %v", q, ErrInvalidQuery, err)
+		}
+	}
+}
+
+// fragments are the pieces random queries are made of, so that they get past
+// the lexer and exercise the parser.
+var fragments = []string{
+	"status", "task", "scheduleAt", "tag", "open", "done", "2020-06-01",
+	"2020-06-01T00:00:00Z", `"a b"`, `"\"`, `"`, "AND", "OR", "NOT", "IN",
+	"(", ")", ",", "=", "!=", "<", "<=", ">", ">=", "~", "!", " ", "x",
+	"customFields.x", "42", "true",
+}
+
+type randomQuery string
+
+func (randomQuery) Generate(r *rand.Rand, size int) reflect.Value {
+	var b strings.Builder
+	for i := r.Intn(size + 1); i > 0; i-- {
+		b.WriteString(fragments[r.Intn(len(fragments))])
+		if r.Intn(2) == 0 {
+			b.WriteByte(' ')
+		}
+	}
+	return reflect.ValueOf(randomQuery(b.String()))
+}
+
+// TestParseFuzz checks that Parse never panics, and either accepts its input
+// or rejects it with ErrInvalidQuery.
+func TestParseFuzz(t *testing.T) {
+	todo := models.ToDoItem{Task: "x"}
+	parse := func(q string) bool {
+		e, err := Parse(q)
+		if err != nil {
+			return errors.Is(err, ErrInvalidQuery)
+		}
+		e.Match(todo)
+		return true
+	}
+	config := &quick.Config{MaxCount: 10000}
+	if err := quick.Check(func(q randomQuery) bool { return parse(string(q)) }, config); err != nil {
+		t.Error(err)
+	}
+	if err := quick.Check(parse, config); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,154 @@
+// Package server runs the listeners of a binary, the transports of the
+// service and the debug listener, with the other components of the process
+// in one run group. When any of them stops, the transports stop accepting
+// connections and drain the requests in flight, one after the other in the
+// reverse order they were registered in, before the others are interrupted.
+package server
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/oklog/oklog/pkg/group"
+	"google.golang.org/grpc"
+)
+
+// Server is a transport serving the connections of a listener.
+type Server interface {
+	Serve(l net.Listener) error
+	// Shutdown stops accepting connections, and waits for the requests in
+	// flight to complete, until ctx is done.
+	Shutdown(ctx context.Context) error
+}
+
+// HTTP returns the Server of an HTTP handler.
+func HTTP(handler http.Handler) Server {
+	return &httpServer{&http.Server{Handler: handler}}
+}
+
+type httpServer struct{ *http.Server }
+
+func (s *httpServer) Serve(l net.Listener) error {
+	if err := s.Server.Serve(l); err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+// GRPC returns the Server of a gRPC server.
+func GRPC(s *grpc.Server) Server {
+	return grpcServer{s}
+}
+
+type grpcServer struct{ *grpc.Server }
+
+func (s grpcServer) Shutdown(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		s.GracefulStop()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		s.Stop()
+		return ctx.Err()
+	}
+}
+
+type transport struct {
+	name     string
+	listener net.Listener
+	server   Server
+}
+
+// Bootstrap collects the transports and components of a process, and runs
+// them.
+type Bootstrap struct {
+	transports      []transport
+	actors          []actor
+	shutdownTimeout time.Duration
+	logger          log.Logger
+}
+
+type actor struct {
+	execute   func() error
+	interrupt func(error)
+}
+
+// New returns a Bootstrap giving its transports shutdownTimeout to drain
+// their requests on shutdown, all together.
+func New(shutdownTimeout time.Duration, logger log.Logger) *Bootstrap {
+	return &Bootstrap{shutdownTimeout: shutdownTimeout, logger: logger}
+}
+
+// Listen binds addr for the transport name, served with s once Run is
+// called. An empty addr disables the transport. Transports registered first
+// are shut down last: register the debug listener first, so that metrics
+// are served until the end.
+func (b *Bootstrap) Listen(name, addr string, s Server) error {
+	if addr == "" {
+		b.logger.Log("transport", name, "disabled", true)
+		return nil
+	}
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	b.transports = append(b.transports, transport{name: name, listener: l, server: s})
+	return nil
+}
+
+// Add runs a component alongside the transports, see group.Group.Add:
+// execute runs the component until interrupt is called.
+func (b *Bootstrap) Add(execute func() error, interrupt func(error)) {
+	b.actors = append(b.actors, actor{execute, interrupt})
+}
+
+// Run serves the transports and runs the components until one of them
+// returns, then shuts everything down. It returns the error that stopped the
+// process.
+func (b *Bootstrap) Run() error {
+	var g group.Group
+	if len(b.transports) > 0 {
+		errc := make(chan error, len(b.transports))
+		stop := make(chan struct{})
+		g.Add(func() error {
+			for _, t := range b.transports {
+				go func(t transport) {
+					b.logger.Log("transport", t.name, "addr", t.listener.Addr())
+					errc <- t.server.Serve(t.listener)
+				}(t)
+			}
+			select {
+			case err := <-errc:
+				return err
+			case <-stop:
+				return nil
+			}
+		}, func(error) {
+			close(stop)
+			b.shutdown()
+		})
+	}
+	for _, a := range b.actors {
+		g.Add(a.execute, a.interrupt)
+	}
+	return g.Run()
+}
+
+// shutdown shuts the transports down, the last registered first.
+func (b *Bootstrap) shutdown() {
+	ctx, cancel := context.WithTimeout(context.Background(), b.shutdownTimeout)
+	defer cancel()
+	for i := len(b.transports) - 1; i >= 0; i-- {
+		t := b.transports[i]
+		if err := t.server.Shutdown(ctx); err != nil {
+			b.logger.Log("transport", t.name, "during", "Shutdown", "err", err)
+		}
+	}
+}
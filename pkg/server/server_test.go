@@ -0,0 +1,58 @@
+package server
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+)
+
+func TestBootstrap(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	slow := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		w.Write([]byte("done"))
+	})
+
+	b := New(time.Second, log.NewNopLogger())
+	if err := b.Listen("debug/HTTP", "", HTTP(http.NotFoundHandler())); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Listen("HTTP", "127.0.0.1:0", HTTP(slow)); err != nil {
+		t.Fatal(err)
+	}
+	if len(b.transports) != 1 {
+		t.Fatalf("want the transport without an address disabled, have %d transports", len(b.transports))
+	}
+	stop, stopped := make(chan struct{}), errors.New("stopped")
+	b.Add(func() error { <-stop; return stopped }, func(error) {})
+
+	ran := make(chan error)
+	go func() { ran <- b.Run() }()
+	body := make(chan string)
+	go func() {
+		resp, err := http.Get("http://" + b.transports[0].listener.Addr().String())
+		if err != nil {
+			body <- err.Error()
+			return
+		}
+		defer resp.Body.Close()
+		b, _ := ioutil.ReadAll(resp.Body)
+		body <- string(b)
+	}()
+
+	// The request in flight is drained before Run returns.
+	<-started
+	close(stop)
+	time.AfterFunc(50*time.Millisecond, func() { close(release) })
+	if have := <-body; have != "done" {
+		t.Errorf("want the request in flight completed, have %q", have)
+	}
+	if err := <-ran; err != stopped {
+		t.Errorf("want %v, have %v", stopped, err)
+	}
+}
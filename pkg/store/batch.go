@@ -0,0 +1,80 @@
+package store
+
+import (
+	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"ray.vhatt/todo-gokit/pkg/models"
+)
+
+// BatchToDo applies action to the todos ids in a single unordered bulk
+// write. A todo failing doesn't stop the others, its write error is
+// reported as its failure.
+func (m mongoStore) BatchToDo(ctx context.Context, action models.BatchAction, ids []models.TaskID) (models.BatchResult, error) {
+	if err := action.Validate(); err != nil {
+		return models.BatchResult{}, err
+	}
+	m = m.forContext(ctx)
+	var (
+		result  models.BatchResult
+		writes  []mongo.WriteModel
+		written []models.TaskID
+	)
+	for _, id := range ids {
+		oid, err := objectID(id)
+		if err != nil {
+			result.Failures = append(result.Failures, models.BatchFailure{ID: id, Error: err.Error()})
+			continue
+		}
+		filter := ofTenant(ctx, bson.M{"_id": oid})
+		if action == models.BatchDelete {
+			writes = append(writes, mongo.NewDeleteOneModel().SetFilter(filter))
+		} else {
+			done := action == models.BatchComplete
+			set := stampCompletion(bson.M{"$set": bson.M{"status": done}}, done)
+			writes = append(writes, mongo.NewUpdateOneModel().SetFilter(filter).SetUpdate(set))
+		}
+		written = append(written, id)
+	}
+	if len(writes) == 0 {
+		return result, nil
+	}
+
+	res, err := m.collection.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false))
+	var bulkErr mongo.BulkWriteException
+	if err != nil && !errors.As(err, &bulkErr) {
+		return models.BatchResult{}, err
+	}
+	for _, e := range bulkErr.WriteErrors {
+		result.Failures = append(result.Failures, models.BatchFailure{ID: written[e.Index], Error: e.Message})
+	}
+	if bulkErr.WriteConcernError != nil {
+		return models.BatchResult{}, err
+	}
+	if action == models.BatchDelete {
+		result.Matched, result.Modified = res.DeletedCount, res.DeletedCount
+	} else {
+		result.Matched, result.Modified = res.MatchedCount, res.ModifiedCount
+	}
+	return result, nil
+}
+
+// batchFailures splits ids into the valid ones and the failures of the
+// others.
+func batchFailures(ids []models.TaskID) ([]models.TaskID, []models.BatchFailure) {
+	var (
+		valid    []models.TaskID
+		failures []models.BatchFailure
+	)
+	for _, id := range ids {
+		if err := id.Validate(); err != nil {
+			failures = append(failures, models.BatchFailure{ID: id, Error: err.Error()})
+			continue
+		}
+		valid = append(valid, id)
+	}
+	return valid, failures
+}
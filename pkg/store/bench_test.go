@@ -0,0 +1,155 @@
+package store_test
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"ray.vhatt/todo-gokit/pkg/fixtures"
+	"ray.vhatt/todo-gokit/pkg/models"
+	"ray.vhatt/todo-gokit/pkg/store"
+)
+
+// backends lists the stores BenchmarkStore compares. A backend whose
+// dependencies aren't available skips itself.
+var backends = []struct {
+	name string
+	new  func(b *testing.B) store.Store
+}{
+	{"memory", func(*testing.B) store.Store { return store.NewInMemoryStore() }},
+	{"sqlite", sqliteBackend},
+	{"mongo", mongoBackend()},
+	{"mongo-partitioned", mongoBackend(store.WithPartitions(4, store.ShardOptions{}))},
+}
+
+// sqliteBackend opens a SQLite database in a scratch directory, removed once
+// the benchmark is done.
+func sqliteBackend(b *testing.B) store.Store {
+	dir, err := ioutil.TempDir("", "sqlite")
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Cleanup(func() { os.RemoveAll(dir) })
+	s, err := store.NewSQLiteStore(filepath.Join(dir, "todos.db"))
+	if err != nil {
+		b.Fatal(err)
+	}
+	return s
+}
+
+// mongoBackend connects to the MongoDB at $STORE_BENCH_MONGO, in a scratch
+// gokit-bench database with a collection per run, dropped once the benchmark
+// is done.
+func mongoBackend(opts ...store.MongoOption) func(b *testing.B) store.Store {
+	return func(b *testing.B) store.Store {
+		uri := os.Getenv("STORE_BENCH_MONGO")
+		if uri == "" {
+			b.Skip("set STORE_BENCH_MONGO to a MongoDB connection string to benchmark it")
+		}
+		collection := fmt.Sprintf("todolist_%d", os.Getpid())
+		b.Cleanup(func() { dropCollections(b, uri, "gokit-bench", collection) })
+		s, err := store.NewMongo(uri, "gokit-bench", collection, opts...)
+		if err != nil {
+			b.Fatal(err)
+		}
+		return s
+	}
+}
+
+// dropCollections drops the collection of dbName and those named after it,
+// like its partitions.
+func dropCollections(b *testing.B, uri, dbName, collection string) {
+	ctx := context.Background()
+	db, err := store.ConnectMongo(uri, dbName)
+	if err != nil {
+		b.Error(err)
+		return
+	}
+	defer db.Client().Disconnect(ctx)
+	names, err := db.Client().Database(dbName).ListCollectionNames(ctx, bson.M{"name": primitive.Regex{Pattern: "^" + collection}})
+	if err != nil {
+		b.Error(err)
+		return
+	}
+	for _, name := range names {
+		if err := db.Collection(name).Drop(ctx); err != nil {
+			b.Error(err)
+		}
+	}
+}
+
+// BenchmarkStore runs the same workload against every backend, e.g.
+//
+//	go test -run NONE -bench Store -count 10 ./pkg/store | benchstat -col /backend -
+//
+// reports the backends side by side.
+func BenchmarkStore(b *testing.B) {
+	for _, backend := range backends {
+		b.Run("backend="+backend.name, func(b *testing.B) {
+			s := backend.new(b)
+			ctx := context.Background()
+
+			// Listings and updates run against a populated store.
+			if _, err := fixtures.Load(ctx, s, fixtures.Options{Count: 1000, Seed: 1}); err != nil {
+				b.Fatal(err)
+			}
+			page, err := s.GetAllToDo(ctx, models.ListOptions{})
+			if err != nil {
+				b.Fatal(err)
+			}
+			ids := make([]models.TaskID, len(page.Todos))
+			for i, todo := range page.Todos {
+				ids[i] = todo.ID
+			}
+
+			todos := fixtures.Generate(fixtures.Options{Count: 1000, Seed: 2})
+			insert := func(i int) error {
+				_, err := s.InsertToDo(ctx, todos[i%len(todos)])
+				return err
+			}
+			complete := func(i int) error {
+				_, err := s.CompleteToDo(ctx, ids[i%len(ids)])
+				return err
+			}
+			list := func(int) error {
+				_, err := s.GetAllToDo(ctx, models.ListOptions{})
+				return err
+			}
+
+			for _, op := range []struct {
+				name string
+				fn   func(i int) error
+			}{
+				{"insert", insert},
+				{"complete", complete},
+				{"list", list},
+				// mix is a write-heavy interactive workload: 6 inserts, 3
+				// completions and 1 listing out of 10 operations.
+				{"mix", func(i int) error {
+					switch n := i % 10; {
+					case n < 6:
+						return insert(i)
+					case n < 9:
+						return complete(i)
+					default:
+						return list(i)
+					}
+				}},
+			} {
+				b.Run("op="+op.name, func(b *testing.B) {
+					for i := 0; i < b.N; i++ {
+						if err := op.fn(i); err != nil {
+							b.Fatal(err)
+						}
+					}
+				})
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"ray.vhatt/todo-gokit/pkg/models"
+)
+
+// Completion is when a todo was done, and how long it took from its
+// creation.
+type Completion struct {
+	At   time.Time
+	Took time.Duration
+}
+
+type onCompletedKey struct{}
+
+// OnCompleted returns a copy of ctx having CompleteToDo and UpdateToDo call
+// fn with the todo they complete, once done. A todo done already isn't
+// completed again: of the changes racing to complete a todo, only one calls
+// fn. The stores making the change in a transaction call fn in it, with a
+// context of the transaction, and fn failing aborts the change. The others
+// call fn once the change is made, and reopen the todo when fn fails, so
+// that completing it can be retried. Either way, the error of fn is
+// returned.
+func OnCompleted(ctx context.Context, fn func(ctx context.Context, todo models.ToDoItem) error) context.Context {
+	return context.WithValue(ctx, onCompletedKey{}, fn)
+}
+
+// completed calls the function OnCompleted set in ctx, if any, with the todo
+// taskID s just completed, and reopens the todo when it fails outside of a
+// transaction.
+func completed(ctx context.Context, s Store, taskID models.TaskID) error {
+	fn, _ := ctx.Value(onCompletedKey{}).(func(context.Context, models.ToDoItem) error)
+	if fn == nil {
+		return nil
+	}
+	todo, err := s.FindByID(ctx, taskID)
+	if err == nil {
+		err = fn(ctx, todo)
+	}
+	if err == nil || inTransaction(ctx) {
+		return err
+	}
+	pending := false
+	if _, reopenErr := s.UpdateToDo(ctx, taskID, models.ToDoUpdate{Status: &pending}); reopenErr != nil {
+		return fmt.Errorf("%w, and reopening the todo failed: %v", err, reopenErr)
+	}
+	return err
+}
+
+// completedAt returns when a todo inserted with status was done: now if it
+// is, nil otherwise.
+func completedAt(status bool) *time.Time {
+	if !status {
+		return nil
+	}
+	now := time.Now()
+	return &now
+}
+
+// stampCompletion adds to update the change of completedAt going with a
+// change of status to done: set unless the todo was done already, or
+// cleared. The state of the todo is reset, to the default of its status.
+func stampCompletion(update bson.M, done bool) bson.M {
+	unset := bson.M{"state": ""}
+	if done {
+		update["$min"] = bson.M{"completedAt": time.Now()}
+	} else {
+		unset["completedAt"] = ""
+	}
+	update["$unset"] = unset
+	return update
+}
+
+// latestCompletions sorts completions from the latest, and keeps the limit
+// first.
+func latestCompletions(completions []Completion, limit int) []Completion {
+	sort.Slice(completions, func(i, j int) bool { return completions[i].At.After(completions[j].At) })
+	if len(completions) > limit {
+		completions = completions[:limit]
+	}
+	return completions
+}
+
+// Completions returns the limit todos done most recently, latest first.
+// The todos done before completions were recorded are left out.
+func (m mongoStore) Completions(ctx context.Context, limit int) ([]Completion, error) {
+	m = m.forContext(ctx)
+	filter := ofTenant(ctx, bson.M{"completedAt": bson.M{"$exists": true}})
+	findOptions := withMaxTime(ctx, options.Find().
+		SetProjection(bson.M{"completedAt": 1}).
+		SetSort(bson.D{{Key: "completedAt", Value: -1}}).
+		SetLimit(int64(limit)))
+	cur, err := m.collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	todos, err := decodeToDos(ctx, cur)
+	if err != nil {
+		return nil, err
+	}
+	completions := make([]Completion, len(todos))
+	for i, todo := range todos {
+		completions[i] = Completion{At: *todo.CompletedAt, Took: todo.CompletedAt.Sub(todo.ID.Time())}
+	}
+	return completions, nil
+}
@@ -0,0 +1,84 @@
+package store_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"ray.vhatt/todo-gokit/pkg/models"
+	"ray.vhatt/todo-gokit/pkg/store"
+	"ray.vhatt/todo-gokit/pkg/store/mongotest"
+)
+
+func TestOnCompleted(t *testing.T) {
+	for _, backend := range []struct {
+		name string
+		new  func(t *testing.T) store.Store
+	}{
+		{"memory", func(*testing.T) store.Store { return store.NewInMemoryStore() }},
+		{"sqlite", func(t *testing.T) store.Store {
+			dir, err := ioutil.TempDir("", "sqlite")
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(func() { os.RemoveAll(dir) })
+			s, err := store.NewSQLiteStore(filepath.Join(dir, "todos.db"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			return s
+		}},
+		{"mongo", func(t *testing.T) store.Store {
+			s, err := store.Open(store.Config{URI: "mongodb:", Collection: "todos", Mongo: mongotest.DB(t)})
+			if err != nil {
+				t.Fatal(err)
+			}
+			return s
+		}},
+	} {
+		t.Run(backend.name, func(t *testing.T) {
+			testOnCompleted(t, backend.new(t))
+		})
+	}
+}
+
+// testOnCompleted checks that the function OnCompleted sets is called once
+// per completion, and that the todo is reopened when it fails.
+func testOnCompleted(t *testing.T, s store.Store) {
+	id, err := s.InsertToDo(context.Background(), models.ToDoItem{Task: "water the plants"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var calls int
+	fail := errors.New("no next occurrence")
+	ctx := store.OnCompleted(context.Background(), func(_ context.Context, todo models.ToDoItem) error {
+		if calls++; todo.ID != id || !todo.Status {
+			t.Errorf("want the todo completed, have %+v", todo)
+		}
+		return fail
+	})
+	if _, err := s.CompleteToDo(ctx, id); err != fail {
+		t.Errorf("want %v, have %v", fail, err)
+	}
+	if todo, err := s.FindByID(ctx, id); err != nil || todo.Status {
+		t.Errorf("want the todo reopened, have %+v, %v", todo, err)
+	}
+
+	fail = nil
+	done, task := true, "water the garden"
+	if _, err := s.UpdateToDo(ctx, id, models.ToDoUpdate{Task: &task, Status: &done}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.CompleteToDo(ctx, id); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.UpdateToDo(ctx, id, models.ToDoUpdate{Status: &done}); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 2 {
+		t.Errorf("want a call per completion, have %d calls", calls)
+	}
+}
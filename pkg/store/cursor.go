@@ -0,0 +1,248 @@
+package store
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"ray.vhatt/todo-gokit/pkg/models"
+)
+
+// listCursor is the position of a listing: the ID of the last todo listed,
+// empty before the first page, and the time the listing started. Every page
+// is read as of that snapshot, leaving out the todos created, or whose
+// schedule came, since. Paging through a listing written to meanwhile
+// neither repeats nor skips todos. A listing sorted by task also keeps the
+// task of the last todo, one sorted by priority its rank.
+type listCursor struct {
+	After      models.TaskID
+	Snapshot   time.Time
+	ByTask     bool
+	Task       string
+	ByPriority bool
+	Priority   int
+}
+
+// parseListCursor parses a listing cursor, or starts a listing now for an
+// empty one.
+func parseListCursor(s string) (listCursor, error) {
+	if s == "" {
+		return listCursor{Snapshot: time.Now()}, nil
+	}
+	// TaskIDs, times and base64 hold no dots. The rank of a listing by
+	// priority follows an empty field, a listing by task has 3 fields.
+	parts := strings.SplitN(s, ".", 4)
+	if len(parts) < 2 {
+		return listCursor{}, ErrInvalidCursor
+	}
+	nanos, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return listCursor{}, ErrInvalidCursor
+	}
+	c := listCursor{After: models.TaskID(parts[0]), Snapshot: time.Unix(0, nanos)}
+	if c.After != "" && c.After.Validate() != nil {
+		return listCursor{}, ErrInvalidCursor
+	}
+	switch {
+	case len(parts) == 4:
+		if parts[2] != "" {
+			return listCursor{}, ErrInvalidCursor
+		}
+		rank, err := strconv.Atoi(parts[3])
+		if err != nil {
+			return listCursor{}, ErrInvalidCursor
+		}
+		c.ByPriority, c.Priority = true, rank
+	case len(parts) == 3:
+		task, err := base64.RawURLEncoding.DecodeString(parts[2])
+		if err != nil {
+			return listCursor{}, ErrInvalidCursor
+		}
+		c.ByTask, c.Task = true, string(task)
+	}
+	return c, nil
+}
+
+// parseListing parses the cursor of the listing opts asks for, and returns
+// its order. A cursor of a listing sorted otherwise is rejected with
+// ErrInvalidCursor.
+func parseListing(opts models.ListOptions) (listCursor, listOrder, error) {
+	order := listOrder{
+		byTask:     opts.Sort == models.SortTask,
+		byPriority: opts.Sort == models.SortPriority,
+		desc:       opts.Order == models.OrderDesc,
+	}
+	c, err := parseListCursor(opts.Cursor)
+	if err != nil {
+		return listCursor{}, listOrder{}, err
+	}
+	if c.After != "" && (c.ByTask != order.byTask || c.ByPriority != order.byPriority) {
+		return listCursor{}, listOrder{}, ErrInvalidCursor
+	}
+	c.ByTask, c.ByPriority = order.byTask, order.byPriority
+	return c, order, nil
+}
+
+func (c listCursor) String() string {
+	s := c.After.String() + "." + strconv.FormatInt(c.Snapshot.UnixNano(), 10)
+	switch {
+	case c.ByTask:
+		s += "." + base64.RawURLEncoding.EncodeToString([]byte(c.Task))
+	case c.ByPriority:
+		s += ".." + strconv.Itoa(c.Priority)
+	}
+	return s
+}
+
+// past returns the cursor resuming the listing after todo.
+func (c listCursor) past(todo models.ToDoItem) listCursor {
+	c.After, c.Task, c.Priority = todo.ID, todo.Task, todo.Priority.Rank()
+	return c
+}
+
+// last returns the last todo listed, as far as the order of the listing
+// goes.
+func (c listCursor) last() models.ToDoItem {
+	return models.ToDoItem{ID: c.After, Task: c.Task, Priority: models.PriorityOfRank(c.Priority)}
+}
+
+// listOrder is the order of a listing: by ID, that is by creation, or by
+// task or priority then ID, ascending unless desc.
+type listOrder struct {
+	byTask     bool
+	byPriority bool
+	desc       bool
+}
+
+// less reports whether a is listed before b.
+func (o listOrder) less(a, b models.ToDoItem) bool {
+	if o.byTask && a.Task != b.Task {
+		return (a.Task < b.Task) != o.desc
+	}
+	if o.byPriority && a.Priority.Rank() != b.Priority.Rank() {
+		return (a.Priority.Rank() < b.Priority.Rank()) != o.desc
+	}
+	return a.ID != b.ID && (a.ID < b.ID) != o.desc
+}
+
+// after reports whether todo is listed after the position of c.
+func (o listOrder) after(c listCursor, todo models.ToDoItem) bool {
+	return c.After == "" || o.less(c.last(), todo)
+}
+
+// listsStatus reports whether todo has the status opts lists.
+func listsStatus(opts models.ListOptions, todo models.ToDoItem) bool {
+	if opts.Overdue && todo.Status {
+		return false
+	}
+	switch opts.Status {
+	case models.StatusDone:
+		return todo.Status
+	case models.StatusPending:
+		return !todo.Status
+	}
+	return true
+}
+
+// listsPriority reports whether todo has the priority opts lists.
+func listsPriority(opts models.ListOptions, todo models.ToDoItem) bool {
+	return opts.Priority == "" || opts.Priority.Rank() == todo.Priority.Rank()
+}
+
+// createdAfter returns the smallest ID of the todos created after the
+// snapshot. IDs only hold seconds, so todos created in the second of the
+// snapshot are still listed.
+func (c listCursor) createdAfter() primitive.ObjectID {
+	var id primitive.ObjectID
+	binary.BigEndian.PutUint32(id[:4], uint32(c.Snapshot.Unix()+1))
+	return id
+}
+
+// WithCursorKey makes NewMongo hand out opaque listing cursors, signed with
+// key so that clients can't forge them, see NewSignedCursors. Replicas
+// serving the same clients must share the key.
+func WithCursorKey(key []byte) MongoOption {
+	return func(m *mongoStore) {
+		m.cursorKey = key
+	}
+}
+
+type signedCursors struct {
+	Store
+	key []byte
+}
+
+// NewSignedCursors returns a Store wrapping the listing cursors of next into
+// opaque tokens: the cursor of next, which holds the position of the last
+// todo in the sort order, followed by its HMAC-SHA256 under key, in base64.
+// Cursors stay stateless, and listings keep seeking past the last todo
+// rather than skipping over the todos already seen. A cursor that wasn't
+// signed with key is rejected with ErrInvalidCursor.
+func NewSignedCursors(next Store, key []byte) Store {
+	return signedCursors{Store: next, key: key}
+}
+
+func (s signedCursors) GetAllToDo(ctx context.Context, opts models.ListOptions) (models.ToDoPage, error) {
+	if opts.Cursor != "" {
+		cursor, ok := s.open(opts.Cursor)
+		if !ok {
+			return models.ToDoPage{}, ErrInvalidCursor
+		}
+		opts.Cursor = cursor
+	}
+	page, err := s.Store.GetAllToDo(ctx, opts)
+	if page.Cursor != "" {
+		page.Cursor = s.seal(page.Cursor)
+	}
+	return page, err
+}
+
+func (s signedCursors) mac(cursor []byte) []byte {
+	h := hmac.New(sha256.New, s.key)
+	h.Write(cursor)
+	return h.Sum(nil)
+}
+
+func (s signedCursors) seal(cursor string) string {
+	b := []byte(cursor)
+	return base64.RawURLEncoding.EncodeToString(append(b, s.mac(b)...))
+}
+
+func (s signedCursors) open(token string) (string, bool) {
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || len(b) < sha256.Size {
+		return "", false
+	}
+	cursor, mac := b[:len(b)-sha256.Size], b[len(b)-sha256.Size:]
+	if !hmac.Equal(mac, s.mac(cursor)) {
+		return "", false
+	}
+	return string(cursor), true
+}
+
+// dueRange returns the bounds opts sets on the due dates listed, nil when
+// unbounded. An overdue listing is bounded by its snapshot.
+func (c listCursor) dueRange(opts models.ListOptions) (after, before *time.Time) {
+	after, before = opts.DueAfter, opts.DueBefore
+	if opts.Overdue && (before == nil || c.Snapshot.Before(*before)) {
+		before = &c.Snapshot
+	}
+	return after, before
+}
+
+// listsDue reports whether todo is due within the bounds opts sets.
+func (c listCursor) listsDue(opts models.ListOptions, todo models.ToDoItem) bool {
+	after, before := c.dueRange(opts)
+	if after == nil && before == nil {
+		return true
+	}
+	return todo.DueDate != nil &&
+		(after == nil || todo.DueDate.After(*after)) &&
+		(before == nil || todo.DueDate.Before(*before))
+}
@@ -0,0 +1,189 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"ray.vhatt/todo-gokit/pkg/models"
+)
+
+func TestSignedCursors(t *testing.T) {
+	ids := make([]models.TaskID, 5)
+	for i := range ids {
+		ids[i] = models.NewTaskID()
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	shard := &fakeShard{max: 2}
+	for _, id := range ids {
+		shard.todos = append(shard.todos, models.ToDoItem{ID: id})
+	}
+	s := NewSignedCursors(shard, []byte("secret"))
+
+	var seen int
+	page, err := s.GetAllToDo(context.Background(), models.ListOptions{})
+	for {
+		if err != nil {
+			t.Fatal(err)
+		}
+		seen += len(page.Todos)
+		if !page.Truncated {
+			break
+		}
+		if last := page.Todos[len(page.Todos)-1].ID.String(); strings.Contains(page.Cursor, last) {
+			t.Errorf("want an opaque cursor, have %s holding %s", page.Cursor, last)
+		}
+		page, err = s.GetAllToDo(context.Background(), models.ListOptions{Cursor: page.Cursor})
+	}
+	if seen != len(ids) {
+		t.Errorf("want %d todos, have %d", len(ids), seen)
+	}
+
+	page, _ = s.GetAllToDo(context.Background(), models.ListOptions{})
+	tampered := []byte(page.Cursor)
+	tampered[0] ^= 'a' ^ 'b'
+	forged := NewSignedCursors(shard, []byte("guess")).(signedCursors).seal(ids[3].String())
+	for _, cursor := range []string{
+		ids[1].String(), // a raw ID
+		forged,
+		string(tampered),
+		"not base64!",
+	} {
+		if _, err := s.GetAllToDo(context.Background(), models.ListOptions{Cursor: cursor}); err != ErrInvalidCursor {
+			t.Errorf("cursor %q: want %v, have %v", cursor, ErrInvalidCursor, err)
+		}
+	}
+}
+
+func TestSnapshotListing(t *testing.T) {
+	a := &fakeShard{max: 1}
+	b := &fakeShard{max: 1}
+	for i := 0; i < 2; i++ {
+		a.todos = append(a.todos, models.ToDoItem{ID: models.NewTaskID()})
+		b.todos = append(b.todos, models.ToDoItem{ID: models.NewTaskID()})
+	}
+	s := NewShardedStore([]Store{a, b}, ShardOptions{})
+
+	page, err := s.GetAllToDo(context.Background(), models.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	seen := len(page.Todos)
+	// A todo created once the listing started, a second later at least.
+	later := models.ToDoItem{ID: models.TaskID(fmt.Sprintf("%08x", time.Now().Add(time.Second).Unix()) + models.NewTaskID().String()[8:])}
+	b.todos = append(b.todos, later)
+	for page.Truncated {
+		if page, err = s.GetAllToDo(context.Background(), models.ListOptions{Cursor: page.Cursor}); err != nil {
+			t.Fatal(err)
+		}
+		for _, todo := range page.Todos {
+			if todo.ID == later.ID {
+				t.Errorf("want the todo created during the listing left out, have it")
+			}
+		}
+		seen += len(page.Todos)
+	}
+	if seen != 4 {
+		t.Errorf("want 4 todos, have %d", seen)
+	}
+}
+
+func TestSortedListing(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	sqlite, err := NewSQLiteStore(filepath.Join(dir, "todos.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlite.(*sqliteStore).maxList = 2
+	memory := NewInMemoryStore()
+	memory.(*memoryStore).maxList = 2
+	shards := []Store{NewInMemoryStore(), NewInMemoryStore()}
+	for _, shard := range shards {
+		shard.(*memoryStore).maxList = 1
+	}
+
+	now := time.Now()
+	past, future := now.Add(-time.Hour), now.Add(time.Hour)
+
+	for name, s := range map[string]Store{
+		"memory":  memory,
+		"sharded": NewShardedStore(shards, ShardOptions{}),
+		"sqlite":  sqlite,
+	} {
+		ctx := context.Background()
+		var ids []models.TaskID
+		for _, todo := range []models.ToDoItem{
+			{Task: "c", DueDate: &past, Priority: models.PriorityHigh},
+			{Task: "a", DueDate: &future, Priority: models.PriorityLow},
+			{Task: "b", Status: true, DueDate: &past},
+			{Task: "a", Status: true, Priority: models.PriorityUrgent},
+			{Task: "a", DueDate: &past, Priority: models.PriorityHigh},
+		} {
+			id, err := s.InsertToDo(ctx, todo)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ids = append(ids, id)
+		}
+		for _, tc := range []struct {
+			opts models.ListOptions
+			want string
+		}{
+			{models.ListOptions{}, "[0 1 2 3 4]"},
+			{models.ListOptions{Order: models.OrderDesc}, "[4 3 2 1 0]"},
+			{models.ListOptions{Sort: models.SortTask}, "[1 3 4 2 0]"},
+			{models.ListOptions{Sort: models.SortTask, Order: models.OrderDesc}, "[0 2 4 3 1]"},
+			{models.ListOptions{Status: models.StatusDone, Sort: models.SortTask}, "[3 2]"},
+			{models.ListOptions{Status: models.StatusPending, Order: models.OrderDesc}, "[4 1 0]"},
+			{models.ListOptions{Overdue: true}, "[0 4]"},
+			{models.ListOptions{DueBefore: &now}, "[0 2 4]"},
+			{models.ListOptions{DueAfter: &now, Sort: models.SortTask}, "[1]"},
+			{models.ListOptions{Sort: models.SortPriority}, "[1 2 0 4 3]"},
+			{models.ListOptions{Sort: models.SortPriority, Order: models.OrderDesc}, "[3 4 0 2 1]"},
+			{models.ListOptions{Priority: models.PriorityHigh, Order: models.OrderDesc}, "[4 0]"},
+			{models.ListOptions{Priority: models.PriorityNormal}, "[2]"},
+		} {
+			var listed []int
+			opts := tc.opts
+			for {
+				page, err := s.GetAllToDo(ctx, opts)
+				if err != nil {
+					t.Fatalf("%s %+v: %v", name, tc.opts, err)
+				}
+				for _, todo := range page.Todos {
+					for i, id := range ids {
+						if todo.ID == id {
+							listed = append(listed, i)
+						}
+					}
+				}
+				if !page.Truncated {
+					break
+				}
+				opts.Cursor = page.Cursor
+			}
+			if have := fmt.Sprint(listed); have != tc.want {
+				t.Errorf("%s %+v: want %s, have %s", name, tc.opts, tc.want, have)
+			}
+		}
+
+		page, err := s.GetAllToDo(ctx, models.ListOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		// A cursor only resumes a listing sorted the same.
+		if _, err := s.GetAllToDo(ctx, models.ListOptions{Cursor: page.Cursor, Sort: models.SortTask}); err != ErrInvalidCursor {
+			t.Errorf("%s: want %v, have %v", name, ErrInvalidCursor, err)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package store
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"ray.vhatt/todo-gokit/pkg/models"
+)
+
+// todoDocument is the shape of a todo in a Mongo collection. It's kept apart
+// from models.ToDoItem so that the service and its clients don't depend on
+// Mongo types.
+type todoDocument struct {
+	ID     primitive.ObjectID `bson:"_id,omitempty"`
+	Task   string             `bson:"task,omitempty"`
+	Status bool               `bson:"status"`
+	// State is left out for todos in the default state of their status.
+	State string `bson:"state,omitempty"`
+	// ScheduleAt is left out for todos active from the start.
+	ScheduleAt *time.Time `bson:"scheduleAt,omitempty"`
+	DueDate    *time.Time `bson:"dueDate,omitempty"`
+	// Priority is the rank of the priority, to sort on.
+	Priority int `bson:"priority"`
+	// CompletedAt is left out while the todo is pending.
+	CompletedAt *time.Time        `bson:"completedAt,omitempty"`
+	Recurrence  models.Recurrence `bson:"recurrence,omitempty"`
+	// RemindAt is left out once the reminder is sent.
+	RemindAt     *time.Time          `bson:"remindAt,omitempty"`
+	CustomFields models.CustomFields `bson:"customFields,omitempty"`
+	// Tenant is left out for the todos of no tenant.
+	Tenant string `bson:"tenant,omitempty"`
+}
+
+// toDocument maps a todo to its document. An empty ID is left for the
+// database to assign.
+func toDocument(t models.ToDoItem) (todoDocument, error) {
+	doc := todoDocument{Task: t.Task, Status: t.Status, State: t.State, ScheduleAt: t.ScheduleAt, DueDate: t.DueDate, Priority: t.Priority.Rank(), CompletedAt: t.CompletedAt, Recurrence: t.Recurrence, RemindAt: t.RemindAt, CustomFields: t.CustomFields, Tenant: t.Tenant}
+	if t.ID != "" {
+		id, err := objectID(t.ID)
+		if err != nil {
+			return todoDocument{}, err
+		}
+		doc.ID = id
+	}
+	return doc, nil
+}
+
+// toModel maps a document back to a todo.
+func (d todoDocument) toModel() models.ToDoItem {
+	return models.ToDoItem{
+		ID:           models.TaskID(d.ID.Hex()),
+		Task:         d.Task,
+		Status:       d.Status,
+		State:        d.State,
+		ScheduleAt:   d.ScheduleAt,
+		DueDate:      d.DueDate,
+		Priority:     models.PriorityOfRank(d.Priority),
+		CompletedAt:  d.CompletedAt,
+		Recurrence:   d.Recurrence,
+		RemindAt:     d.RemindAt,
+		CustomFields: d.CustomFields,
+		Tenant:       d.Tenant,
+	}
+}
+
+// objectID returns the ObjectID taskID encodes.
+func objectID(taskID models.TaskID) (primitive.ObjectID, error) {
+	if err := taskID.Validate(); err != nil {
+		return primitive.NilObjectID, err
+	}
+	return primitive.ObjectIDFromHex(string(taskID))
+}
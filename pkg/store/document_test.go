@@ -0,0 +1,33 @@
+package store
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"ray.vhatt/todo-gokit/pkg/models"
+)
+
+func TestDocumentRoundTrip(t *testing.T) {
+	later := time.Now().Add(time.Hour)
+	want := models.ToDoItem{ID: models.NewTaskID(), Task: "water the plants", Status: true, State: "shipped", ScheduleAt: &later, Priority: models.PriorityHigh, CustomFields: models.CustomFields{"points": 3.0}}
+	doc, err := toDocument(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if have := doc.toModel(); !reflect.DeepEqual(have, want) {
+		t.Errorf("want %v, have %v", want, have)
+	}
+
+	doc, err = toDocument(models.ToDoItem{Task: "new"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !doc.ID.IsZero() {
+		t.Errorf("want no ID for a new todo, have %s", doc.ID.Hex())
+	}
+
+	if _, err := toDocument(models.ToDoItem{ID: "nope"}); err != models.ErrInvalidTaskID {
+		t.Errorf("want %v, have %v", models.ErrInvalidTaskID, err)
+	}
+}
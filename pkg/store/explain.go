@@ -0,0 +1,78 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// explainTimeout bounds the background explain command run for a slow query.
+const explainTimeout = 10 * time.Second
+
+// MongoOption configures optional behaviour of the Mongo store.
+type MongoOption func(*mongoStore)
+
+// WithSlowQueryExplain turns on a diagnostic mode: every store operation
+// taking longer than threshold has its query plan fetched with explain, in
+// the background, and logged to logger. It's meant to track down missing
+// indexes in production.
+func WithSlowQueryExplain(threshold time.Duration, logger log.Logger) MongoOption {
+	return func(m *mongoStore) {
+		m.slowQuery = threshold
+		m.logger = logger
+	}
+}
+
+// explainSlow runs explain for cmd in the background if the operation op,
+// started at begin, was slower than the configured threshold. It's meant to
+// be deferred.
+func (m mongoStore) explainSlow(begin time.Time, op string, cmd bson.D) {
+	took := time.Since(begin)
+	if m.slowQuery <= 0 || took < m.slowQuery {
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), explainTimeout)
+		defer cancel()
+
+		explain := bson.D{{Key: "explain", Value: cmd}, {Key: "verbosity", Value: "queryPlanner"}}
+		plan, err := m.collection.Database().RunCommand(ctx, explain).DecodeBytes()
+		if err != nil {
+			m.logger.Log("op", op, "took", took, "during", "explain", "err", err)
+			return
+		}
+		m.logger.Log("op", op, "took", took, "plan", plan.Lookup("queryPlanner").String())
+	}()
+}
+
+// findCommand returns the find command matching a Find call with filter,
+// sort and limit. A nil sort or a limit of 0 are left out.
+func (m mongoStore) findCommand(filter, sort interface{}, limit int64) bson.D {
+	cmd := bson.D{{Key: "find", Value: m.collection.Name()}, {Key: "filter", Value: filter}}
+	if sort != nil {
+		cmd = append(cmd, bson.E{Key: "sort", Value: sort})
+	}
+	if limit > 0 {
+		cmd = append(cmd, bson.E{Key: "limit", Value: limit})
+	}
+	return cmd
+}
+
+// updateCommand returns the update command matching an UpdateOne call.
+func (m mongoStore) updateCommand(filter, update interface{}) bson.D {
+	return bson.D{
+		{Key: "update", Value: m.collection.Name()},
+		{Key: "updates", Value: bson.A{bson.M{"q": filter, "u": update}}},
+	}
+}
+
+// deleteCommand returns the delete command matching a DeleteOne call.
+func (m mongoStore) deleteCommand(filter interface{}) bson.D {
+	return bson.D{
+		{Key: "delete", Value: m.collection.Name()},
+		{Key: "deletes", Value: bson.A{bson.M{"q": filter, "limit": 1}}},
+	}
+}
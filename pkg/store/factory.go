@@ -0,0 +1,68 @@
+package store
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// Config selects the backend of a Store and configures it.
+type Config struct {
+	// URI locates the store, its scheme selects the backend, e.g.
+	// mongodb://localhost:27017 for Mongo, sqlite:todos.db for a SQLite
+	// database file, or memory: to keep the todos in memory.
+	URI string
+	// Database and Collection hold the todos, on the backends that have
+	// them.
+	Database   string
+	Collection string
+	// MongoOptions tune the Mongo backend.
+	MongoOptions []MongoOption
+	// Mongo is the connection of the Mongo backend to the database, when
+	// it's shared with other stores. Nil connects to URI.
+	Mongo *MongoDB
+}
+
+// Factory builds the Store cfg configures.
+type Factory func(cfg Config) (Store, error)
+
+// factories are the backends, keyed by the scheme of their URIs.
+var factories = map[string]Factory{
+	"mongodb":     openMongo,
+	"mongodb+srv": openMongo,
+	"memory":      openMemory,
+	"sqlite":      openSQLite,
+}
+
+// Register makes Open build the stores whose URI scheme is scheme with f,
+// to plug in another backend. It's meant to be called from init functions,
+// and panics if the scheme is taken.
+func Register(scheme string, f Factory) {
+	if _, ok := factories[scheme]; ok {
+		panic(fmt.Sprintf("store: backend %q registered twice", scheme))
+	}
+	factories[scheme] = f
+}
+
+// Open returns the Store of the backend the scheme of cfg.URI selects.
+func Open(cfg Config) (Store, error) {
+	u, err := url.Parse(cfg.URI)
+	if err != nil {
+		return nil, err
+	}
+	f, ok := factories[u.Scheme]
+	if !ok {
+		return nil, fmt.Errorf("no store backend for %q", cfg.URI)
+	}
+	return f(cfg)
+}
+
+func openMongo(cfg Config) (Store, error) {
+	if cfg.Mongo != nil {
+		return newMongo(cfg.Mongo, cfg.Collection, cfg.MongoOptions...)
+	}
+	return NewMongo(cfg.URI, cfg.Database, cfg.Collection, cfg.MongoOptions...)
+}
+
+func openMemory(Config) (Store, error) {
+	return NewInMemoryStore(), nil
+}
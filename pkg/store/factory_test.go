@@ -0,0 +1,25 @@
+package store
+
+import "testing"
+
+func TestOpen(t *testing.T) {
+	var opened Config
+	Register("fake", func(cfg Config) (Store, error) {
+		opened = cfg
+		return &fakeShard{}, nil
+	})
+	defer delete(factories, "fake")
+
+	cfg := Config{URI: "fake://somewhere", Collection: "todolist"}
+	if _, err := Open(cfg); err != nil {
+		t.Fatal(err)
+	}
+	if opened.URI != cfg.URI || opened.Collection != cfg.Collection {
+		t.Errorf("want the fake backend opened with %+v, have %+v", cfg, opened)
+	}
+	for _, uri := range []string{"postgres://localhost/todos", "localhost:27017", ""} {
+		if _, err := Open(Config{URI: uri}); err == nil {
+			t.Errorf("%q: want an error", uri)
+		}
+	}
+}
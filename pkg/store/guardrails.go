@@ -0,0 +1,78 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ErrQueryTooExpensive is returned, wrapped with the reason, when a query is
+// rejected by the store guardrails.
+var ErrQueryTooExpensive = errors.New("query rejected by cost guardrails")
+
+// Guardrails protect a shared database cluster from pathological queries.
+// The zero value of a field disables the corresponding check.
+type Guardrails struct {
+	// MaxListSize is the largest page of todos a list query returns,
+	// longer listings are truncated with a cursor to resume them. It never
+	// rejects a listing.
+	MaxListSize int64
+	// MaxRegexTerms is the largest number of words a regex search may match
+	// against.
+	MaxRegexTerms int
+	// RequireRegexIndex rejects regex searches on the task field unless it
+	// is indexed.
+	RequireRegexIndex bool
+}
+
+// DefaultGuardrails are applied by NewMongoStore unless WithGuardrails is
+// given.
+var DefaultGuardrails = Guardrails{
+	MaxListSize:   10000,
+	MaxRegexTerms: 10,
+}
+
+// WithGuardrails overrides the DefaultGuardrails of the store.
+func WithGuardrails(g Guardrails) MongoOption {
+	return func(m *mongoStore) {
+		m.guardrails = g
+	}
+}
+
+// checkRegex rejects a regex search over terms words when it's too broad
+// or can't use an index.
+func (m mongoStore) checkRegex(terms int) error {
+	if max := m.guardrails.MaxRegexTerms; max > 0 && terms > max {
+		return fmt.Errorf("%w: searching %d words, the limit is %d", ErrQueryTooExpensive, terms, max)
+	}
+	if m.guardrails.RequireRegexIndex && !m.taskIndexed {
+		return fmt.Errorf("%w: regex search on unindexed task field", ErrQueryTooExpensive)
+	}
+	return nil
+}
+
+// hasIndexOn reports whether collection has an index whose first key is
+// field.
+func hasIndexOn(ctx context.Context, collection *mongo.Collection, field string) (bool, error) {
+	cur, err := collection.Indexes().List(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer cur.Close(ctx)
+
+	for cur.Next(ctx) {
+		var index struct {
+			Key bson.D `bson:"key"`
+		}
+		if err := cur.Decode(&index); err != nil {
+			return false, err
+		}
+		if len(index.Key) > 0 && index.Key[0].Key == field {
+			return true, nil
+		}
+	}
+	return false, cur.Err()
+}
@@ -0,0 +1,377 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"ray.vhatt/todo-gokit/pkg/models"
+	"ray.vhatt/todo-gokit/pkg/query"
+	"ray.vhatt/todo-gokit/pkg/tenant"
+)
+
+type memoryStore struct {
+	mtx     sync.RWMutex
+	todos   map[models.TaskID]memoryToDo
+	counter uint64
+	maxList int64
+	feed    feed
+}
+
+// memoryToDo is a todo, and when it was inserted to list it as of the
+// snapshot of a listing.
+type memoryToDo struct {
+	models.ToDoItem
+	created time.Time
+}
+
+// NewInMemoryStore returns a Store keeping the todos in memory, to run the
+// service without MongoDB in development and tests. The IDs it assigns are
+// a counter, the first todo inserted is 000000000000000000000001, so runs
+// are reproducible. Listings are paged like the Mongo store's with the
+// DefaultGuardrails.
+func NewInMemoryStore() Store {
+	return &memoryStore{
+		todos:   make(map[models.TaskID]memoryToDo),
+		maxList: DefaultGuardrails.MaxListSize,
+	}
+}
+
+func (m *memoryStore) Ping(context.Context) error {
+	return nil
+}
+
+func (m *memoryStore) InsertToDo(ctx context.Context, task models.ToDoItem) (models.TaskID, error) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	task.Tenant = tenant.FromContext(ctx)
+	if task.ID == "" {
+		m.counter++
+		task.ID = models.TaskID(fmt.Sprintf("%024x", m.counter))
+	} else if err := task.ID.Validate(); err != nil {
+		return "", err
+	} else if _, ok := m.todos[task.ID]; ok {
+		return "", fmt.Errorf("%w: %s exists", ErrConflict, task.ID)
+	}
+	if task.ScheduleAt != nil {
+		at := *task.ScheduleAt
+		task.ScheduleAt = &at
+	}
+	if task.DueDate != nil {
+		at := *task.DueDate
+		task.DueDate = &at
+	}
+	if task.RemindAt != nil {
+		at := *task.RemindAt
+		task.RemindAt = &at
+	}
+	task.CustomFields = task.CustomFields.Merge(nil)
+	task.Priority = models.PriorityOfRank(task.Priority.Rank())
+	task.CompletedAt = completedAt(task.Status)
+	m.todos[task.ID] = memoryToDo{ToDoItem: task, created: time.Now()}
+	m.feed.publish(change(models.ToDoCreated, task))
+	return task.ID, nil
+}
+
+// update applies fn to the todo with taskID of the tenant of ctx, or returns
+// ErrToDoNotFound. A todo fn completes is handed to the function
+// OnCompleted set, once unlocked.
+func (m *memoryStore) update(ctx context.Context, taskID models.TaskID, fn func(*models.ToDoItem)) (models.TaskID, error) {
+	if err := taskID.Validate(); err != nil {
+		return "", err
+	}
+	m.mtx.Lock()
+	todo, ok := m.find(ctx, taskID)
+	if !ok {
+		m.mtx.Unlock()
+		return "", ErrToDoNotFound
+	}
+	wasDone := todo.Status
+	fn(&todo.ToDoItem)
+	m.todos[taskID] = todo
+	m.feed.publish(change(models.ToDoUpdated, todo.ToDoItem))
+	m.mtx.Unlock()
+	if !wasDone && todo.Status {
+		return taskID, completed(ctx, m, taskID)
+	}
+	return taskID, nil
+}
+
+// CompleteToDo marks the todo taskID done unless it is already, like the
+// Mongo store.
+func (m *memoryStore) CompleteToDo(ctx context.Context, taskID models.TaskID) (models.TaskID, error) {
+	return m.update(ctx, taskID, func(todo *models.ToDoItem) {
+		if !todo.Status {
+			setStatus(todo, true)
+		}
+	})
+}
+
+func (m *memoryStore) UnDoToDo(ctx context.Context, taskID models.TaskID) (models.TaskID, error) {
+	return m.update(ctx, taskID, func(todo *models.ToDoItem) { setStatus(todo, false) })
+}
+
+// setStatus sets the status of todo, and when it was done like the Mongo
+// store does. Its state is reset.
+func setStatus(todo *models.ToDoItem, done bool) {
+	todo.Status = done
+	todo.State = ""
+	switch {
+	case !done:
+		todo.CompletedAt = nil
+	case todo.CompletedAt == nil:
+		todo.CompletedAt = completedAt(true)
+	}
+}
+
+func (m *memoryStore) UpdateToDo(ctx context.Context, taskID models.TaskID, updates models.ToDoUpdate) (models.TaskID, error) {
+	return m.update(ctx, taskID, func(todo *models.ToDoItem) {
+		if updates.Task != nil {
+			todo.Task = *updates.Task
+		}
+		if updates.Status != nil {
+			setStatus(todo, *updates.Status)
+		}
+		if updates.State != nil {
+			todo.State = *updates.State
+		}
+		if updates.ScheduleAt != nil {
+			at := *updates.ScheduleAt
+			todo.ScheduleAt = &at
+		}
+		if updates.DueDate != nil {
+			at := *updates.DueDate
+			todo.DueDate = &at
+		}
+		if updates.Priority != nil {
+			todo.Priority = models.PriorityOfRank(updates.Priority.Rank())
+		}
+		if updates.Recurrence != nil {
+			todo.Recurrence = *updates.Recurrence
+		}
+		if updates.RemindAt != nil {
+			at := *updates.RemindAt
+			todo.RemindAt = &at
+		}
+		if len(updates.CustomFields) > 0 {
+			todo.CustomFields = todo.CustomFields.Merge(updates.CustomFields)
+		}
+	})
+}
+
+func (m *memoryStore) DeleteToDo(ctx context.Context, taskID models.TaskID) (models.TaskID, error) {
+	if err := taskID.Validate(); err != nil {
+		return "", err
+	}
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	todo, ok := m.find(ctx, taskID)
+	if !ok {
+		return "", ErrToDoNotFound
+	}
+	delete(m.todos, taskID)
+	m.feed.publish(change(models.ToDoDeleted, todo.ToDoItem))
+	return taskID, nil
+}
+
+// BatchToDo applies action to the todos ids, like the Mongo store.
+func (m *memoryStore) BatchToDo(ctx context.Context, action models.BatchAction, ids []models.TaskID) (models.BatchResult, error) {
+	if err := action.Validate(); err != nil {
+		return models.BatchResult{}, err
+	}
+	valid, failures := batchFailures(ids)
+	result := models.BatchResult{Failures: failures}
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	for _, id := range valid {
+		todo, ok := m.find(ctx, id)
+		if !ok {
+			continue
+		}
+		result.Matched++
+		if action == models.BatchDelete {
+			delete(m.todos, id)
+			m.feed.publish(change(models.ToDoDeleted, todo.ToDoItem))
+			result.Modified++
+			continue
+		}
+		if status := action == models.BatchComplete; todo.Status != status {
+			setStatus(&todo.ToDoItem, status)
+			m.todos[id] = todo
+			m.feed.publish(change(models.ToDoUpdated, todo.ToDoItem))
+			result.Modified++
+		}
+	}
+	return result, nil
+}
+
+// Completions returns the limit todos done most recently, like the Mongo
+// store.
+func (m *memoryStore) Completions(ctx context.Context, limit int) ([]Completion, error) {
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+	var completions []Completion
+	t := tenant.FromContext(ctx)
+	for _, todo := range m.todos {
+		if todo.Tenant == t && todo.CompletedAt != nil {
+			completions = append(completions, Completion{At: *todo.CompletedAt, Took: todo.CompletedAt.Sub(todo.created)})
+		}
+	}
+	return latestCompletions(completions, limit), nil
+}
+
+// ClaimReminders returns the limit pending todos of every tenant whose
+// reminder is due at now, earliest first, and clears their reminders, like
+// the Mongo store.
+func (m *memoryStore) ClaimReminders(_ context.Context, now time.Time, limit int) ([]models.ToDoItem, error) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	var due []models.ToDoItem
+	for _, todo := range m.todos {
+		if !todo.Status && todo.RemindAt != nil && !todo.RemindAt.After(now) {
+			due = append(due, todo.ToDoItem)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].RemindAt.Before(*due[j].RemindAt) })
+	if len(due) > limit {
+		due = due[:limit]
+	}
+	for _, todo := range due {
+		claimed := m.todos[todo.ID]
+		claimed.RemindAt = nil
+		m.todos[todo.ID] = claimed
+		m.feed.publish(change(models.ToDoUpdated, claimed.ToDoItem))
+	}
+	return due, nil
+}
+
+// WatchToDos returns the changes of the todos from now on, until ctx is
+// done.
+func (m *memoryStore) WatchToDos(ctx context.Context) (<-chan models.ToDoEvent, error) {
+	return m.feed.watch(ctx), nil
+}
+
+func (m *memoryStore) FindByID(ctx context.Context, taskID models.TaskID) (models.ToDoItem, error) {
+	if err := taskID.Validate(); err != nil {
+		return models.ToDoItem{}, err
+	}
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+	todo, ok := m.find(ctx, taskID)
+	if !ok {
+		return models.ToDoItem{}, ErrToDoNotFound
+	}
+	return todo.ToDoItem, nil
+}
+
+// find returns the todo with taskID, unless it belongs to another tenant
+// than that of ctx. m.mtx must be held.
+func (m *memoryStore) find(ctx context.Context, taskID models.TaskID) (memoryToDo, bool) {
+	todo, ok := m.todos[taskID]
+	if !ok || todo.Tenant != tenant.FromContext(ctx) {
+		return memoryToDo{}, false
+	}
+	return todo, true
+}
+
+// GetAllToDo lists the todos like the Mongo store.
+func (m *memoryStore) GetAllToDo(ctx context.Context, opts models.ListOptions) (models.ToDoPage, error) {
+	cursor, order, err := parseListing(opts)
+	if err != nil {
+		return models.ToDoPage{}, err
+	}
+	var q query.Expr
+	if opts.Query != "" {
+		if q, err = query.Parse(opts.Query); err != nil {
+			return models.ToDoPage{}, err
+		}
+	}
+
+	m.mtx.RLock()
+	var todos []models.ToDoItem
+	for _, todo := range m.sorted(ctx) {
+		switch {
+		case !order.after(cursor, todo.ToDoItem), todo.created.After(cursor.Snapshot):
+		case !opts.Scheduled && todo.ScheduleAt != nil && todo.ScheduleAt.After(cursor.Snapshot):
+		case !listsStatus(opts, todo.ToDoItem):
+		case !cursor.listsDue(opts, todo.ToDoItem):
+		case !listsPriority(opts, todo.ToDoItem):
+		case q != nil && !q.Match(todo.ToDoItem):
+		default:
+			todos = append(todos, todo.ToDoItem)
+		}
+	}
+	m.mtx.RUnlock()
+	sort.SliceStable(todos, func(i, j int) bool { return order.less(todos[i], todos[j]) })
+
+	page := models.ToDoPage{Todos: todos}
+	if m.maxList > 0 && int64(len(todos)) > m.maxList {
+		page.Todos = todos[:m.maxList]
+		page.Truncated = true
+		page.Cursor = cursor.past(page.Todos[m.maxList-1]).String()
+	}
+	return page, nil
+}
+
+// FindSimilarToDo returns the todos sharing at least one word with task,
+// like the Mongo store.
+func (m *memoryStore) FindSimilarToDo(ctx context.Context, task string) ([]models.ToDoItem, error) {
+	words := strings.Fields(strings.ToLower(task))
+	if len(words) == 0 {
+		return nil, nil
+	}
+
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+	var results []models.ToDoItem
+	for _, todo := range m.sorted(ctx) {
+		lower := strings.ToLower(todo.Task)
+		for _, w := range words {
+			if strings.Contains(lower, w) {
+				results = append(results, todo.ToDoItem)
+				break
+			}
+		}
+		if len(results) == similarCandidateLimit {
+			break
+		}
+	}
+	return results, nil
+}
+
+// SearchToDo returns the todos whose task has words of q, scored by
+// textScore.
+func (m *memoryStore) SearchToDo(ctx context.Context, q string) ([]models.SearchResult, error) {
+	terms := searchTerms(q)
+	if len(terms) == 0 {
+		return nil, nil
+	}
+
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+	var results []models.SearchResult
+	for _, todo := range m.sorted(ctx) {
+		if score := textScore(terms, todo.Task); score > 0 {
+			results = append(results, models.SearchResult{ToDoItem: todo.ToDoItem, Score: score})
+		}
+	}
+	return rankResults(results), nil
+}
+
+// sorted returns the todos of the tenant of ctx in the order of their IDs.
+// m.mtx must be held.
+func (m *memoryStore) sorted(ctx context.Context) []memoryToDo {
+	t := tenant.FromContext(ctx)
+	todos := make([]memoryToDo, 0, len(m.todos))
+	for _, todo := range m.todos {
+		if todo.Tenant == t {
+			todos = append(todos, todo)
+		}
+	}
+	sort.Slice(todos, func(i, j int) bool { return todos[i].ID < todos[j].ID })
+	return todos
+}
@@ -0,0 +1,59 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"ray.vhatt/todo-gokit/pkg/models"
+)
+
+func TestInMemoryStore(t *testing.T) {
+	s := NewInMemoryStore()
+	s.(*memoryStore).maxList = 2
+	ctx := context.Background()
+
+	later := time.Now().Add(time.Hour)
+	for _, todo := range []models.ToDoItem{{Task: "a"}, {Task: "b"}, {Task: "c", ScheduleAt: &later}, {Task: "d"}} {
+		if _, err := s.InsertToDo(ctx, todo); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if todo, err := s.FindByID(ctx, "000000000000000000000002"); err != nil || todo.Task != "b" {
+		t.Errorf("want the second todo b, have %+v, %v", todo, err)
+	}
+
+	var tasks []string
+	page, err := s.GetAllToDo(ctx, models.ListOptions{})
+	for {
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, todo := range page.Todos {
+			tasks = append(tasks, todo.Task)
+		}
+		if !page.Truncated {
+			break
+		}
+		// Inserted after the listing started, so left out of it.
+		s.InsertToDo(ctx, models.ToDoItem{Task: "e"})
+		page, err = s.GetAllToDo(ctx, models.ListOptions{Cursor: page.Cursor})
+	}
+	if want, have := "[a b d]", fmt.Sprint(tasks); want != have {
+		t.Errorf("want %s, have %s", want, have)
+	}
+
+	if _, err := s.FindByID(ctx, "ffffffffffffffffffffffff"); err != ErrToDoNotFound {
+		t.Errorf("want %v, have %v", ErrToDoNotFound, err)
+	}
+	if _, err := s.UnDoToDo(ctx, "ffffffffffffffffffffffff"); err != ErrToDoNotFound {
+		t.Errorf("want %v, have %v", ErrToDoNotFound, err)
+	}
+	if _, err := s.DeleteToDo(ctx, "ffffffffffffffffffffffff"); err != ErrToDoNotFound {
+		t.Errorf("want %v, have %v", ErrToDoNotFound, err)
+	}
+	if _, err := s.DeleteToDo(ctx, "x"); err != models.ErrInvalidTaskID {
+		t.Errorf("want %v, have %v", models.ErrInvalidTaskID, err)
+	}
+}
@@ -0,0 +1,94 @@
+package store
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoDB is a connection to a Mongo database, shared by the stores keeping
+// their documents in its collections, like those of the jobs, views or
+// webhooks.
+type MongoDB struct {
+	client *mongo.Client
+	name   string
+}
+
+// ConnectMongo connects to the MongoDB at connectionString, and checks the
+// connection, to keep documents in its dbName database.
+func ConnectMongo(connectionString, dbName string) (*MongoDB, error) {
+	client, err := mongo.Connect(context.TODO(), options.Client().ApplyURI(connectionString))
+	if err != nil {
+		return nil, err
+	}
+	if err := client.Ping(context.TODO(), nil); err != nil {
+		return nil, err
+	}
+	return &MongoDB{client: client, name: dbName}, nil
+}
+
+// Client returns the client of the connection.
+func (db *MongoDB) Client() *mongo.Client {
+	return db.client
+}
+
+// Name returns the name of the database.
+func (db *MongoDB) Name() string {
+	return db.name
+}
+
+// Collection returns the collection of the database called name.
+func (db *MongoDB) Collection(name string) Collection {
+	return Collection{db.client.Database(db.name).Collection(name)}
+}
+
+// Drop drops the database, for tests.
+func (db *MongoDB) Drop(ctx context.Context) error {
+	return db.client.Database(db.name).Drop(ctx)
+}
+
+// Collection is a Mongo collection of documents identified by their _id.
+type Collection struct {
+	*mongo.Collection
+}
+
+// Put replaces the document identified by id with doc, or inserts it.
+func (c Collection) Put(ctx context.Context, id, doc interface{}) error {
+	_, err := c.ReplaceOne(ctx, bson.M{"_id": id}, doc, options.Replace().SetUpsert(true))
+	return err
+}
+
+// Get decodes the document identified by id into v. It returns false when
+// there's none.
+func (c Collection) Get(ctx context.Context, id, v interface{}) (bool, error) {
+	err := c.FindOne(ctx, bson.M{"_id": id}).Decode(v)
+	if err == mongo.ErrNoDocuments {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Delete deletes the document identified by id. It returns false when there
+// was none.
+func (c Collection) Delete(ctx context.Context, id interface{}) (bool, error) {
+	res, err := c.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return false, err
+	}
+	return res.DeletedCount > 0, nil
+}
+
+// All decodes the documents matching filter into the slice results points
+// to, in the order of sort, by _id when it's empty.
+func (c Collection) All(ctx context.Context, filter interface{}, sort string, results interface{}) error {
+	if sort == "" {
+		sort = "_id"
+	}
+	cur, err := c.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: sort, Value: 1}}))
+	if err != nil {
+		return err
+	}
+	return cur.All(ctx, results)
+}
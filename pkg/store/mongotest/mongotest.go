@@ -0,0 +1,43 @@
+// Package mongotest connects the tests of the Mongo backed stores to a
+// scratch database, so that they run against the same MongoDB as the
+// in-memory stores do.
+package mongotest
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync/atomic"
+	"testing"
+
+	"ray.vhatt/todo-gokit/pkg/store"
+)
+
+// EnvURI is the environment variable holding the connection string of the
+// MongoDB the tests run against.
+const EnvURI = "STORE_TEST_MONGO"
+
+var databases int64
+
+// DB returns a connection to a scratch database of the MongoDB at $EnvURI,
+// dropped once t is done. It skips t when the variable isn't set.
+func DB(t testing.TB) *store.MongoDB {
+	t.Helper()
+	uri := os.Getenv(EnvURI)
+	if uri == "" {
+		t.Skip("set " + EnvURI + " to a MongoDB connection string to test against it")
+	}
+	name := fmt.Sprintf("gokit_test_%d_%d", os.Getpid(), atomic.AddInt64(&databases, 1))
+	db, err := store.ConnectMongo(uri, name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		ctx := context.Background()
+		if err := db.Drop(ctx); err != nil {
+			t.Error(err)
+		}
+		db.Client().Disconnect(ctx)
+	})
+	return db
+}
@@ -0,0 +1,347 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"ray.vhatt/todo-gokit/pkg/events"
+	"ray.vhatt/todo-gokit/pkg/models"
+	"ray.vhatt/todo-gokit/pkg/tenant"
+)
+
+// DefaultOutboxLease is how long an event claimed from a MongoOutbox is
+// hidden from the other claims, awaiting its acknowledgement.
+const DefaultOutboxLease = time.Minute
+
+// WithOutbox makes the Mongo store write the events of each change of the
+// todos to the collection outbox of its database, in the same transaction
+// as the change: an event is recorded if and only if its change is. Mongo
+// only runs transactions on replica sets and sharded clusters. The events
+// are published by an events.Relay reading a MongoOutbox.
+func WithOutbox(outbox string) MongoOption {
+	return func(m *mongoStore) {
+		m.outbox = outbox
+	}
+}
+
+// outboxDocument is an event awaiting publishing. The event is kept as its
+// JSON, as published.
+type outboxDocument struct {
+	ID         string    `bson:"_id"`
+	Type       string    `bson:"type"`
+	TaskID     string    `bson:"taskID"`
+	Event      []byte    `bson:"event"`
+	Created    time.Time `bson:"created"`
+	LeaseUntil time.Time `bson:"leaseUntil"`
+}
+
+// withOutbox returns m, writing its events to its outbox if it has one.
+func (m mongoStore) withOutbox() Store {
+	if m.outbox == "" {
+		return m
+	}
+	return outboxStore{mongoStore: m, outbox: m.collection.Database().Collection(m.outbox)}
+}
+
+// outboxStore is a mongoStore making its changes in transactions, along
+// with their events.
+type outboxStore struct {
+	mongoStore
+	outbox *mongo.Collection
+}
+
+func (s outboxStore) InsertToDo(ctx context.Context, task models.ToDoItem) (models.TaskID, error) {
+	var id models.TaskID
+	err := s.transact(ctx, func(sc context.Context) ([]events.Event, error) {
+		var err error
+		if id, err = s.mongoStore.InsertToDo(sc, task); err != nil {
+			return nil, err
+		}
+		return s.eventsOf(sc, []models.TaskID{id}, events.ToDoCreated)
+	})
+	return id, err
+}
+
+func (s outboxStore) CompleteToDo(ctx context.Context, taskID models.TaskID) (models.TaskID, error) {
+	return s.change(ctx, taskID, s.mongoStore.CompleteToDo, events.ToDoCompleted)
+}
+
+func (s outboxStore) UnDoToDo(ctx context.Context, taskID models.TaskID) (models.TaskID, error) {
+	return s.change(ctx, taskID, s.mongoStore.UnDoToDo, events.ToDoReopened)
+}
+
+func (s outboxStore) UpdateToDo(ctx context.Context, taskID models.TaskID, updates models.ToDoUpdate) (models.TaskID, error) {
+	types := []string{events.ToDoUpdated}
+	if updates.Status != nil && *updates.Status {
+		types = append(types, events.ToDoCompleted)
+	} else if updates.Status != nil {
+		types = append(types, events.ToDoReopened)
+	}
+	update := func(ctx context.Context, taskID models.TaskID) (models.TaskID, error) {
+		return s.mongoStore.UpdateToDo(ctx, taskID, updates)
+	}
+	return s.change(ctx, taskID, update, types...)
+}
+
+func (s outboxStore) DeleteToDo(ctx context.Context, taskID models.TaskID) (models.TaskID, error) {
+	return s.change(ctx, taskID, s.mongoStore.DeleteToDo, events.ToDoDeleted)
+}
+
+var batchEvents = map[models.BatchAction]string{
+	models.BatchComplete: events.ToDoCompleted,
+	models.BatchUndo:     events.ToDoReopened,
+	models.BatchDelete:   events.ToDoDeleted,
+}
+
+// BatchToDo records an event per todo of ids found, and not failed.
+func (s outboxStore) BatchToDo(ctx context.Context, action models.BatchAction, ids []models.TaskID) (models.BatchResult, error) {
+	if err := action.Validate(); err != nil {
+		return models.BatchResult{}, err
+	}
+	var result models.BatchResult
+	err := s.transact(ctx, func(sc context.Context) ([]events.Event, error) {
+		found, err := s.found(sc, ids)
+		if err != nil {
+			return nil, err
+		}
+		if result, err = s.mongoStore.BatchToDo(sc, action, ids); err != nil {
+			return nil, err
+		}
+		for _, f := range result.Failures {
+			delete(found, f.ID)
+		}
+		var changed []models.TaskID
+		for _, id := range ids {
+			if found[id] {
+				delete(found, id)
+				changed = append(changed, id)
+			}
+		}
+		return s.eventsOf(sc, changed, batchEvents[action])
+	})
+	return result, err
+}
+
+// change applies fn to the todo taskID, recording an event of each of
+// types unless the todo is missing.
+func (s outboxStore) change(ctx context.Context, taskID models.TaskID, fn func(context.Context, models.TaskID) (models.TaskID, error), types ...string) (models.TaskID, error) {
+	var id models.TaskID
+	err := s.transact(ctx, func(sc context.Context) ([]events.Event, error) {
+		found, err := s.found(sc, []models.TaskID{taskID})
+		if err != nil {
+			return nil, err
+		}
+		if id, err = fn(sc, taskID); err != nil || !found[taskID] {
+			return nil, err
+		}
+		return s.eventsOf(sc, []models.TaskID{id}, types...)
+	})
+	return id, err
+}
+
+// found returns the todos of ids that exist for the tenant of ctx. Invalid
+// IDs are left out.
+func (s outboxStore) found(ctx context.Context, ids []models.TaskID) (map[models.TaskID]bool, error) {
+	m := s.forContext(ctx)
+	oids := make([]primitive.ObjectID, 0, len(ids))
+	for _, id := range ids {
+		if oid, err := objectID(id); err == nil {
+			oids = append(oids, oid)
+		}
+	}
+	found := make(map[models.TaskID]bool, len(oids))
+	if len(oids) == 0 {
+		return found, nil
+	}
+	cur, err := m.collection.Find(ctx, ofTenant(ctx, bson.M{"_id": bson.M{"$in": oids}}), options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+	for cur.Next(ctx) {
+		var doc struct {
+			ID primitive.ObjectID `bson:"_id"`
+		}
+		if err := cur.Decode(&doc); err != nil {
+			return nil, err
+		}
+		found[models.TaskID(doc.ID.Hex())] = true
+	}
+	return found, cur.Err()
+}
+
+// eventsOf returns an event of each of types about each todo of ids. Those
+// created and updated carry the todo, as the transaction of ctx sees it.
+func (s outboxStore) eventsOf(ctx context.Context, ids []models.TaskID, types ...string) ([]events.Event, error) {
+	var es []events.Event
+	for _, id := range ids {
+		for _, eventType := range types {
+			e := events.New(eventType, id, tenant.FromContext(ctx))
+			if eventType == events.ToDoCreated || eventType == events.ToDoUpdated {
+				todo, err := s.mongoStore.FindByID(ctx, id)
+				if err != nil {
+					return nil, err
+				}
+				e.Todo = &todo
+			}
+			es = append(es, e)
+		}
+	}
+	return es, nil
+}
+
+// Transact runs fn in a transaction of s if s writes the events of its
+// changes to an outbox, see WithOutbox, and as is otherwise. In the
+// transaction, the changes fn makes to s and the events it records with
+// Record are written all together, or not at all.
+func Transact(ctx context.Context, s Store, fn func(ctx context.Context) error) error {
+	t, ok := transactorOf(s)
+	if !ok {
+		return fn(ctx)
+	}
+	return t.transact(ctx, func(ctx context.Context) ([]events.Event, error) {
+		return nil, fn(ctx)
+	})
+}
+
+// Record adds es to the events written to the outbox by the transaction of
+// ctx, see Transact. It reports whether ctx is of such a transaction.
+func Record(ctx context.Context, es ...events.Event) bool {
+	recorded, ok := ctx.Value(txKey{}).(*[]events.Event)
+	if ok {
+		*recorded = append(*recorded, es...)
+	}
+	return ok
+}
+
+// WritesOutbox reports whether s writes the events of its changes to an
+// outbox, see WithOutbox.
+func WritesOutbox(s Store) bool {
+	_, ok := transactorOf(s)
+	return ok
+}
+
+// transactorOf returns the outboxStore running the transactions of s, if
+// any. The shards of a Mongo store share their database, so a transaction
+// of the first spans them all.
+func transactorOf(s Store) (outboxStore, bool) {
+	switch s := s.(type) {
+	case outboxStore:
+		return s, true
+	case signedCursors:
+		return transactorOf(s.Store)
+	case shardedStore:
+		if len(s.shards) > 0 {
+			return transactorOf(s.shards[0])
+		}
+	}
+	return outboxStore{}, false
+}
+
+// txKey is the key of the events recorded so far in the transaction of a
+// context.
+type txKey struct{}
+
+// inTransaction reports whether ctx is of a transaction of an outboxStore.
+func inTransaction(ctx context.Context) bool {
+	_, ok := ctx.Value(txKey{}).(*[]events.Event)
+	return ok
+}
+
+// transact runs fn in a transaction, writing the events it returns to the
+// outbox before committing. fn is run again if the transaction fails
+// transiently. Run in the transaction of ctx, fn joins it: its events are
+// written along with the others of the transaction.
+func (s outboxStore) transact(ctx context.Context, fn func(context.Context) ([]events.Event, error)) error {
+	if recorded, ok := ctx.Value(txKey{}).(*[]events.Event); ok {
+		es, err := fn(ctx)
+		if err != nil {
+			return err
+		}
+		*recorded = append(*recorded, es...)
+		return nil
+	}
+	session, err := s.client.StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+	_, err = session.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+		var recorded []events.Event
+		es, err := fn(context.WithValue(sc, txKey{}, &recorded))
+		if err != nil {
+			return nil, err
+		}
+		if es = append(es, recorded...); len(es) == 0 {
+			return nil, nil
+		}
+		docs := make([]interface{}, len(es))
+		for i, e := range es {
+			body, err := json.Marshal(e)
+			if err != nil {
+				return nil, err
+			}
+			docs[i] = outboxDocument{ID: e.ID, Type: e.Type, TaskID: e.TaskID.String(), Event: body, Created: e.At}
+		}
+		_, err = s.outbox.InsertMany(sc, docs)
+		return nil, err
+	})
+	return err
+}
+
+// MongoOutbox is the events.Outbox of the events a Mongo store writes with
+// WithOutbox.
+type MongoOutbox struct {
+	collection *mongo.Collection
+	lease      time.Duration
+}
+
+// NewMongoOutbox returns the outbox collectionName of db, whose events are
+// claimed for DefaultOutboxLease.
+func NewMongoOutbox(db *MongoDB, collectionName string) (*MongoOutbox, error) {
+	collection := db.Collection(collectionName).Collection
+	_, err := collection.Indexes().CreateOne(context.TODO(), mongo.IndexModel{
+		Keys: bson.D{{Key: "leaseUntil", Value: 1}, {Key: "created", Value: 1}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &MongoOutbox{collection: collection, lease: DefaultOutboxLease}, nil
+}
+
+// Claim leases the events one at a time, so replicas relaying the same
+// outbox never both get one while its lease runs.
+func (o *MongoOutbox) Claim(ctx context.Context, now time.Time, limit int) ([]events.Event, error) {
+	filter := bson.M{"leaseUntil": bson.M{"$lte": now}}
+	update := bson.M{"$set": bson.M{"leaseUntil": now.Add(o.lease)}}
+	claim := options.FindOneAndUpdate().SetSort(bson.D{{Key: "created", Value: 1}, {Key: "_id", Value: 1}})
+	var claimed []events.Event
+	for len(claimed) < limit {
+		var doc outboxDocument
+		err := o.collection.FindOneAndUpdate(ctx, filter, update, claim).Decode(&doc)
+		if err == mongo.ErrNoDocuments {
+			break
+		}
+		if err != nil {
+			return claimed, err
+		}
+		var e events.Event
+		if err := json.Unmarshal(doc.Event, &e); err != nil {
+			return claimed, err
+		}
+		claimed = append(claimed, e)
+	}
+	return claimed, nil
+}
+
+// Ack deletes the event id.
+func (o *MongoOutbox) Ack(ctx context.Context, id string) error {
+	_, err := o.collection.DeleteOne(ctx, bson.M{"_id": id})
+	return err
+}
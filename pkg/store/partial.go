@@ -0,0 +1,46 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"ray.vhatt/todo-gokit/pkg/models"
+)
+
+type partialKey struct{}
+
+// WithPartialResults returns a copy of ctx under which listings stop reading
+// reserve before the deadline of ctx, and answer with the todos read so far
+// rather than fail with it, leaving reserve to send them back. Such a page is
+// Partial and Truncated: its Cursor resumes the listing where it stopped. It
+// has no effect on a ctx without a deadline, and a reserve of 0 turns partial
+// results back off.
+func WithPartialResults(ctx context.Context, reserve time.Duration) context.Context {
+	return context.WithValue(ctx, partialKey{}, reserve)
+}
+
+// listContext returns the context to read a listing with: one ending reserve
+// before the deadline of ctx when partial results were asked for, else ctx.
+func listContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	reserve, ok := ctx.Value(partialKey{}).(time.Duration)
+	deadline, hasDeadline := ctx.Deadline()
+	if !ok || reserve <= 0 || !hasDeadline {
+		return ctx, func() {}
+	}
+	return context.WithDeadline(ctx, deadline.Add(-reserve))
+}
+
+// cutShort reports whether a listing read with listCtx failed because it ran
+// out of time for reading, ctx, the request, still going.
+func cutShort(ctx, listCtx context.Context) bool {
+	return ctx.Err() == nil && listCtx.Err() == context.DeadlineExceeded
+}
+
+// partialPage returns the page of the todos read after c before the listing
+// was cut short.
+func (c listCursor) partialPage(todos []models.ToDoItem) models.ToDoPage {
+	if len(todos) > 0 {
+		c = c.past(todos[len(todos)-1])
+	}
+	return models.ToDoPage{Todos: todos, Truncated: true, Cursor: c.String(), Partial: true}
+}
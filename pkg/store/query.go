@@ -0,0 +1,55 @@
+package store
+
+import (
+	"regexp"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"ray.vhatt/todo-gokit/pkg/query"
+)
+
+// mongoOps maps the comparison operators of a query to Mongo's.
+var mongoOps = map[string]string{
+	"=":  "$eq",
+	"!=": "$ne",
+	"<":  "$lt",
+	"<=": "$lte",
+	">":  "$gt",
+	">=": "$gte",
+	"IN": "$in",
+}
+
+// mongoFilter compiles e to a Mongo filter, and counts the regex searches
+// it makes so that they can be checked against the guardrails. The query
+// fields are named like the document fields.
+func mongoFilter(e query.Expr) (filter bson.M, regexes int) {
+	switch e := e.(type) {
+	case query.And:
+		operands := make(bson.A, len(e))
+		for i, operand := range e {
+			f, n := mongoFilter(operand)
+			operands[i], regexes = f, regexes+n
+		}
+		return bson.M{"$and": operands}, regexes
+	case query.Or:
+		operands := make(bson.A, len(e))
+		for i, operand := range e {
+			f, n := mongoFilter(operand)
+			operands[i], regexes = f, regexes+n
+		}
+		return bson.M{"$or": operands}, regexes
+	case query.Not:
+		f, n := mongoFilter(e.Expr)
+		return bson.M{"$nor": bson.A{f}}, n
+	case query.Compare:
+		if e.Op == "~" {
+			pattern := regexp.QuoteMeta(e.Values[0].(string))
+			return bson.M{e.Field: primitive.Regex{Pattern: pattern, Options: "i"}}, 1
+		}
+		if e.Op == "IN" {
+			return bson.M{e.Field: bson.M{"$in": bson.A(e.Values)}}, 0
+		}
+		return bson.M{e.Field: bson.M{mongoOps[e.Op]: e.Values[0]}}, 0
+	}
+	return bson.M{}, 0
+}
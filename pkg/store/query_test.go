@@ -0,0 +1,33 @@
+package store
+
+import (
+	"fmt"
+	"testing"
+
+	"ray.vhatt/todo-gokit/pkg/query"
+)
+
+func TestMongoFilter(t *testing.T) {
+	for q, want := range map[string]struct {
+		filter  string
+		regexes int
+	}{
+		"status=open":                     {"map[status:map[$eq:false]]", 0},
+		"task IN (a, b)":                  {"map[task:map[$in:[a b]]]", 0},
+		"task~a.b":                        {`map[task:{"pattern": "a\.b", "options": "i"}]`, 1},
+		"NOT task~a OR task~b":            {`map[$or:[map[$nor:[map[task:{"pattern": "a", "options": "i"}]]] map[task:{"pattern": "b", "options": "i"}]]]`, 2},
+		"status=done AND task!=a":         {"map[$and:[map[status:map[$eq:true]] map[task:map[$ne:a]]]]", 0},
+		"scheduleAt>2020-06-01T10:00:00Z": {"map[scheduleAt:map[$gt:2020-06-01 10:00:00 +0000 UTC]]", 0},
+		"customFields.points>=3":          {"map[customFields.points:map[$gte:3]]", 0},
+		`customFields.team IN (a, "b")`:   {"map[customFields.team:map[$in:[a b]]]", 0},
+	} {
+		e, err := query.Parse(q)
+		if err != nil {
+			t.Fatal(err)
+		}
+		filter, regexes := mongoFilter(e)
+		if have := fmt.Sprint(filter); have != want.filter || regexes != want.regexes {
+			t.Errorf("%s: want %s with %d regexes, have %s with %d", q, want.filter, want.regexes, have, regexes)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"ray.vhatt/todo-gokit/pkg/models"
+)
+
+// ClaimReminders returns the limit pending todos of every tenant whose
+// reminder is due at now, earliest first, and clears their reminders. Each
+// todo is claimed atomically, so replicas polling the same collection never
+// both get it.
+func (m mongoStore) ClaimReminders(ctx context.Context, now time.Time, limit int) ([]models.ToDoItem, error) {
+	m = m.forContext(ctx)
+	filter := bson.M{"remindAt": bson.M{"$lte": now}, "status": false}
+	update := bson.M{"$unset": bson.M{"remindAt": ""}}
+	claim := options.FindOneAndUpdate().SetSort(bson.D{{Key: "remindAt", Value: 1}})
+	var todos []models.ToDoItem
+	for len(todos) < limit {
+		var doc todoDocument
+		err := m.collection.FindOneAndUpdate(ctx, filter, update, claim).Decode(&doc)
+		if err == mongo.ErrNoDocuments {
+			break
+		}
+		if err != nil {
+			return todos, err
+		}
+		todos = append(todos, doc.toModel())
+	}
+	return todos, nil
+}
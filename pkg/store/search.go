@@ -0,0 +1,90 @@
+package store
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"unicode"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"ray.vhatt/todo-gokit/pkg/models"
+)
+
+// searchLimit bounds the number of matches SearchToDo returns.
+const searchLimit = 50
+
+// textIndexName names the text index on the task field.
+const textIndexName = "task_text"
+
+// SearchToDo returns the todos whose task matches the words of q, best
+// matches first, as scored by the text index of the collection. Words are
+// stemmed, and a word prefixed with - excludes the todos containing it.
+func (m mongoStore) SearchToDo(ctx context.Context, q string) ([]models.SearchResult, error) {
+	m = m.forContext(ctx)
+	filter := ofTenant(ctx, bson.M{"$text": bson.M{"$search": q}})
+	score := bson.M{"score": bson.M{"$meta": "textScore"}}
+	findOptions := withMaxTime(ctx, options.Find().
+		SetProjection(score).
+		SetSort(score).
+		SetLimit(searchLimit))
+	cur, err := m.collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), closeTimeout)
+		defer cancel()
+		cur.Close(ctx)
+	}()
+
+	var results []models.SearchResult
+	for cur.Next(ctx) {
+		var doc struct {
+			todoDocument `bson:",inline"`
+			Score        float64 `bson:"score"`
+		}
+		if err := cur.Decode(&doc); err != nil {
+			return nil, err
+		}
+		results = append(results, models.SearchResult{ToDoItem: doc.toModel(), Score: doc.Score})
+	}
+	return results, cur.Err()
+}
+
+// searchTerms splits a search into the lower case words it looks for.
+func searchTerms(q string) []string {
+	return strings.FieldsFunc(strings.ToLower(q), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+// textScore scores task against terms for the stores without a text index:
+// the number of words of task among terms. Unlike Mongo, words aren't
+// stemmed.
+func textScore(terms []string, task string) float64 {
+	var score float64
+	for _, w := range searchTerms(task) {
+		for _, term := range terms {
+			if w == term {
+				score++
+				break
+			}
+		}
+	}
+	return score
+}
+
+// rankResults sorts results best first, and keeps the searchLimit first.
+func rankResults(results []models.SearchResult) []models.SearchResult {
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].ID < results[j].ID
+	})
+	if len(results) > searchLimit {
+		results = results[:searchLimit]
+	}
+	return results
+}
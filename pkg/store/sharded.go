@@ -0,0 +1,387 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"sync"
+	"time"
+
+	"ray.vhatt/todo-gokit/pkg/models"
+)
+
+// ShardOptions tune how a sharded store fans queries out to its shards.
+type ShardOptions struct {
+	// Concurrency is the largest number of shards queried at once, 0 means
+	// all of them.
+	Concurrency int
+	// Timeout bounds each shard query, 0 means no timeout besides the one of
+	// the request.
+	Timeout time.Duration
+	// AllowPartial makes listings return the todos of the healthy shards,
+	// flagged as partial, when some shards fail. Otherwise any shard failure
+	// fails the listing.
+	AllowPartial bool
+}
+
+type shardedStore struct {
+	shards []Store
+	opts   ShardOptions
+}
+
+// NewShardedStore returns a Store partitioning todos across shards by ID.
+// Writes go to the shard owning the ID, listings query every shard in
+// parallel and merge the results in ID order.
+func NewShardedStore(shards []Store, opts ShardOptions) Store {
+	return shardedStore{
+		shards: shards,
+		opts:   opts,
+	}
+}
+
+// shardFor returns the shard owning taskID.
+func (s shardedStore) shardFor(taskID models.TaskID) (Store, error) {
+	if err := taskID.Validate(); err != nil {
+		return nil, err
+	}
+	return s.shards[s.shardIndex(taskID)], nil
+}
+
+// shardIndex returns the index of the shard owning the valid taskID.
+func (s shardedStore) shardIndex(taskID models.TaskID) int {
+	h := fnv.New32a()
+	h.Write([]byte(taskID))
+	return int(h.Sum32() % uint32(len(s.shards)))
+}
+
+// fanOut calls fn for every shard, with bounded concurrency and a per-shard
+// timeout, and returns the error of each shard.
+func (s shardedStore) fanOut(ctx context.Context, fn func(ctx context.Context, i int, shard Store) error) []error {
+	n := s.opts.Concurrency
+	if n <= 0 || n > len(s.shards) {
+		n = len(s.shards)
+	}
+	sem := make(chan struct{}, n)
+	errs := make([]error, len(s.shards))
+
+	var wg sync.WaitGroup
+	for i, shard := range s.shards {
+		wg.Add(1)
+		go func(i int, shard Store) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				errs[i] = ctx.Err()
+				return
+			}
+
+			shardCtx := ctx
+			if s.opts.Timeout > 0 {
+				var cancel context.CancelFunc
+				shardCtx, cancel = context.WithTimeout(ctx, s.opts.Timeout)
+				defer cancel()
+			}
+			errs[i] = fn(shardCtx, i, shard)
+		}(i, shard)
+	}
+	wg.Wait()
+	return errs
+}
+
+// checkShards applies the partial failure policy to the errors of a fan-out.
+// It reports whether some shards failed but their absence is tolerated. A
+// cancelled request fails rather than returning partial results.
+func (s shardedStore) checkShards(ctx context.Context, errs []error) (partial bool, err error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	failed := 0
+	for i, err := range errs {
+		if err == nil {
+			continue
+		}
+		if !s.opts.AllowPartial {
+			return false, fmt.Errorf("shard %d: %w", i, err)
+		}
+		failed++
+	}
+	if failed > 0 && failed == len(errs) {
+		return false, fmt.Errorf("all %d shards failed, first: %w", failed, errs[0])
+	}
+	return failed > 0, nil
+}
+
+func (s shardedStore) Ping(ctx context.Context) error {
+	for i, err := range s.fanOut(ctx, func(ctx context.Context, _ int, shard Store) error {
+		return shard.Ping(ctx)
+	}) {
+		if err != nil {
+			return fmt.Errorf("shard %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func (s shardedStore) InsertToDo(ctx context.Context, task models.ToDoItem) (models.TaskID, error) {
+	// The ID is picked here rather than by the database, so that it can be
+	// routed to its shard.
+	if task.ID == "" {
+		task.ID = models.NewTaskID()
+	}
+	shard, err := s.shardFor(task.ID)
+	if err != nil {
+		return "", err
+	}
+	return shard.InsertToDo(ctx, task)
+}
+
+func (s shardedStore) CompleteToDo(ctx context.Context, taskID models.TaskID) (models.TaskID, error) {
+	shard, err := s.shardFor(taskID)
+	if err != nil {
+		return "", err
+	}
+	return shard.CompleteToDo(ctx, taskID)
+}
+
+func (s shardedStore) UnDoToDo(ctx context.Context, taskID models.TaskID) (models.TaskID, error) {
+	shard, err := s.shardFor(taskID)
+	if err != nil {
+		return "", err
+	}
+	return shard.UnDoToDo(ctx, taskID)
+}
+
+func (s shardedStore) UpdateToDo(ctx context.Context, taskID models.TaskID, updates models.ToDoUpdate) (models.TaskID, error) {
+	shard, err := s.shardFor(taskID)
+	if err != nil {
+		return "", err
+	}
+	return shard.UpdateToDo(ctx, taskID, updates)
+}
+
+func (s shardedStore) DeleteToDo(ctx context.Context, taskID models.TaskID) (models.TaskID, error) {
+	shard, err := s.shardFor(taskID)
+	if err != nil {
+		return "", err
+	}
+	return shard.DeleteToDo(ctx, taskID)
+}
+
+// BatchToDo sends each shard the part of the batch it owns, in parallel. The
+// todos of a failing shard are reported as failures, the batch goes on with
+// the others.
+func (s shardedStore) BatchToDo(ctx context.Context, action models.BatchAction, ids []models.TaskID) (models.BatchResult, error) {
+	if err := action.Validate(); err != nil {
+		return models.BatchResult{}, err
+	}
+	valid, failures := batchFailures(ids)
+	owned := make([][]models.TaskID, len(s.shards))
+	for _, id := range valid {
+		i := s.shardIndex(id)
+		owned[i] = append(owned[i], id)
+	}
+
+	results := make([]models.BatchResult, len(s.shards))
+	errs := s.fanOut(ctx, func(ctx context.Context, i int, shard Store) error {
+		if len(owned[i]) == 0 {
+			return nil
+		}
+		var err error
+		results[i], err = shard.BatchToDo(ctx, action, owned[i])
+		return err
+	})
+	if err := ctx.Err(); err != nil {
+		return models.BatchResult{}, err
+	}
+
+	merged := models.BatchResult{Failures: failures}
+	for i, r := range results {
+		if errs[i] != nil {
+			for _, id := range owned[i] {
+				merged.Failures = append(merged.Failures, models.BatchFailure{ID: id, Error: fmt.Sprintf("shard %d: %v", i, errs[i])})
+			}
+			continue
+		}
+		merged.Matched += r.Matched
+		merged.Modified += r.Modified
+		merged.Failures = append(merged.Failures, r.Failures...)
+	}
+	return merged, nil
+}
+
+func (s shardedStore) FindByID(ctx context.Context, taskID models.TaskID) (models.ToDoItem, error) {
+	shard, err := s.shardFor(taskID)
+	if err != nil {
+		return models.ToDoItem{}, err
+	}
+	return shard.FindByID(ctx, taskID)
+}
+
+// GetAllToDo merges the pages of every shard, in the order of the listing.
+// When a shard page is truncated, the todos it holds past its cursor are
+// unknown, so the merged page stops at the first such cursor, or at the
+// cursor of the listing for a shard cut short before reading any todo. The
+// shards share the snapshot of the listing.
+func (s shardedStore) GetAllToDo(ctx context.Context, opts models.ListOptions) (models.ToDoPage, error) {
+	cursor, order, err := parseListing(opts)
+	if err != nil {
+		return models.ToDoPage{}, err
+	}
+	opts.Cursor = cursor.String()
+
+	pages := make([]models.ToDoPage, len(s.shards))
+	partial, err := s.checkShards(ctx, s.fanOut(ctx, func(ctx context.Context, i int, shard Store) error {
+		var err error
+		pages[i], err = shard.GetAllToDo(ctx, opts)
+		return err
+	}))
+	if err != nil {
+		return models.ToDoPage{}, err
+	}
+
+	var (
+		todos    []models.ToDoItem
+		boundary listCursor
+		bounded  bool
+	)
+	for _, page := range pages {
+		todos = append(todos, page.Todos...)
+		partial = partial || page.Partial
+		if !page.Truncated {
+			continue
+		}
+		last := cursor
+		if len(page.Todos) > 0 {
+			last = cursor.past(page.Todos[len(page.Todos)-1])
+		}
+		// The start of the listing comes before any todo.
+		if !bounded || last.After == "" || (boundary.After != "" && order.less(last.last(), boundary.last())) {
+			boundary, bounded = last, true
+		}
+	}
+	sort.Slice(todos, func(i, j int) bool { return order.less(todos[i], todos[j]) })
+
+	merged := models.ToDoPage{Todos: todos, Partial: partial}
+	if bounded {
+		n := sort.Search(len(todos), func(i int) bool { return order.after(boundary, todos[i]) })
+		merged.Todos = todos[:n]
+		merged.Truncated = true
+		merged.Cursor = boundary.String()
+	}
+	return merged, nil
+}
+
+func (s shardedStore) FindSimilarToDo(ctx context.Context, task string) ([]models.ToDoItem, error) {
+	results := make([][]models.ToDoItem, len(s.shards))
+	_, err := s.checkShards(ctx, s.fanOut(ctx, func(ctx context.Context, i int, shard Store) error {
+		var err error
+		results[i], err = shard.FindSimilarToDo(ctx, task)
+		return err
+	}))
+	if err != nil {
+		return nil, err
+	}
+
+	var todos []models.ToDoItem
+	for _, r := range results {
+		todos = append(todos, r...)
+	}
+	return todos, nil
+}
+
+// SearchToDo merges the matches of every shard, best first. The scores of
+// the shards compare: the text index scores a todo regardless of the
+// others.
+// Completions merges the latest completions of every shard.
+func (s shardedStore) Completions(ctx context.Context, limit int) ([]Completion, error) {
+	results := make([][]Completion, len(s.shards))
+	_, err := s.checkShards(ctx, s.fanOut(ctx, func(ctx context.Context, i int, shard Store) error {
+		var err error
+		results[i], err = shard.Completions(ctx, limit)
+		return err
+	}))
+	if err != nil {
+		return nil, err
+	}
+
+	var merged []Completion
+	for _, r := range results {
+		merged = append(merged, r...)
+	}
+	return latestCompletions(merged, limit), nil
+}
+
+// ClaimReminders claims up to limit reminders from every shard. The
+// reminders claimed are returned even when some shards fail, since they're
+// cleared already.
+func (s shardedStore) ClaimReminders(ctx context.Context, now time.Time, limit int) ([]models.ToDoItem, error) {
+	results := make([][]models.ToDoItem, len(s.shards))
+	_, err := s.checkShards(ctx, s.fanOut(ctx, func(ctx context.Context, i int, shard Store) error {
+		var err error
+		results[i], err = shard.ClaimReminders(ctx, now, limit)
+		return err
+	}))
+
+	var merged []models.ToDoItem
+	for _, r := range results {
+		merged = append(merged, r...)
+	}
+	return merged, err
+}
+
+func (s shardedStore) SearchToDo(ctx context.Context, q string) ([]models.SearchResult, error) {
+	results := make([][]models.SearchResult, len(s.shards))
+	_, err := s.checkShards(ctx, s.fanOut(ctx, func(ctx context.Context, i int, shard Store) error {
+		var err error
+		results[i], err = shard.SearchToDo(ctx, q)
+		return err
+	}))
+	if err != nil {
+		return nil, err
+	}
+
+	var merged []models.SearchResult
+	for _, r := range results {
+		merged = append(merged, r...)
+	}
+	return rankResults(merged), nil
+}
+
+// WatchToDos merges the changes of every shard. It fails if any shard
+// can't be watched. The changes end once those of any shard do, so that
+// the watcher knows it misses some.
+func (s shardedStore) WatchToDos(ctx context.Context) (<-chan models.ToDoEvent, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	streams := make([]<-chan models.ToDoEvent, len(s.shards))
+	for i, shard := range s.shards {
+		var err error
+		if streams[i], err = shard.WatchToDos(ctx); err != nil {
+			cancel()
+			return nil, fmt.Errorf("shard %d: %w", i, err)
+		}
+	}
+	merged := make(chan models.ToDoEvent, watchBuffer)
+	var wg sync.WaitGroup
+	for _, changes := range streams {
+		wg.Add(1)
+		go func(changes <-chan models.ToDoEvent) {
+			defer wg.Done()
+			defer cancel()
+			for e := range changes {
+				select {
+				case merged <- e:
+				case <-ctx.Done():
+				}
+			}
+		}(changes)
+	}
+	go func() {
+		wg.Wait()
+		cancel()
+		close(merged)
+	}()
+	return merged, nil
+}
@@ -0,0 +1,202 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"ray.vhatt/todo-gokit/pkg/models"
+)
+
+// fakeShard is a Store listing its todos by pages of max items, as of the
+// snapshot of the listing like the Mongo store. Its next listing runs out of
+// time before reading any todo when cutShort is set.
+type fakeShard struct {
+	Store
+	todos    []models.ToDoItem
+	max      int
+	err      error
+	cutShort bool
+}
+
+func (f *fakeShard) GetAllToDo(_ context.Context, opts models.ListOptions) (models.ToDoPage, error) {
+	if f.err != nil {
+		return models.ToDoPage{}, f.err
+	}
+	cursor, err := parseListCursor(opts.Cursor)
+	if err != nil {
+		return models.ToDoPage{}, err
+	}
+	if f.cutShort {
+		f.cutShort = false
+		return cursor.partialPage(nil), nil
+	}
+	var page models.ToDoPage
+	for _, t := range f.todos {
+		if t.ID <= cursor.After || t.ID.String() >= cursor.createdAfter().Hex() {
+			continue
+		}
+		if len(page.Todos) == f.max {
+			page.Truncated = true
+			page.Cursor = listCursor{After: page.Todos[f.max-1].ID, Snapshot: cursor.Snapshot}.String()
+			break
+		}
+		page.Todos = append(page.Todos, t)
+	}
+	return page, nil
+}
+
+func (f *fakeShard) BatchToDo(_ context.Context, _ models.BatchAction, ids []models.TaskID) (models.BatchResult, error) {
+	if f.err != nil {
+		return models.BatchResult{}, f.err
+	}
+	var result models.BatchResult
+	for _, id := range ids {
+		for _, t := range f.todos {
+			if t.ID == id {
+				result.Matched++
+				result.Modified++
+			}
+		}
+	}
+	return result, nil
+}
+
+func TestShardedGetAllToDo(t *testing.T) {
+	ids := make([]models.TaskID, 9)
+	for i := range ids {
+		ids[i] = models.NewTaskID()
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	todo := func(i int) models.ToDoItem { return models.ToDoItem{ID: ids[i]} }
+
+	a := &fakeShard{todos: []models.ToDoItem{todo(0), todo(3), todo(6)}, max: 2}
+	b := &fakeShard{todos: []models.ToDoItem{todo(1), todo(4), todo(7)}, max: 2}
+	c := &fakeShard{todos: []models.ToDoItem{todo(2), todo(5), todo(8)}, max: 5}
+	s := NewShardedStore([]Store{a, b, c}, ShardOptions{Concurrency: 2})
+
+	// a and b are truncated after 3 and 4, so nothing past 3 is known yet.
+	var seen []models.TaskID
+	page, err := s.GetAllToDo(context.Background(), models.ListOptions{})
+	for {
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, item := range page.Todos {
+			seen = append(seen, item.ID)
+		}
+		if !page.Truncated {
+			break
+		}
+		page, err = s.GetAllToDo(context.Background(), models.ListOptions{Cursor: page.Cursor})
+	}
+
+	if want, have := len(ids), len(seen); want != have {
+		t.Fatalf("want %d todos, have %d", want, have)
+	}
+	for i := range ids {
+		if ids[i] != seen[i] {
+			t.Errorf("todo %d: want %s, have %s", i, ids[i], seen[i])
+		}
+	}
+}
+
+func TestShardedCutShort(t *testing.T) {
+	a := &fakeShard{todos: []models.ToDoItem{{ID: models.NewTaskID()}}, max: 5}
+	b := &fakeShard{todos: []models.ToDoItem{{ID: models.NewTaskID()}}, max: 5, cutShort: true}
+	s := NewShardedStore([]Store{a, b}, ShardOptions{})
+
+	// What b holds is unknown, so a's todo can't be listed yet.
+	page, err := s.GetAllToDo(context.Background(), models.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Todos) != 0 || !page.Partial || !page.Truncated {
+		t.Fatalf("want an empty partial page, have %+v", page)
+	}
+	if page, err = s.GetAllToDo(context.Background(), models.ListOptions{Cursor: page.Cursor}); err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Todos) != 2 || page.Partial || page.Truncated {
+		t.Errorf("want both todos once resumed, have %+v", page)
+	}
+}
+
+func TestShardedPartialFailure(t *testing.T) {
+	up := &fakeShard{todos: []models.ToDoItem{{ID: models.NewTaskID()}}, max: 10}
+	down := &fakeShard{err: errors.New("shard down")}
+
+	strict := NewShardedStore([]Store{up, down}, ShardOptions{})
+	if _, err := strict.GetAllToDo(context.Background(), models.ListOptions{}); err == nil {
+		t.Error("want error from a failed shard, have none")
+	}
+
+	lenient := NewShardedStore([]Store{up, down}, ShardOptions{AllowPartial: true})
+	page, err := lenient.GetAllToDo(context.Background(), models.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !page.Partial || len(page.Todos) != 1 {
+		t.Errorf("want 1 todo flagged partial, have %d todos, partial %v", len(page.Todos), page.Partial)
+	}
+
+	allDown := NewShardedStore([]Store{down, down}, ShardOptions{AllowPartial: true})
+	if _, err := allDown.GetAllToDo(context.Background(), models.ListOptions{}); err == nil {
+		t.Error("want error when every shard failed, have none")
+	}
+}
+
+func TestShardedBatchToDo(t *testing.T) {
+	up := &fakeShard{}
+	down := &fakeShard{err: errors.New("shard down")}
+	s := NewShardedStore([]Store{up, down}, ShardOptions{})
+
+	var ids []models.TaskID
+	for owned := [2]int{}; owned[0] < 2 || owned[1] < 1; {
+		id := models.NewTaskID()
+		i := s.(shardedStore).shardIndex(id)
+		if (i == 0 && owned[0] < 2) || (i == 1 && owned[1] < 1) {
+			owned[i]++
+			ids = append(ids, id)
+			if i == 0 {
+				up.todos = append(up.todos, models.ToDoItem{ID: id})
+			}
+		}
+	}
+	result, err := s.BatchToDo(context.Background(), models.BatchComplete, append(ids, "bogus"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Matched != 2 || result.Modified != 2 || len(result.Failures) != 2 {
+		t.Errorf("want the todos of the healthy shard completed, and the others failed, have %+v", result)
+	}
+}
+
+// blockingShard is a Store whose listings hang until their context is done.
+type blockingShard struct {
+	Store
+}
+
+func (blockingShard) GetAllToDo(ctx context.Context, _ models.ListOptions) (models.ToDoPage, error) {
+	<-ctx.Done()
+	return models.ToDoPage{}, ctx.Err()
+}
+
+func TestShardedCancellation(t *testing.T) {
+	up := &fakeShard{todos: []models.ToDoItem{{ID: models.NewTaskID()}}, max: 10}
+	s := NewShardedStore([]Store{up, blockingShard{}}, ShardOptions{AllowPartial: true})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+
+	begin := time.Now()
+	_, err := s.GetAllToDo(ctx, models.ListOptions{})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("want %v, have %v", context.Canceled, err)
+	}
+	if took := time.Since(begin); took > time.Second {
+		t.Errorf("cancelled listing took %s", took)
+	}
+}
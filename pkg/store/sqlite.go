@@ -0,0 +1,711 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	// Registers the sqlite3 database/sql driver.
+	_ "github.com/mattn/go-sqlite3"
+
+	"ray.vhatt/todo-gokit/pkg/models"
+	"ray.vhatt/todo-gokit/pkg/query"
+	"ray.vhatt/todo-gokit/pkg/tenant"
+)
+
+// sqliteSchema bootstraps a database. Times are Unix nanoseconds, created is
+// when the todo was inserted, to list it as of the snapshot of a listing.
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS todos (
+	id           TEXT PRIMARY KEY,
+	task         TEXT NOT NULL,
+	status       INTEGER NOT NULL DEFAULT 0,
+	schedule_at  INTEGER,
+	created      INTEGER NOT NULL,
+	due_at       INTEGER,
+	priority     INTEGER NOT NULL DEFAULT 2,
+	completed_at INTEGER,
+	recurrence   TEXT NOT NULL DEFAULT '',
+	remind_at    INTEGER,
+	custom_fields TEXT NOT NULL DEFAULT '',
+	state        TEXT NOT NULL DEFAULT '',
+	tenant       TEXT NOT NULL DEFAULT ''
+);
+CREATE INDEX IF NOT EXISTS todos_created ON todos (created);
+`
+
+// completedAtSQL sets completed_at along with a status, given as the status
+// then the time: to the time unless the todo was done already, or to NULL.
+const completedAtSQL = "completed_at = CASE WHEN ? THEN COALESCE(completed_at, ?) END"
+
+// sqliteColumns are the columns added to the schema since its first
+// version, with their definitions.
+var sqliteColumns = []struct{ name, definition string }{
+	{"due_at", "INTEGER"},
+	{"priority", "INTEGER NOT NULL DEFAULT 2"},
+	{"completed_at", "INTEGER"},
+	{"recurrence", "TEXT NOT NULL DEFAULT ''"},
+	{"remind_at", "INTEGER"},
+	{"custom_fields", "TEXT NOT NULL DEFAULT ''"},
+	{"state", "TEXT NOT NULL DEFAULT ''"},
+	{"tenant", "TEXT NOT NULL DEFAULT ''"},
+}
+
+// sqliteIndexes are created once the columns they index are migrated.
+const sqliteIndexes = `
+CREATE INDEX IF NOT EXISTS todos_due ON todos (due_at) WHERE due_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS todos_priority ON todos (priority, id);
+CREATE INDEX IF NOT EXISTS todos_completed ON todos (completed_at) WHERE completed_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS todos_remind ON todos (remind_at) WHERE remind_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS todos_tenant ON todos (tenant, id);
+`
+
+type sqliteStore struct {
+	db      *sql.DB
+	maxList int64
+	feed    feed
+}
+
+// NewSQLiteStore returns a Store keeping the todos in the SQLite database
+// file at path, created if need be, so the service runs as a single binary
+// with local persistence. The database is put in WAL mode, readers don't
+// wait for writers. Listings are paged like the Mongo store's with the
+// DefaultGuardrails.
+func NewSQLiteStore(path string) (Store, error) {
+	dsn := "file:" + path + "?_journal_mode=WAL&_busy_timeout=5000&_synchronous=NORMAL"
+	db, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		return nil, err
+	}
+	if err := bootstrapSQLite(db); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("bootstrapping %s: %w", path, err)
+	}
+	return &sqliteStore{db: db, maxList: DefaultGuardrails.MaxListSize}, nil
+}
+
+// bootstrapSQLite creates the schema, adding the columns a database created
+// by an older version lacks.
+func bootstrapSQLite(db *sql.DB) error {
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		return err
+	}
+	rows, err := db.Query("PRAGMA table_info(todos)")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	has := make(map[string]bool)
+	for rows.Next() {
+		var (
+			cid, notNull, pk int
+			name, kind       string
+			dflt             sql.NullString
+		)
+		if err := rows.Scan(&cid, &name, &kind, &notNull, &dflt, &pk); err != nil {
+			return err
+		}
+		has[name] = true
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for _, column := range sqliteColumns {
+		if has[column.name] {
+			continue
+		}
+		if _, err := db.Exec("ALTER TABLE todos ADD COLUMN " + column.name + " " + column.definition); err != nil {
+			return err
+		}
+	}
+	_, err = db.Exec(sqliteIndexes)
+	return err
+}
+
+func openSQLite(cfg Config) (Store, error) {
+	u, err := url.Parse(cfg.URI)
+	if err != nil {
+		return nil, err
+	}
+	// Both sqlite:todos.db and sqlite:///var/lib/todos.db are accepted.
+	path := u.Opaque
+	if path == "" {
+		path = u.Path
+	}
+	if path == "" {
+		return nil, fmt.Errorf("no database file in %q", cfg.URI)
+	}
+	return NewSQLiteStore(path)
+}
+
+func (s *sqliteStore) Ping(ctx context.Context) error {
+	return s.db.PingContext(ctx)
+}
+
+func (s *sqliteStore) InsertToDo(ctx context.Context, task models.ToDoItem) (models.TaskID, error) {
+	if task.ID == "" {
+		task.ID = models.NewTaskID()
+	} else if err := task.ID.Validate(); err != nil {
+		return "", err
+	}
+	customFields, err := customFieldsValue(task.CustomFields)
+	if err != nil {
+		return "", err
+	}
+	_, err = s.db.ExecContext(ctx,
+		"INSERT INTO todos (id, task, status, state, schedule_at, due_at, priority, completed_at, recurrence, remind_at, custom_fields, tenant, created) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		task.ID.String(), task.Task, task.Status, task.State, nanos(task.ScheduleAt), nanos(task.DueDate), task.Priority.Rank(), nanos(completedAt(task.Status)), task.Recurrence, nanos(task.RemindAt), customFields, tenant.FromContext(ctx), time.Now().UnixNano())
+	if err != nil {
+		return "", err
+	}
+	s.publish(ctx, models.ToDoCreated, task.ID)
+	return task.ID, nil
+}
+
+func (s *sqliteStore) CompleteToDo(ctx context.Context, taskID models.TaskID) (models.TaskID, error) {
+	status := true
+	return s.UpdateToDo(ctx, taskID, models.ToDoUpdate{Status: &status})
+}
+
+func (s *sqliteStore) UnDoToDo(ctx context.Context, taskID models.TaskID) (models.TaskID, error) {
+	status := false
+	return s.UpdateToDo(ctx, taskID, models.ToDoUpdate{Status: &status})
+}
+
+// UpdateToDo sets the fields of the todo that updates sets, leaving the
+// others alone, or returns ErrToDoNotFound like on Mongo, for the todos of
+// another tenant too. The custom fields are merged with those of the todo,
+// and whether it's completed is told, in a transaction.
+func (s *sqliteStore) UpdateToDo(ctx context.Context, taskID models.TaskID, updates models.ToDoUpdate) (models.TaskID, error) {
+	if err := taskID.Validate(); err != nil {
+		return "", err
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+	var flipped bool
+	if updates.Status != nil && *updates.Status {
+		res, err := tx.ExecContext(ctx, "UPDATE todos SET status = 1 WHERE id = ? AND tenant = ? AND status = 0", taskID.String(), tenant.FromContext(ctx))
+		if err != nil {
+			return "", err
+		}
+		flipped = found(res) == nil
+	}
+	var set []string
+	var args []interface{}
+	if len(updates.CustomFields) > 0 {
+		var current string
+		err := tx.QueryRowContext(ctx, "SELECT custom_fields FROM todos WHERE id = ? AND tenant = ?", taskID.String(), tenant.FromContext(ctx)).Scan(&current)
+		if err == sql.ErrNoRows {
+			return "", ErrToDoNotFound
+		}
+		if err != nil {
+			return "", err
+		}
+		fields, err := scanCustomFields(current)
+		if err != nil {
+			return "", err
+		}
+		value, err := customFieldsValue(fields.Merge(updates.CustomFields))
+		if err != nil {
+			return "", err
+		}
+		set, args = append(set, "custom_fields = ?"), append(args, value)
+	}
+	if updates.Task != nil {
+		set, args = append(set, "task = ?"), append(args, *updates.Task)
+	}
+	if updates.Status != nil {
+		set, args = append(set, "status = ?", completedAtSQL), append(args, *updates.Status, *updates.Status, time.Now().UnixNano())
+	}
+	switch {
+	case updates.State != nil:
+		set, args = append(set, "state = ?"), append(args, *updates.State)
+	case updates.Status != nil:
+		set = append(set, "state = ''")
+	}
+	if updates.ScheduleAt != nil {
+		set, args = append(set, "schedule_at = ?"), append(args, nanos(updates.ScheduleAt))
+	}
+	if updates.DueDate != nil {
+		set, args = append(set, "due_at = ?"), append(args, nanos(updates.DueDate))
+	}
+	if updates.Priority != nil {
+		set, args = append(set, "priority = ?"), append(args, updates.Priority.Rank())
+	}
+	if updates.Recurrence != nil {
+		set, args = append(set, "recurrence = ?"), append(args, *updates.Recurrence)
+	}
+	if updates.RemindAt != nil {
+		set, args = append(set, "remind_at = ?"), append(args, nanos(updates.RemindAt))
+	}
+	if len(set) == 0 {
+		return taskID, nil
+	}
+	args = append(args, taskID.String(), tenant.FromContext(ctx))
+	res, err := tx.ExecContext(ctx, "UPDATE todos SET "+strings.Join(set, ", ")+" WHERE id = ? AND tenant = ?", args...)
+	if err != nil {
+		return "", err
+	}
+	if err := found(res); err != nil {
+		return "", err
+	}
+	if err := tx.Commit(); err != nil {
+		return "", err
+	}
+	s.publish(ctx, models.ToDoUpdated, taskID)
+	if flipped {
+		return taskID, completed(ctx, s, taskID)
+	}
+	return taskID, nil
+}
+
+func (s *sqliteStore) DeleteToDo(ctx context.Context, taskID models.TaskID) (models.TaskID, error) {
+	if err := taskID.Validate(); err != nil {
+		return "", err
+	}
+	res, err := s.db.ExecContext(ctx, "DELETE FROM todos WHERE id = ? AND tenant = ?", taskID.String(), tenant.FromContext(ctx))
+	if err != nil {
+		return "", err
+	}
+	if err := found(res); err != nil {
+		return "", err
+	}
+	s.publish(ctx, models.ToDoDeleted, taskID)
+	return taskID, nil
+}
+
+// found returns ErrToDoNotFound unless res matched a todo.
+func found(res sql.Result) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrToDoNotFound
+	}
+	return nil
+}
+
+// BatchToDo applies action to the todos ids, like the Mongo store, in a
+// single transaction.
+func (s *sqliteStore) BatchToDo(ctx context.Context, action models.BatchAction, ids []models.TaskID) (models.BatchResult, error) {
+	if err := action.Validate(); err != nil {
+		return models.BatchResult{}, err
+	}
+	valid, failures := batchFailures(ids)
+	result := models.BatchResult{Failures: failures}
+	if len(valid) == 0 {
+		return result, nil
+	}
+	in := "(?" + strings.Repeat(", ?", len(valid)-1) + ") AND tenant = ?"
+	args := make([]interface{}, len(valid), len(valid)+1)
+	for i, id := range valid {
+		args[i] = id.String()
+	}
+	args = append(args, tenant.FromContext(ctx))
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.BatchResult{}, err
+	}
+	defer tx.Rollback()
+	status := action == models.BatchComplete
+	rows, err := tx.QueryContext(ctx, "SELECT id, status FROM todos WHERE id IN "+in, args...)
+	if err != nil {
+		return models.BatchResult{}, err
+	}
+	var changed []models.TaskID
+	for rows.Next() {
+		var (
+			id   string
+			done bool
+		)
+		if err := rows.Scan(&id, &done); err != nil {
+			rows.Close()
+			return models.BatchResult{}, err
+		}
+		result.Matched++
+		if action == models.BatchDelete || done != status {
+			changed = append(changed, models.TaskID(id))
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return models.BatchResult{}, err
+	}
+	var res sql.Result
+	if action == models.BatchDelete {
+		res, err = tx.ExecContext(ctx, "DELETE FROM todos WHERE id IN "+in, args...)
+	} else {
+		res, err = tx.ExecContext(ctx, "UPDATE todos SET status = ?, "+completedAtSQL+", state = '' WHERE status != ? AND id IN "+in, append([]interface{}{status, status, time.Now().UnixNano(), status}, args...)...)
+	}
+	if err != nil {
+		return models.BatchResult{}, err
+	}
+	if result.Modified, err = res.RowsAffected(); err != nil {
+		return models.BatchResult{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return models.BatchResult{}, err
+	}
+	if action == models.BatchDelete {
+		s.publish(ctx, models.ToDoDeleted, changed...)
+	} else {
+		s.publish(ctx, models.ToDoUpdated, changed...)
+	}
+	return result, nil
+}
+
+// Completions returns the limit todos done most recently, latest first,
+// like the Mongo store's.
+func (s *sqliteStore) Completions(ctx context.Context, limit int) ([]Completion, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT completed_at, created FROM todos WHERE tenant = ? AND completed_at IS NOT NULL ORDER BY completed_at DESC LIMIT ?", tenant.FromContext(ctx), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var completions []Completion
+	for rows.Next() {
+		var doneAt, created int64
+		if err := rows.Scan(&doneAt, &created); err != nil {
+			return nil, err
+		}
+		completions = append(completions, Completion{At: time.Unix(0, doneAt), Took: time.Duration(doneAt - created)})
+	}
+	return completions, rows.Err()
+}
+
+// ClaimReminders returns the limit pending todos of every tenant whose
+// reminder is due at now, earliest first, and clears their reminders in the
+// same transaction, like the Mongo store.
+func (s *sqliteStore) ClaimReminders(ctx context.Context, now time.Time, limit int) ([]models.ToDoItem, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	rows, err := tx.QueryContext(ctx, "SELECT id, task, status, state, schedule_at, due_at, priority, completed_at, recurrence, remind_at, custom_fields, tenant FROM todos WHERE remind_at <= ? AND status = 0 ORDER BY remind_at LIMIT ?", now.UnixNano(), limit)
+	if err != nil {
+		return nil, err
+	}
+	var todos []models.ToDoItem
+	for rows.Next() {
+		todo, err := scanToDo(rows)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		todos = append(todos, todo)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(todos) == 0 {
+		return nil, nil
+	}
+	args := make([]interface{}, len(todos))
+	for i, todo := range todos {
+		args[i] = todo.ID.String()
+	}
+	if _, err := tx.ExecContext(ctx, "UPDATE todos SET remind_at = NULL WHERE id IN (?"+strings.Repeat(", ?", len(todos)-1)+")", args...); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	for _, todo := range todos {
+		claimed := todo
+		claimed.RemindAt = nil
+		s.feed.publish(change(models.ToDoUpdated, claimed))
+	}
+	return todos, nil
+}
+
+// WatchToDos returns the changes of the todos made through s from now on,
+// until ctx is done. SQLite has no change stream: the changes made by other
+// processes sharing the database aren't seen.
+func (s *sqliteStore) WatchToDos(ctx context.Context) (<-chan models.ToDoEvent, error) {
+	return s.feed.watch(ctx), nil
+}
+
+// publish hands the changes of the todos ids of the tenant of ctx, as
+// eventType, to the watchers. The todos are looked up unless deleted, those
+// missing weren't changed.
+func (s *sqliteStore) publish(ctx context.Context, eventType string, ids ...models.TaskID) {
+	if !s.feed.watched() {
+		return
+	}
+	for _, id := range ids {
+		if eventType == models.ToDoDeleted {
+			s.feed.publish(models.ToDoEvent{Type: eventType, TaskID: id, Tenant: tenant.FromContext(ctx)})
+			continue
+		}
+		if todo, err := s.FindByID(ctx, id); err == nil {
+			s.feed.publish(change(eventType, todo))
+		}
+	}
+}
+
+// FindByID returns the todo with taskID, or ErrToDoNotFound when there's none
+// for the tenant of ctx.
+func (s *sqliteStore) FindByID(ctx context.Context, taskID models.TaskID) (models.ToDoItem, error) {
+	if err := taskID.Validate(); err != nil {
+		return models.ToDoItem{}, err
+	}
+	row := s.db.QueryRowContext(ctx, "SELECT id, task, status, state, schedule_at, due_at, priority, completed_at, recurrence, remind_at, custom_fields, tenant FROM todos WHERE id = ? AND tenant = ?", taskID.String(), tenant.FromContext(ctx))
+	todo, err := scanToDo(row)
+	if err == sql.ErrNoRows {
+		return models.ToDoItem{}, ErrToDoNotFound
+	}
+	return todo, err
+}
+
+// GetAllToDo lists the todos like the Mongo store. The statuses are
+// filtered by the database, queries as the rows are read.
+func (s *sqliteStore) GetAllToDo(ctx context.Context, opts models.ListOptions) (models.ToDoPage, error) {
+	cursor, order, err := parseListing(opts)
+	if err != nil {
+		return models.ToDoPage{}, err
+	}
+	var q query.Expr
+	if opts.Query != "" {
+		if q, err = query.Parse(opts.Query); err != nil {
+			return models.ToDoPage{}, err
+		}
+	}
+
+	op, dir := ">", ""
+	if order.desc {
+		op, dir = "<", " DESC"
+	}
+	stmt := "SELECT id, task, status, state, schedule_at, due_at, priority, completed_at, recurrence, remind_at, custom_fields, tenant FROM todos WHERE tenant = ? AND created <= ?"
+	args := []interface{}{tenant.FromContext(ctx), cursor.Snapshot.UnixNano()}
+	switch {
+	case cursor.After == "":
+	case order.byTask:
+		stmt += " AND (task " + op + " ? OR (task = ? AND id " + op + " ?))"
+		args = append(args, cursor.Task, cursor.Task, cursor.After.String())
+	case order.byPriority:
+		stmt += " AND (priority " + op + " ? OR (priority = ? AND id " + op + " ?))"
+		args = append(args, cursor.Priority, cursor.Priority, cursor.After.String())
+	default:
+		stmt += " AND id " + op + " ?"
+		args = append(args, cursor.After.String())
+	}
+	if !opts.Scheduled {
+		stmt += " AND (schedule_at IS NULL OR schedule_at <= ?)"
+		args = append(args, cursor.Snapshot.UnixNano())
+	}
+	if opts.Status != "" {
+		stmt += " AND status = ?"
+		args = append(args, opts.Status == models.StatusDone)
+	}
+	if opts.Overdue {
+		stmt += " AND status = 0"
+	}
+	after, before := cursor.dueRange(opts)
+	if after != nil {
+		stmt += " AND due_at > ?"
+		args = append(args, after.UnixNano())
+	}
+	if before != nil {
+		stmt += " AND due_at < ?"
+		args = append(args, before.UnixNano())
+	}
+	if opts.Priority != "" {
+		stmt += " AND priority = ?"
+		args = append(args, opts.Priority.Rank())
+	}
+	switch {
+	case order.byTask:
+		stmt += " ORDER BY task" + dir + ", id" + dir
+	case order.byPriority:
+		stmt += " ORDER BY priority" + dir + ", id" + dir
+	default:
+		stmt += " ORDER BY id" + dir
+	}
+
+	listCtx, cancel := listContext(ctx)
+	defer cancel()
+	rows, err := s.db.QueryContext(listCtx, stmt, args...)
+	if err != nil {
+		if cutShort(ctx, listCtx) {
+			return cursor.partialPage(nil), nil
+		}
+		return models.ToDoPage{}, err
+	}
+	defer rows.Close()
+
+	var todos []models.ToDoItem
+	for rows.Next() {
+		todo, err := scanToDo(rows)
+		if err != nil {
+			return models.ToDoPage{}, err
+		}
+		if q != nil && !q.Match(todo) {
+			continue
+		}
+		todos = append(todos, todo)
+		// One more than needed tells whether the page is truncated.
+		if s.maxList > 0 && int64(len(todos)) > s.maxList {
+			break
+		}
+	}
+	if err := rows.Err(); err != nil {
+		if cutShort(ctx, listCtx) {
+			return cursor.partialPage(todos), nil
+		}
+		return models.ToDoPage{}, err
+	}
+
+	page := models.ToDoPage{Todos: todos}
+	if s.maxList > 0 && int64(len(todos)) > s.maxList {
+		page.Todos = todos[:s.maxList]
+		page.Truncated = true
+		page.Cursor = cursor.past(page.Todos[s.maxList-1]).String()
+	}
+	return page, nil
+}
+
+// FindSimilarToDo returns the todos sharing at least one word with task,
+// like the Mongo store.
+func (s *sqliteStore) FindSimilarToDo(ctx context.Context, task string) ([]models.ToDoItem, error) {
+	words := strings.Fields(strings.ToLower(task))
+	if len(words) == 0 {
+		return nil, nil
+	}
+	where, args := containsAny(words)
+	return s.selectToDos(ctx, "SELECT id, task, status, state, schedule_at, due_at, priority, completed_at, recurrence, remind_at, custom_fields, tenant FROM todos WHERE tenant = ? AND "+where+" ORDER BY id LIMIT ?", append(append([]interface{}{tenant.FromContext(ctx)}, args...), similarCandidateLimit)...)
+}
+
+// SearchToDo returns the todos whose task has words of q, scored by
+// textScore. The database only picks the tasks containing them.
+func (s *sqliteStore) SearchToDo(ctx context.Context, q string) ([]models.SearchResult, error) {
+	terms := searchTerms(q)
+	if len(terms) == 0 {
+		return nil, nil
+	}
+	where, args := containsAny(terms)
+	todos, err := s.selectToDos(ctx, "SELECT id, task, status, state, schedule_at, due_at, priority, completed_at, recurrence, remind_at, custom_fields, tenant FROM todos WHERE tenant = ? AND "+where, append([]interface{}{tenant.FromContext(ctx)}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+	var results []models.SearchResult
+	for _, todo := range todos {
+		if score := textScore(terms, todo.Task); score > 0 {
+			results = append(results, models.SearchResult{ToDoItem: todo, Score: score})
+		}
+	}
+	return rankResults(results), nil
+}
+
+// containsAny returns the condition matching the tasks containing any of
+// words, ignoring case, and its arguments.
+func containsAny(words []string) (string, []interface{}) {
+	likes := make([]string, len(words))
+	args := make([]interface{}, len(words))
+	escape := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	for i, w := range words {
+		likes[i] = `lower(task) LIKE ? ESCAPE '\'`
+		args[i] = "%" + escape.Replace(w) + "%"
+	}
+	return "(" + strings.Join(likes, " OR ") + ")", args
+}
+
+// selectToDos runs stmt, selecting id, task, status, state, schedule_at,
+// due_at, priority, completed_at, recurrence, remind_at, custom_fields,
+// tenant.
+func (s *sqliteStore) selectToDos(ctx context.Context, stmt string, args ...interface{}) ([]models.ToDoItem, error) {
+	rows, err := s.db.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var todos []models.ToDoItem
+	for rows.Next() {
+		todo, err := scanToDo(rows)
+		if err != nil {
+			return nil, err
+		}
+		todos = append(todos, todo)
+	}
+	return todos, rows.Err()
+}
+
+// scanToDo reads a todo selected as id, task, status, state, schedule_at,
+// due_at, priority, completed_at, recurrence, remind_at, custom_fields,
+// tenant.
+func scanToDo(row interface{ Scan(...interface{}) error }) (models.ToDoItem, error) {
+	var (
+		todo       models.ToDoItem
+		id         string
+		scheduleAt sql.NullInt64
+		dueAt      sql.NullInt64
+		priority   int
+		doneAt     sql.NullInt64
+		remindAt   sql.NullInt64
+		fields     string
+	)
+	if err := row.Scan(&id, &todo.Task, &todo.Status, &todo.State, &scheduleAt, &dueAt, &priority, &doneAt, &todo.Recurrence, &remindAt, &fields, &todo.Tenant); err != nil {
+		return models.ToDoItem{}, err
+	}
+	var err error
+	if todo.CustomFields, err = scanCustomFields(fields); err != nil {
+		return models.ToDoItem{}, err
+	}
+	todo.ID = models.TaskID(id)
+	todo.Priority = models.PriorityOfRank(priority)
+	if scheduleAt.Valid {
+		at := time.Unix(0, scheduleAt.Int64)
+		todo.ScheduleAt = &at
+	}
+	if dueAt.Valid {
+		at := time.Unix(0, dueAt.Int64)
+		todo.DueDate = &at
+	}
+	if doneAt.Valid {
+		at := time.Unix(0, doneAt.Int64)
+		todo.CompletedAt = &at
+	}
+	if remindAt.Valid {
+		at := time.Unix(0, remindAt.Int64)
+		todo.RemindAt = &at
+	}
+	return todo, nil
+}
+
+// customFieldsValue is the column value of custom fields: their JSON, or
+// empty without any.
+func customFieldsValue(fields models.CustomFields) (string, error) {
+	if len(fields) == 0 {
+		return "", nil
+	}
+	b, err := json.Marshal(fields)
+	return string(b), err
+}
+
+// scanCustomFields reads the custom fields of a column value.
+func scanCustomFields(value string) (models.CustomFields, error) {
+	if value == "" {
+		return nil, nil
+	}
+	var fields models.CustomFields
+	err := json.Unmarshal([]byte(value), &fields)
+	return fields, err
+}
+
+// nanos is the column value of an optional time.
+func nanos(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return t.UnixNano()
+}
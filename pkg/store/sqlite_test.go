@@ -0,0 +1,141 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"ray.vhatt/todo-gokit/pkg/models"
+)
+
+func TestSQLiteStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "todos.db")
+
+	s, err := Open(Config{URI: "sqlite:" + path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.(*sqliteStore).maxList = 2
+	ctx := context.Background()
+
+	later := time.Now().Add(time.Hour)
+	var ids []models.TaskID
+	for _, todo := range []models.ToDoItem{{Task: "water the plants"}, {Task: "b"}, {Task: "c", ScheduleAt: &later}, {Task: "d"}} {
+		id, err := s.InsertToDo(ctx, todo)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, id)
+	}
+	if _, err := s.CompleteToDo(ctx, ids[1]); err != nil {
+		t.Fatal(err)
+	}
+	if todo, err := s.FindByID(ctx, ids[1]); err != nil || todo.Task != "b" || !todo.Status {
+		t.Errorf("want b done, have %+v, %v", todo, err)
+	}
+	if todo, err := s.FindByID(ctx, ids[2]); err != nil || todo.ScheduleAt == nil || !todo.ScheduleAt.Equal(later) {
+		t.Errorf("want c scheduled at %v, have %+v, %v", later, todo, err)
+	}
+
+	var tasks []string
+	page, err := s.GetAllToDo(ctx, models.ListOptions{})
+	for {
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, todo := range page.Todos {
+			tasks = append(tasks, todo.Task)
+		}
+		if !page.Truncated {
+			break
+		}
+		// Inserted after the listing started, so left out of it.
+		s.InsertToDo(ctx, models.ToDoItem{Task: "e"})
+		page, err = s.GetAllToDo(ctx, models.ListOptions{Cursor: page.Cursor})
+	}
+	if want, have := "[water the plants b d]", fmt.Sprint(tasks); want != have {
+		t.Errorf("want %s, have %s", want, have)
+	}
+	if page, err := s.GetAllToDo(ctx, models.ListOptions{Query: "status = done"}); err != nil || len(page.Todos) != 1 {
+		t.Errorf("want b listed, have %+v, %v", page, err)
+	}
+	if similar, err := s.FindSimilarToDo(ctx, "Plants"); err != nil || len(similar) != 1 {
+		t.Errorf("want the plants found, have %+v, %v", similar, err)
+	}
+	if results, err := s.SearchToDo(ctx, "plants water"); err != nil || len(results) != 1 || results[0].Score != 2 {
+		t.Errorf("want the plants found, have %+v, %v", results, err)
+	}
+
+	result, err := s.BatchToDo(ctx, models.BatchComplete, []models.TaskID{ids[1], ids[3], models.NewTaskID(), "bogus"})
+	if err != nil || result.Matched != 2 || result.Modified != 1 || len(result.Failures) != 1 {
+		t.Errorf("want d completed, have %+v, %v", result, err)
+	}
+	if completions, err := s.Completions(ctx, 1); err != nil || len(completions) != 1 || completions[0].Took < 0 {
+		t.Errorf("want the latest completion, have %+v, %v", completions, err)
+	}
+	remind := time.Now().Add(-time.Minute)
+	if _, err := s.UpdateToDo(ctx, ids[3], models.ToDoUpdate{RemindAt: &remind}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.UpdateToDo(ctx, ids[0], models.ToDoUpdate{RemindAt: &remind}); err != nil {
+		t.Fatal(err)
+	}
+	if todos, err := s.ClaimReminders(ctx, time.Now(), 10); err != nil || len(todos) != 1 || todos[0].ID != ids[0] || !todos[0].RemindAt.Equal(remind) {
+		t.Errorf("want the reminder of the plants claimed, have %+v, %v", todos, err)
+	}
+	if todos, err := s.ClaimReminders(ctx, time.Now(), 10); err != nil || len(todos) != 0 {
+		t.Errorf("want no reminder claimed twice, have %+v, %v", todos, err)
+	}
+	if _, err := s.UpdateToDo(ctx, ids[2], models.ToDoUpdate{CustomFields: models.CustomFields{"points": 3.0, "team": "backend"}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.UpdateToDo(ctx, ids[2], models.ToDoUpdate{CustomFields: models.CustomFields{"points": 5.0, "team": nil}}); err != nil {
+		t.Fatal(err)
+	}
+	if page, err := s.GetAllToDo(ctx, models.ListOptions{Scheduled: true, Query: "customFields.points > 4"}); err != nil || len(page.Todos) != 1 || fmt.Sprint(page.Todos[0].CustomFields) != "map[points:5]" {
+		t.Errorf("want the custom fields of c merged, have %+v, %v", page, err)
+	}
+	blocked := "blocked"
+	if _, err := s.UpdateToDo(ctx, ids[1], models.ToDoUpdate{State: &blocked}); err != nil {
+		t.Fatal(err)
+	}
+	if todo, err := s.FindByID(ctx, ids[1]); err != nil || todo.State != "blocked" {
+		t.Errorf("want b blocked, have %+v, %v", todo, err)
+	}
+	if _, err := s.UnDoToDo(ctx, ids[1]); err != nil {
+		t.Fatal(err)
+	}
+	if todo, err := s.FindByID(ctx, ids[1]); err != nil || todo.CompletedAt != nil || todo.State != "" {
+		t.Errorf("want b pending, its state reset, have %+v, %v", todo, err)
+	}
+
+	if _, err := s.DeleteToDo(ctx, ids[0]); err != nil {
+		t.Fatal(err)
+	}
+	// The todos survive reopening the database.
+	s, err = NewSQLiteStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.FindByID(ctx, ids[0]); err != ErrToDoNotFound {
+		t.Errorf("want %v, have %v", ErrToDoNotFound, err)
+	}
+	if _, err := s.CompleteToDo(ctx, ids[0]); err != ErrToDoNotFound {
+		t.Errorf("want %v completing a deleted todo, have %v", ErrToDoNotFound, err)
+	}
+	if _, err := s.DeleteToDo(ctx, ids[0]); err != ErrToDoNotFound {
+		t.Errorf("want %v deleting it again, have %v", ErrToDoNotFound, err)
+	}
+	if _, err := s.FindByID(ctx, ids[3]); err != nil {
+		t.Errorf("want d kept, have %v", err)
+	}
+}
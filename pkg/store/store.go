@@ -3,135 +3,630 @@ package store
 import (
 	"context"
 	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
 
+	"github.com/go-kit/kit/log"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"ray.vhatt/todo-gokit/pkg/models"
+	"ray.vhatt/todo-gokit/pkg/query"
+	"ray.vhatt/todo-gokit/pkg/tenant"
 )
 
 type Store interface {
 	Ping(context.Context) error
-	InsertToDo(context.Context, models.ToDoItem) (string, error)
-	CompleteToDo(context.Context, string) (string, error)
-	UnDoToDo(context.Context, string) (string, error)
-	DeleteToDo(context.Context, string) (string, error)
-	GetAllToDo(context.Context) ([]models.ToDoItem, error)
+	InsertToDo(context.Context, models.ToDoItem) (models.TaskID, error)
+	CompleteToDo(context.Context, models.TaskID) (models.TaskID, error)
+	UnDoToDo(context.Context, models.TaskID) (models.TaskID, error)
+	UpdateToDo(context.Context, models.TaskID, models.ToDoUpdate) (models.TaskID, error)
+	DeleteToDo(context.Context, models.TaskID) (models.TaskID, error)
+	BatchToDo(context.Context, models.BatchAction, []models.TaskID) (models.BatchResult, error)
+	FindByID(context.Context, models.TaskID) (models.ToDoItem, error)
+	Completions(context.Context, int) ([]Completion, error)
+	ClaimReminders(context.Context, time.Time, int) ([]models.ToDoItem, error)
+	GetAllToDo(context.Context, models.ListOptions) (models.ToDoPage, error)
+	FindSimilarToDo(context.Context, string) ([]models.ToDoItem, error)
+	SearchToDo(context.Context, string) ([]models.SearchResult, error)
+	WatchToDos(context.Context) (<-chan models.ToDoEvent, error)
 }
 
+// similarCandidateLimit bounds the number of candidates FindSimilarToDo
+// hands back for ranking.
+const similarCandidateLimit = 50
+
+// closeTimeout bounds closing a cursor once its request is done.
+const closeTimeout = 5 * time.Second
+
+// ErrInvalidCursor is returned when a listing is resumed from a cursor the
+// store didn't hand out.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+var (
+	// ErrToDoNotFound is returned when no todo has the ID asked for.
+	ErrToDoNotFound = errors.New("todo not found")
+
+	// ErrInvalidID is returned for a task ID that can't address a todo.
+	// It's models.ErrInvalidTaskID, so that checking for either matches.
+	ErrInvalidID = models.ErrInvalidTaskID
+
+	// ErrConflict is returned when a write clashes with a todo already
+	// stored, such as one with the same ID.
+	ErrConflict = errors.New("todo conflict")
+)
+
+// duplicateKey is the code of the Mongo errors about a write breaking a
+// unique index.
+const duplicateKey = 11000
+
 type mongoStore struct {
 	client     *mongo.Client
 	collection *mongo.Collection
+	slowQuery  time.Duration
+	logger     log.Logger
+	guardrails Guardrails
+	// taskIndexed records whether the task field was indexed at startup.
+	taskIndexed bool
+
+	partitions   int
+	shardOptions ShardOptions
+	cursorKey    []byte
+
+	// baseCollection and suffix make up the collection name, suffix is set
+	// on partitions.
+	baseCollection string
+	suffix         string
+
+	// outbox is the collection the events are written to, if any.
+	outbox string
 }
 
 // NewMongoStore return a pointer to newly create instance of mongoStore
-func NewMongoStore(connetionString string, dbName string, collectionName string) (*mongoStore, error) {
-	// Set client options
-	clientOptions := options.Client().ApplyURI(connetionString)
-	// connect to MongoDB
-	client, err := mongo.Connect(context.TODO(), clientOptions)
-
+func NewMongoStore(connetionString string, dbName string, collectionName string, opts ...MongoOption) (*mongoStore, error) {
+	db, err := ConnectMongo(connetionString, dbName)
 	if err != nil {
 		return nil, err
 	}
+	return newMongoStore(db, collectionName, opts...)
+}
+
+// newMongoStore returns the mongoStore of the todos in the collection
+// collectionName of db.
+func newMongoStore(db *MongoDB, collectionName string, opts ...MongoOption) (*mongoStore, error) {
+	var err error
+	collection := db.Collection(collectionName).Collection
+	m := &mongoStore{
+		client:         db.Client(),
+		collection:     collection,
+		logger:         log.NewNopLogger(),
+		guardrails:     DefaultGuardrails,
+		baseCollection: collectionName,
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	if err := prepareCollection(context.TODO(), collection); err != nil {
+		return nil, fmt.Errorf("preparing the collection: %w", err)
+	}
+	if m.guardrails.RequireRegexIndex {
+		m.taskIndexed, err = hasIndexOn(context.TODO(), collection, "task")
+		if err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// prepareCollection creates the indexes the queries of the store run on,
+// unless collection has them already: the text index of SearchToDo, the due
+// dates and priorities the listings filter and sort on, the completion times
+// of Completions, the reminders ClaimReminders polls and the tenants every
+// query is scoped to. The todos stored before priorities get the normal one,
+// so that they sort with it.
+func prepareCollection(ctx context.Context, collection *mongo.Collection) error {
+	_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "task", Value: "text"}},
+			Options: options.Index().SetName(textIndexName),
+		},
+		{
+			Keys:    bson.D{{Key: "dueDate", Value: 1}},
+			Options: options.Index().SetName("dueDate_1").SetSparse(true),
+		},
+		{Keys: bson.D{{Key: "priority", Value: 1}, {Key: "_id", Value: 1}}},
+		{
+			Keys:    bson.D{{Key: "completedAt", Value: -1}},
+			Options: options.Index().SetSparse(true),
+		},
+		{
+			Keys:    bson.D{{Key: "remindAt", Value: 1}},
+			Options: options.Index().SetSparse(true),
+		},
+		{Keys: bson.D{{Key: "tenant", Value: 1}, {Key: "_id", Value: 1}}},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = collection.UpdateMany(ctx,
+		bson.M{"priority": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"priority": models.PriorityNormal.Rank()}})
+	return err
+}
 
-	// Check the connection
-	err = client.Ping(context.TODO(), nil)
+// NewMongo returns a Mongo backed Store. When WithPartitions is given, the
+// todos are spread over several collections queried as shards. When
+// WithCursorKey is given, listing cursors are signed.
+func NewMongo(connectionString, dbName, collectionName string, opts ...MongoOption) (Store, error) {
+	db, err := ConnectMongo(connectionString, dbName)
+	if err != nil {
+		return nil, err
+	}
+	return newMongo(db, collectionName, opts...)
+}
 
+// newMongo is NewMongo over the connection db.
+func newMongo(db *MongoDB, collectionName string, opts ...MongoOption) (Store, error) {
+	m, err := newMongoStore(db, collectionName, opts...)
 	if err != nil {
 		return nil, err
 	}
+	s, err := m.partitioned(db.Name(), collectionName)
+	if err != nil {
+		return nil, err
+	}
+	if m.cursorKey != nil {
+		s = NewSignedCursors(s, m.cursorKey)
+	}
+	return s, nil
+}
+
+// partitioned returns m, or the sharded store over its partitions.
+func (m *mongoStore) partitioned(dbName, collectionName string) (Store, error) {
+	if m.partitions <= 1 {
+		return m.withOutbox(), nil
+	}
+	var err error
+	shards := make([]Store, m.partitions)
+	for i := range shards {
+		shard := *m
+		shard.suffix = fmt.Sprintf("_%d", i)
+		shard.collection = m.client.Database(dbName).Collection(collectionName + shard.suffix)
+		if err := prepareCollection(context.TODO(), shard.collection); err != nil {
+			return nil, fmt.Errorf("preparing the collection: %w", err)
+		}
+		if shard.guardrails.RequireRegexIndex {
+			shard.taskIndexed, err = hasIndexOn(context.TODO(), shard.collection, "task")
+			if err != nil {
+				return nil, err
+			}
+		}
+		shards[i] = shard.withOutbox()
+	}
+	return NewShardedStore(shards, m.shardOptions), nil
+}
 
-	collection := client.Database(dbName).Collection(collectionName)
-	return &mongoStore{
-		client:     client,
-		collection: collection,
-	}, nil
+// WithPartitions makes NewMongo spread the todos over n collections, named
+// after the collection with a _<i> suffix, and fan queries out to them as
+// set by opts.
+func WithPartitions(n int, opts ShardOptions) MongoOption {
+	return func(m *mongoStore) {
+		m.partitions = n
+		m.shardOptions = opts
+	}
 }
 
 func (m mongoStore) Ping(ctx context.Context) error {
 	return m.client.Ping(ctx, nil)
 }
 
-func (m mongoStore) InsertToDo(ctx context.Context, task models.ToDoItem) (string, error) {
-	insertResult, err := m.collection.InsertOne(ctx, task)
-
+func (m mongoStore) InsertToDo(ctx context.Context, task models.ToDoItem) (models.TaskID, error) {
+	m = m.forContext(ctx)
+	task.CompletedAt = completedAt(task.Status)
+	task.Tenant = tenant.FromContext(ctx)
+	doc, err := toDocument(task)
 	if err != nil {
 		return "", err
 	}
+	insertResult, err := m.collection.InsertOne(ctx, doc)
+
+	if err != nil {
+		return "", writeError(err)
+	}
 	objID, ok := insertResult.InsertedID.(primitive.ObjectID)
 
 	if !ok {
 		return "", errors.New("Malform InsertID")
 	}
 
-	return objID.Hex(), nil
+	return models.TaskID(objID.Hex()), nil
 }
 
-func (m mongoStore) CompleteToDo(ctx context.Context, taskId string) (string, error) {
-	id, err := primitive.ObjectIDFromHex(taskId)
+// CompleteToDo marks the todo taskID done unless it is already, or returns
+// ErrToDoNotFound. See OnCompleted.
+func (m mongoStore) CompleteToDo(ctx context.Context, taskID models.TaskID) (models.TaskID, error) {
+	m = m.forContext(ctx)
+	id, err := objectID(taskID)
 	if err != nil {
 		return "", err
 	}
 
-	filter := bson.M{"_id": id}
-	update := bson.M{"$set": bson.M{"status": true}}
-	_, err = m.collection.UpdateOne(ctx, filter, update)
+	flipped, err := m.complete(ctx, id)
 	if err != nil {
 		return "", err
 	}
-	return taskId, nil
+	if !flipped {
+		// Missing, or done already.
+		if _, err := m.FindByID(ctx, taskID); err != nil {
+			return "", err
+		}
+		return taskID, nil
+	}
+	return taskID, completed(ctx, m, taskID)
 }
 
-func (m mongoStore) UnDoToDo(ctx context.Context, taskId string) (string, error) {
-	id, err := primitive.ObjectIDFromHex(taskId)
+// complete marks the todo id done unless it is already, and reports whether
+// it did.
+func (m mongoStore) complete(ctx context.Context, id primitive.ObjectID) (bool, error) {
+	filter := ofTenant(ctx, bson.M{"_id": id, "status": false})
+	update := stampCompletion(bson.M{"$set": bson.M{"status": true}}, true)
+	defer m.explainSlow(time.Now(), "CompleteToDo", m.updateCommand(filter, update))
+	res, err := m.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, writeError(err)
+	}
+	return res.ModifiedCount > 0, nil
+}
+
+// UnDoToDo marks the todo taskID pending, or returns ErrToDoNotFound.
+func (m mongoStore) UnDoToDo(ctx context.Context, taskID models.TaskID) (models.TaskID, error) {
+	m = m.forContext(ctx)
+	id, err := objectID(taskID)
 	if err != nil {
 		return "", err
 	}
-	filter := bson.M{"_id": id}
-	update := bson.M{"$set": bson.M{"status": false}}
-	_, err = m.collection.UpdateOne(ctx, filter, update)
+
+	filter := ofTenant(ctx, bson.M{"_id": id})
+	update := stampCompletion(bson.M{"$set": bson.M{"status": false}}, false)
+	defer m.explainSlow(time.Now(), "UnDoToDo", m.updateCommand(filter, update))
+	res, err := m.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return "", writeError(err)
+	}
+	if res.MatchedCount == 0 {
+		return "", ErrToDoNotFound
+	}
+	return taskID, nil
+}
+
+// UpdateToDo sets the fields of the todo that updates sets, leaving the
+// others alone. It returns ErrToDoNotFound if there's no todo taskID.
+func (m mongoStore) UpdateToDo(ctx context.Context, taskID models.TaskID, updates models.ToDoUpdate) (models.TaskID, error) {
+	m = m.forContext(ctx)
+	id, err := objectID(taskID)
 	if err != nil {
 		return "", err
 	}
-	return taskId, nil
+	var flipped bool
+	if updates.Status != nil && *updates.Status {
+		if flipped, err = m.complete(ctx, id); err != nil {
+			return "", err
+		}
+	}
+
+	set := bson.M{}
+	if updates.Task != nil {
+		set["task"] = *updates.Task
+	}
+	if updates.Status != nil {
+		set["status"] = *updates.Status
+	}
+	if updates.ScheduleAt != nil {
+		set["scheduleAt"] = *updates.ScheduleAt
+	}
+	if updates.DueDate != nil {
+		set["dueDate"] = *updates.DueDate
+	}
+	if updates.Priority != nil {
+		set["priority"] = updates.Priority.Rank()
+	}
+	if updates.Recurrence != nil {
+		set["recurrence"] = *updates.Recurrence
+	}
+	if updates.RemindAt != nil {
+		set["remindAt"] = *updates.RemindAt
+	}
+	update := bson.M{}
+	if updates.Status != nil {
+		stampCompletion(update, *updates.Status)
+	}
+	unset, _ := update["$unset"].(bson.M)
+	if unset == nil {
+		unset = bson.M{}
+	}
+	if updates.State != nil {
+		set["state"] = *updates.State
+		delete(unset, "state")
+	}
+	for name, v := range updates.CustomFields {
+		if v == nil {
+			unset["customFields."+name] = ""
+		} else {
+			set["customFields."+name] = v
+		}
+	}
+	filter := ofTenant(ctx, bson.M{"_id": id})
+	if len(set) > 0 {
+		update["$set"] = set
+	}
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	} else {
+		delete(update, "$unset")
+	}
+	defer m.explainSlow(time.Now(), "UpdateToDo", m.updateCommand(filter, update))
+	res, err := m.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return "", writeError(err)
+	}
+	if res.MatchedCount == 0 {
+		return "", ErrToDoNotFound
+	}
+	if flipped {
+		return taskID, completed(ctx, m, taskID)
+	}
+	return taskID, nil
 }
 
-func (m mongoStore) DeleteToDo(ctx context.Context, taskId string) (string, error) {
-	id, err := primitive.ObjectIDFromHex(taskId)
+// DeleteToDo deletes the todo taskID, or returns ErrToDoNotFound.
+func (m mongoStore) DeleteToDo(ctx context.Context, taskID models.TaskID) (models.TaskID, error) {
+	m = m.forContext(ctx)
+	id, err := objectID(taskID)
 	if err != nil {
 		return "", err
 	}
 
-	filter := bson.M{"_id": id}
-	_, err = m.collection.DeleteOne(ctx, filter)
+	filter := ofTenant(ctx, bson.M{"_id": id})
+	defer m.explainSlow(time.Now(), "DeleteToDo", m.deleteCommand(filter))
+	res, err := m.collection.DeleteOne(ctx, filter)
 	if err != nil {
 		return "", err
 	}
-	return taskId, nil
+	if res.DeletedCount == 0 {
+		return "", ErrToDoNotFound
+	}
+	return taskID, nil
+}
+
+// writeError returns ErrConflict, wrapping err, when err is about a write
+// breaking a unique index, and err otherwise.
+func writeError(err error) error {
+	var we mongo.WriteException
+	if errors.As(err, &we) {
+		for _, e := range we.WriteErrors {
+			if e.Code == duplicateKey {
+				return fmt.Errorf("%w: %v", ErrConflict, err)
+			}
+		}
+	}
+	return err
+}
+
+// FindByID returns the todo with taskID, or ErrToDoNotFound.
+func (m mongoStore) FindByID(ctx context.Context, taskID models.TaskID) (models.ToDoItem, error) {
+	m = m.forContext(ctx)
+	id, err := objectID(taskID)
+	if err != nil {
+		return models.ToDoItem{}, err
+	}
+
+	filter := ofTenant(ctx, bson.M{"_id": id})
+	defer m.explainSlow(time.Now(), "FindByID", m.findCommand(filter, nil, 1))
+	var doc todoDocument
+	err = m.collection.FindOne(ctx, filter).Decode(&doc)
+	if err == mongo.ErrNoDocuments {
+		return models.ToDoItem{}, ErrToDoNotFound
+	}
+	if err != nil {
+		return models.ToDoItem{}, err
+	}
+	return doc.toModel(), nil
 }
 
-func (m mongoStore) GetAllToDo(ctx context.Context) ([]models.ToDoItem, error) {
-	cur, err := m.collection.Find(ctx, bson.D{{}})
+// GetAllToDo lists the todos in insertion order, or by task, as opts sorts
+// them, resuming after the cursor of opts when it's not empty. Todos
+// scheduled for later are left out unless opts asks for them, and only those
+// with its status and passing its query are listed, the server filters
+// them. Guardrails.MaxListSize caps the page rather than rejecting the
+// listing: past it, the page is truncated and its cursor resumes the rest.
+// Every page of a listing is read as of its first page, see listCursor. A
+// listing may be cut short by its deadline, see WithPartialResults.
+func (m mongoStore) GetAllToDo(ctx context.Context, opts models.ListOptions) (models.ToDoPage, error) {
+	m = m.forContext(ctx)
+	cursor, order, err := parseListing(opts)
 	if err != nil {
+		return models.ToDoPage{}, err
+	}
+	filter := ofTenant(ctx, bson.M{"_id": bson.M{"$lt": cursor.createdAfter()}})
+	var and bson.A
+	if cursor.After != "" {
+		after, err := objectID(cursor.After)
+		if err != nil {
+			return models.ToDoPage{}, ErrInvalidCursor
+		}
+		and = append(and, mongoSeek(cursor, order, after))
+	}
+	if !opts.Scheduled {
+		// Also matches the todos without a schedule.
+		filter["scheduleAt"] = bson.M{"$not": bson.M{"$gt": cursor.Snapshot}}
+	}
+	if opts.Status != "" {
+		filter["status"] = opts.Status == models.StatusDone
+	}
+	if opts.Overdue {
+		filter["status"] = false
+	}
+	if after, before := cursor.dueRange(opts); after != nil || before != nil {
+		due := bson.M{}
+		if after != nil {
+			due["$gt"] = *after
+		}
+		if before != nil {
+			due["$lt"] = *before
+		}
+		filter["dueDate"] = due
+	}
+	if opts.Priority != "" {
+		filter["priority"] = opts.Priority.Rank()
+	}
+	if opts.Query != "" {
+		e, err := query.Parse(opts.Query)
+		if err != nil {
+			return models.ToDoPage{}, err
+		}
+		q, regexes := mongoFilter(e)
+		if regexes > 0 {
+			if err := m.checkRegex(regexes); err != nil {
+				return models.ToDoPage{}, err
+			}
+		}
+		and = append(and, q)
+	}
+	if len(and) > 0 {
+		filter["$and"] = and
+	}
+
+	dir := 1
+	if order.desc {
+		dir = -1
+	}
+	sort := bson.D{{Key: "_id", Value: dir}}
+	switch {
+	case order.byTask:
+		sort = bson.D{{Key: "task", Value: dir}, {Key: "_id", Value: dir}}
+	case order.byPriority:
+		sort = bson.D{{Key: "priority", Value: dir}, {Key: "_id", Value: dir}}
+	}
+	findOptions := withMaxTime(ctx, options.Find().SetSort(sort))
+	max, limit := m.guardrails.MaxListSize, int64(0)
+	if max > 0 {
+		// Fetch one more than needed to learn whether the page is truncated.
+		limit = max + 1
+		findOptions.SetLimit(limit)
+	}
+
+	listCtx, cancel := listContext(ctx)
+	defer cancel()
+	defer m.explainSlow(time.Now(), "GetAllToDo", m.findCommand(filter, sort, limit))
+	cur, err := m.collection.Find(listCtx, filter, findOptions)
+	if err != nil {
+		if cutShort(ctx, listCtx) {
+			return cursor.partialPage(nil), nil
+		}
+		return models.ToDoPage{}, err
+	}
+
+	todos, err := decodeToDos(listCtx, cur)
+	if err != nil {
+		if cutShort(ctx, listCtx) {
+			return cursor.partialPage(todos), nil
+		}
+		return models.ToDoPage{}, err
+	}
+
+	page := models.ToDoPage{Todos: todos}
+	if max > 0 && int64(len(todos)) > max {
+		page.Todos = todos[:max]
+		page.Truncated = true
+		page.Cursor = cursor.past(page.Todos[max-1]).String()
+	}
+	return page, nil
+}
+
+// mongoSeek matches the todos listed in order after the position of cursor,
+// the todo after.
+func mongoSeek(cursor listCursor, order listOrder, after primitive.ObjectID) bson.M {
+	op := "$gt"
+	if order.desc {
+		op = "$lt"
+	}
+	var key string
+	var value interface{}
+	switch {
+	case order.byTask:
+		key, value = "task", cursor.Task
+	case order.byPriority:
+		key, value = "priority", cursor.Priority
+	default:
+		return bson.M{"_id": bson.M{op: after}}
+	}
+	return bson.M{"$or": bson.A{
+		bson.M{key: bson.M{op: value}},
+		bson.M{key: value, "_id": bson.M{op: after}},
+	}}
+}
+
+// FindSimilarToDo returns the todos sharing at least one word with task. It's
+// only a cheap prefilter, ranking the candidates is left to the caller.
+func (m mongoStore) FindSimilarToDo(ctx context.Context, task string) ([]models.ToDoItem, error) {
+	m = m.forContext(ctx)
+	words := strings.Fields(task)
+	if len(words) == 0 {
+		return nil, nil
+	}
+	if err := m.checkRegex(len(words)); err != nil {
 		return nil, err
 	}
+	for i, w := range words {
+		words[i] = regexp.QuoteMeta(w)
+	}
 
-	defer cur.Close(ctx)
+	filter := ofTenant(ctx, bson.M{"task": primitive.Regex{Pattern: strings.Join(words, "|"), Options: "i"}})
+	defer m.explainSlow(time.Now(), "FindSimilarToDo", m.findCommand(filter, nil, similarCandidateLimit))
+	cur, err := m.collection.Find(ctx, filter, withMaxTime(ctx, options.Find().SetLimit(similarCandidateLimit)))
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeToDos(ctx, cur)
+}
+
+// withMaxTime has the server give up a find past the deadline of ctx. The
+// driver stops waiting for a cancelled query, but the server would run it to
+// completion, for no one.
+func withMaxTime(ctx context.Context, opts *options.FindOptions) *options.FindOptions {
+	if deadline, ok := ctx.Deadline(); ok {
+		opts.SetMaxTime(time.Until(deadline))
+	}
+	return opts
+}
+
+// decodeToDos drains cur into a slice of todos and closes it. The cursor is
+// closed even when ctx is done, typically because the client went away, or
+// the server would hold it, and the rest of the listing, until it times out.
+// On failure, the todos decoded until then are returned with the error.
+func decodeToDos(ctx context.Context, cur *mongo.Cursor) ([]models.ToDoItem, error) {
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), closeTimeout)
+		defer cancel()
+		cur.Close(ctx)
+	}()
 
 	var results []models.ToDoItem
 	for cur.Next(ctx) {
-		var result models.ToDoItem
-		err = cur.Decode(&result)
-		if err != nil {
-			return nil, err
+		var doc todoDocument
+		if err := cur.Decode(&doc); err != nil {
+			return results, err
 		}
-		results = append(results, result)
+		results = append(results, doc.toModel())
 	}
 
 	if err := cur.Err(); err != nil {
-		return nil, err
+		return results, err
 	}
 	return results, nil
 }
@@ -0,0 +1,23 @@
+package store
+
+import (
+	"errors"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestWriteError(t *testing.T) {
+	dup := mongo.WriteException{WriteErrors: mongo.WriteErrors{{Code: duplicateKey, Message: "E11000 duplicate key error"}}}
+	if err := writeError(dup); !errors.Is(err, ErrConflict) {
+		t.Errorf("want %v, have %v", ErrConflict, err)
+	}
+	other := mongo.WriteException{WriteErrors: mongo.WriteErrors{{Code: 121, Message: "Document failed validation"}}}
+	if err := writeError(other); errors.Is(err, ErrConflict) {
+		t.Errorf("want the error as is, have %v", err)
+	}
+	boom := errors.New("boom")
+	if err := writeError(boom); err != boom {
+		t.Errorf("want %v, have %v", boom, err)
+	}
+}
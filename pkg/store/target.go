@@ -0,0 +1,49 @@
+package store
+
+import (
+	"context"
+)
+
+// Target redirects the store operations of a request to another database
+// or collection, e.g. to run integration tests or preview data against the
+// production code paths without touching live data. Empty fields keep the
+// configured database or collection.
+type Target struct {
+	Database   string
+	Collection string
+}
+
+type targetKey struct{}
+
+// WithTarget returns a copy of ctx routing the store operations made with it
+// to target. It's meant for test and admin scopes only, callers are
+// responsible for restricting who may set it.
+func WithTarget(ctx context.Context, target Target) context.Context {
+	return context.WithValue(ctx, targetKey{}, target)
+}
+
+// TargetFrom returns the Target set on ctx, if any.
+func TargetFrom(ctx context.Context) (Target, bool) {
+	target, ok := ctx.Value(targetKey{}).(Target)
+	return target, ok
+}
+
+// forContext returns a copy of m operating on the Target set on ctx, if any.
+// The collection of a partition keeps its partition suffix.
+func (m mongoStore) forContext(ctx context.Context) mongoStore {
+	target, ok := TargetFrom(ctx)
+	if !ok || (target.Database == "" && target.Collection == "") {
+		return m
+	}
+
+	db := m.collection.Database()
+	if target.Database != "" {
+		db = m.client.Database(target.Database)
+	}
+	name := m.baseCollection
+	if target.Collection != "" {
+		name = target.Collection
+	}
+	m.collection = db.Collection(name + m.suffix)
+	return m
+}
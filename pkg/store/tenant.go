@@ -0,0 +1,21 @@
+package store
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"ray.vhatt/todo-gokit/pkg/tenant"
+)
+
+// ofTenant adds to filter the condition matching the todos of the tenant ctx
+// carries, and returns it. Without a tenant, only the todos stored for no
+// tenant match, like those stored before the todos had one.
+func ofTenant(ctx context.Context, filter bson.M) bson.M {
+	if t := tenant.FromContext(ctx); t != "" {
+		filter["tenant"] = t
+	} else {
+		filter["tenant"] = bson.M{"$exists": false}
+	}
+	return filter
+}
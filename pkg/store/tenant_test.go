@@ -0,0 +1,138 @@
+package store_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"ray.vhatt/todo-gokit/pkg/models"
+	"ray.vhatt/todo-gokit/pkg/store"
+	"ray.vhatt/todo-gokit/pkg/store/mongotest"
+	"ray.vhatt/todo-gokit/pkg/tenant"
+)
+
+func TestTenantIsolation(t *testing.T) {
+	for _, backend := range []struct {
+		name string
+		new  func(t *testing.T) store.Store
+	}{
+		{"memory", func(*testing.T) store.Store { return store.NewInMemoryStore() }},
+		{"sqlite", func(t *testing.T) store.Store {
+			dir, err := ioutil.TempDir("", "sqlite")
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(func() { os.RemoveAll(dir) })
+			s, err := store.NewSQLiteStore(filepath.Join(dir, "todos.db"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			return s
+		}},
+		{"sharded", func(*testing.T) store.Store {
+			return store.NewShardedStore([]store.Store{store.NewInMemoryStore(), store.NewInMemoryStore()}, store.ShardOptions{})
+		}},
+		{"mongo", func(t *testing.T) store.Store {
+			s, err := store.Open(store.Config{URI: "mongodb:", Collection: "todos", Mongo: mongotest.DB(t)})
+			if err != nil {
+				t.Fatal(err)
+			}
+			return s
+		}},
+	} {
+		t.Run(backend.name, func(t *testing.T) {
+			testTenantIsolation(t, backend.new(t))
+		})
+	}
+}
+
+// testTenantIsolation checks that the todos of tenant a are out of reach of
+// tenant b and of the requests made for no tenant.
+func testTenantIsolation(t *testing.T, s store.Store) {
+	a := tenant.NewContext(context.Background(), "a")
+	id, err := s.InsertToDo(a, models.ToDoItem{Task: "water the plants"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.CompleteToDo(a, id); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, ctx := range map[string]context.Context{
+		"b":         tenant.NewContext(context.Background(), "b"),
+		"no tenant": context.Background(),
+	} {
+		watchCtx, cancel := context.WithCancel(ctx)
+		changes, err := s.WatchToDos(watchCtx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		other, err := s.InsertToDo(ctx, models.ToDoItem{Task: "water the garden"})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if todo, err := s.FindByID(ctx, id); !errors.Is(err, store.ErrToDoNotFound) {
+			t.Errorf("%s: FindByID: want %v, have %+v, %v", name, store.ErrToDoNotFound, todo, err)
+		}
+		if page, err := s.GetAllToDo(ctx, models.ListOptions{}); err != nil || len(page.Todos) != 1 || page.Todos[0].ID != other {
+			t.Errorf("%s: GetAllToDo: want %s only, have %+v, %v", name, other, page.Todos, err)
+		}
+		if todos, err := s.FindSimilarToDo(ctx, "plants"); err != nil || len(todos) != 0 {
+			t.Errorf("%s: FindSimilarToDo: want none, have %+v, %v", name, todos, err)
+		}
+		if results, err := s.SearchToDo(ctx, "plants"); err != nil || len(results) != 0 {
+			t.Errorf("%s: SearchToDo: want none, have %+v, %v", name, results, err)
+		}
+		if completions, err := s.Completions(ctx, 10); err != nil || len(completions) != 0 {
+			t.Errorf("%s: Completions: want none, have %+v, %v", name, completions, err)
+		}
+		if _, err := s.UnDoToDo(ctx, id); !errors.Is(err, store.ErrToDoNotFound) {
+			t.Errorf("%s: UnDoToDo: want %v, have %v", name, store.ErrToDoNotFound, err)
+		}
+		task := "stolen"
+		if _, err := s.UpdateToDo(ctx, id, models.ToDoUpdate{Task: &task}); !errors.Is(err, store.ErrToDoNotFound) {
+			t.Errorf("%s: UpdateToDo: want %v, have %v", name, store.ErrToDoNotFound, err)
+		}
+		if _, err := s.DeleteToDo(ctx, id); !errors.Is(err, store.ErrToDoNotFound) {
+			t.Errorf("%s: DeleteToDo: want %v, have %v", name, store.ErrToDoNotFound, err)
+		}
+		if result, err := s.BatchToDo(ctx, models.BatchDelete, []models.TaskID{id}); err != nil || result.Matched != 0 {
+			t.Errorf("%s: BatchToDo: want nothing matched, have %+v, %v", name, result, err)
+		}
+
+		if _, err := s.CompleteToDo(a, other); !errors.Is(err, store.ErrToDoNotFound) {
+			t.Errorf("%s: a completed the todo of %s: %v", name, name, err)
+		}
+		if _, err := s.UnDoToDo(a, id); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := s.CompleteToDo(a, id); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := s.DeleteToDo(ctx, other); err != nil {
+			t.Fatal(err)
+		}
+		for _, want := range []string{models.ToDoCreated, models.ToDoDeleted} {
+			select {
+			case e := <-changes:
+				if e.Type != want || e.TaskID != other {
+					t.Errorf("%s: want the %s change of %s, have %+v", name, want, other, e)
+				}
+			case <-time.After(time.Second):
+				t.Fatalf("%s: want the %s change of %s", name, want, other)
+			}
+		}
+		cancel()
+		for range changes {
+		}
+	}
+
+	if todo, err := s.FindByID(a, id); err != nil || todo.Task != "water the plants" || !todo.Status {
+		t.Errorf("want the todo of a done and untouched, have %+v, %v", todo, err)
+	}
+}
@@ -0,0 +1,152 @@
+package store
+
+import (
+	"context"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"ray.vhatt/todo-gokit/pkg/models"
+	"ray.vhatt/todo-gokit/pkg/tenant"
+)
+
+// watchBuffer is the number of changes a watcher can lag behind by.
+const watchBuffer = 64
+
+// changeTypes map the operations of Mongo change streams to the events of
+// WatchToDos.
+var changeTypes = map[string]string{
+	"insert":  models.ToDoCreated,
+	"update":  models.ToDoUpdated,
+	"replace": models.ToDoUpdated,
+	"delete":  models.ToDoDeleted,
+}
+
+// changeDocument is an event of a change stream.
+type changeDocument struct {
+	OperationType string `bson:"operationType"`
+	DocumentKey   struct {
+		ID primitive.ObjectID `bson:"_id"`
+	} `bson:"documentKey"`
+	FullDocument *todoDocument `bson:"fullDocument"`
+}
+
+// WatchToDos returns the changes of the todos of the tenant of ctx from now
+// on, read from a change stream of the collection, until ctx is done or the
+// stream fails. Updates carry the todo as it is when the change is read.
+// Deletions carry no document to tell the tenant by, so watchers see those of
+// every tenant, with nothing but the ID of the todo deleted. Change streams
+// need a replica set or a sharded cluster.
+func (m mongoStore) WatchToDos(ctx context.Context) (<-chan models.ToDoEvent, error) {
+	m = m.forContext(ctx)
+	t := tenant.FromContext(ctx)
+	owned := ofTenant(ctx, bson.M{})["tenant"]
+	match := bson.M{"$or": bson.A{
+		bson.M{"operationType": bson.M{"$in": bson.A{"insert", "update", "replace"}}, "fullDocument.tenant": owned},
+		bson.M{"operationType": "delete"},
+	}}
+	pipeline := mongo.Pipeline{{{Key: "$match", Value: match}}}
+	stream, err := m.collection.Watch(ctx, pipeline, options.ChangeStream().SetFullDocument(options.UpdateLookup))
+	if err != nil {
+		return nil, err
+	}
+	changes := make(chan models.ToDoEvent, watchBuffer)
+	go func() {
+		defer close(changes)
+		defer stream.Close(context.Background())
+		for stream.Next(ctx) {
+			var doc changeDocument
+			if err := stream.Decode(&doc); err != nil {
+				m.logger.Log("during", "WatchToDos", "err", err)
+				return
+			}
+			e := models.ToDoEvent{Type: changeTypes[doc.OperationType], TaskID: models.TaskID(doc.DocumentKey.ID.Hex()), Tenant: t}
+			if doc.FullDocument != nil && e.Type != models.ToDoDeleted {
+				todo := doc.FullDocument.toModel()
+				e.Todo = &todo
+			}
+			select {
+			case changes <- e:
+			case <-ctx.Done():
+				return
+			}
+		}
+		if err := stream.Err(); err != nil && ctx.Err() == nil {
+			m.logger.Log("during", "WatchToDos", "err", err)
+		}
+	}()
+	return changes, nil
+}
+
+// feed hands the changes of a store to its watchers, for the backends
+// without a change stream of their own. Only the changes made through the
+// store are seen.
+type feed struct {
+	mtx      sync.Mutex
+	watchers map[chan models.ToDoEvent]string // to their tenant
+}
+
+// watch returns the changes to the todos of the tenant of ctx published from
+// now on, until ctx is done.
+func (f *feed) watch(ctx context.Context) <-chan models.ToDoEvent {
+	changes := make(chan models.ToDoEvent, watchBuffer)
+	f.mtx.Lock()
+	if f.watchers == nil {
+		f.watchers = make(map[chan models.ToDoEvent]string)
+	}
+	f.watchers[changes] = tenant.FromContext(ctx)
+	f.mtx.Unlock()
+	go func() {
+		<-ctx.Done()
+		f.mtx.Lock()
+		f.drop(changes)
+		f.mtx.Unlock()
+	}()
+	return changes
+}
+
+// watched reports whether anyone watches the changes, to spare looking up
+// the todos changed otherwise.
+func (f *feed) watched() bool {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	return len(f.watchers) > 0
+}
+
+// publish hands e to the watchers of its tenant. A watcher lagging too far
+// behind to take it is dropped, its channel closed, rather than blocking the
+// store or silently missing changes.
+func (f *feed) publish(e models.ToDoEvent) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	for changes, t := range f.watchers {
+		if t != e.Tenant {
+			continue
+		}
+		select {
+		case changes <- e:
+		default:
+			f.drop(changes)
+		}
+	}
+}
+
+// drop closes changes, unless it's closed already. f.mtx must be held.
+func (f *feed) drop(changes chan models.ToDoEvent) {
+	if _, ok := f.watchers[changes]; ok {
+		delete(f.watchers, changes)
+		close(changes)
+	}
+}
+
+// change returns the event of todo, changed as eventType.
+func change(eventType string, todo models.ToDoItem) models.ToDoEvent {
+	e := models.ToDoEvent{Type: eventType, TaskID: todo.ID, Tenant: todo.Tenant}
+	if eventType != models.ToDoDeleted {
+		e.Todo = &todo
+	}
+	return e
+}
@@ -0,0 +1,65 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"ray.vhatt/todo-gokit/pkg/models"
+)
+
+func TestWatchToDos(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	sqlite, err := NewSQLiteStore(filepath.Join(dir, "todos.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, s := range map[string]Store{
+		"memory":  NewInMemoryStore(),
+		"sqlite":  sqlite,
+		"sharded": NewShardedStore([]Store{NewInMemoryStore(), NewInMemoryStore()}, ShardOptions{}),
+	} {
+		ctx, cancel := context.WithCancel(context.Background())
+		changes, err := s.WatchToDos(ctx)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		id, err := s.InsertToDo(ctx, models.ToDoItem{Task: "a"})
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		s.CompleteToDo(ctx, id)
+		s.CompleteToDo(ctx, "ffffffffffffffffffffffff")
+		s.BatchToDo(ctx, models.BatchDelete, []models.TaskID{id, "ffffffffffffffffffffffff"})
+
+		var have []string
+		for len(have) < 3 {
+			select {
+			case e := <-changes:
+				done := e.Todo != nil && e.Todo.Status
+				have = append(have, fmt.Sprintf("%s:%t", e.Type, done))
+				if e.TaskID != id {
+					t.Errorf("%s: want the changes of %s, have %+v", name, id, e)
+				}
+			case <-time.After(time.Second):
+				t.Fatalf("%s: want 3 changes, have %v", name, have)
+			}
+		}
+		if want := "[created:false updated:true deleted:false]"; fmt.Sprint(have) != want {
+			t.Errorf("%s: want %s, have %v", name, want, have)
+		}
+
+		cancel()
+		for range changes {
+		}
+	}
+}
@@ -0,0 +1,19 @@
+// Package tenant carries the tenant a request is made for, so the rules
+// configured per tenant can be applied to it.
+package tenant
+
+import "context"
+
+type tenantKey struct{}
+
+// NewContext returns a copy of ctx carrying the tenant id.
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, id)
+}
+
+// FromContext returns the tenant ctx carries, or the empty string for
+// requests made for no tenant in particular.
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(tenantKey{}).(string)
+	return id
+}
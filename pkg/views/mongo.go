@@ -0,0 +1,93 @@
+package views
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"ray.vhatt/todo-gokit/pkg/store"
+)
+
+type mongoStore struct {
+	collection store.Collection
+}
+
+// viewDocument is a View, identified by its tenant and name.
+type viewDocument struct {
+	ID   string `bson:"_id"`
+	View `bson:",inline"`
+}
+
+// NewMongoStore returns a Store keeping the views in the collection
+// collectionName of db, so that they survive restarts. The views saved
+// before they had a tenant are given their name back, as those of no
+// tenant.
+func NewMongoStore(db *store.MongoDB, collectionName string) (Store, error) {
+	collection := db.Collection(collectionName)
+	var untenanted []viewDocument
+	if err := collection.All(context.TODO(), bson.M{"name": bson.M{"$exists": false}}, "", &untenanted); err != nil {
+		return nil, err
+	}
+	for _, doc := range untenanted {
+		if _, err := collection.UpdateOne(context.TODO(), bson.M{"_id": doc.ID}, bson.M{"$set": bson.M{"name": doc.ID}}); err != nil {
+			return nil, err
+		}
+	}
+	_, err := collection.Indexes().CreateOne(context.TODO(), mongo.IndexModel{
+		Keys:    bson.D{{Key: "tenant", Value: 1}, {Key: "name", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return mongoStore{collection: collection}, nil
+}
+
+// viewID identifies the view name of tenant. The views of no tenant are
+// identified by their name, as they were before the views had a tenant.
+func viewID(tenant, name string) string {
+	if tenant == "" {
+		return name
+	}
+	return tenant + "/" + name
+}
+
+func (s mongoStore) Save(ctx context.Context, view View) error {
+	id := viewID(view.Tenant, view.Name)
+	return s.collection.Put(ctx, id, viewDocument{ID: id, View: view})
+}
+
+func (s mongoStore) Get(ctx context.Context, tenant, name string) (View, error) {
+	var doc viewDocument
+	found, err := s.collection.Get(ctx, viewID(tenant, name), &doc)
+	if err == nil && !found {
+		return View{}, ErrViewNotFound
+	}
+	return doc.View, err
+}
+
+func (s mongoStore) List(ctx context.Context, tenant string) ([]View, error) {
+	filter := bson.M{"tenant": tenant}
+	if tenant == "" {
+		filter["tenant"] = bson.M{"$exists": false}
+	}
+	var docs []viewDocument
+	if err := s.collection.All(ctx, filter, "name", &docs); err != nil {
+		return nil, err
+	}
+	views := make([]View, 0, len(docs))
+	for _, doc := range docs {
+		views = append(views, doc.View)
+	}
+	return views, nil
+}
+
+func (s mongoStore) Delete(ctx context.Context, tenant, name string) error {
+	found, err := s.collection.Delete(ctx, viewID(tenant, name))
+	if err == nil && !found {
+		return ErrViewNotFound
+	}
+	return err
+}
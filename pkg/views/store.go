@@ -0,0 +1,76 @@
+package views
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+// Store persists saved views, those of each tenant apart from the others'.
+type Store interface {
+	// Save creates or replaces the view of view.Tenant of the same name.
+	Save(ctx context.Context, view View) error
+	// Get returns the view name of tenant, or ErrViewNotFound.
+	Get(ctx context.Context, tenant, name string) (View, error)
+	// List returns every view of tenant, ordered by name.
+	List(ctx context.Context, tenant string) ([]View, error)
+	// Delete forgets the view name of tenant, or returns ErrViewNotFound.
+	Delete(ctx context.Context, tenant, name string) error
+}
+
+// viewKey identifies a view in memory.
+type viewKey struct {
+	tenant, name string
+}
+
+type memoryStore struct {
+	mtx   sync.Mutex
+	views map[viewKey]View
+}
+
+// NewMemoryStore returns a Store keeping the views in memory, they don't
+// survive restarts.
+func NewMemoryStore() Store {
+	return &memoryStore{views: make(map[viewKey]View)}
+}
+
+func (s *memoryStore) Save(_ context.Context, view View) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.views[viewKey{view.Tenant, view.Name}] = view
+	return nil
+}
+
+func (s *memoryStore) Get(_ context.Context, tenant, name string) (View, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	view, ok := s.views[viewKey{tenant, name}]
+	if !ok {
+		return View{}, ErrViewNotFound
+	}
+	return view, nil
+}
+
+func (s *memoryStore) List(_ context.Context, tenant string) ([]View, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	views := make([]View, 0, len(s.views))
+	for key, view := range s.views {
+		if key.tenant == tenant {
+			views = append(views, view)
+		}
+	}
+	sort.Slice(views, func(i, j int) bool { return views[i].Name < views[j].Name })
+	return views, nil
+}
+
+func (s *memoryStore) Delete(_ context.Context, tenant, name string) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	key := viewKey{tenant, name}
+	if _, ok := s.views[key]; !ok {
+		return ErrViewNotFound
+	}
+	delete(s.views, key)
+	return nil
+}
@@ -0,0 +1,122 @@
+// Package views defines the named, saved filters users list their todos
+// through, and where they're kept.
+package views
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"ray.vhatt/todo-gokit/pkg/models"
+	"ray.vhatt/todo-gokit/pkg/query"
+)
+
+var (
+	// ErrViewNotFound is returned for a view name that wasn't saved.
+	ErrViewNotFound = errors.New("view not found")
+
+	// ErrInvalidView is returned, wrapped with the reason, when saving a view
+	// that can't be applied.
+	ErrInvalidView = errors.New("invalid view")
+)
+
+// View is a saved filter of a tenant, applied to todos in the order given
+// by Sort.
+type View struct {
+	Name   string `json:"name" bson:"name"`
+	Tenant string `json:"-" bson:"tenant,omitempty"`
+	Filter Filter `json:"filter" bson:"filter"`
+	// Sort is the field todos are ordered by, prefixed with - for a
+	// descending order. Empty keeps the listing order.
+	Sort string `json:"sort,omitempty" bson:"sort,omitempty"`
+}
+
+// Filter selects todos. Its fields are ANDed together, unset ones match
+// every todo.
+type Filter struct {
+	// Done selects the completed or the open todos.
+	Done *bool `json:"done,omitempty" bson:"done,omitempty"`
+	// Task selects the todos containing it, ignoring case.
+	Task string `json:"task,omitempty" bson:"task,omitempty"`
+	// Scheduled selects the todos with or without a schedule.
+	Scheduled *bool `json:"scheduled,omitempty" bson:"scheduled,omitempty"`
+	// Query selects the todos passing a filter expression, see the query
+	// package.
+	Query string `json:"query,omitempty" bson:"query,omitempty"`
+}
+
+var validName = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,63}$`)
+
+// less orders todos by each field a view can be sorted by. Todos without a
+// schedule sort last.
+var less = map[string]func(a, b models.ToDoItem) bool{
+	"task": func(a, b models.ToDoItem) bool { return a.Task < b.Task },
+	"scheduleAt": func(a, b models.ToDoItem) bool {
+		if a.ScheduleAt == nil || b.ScheduleAt == nil {
+			return b.ScheduleAt == nil && a.ScheduleAt != nil
+		}
+		return a.ScheduleAt.Before(*b.ScheduleAt)
+	},
+}
+
+// Validate returns ErrInvalidView unless v can be saved.
+func (v View) Validate() error {
+	if !validName.MatchString(v.Name) {
+		return fmt.Errorf("%w: name must be 1 to 64 lower case letters, digits or dashes", ErrInvalidView)
+	}
+	if v.Sort != "" {
+		if _, ok := less[strings.TrimPrefix(v.Sort, "-")]; !ok {
+			return fmt.Errorf("%w: unknown sort field %q", ErrInvalidView, v.Sort)
+		}
+	}
+	if v.Filter.Query != "" {
+		if _, err := query.Parse(v.Filter.Query); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidView, err)
+		}
+	}
+	return nil
+}
+
+// match reports whether todo passes the filter, q being its parsed query.
+func (f Filter) match(todo models.ToDoItem, q query.Expr) bool {
+	if q != nil && !q.Match(todo) {
+		return false
+	}
+	if f.Done != nil && *f.Done != todo.Status {
+		return false
+	}
+	if f.Task != "" && !strings.Contains(strings.ToLower(todo.Task), strings.ToLower(f.Task)) {
+		return false
+	}
+	if f.Scheduled != nil && *f.Scheduled != (todo.ScheduleAt != nil) {
+		return false
+	}
+	return true
+}
+
+// Apply returns the todos passing the filter of v, sorted. v must be valid.
+func (v View) Apply(todos []models.ToDoItem) []models.ToDoItem {
+	var q query.Expr
+	if v.Filter.Query != "" {
+		q, _ = query.Parse(v.Filter.Query)
+	}
+	var results []models.ToDoItem
+	for _, t := range todos {
+		if v.Filter.match(t, q) {
+			results = append(results, t)
+		}
+	}
+	if v.Sort != "" {
+		field := strings.TrimPrefix(v.Sort, "-")
+		desc := field != v.Sort
+		sort.SliceStable(results, func(i, j int) bool {
+			if desc {
+				return less[field](results[j], results[i])
+			}
+			return less[field](results[i], results[j])
+		})
+	}
+	return results
+}
@@ -0,0 +1,133 @@
+package views
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"ray.vhatt/todo-gokit/pkg/models"
+	"ray.vhatt/todo-gokit/pkg/store/mongotest"
+)
+
+func TestValidate(t *testing.T) {
+	for _, v := range []View{
+		{Name: "work"},
+		{Name: "this-week", Sort: "-scheduleAt"},
+		{Name: "2020", Sort: "task"},
+	} {
+		if err := v.Validate(); err != nil {
+			t.Errorf("%+v: want valid, have %v", v, err)
+		}
+	}
+	for _, v := range []View{
+		{},
+		{Name: "Work"},
+		{Name: "-work"},
+		{Name: "work/home"},
+		{Name: "work", Sort: "priority"},
+		{Name: "work", Filter: Filter{Query: "tag IN (work)"}},
+	} {
+		if err := v.Validate(); !errors.Is(err, ErrInvalidView) {
+			t.Errorf("%+v: want %v, have %v", v, ErrInvalidView, err)
+		}
+	}
+}
+
+func TestApply(t *testing.T) {
+	yes, no := true, false
+	now := time.Now()
+	later := now.Add(time.Hour)
+	todos := []models.ToDoItem{
+		{Task: "Water the plants"},
+		{Task: "water the garden", ScheduleAt: &later},
+		{Task: "call mum", Status: true},
+		{Task: "plant seeds", ScheduleAt: &now},
+	}
+	tasks := func(todos []models.ToDoItem) (tasks []string) {
+		for _, t := range todos {
+			tasks = append(tasks, t.Task)
+		}
+		return tasks
+	}
+
+	for _, test := range []struct {
+		view View
+		want []string
+	}{
+		{View{}, []string{"Water the plants", "water the garden", "call mum", "plant seeds"}},
+		{View{Filter: Filter{Done: &no}}, []string{"Water the plants", "water the garden", "plant seeds"}},
+		{View{Filter: Filter{Done: &yes}}, []string{"call mum"}},
+		{View{Filter: Filter{Task: "WATER"}, Sort: "-task"}, []string{"water the garden", "Water the plants"}},
+		{View{Filter: Filter{Task: "plant", Scheduled: &no}}, []string{"Water the plants"}},
+		{View{Sort: "scheduleAt"}, []string{"plant seeds", "water the garden", "Water the plants", "call mum"}},
+		{View{Filter: Filter{Done: &no, Query: "task~water OR task~seeds"}, Sort: "task"}, []string{"Water the plants", "plant seeds", "water the garden"}},
+	} {
+		if have := tasks(test.view.Apply(todos)); fmt.Sprint(test.want) != fmt.Sprint(have) {
+			t.Errorf("%+v: want %v, have %v", test.view, test.want, have)
+		}
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, NewMemoryStore())
+}
+
+func TestMongoStore(t *testing.T) {
+	s, err := NewMongoStore(mongotest.DB(t), "views")
+	if err != nil {
+		t.Fatal(err)
+	}
+	testStore(t, s)
+}
+
+// testStore runs the checks every Store passes.
+func testStore(t *testing.T, s Store) {
+	ctx := context.Background()
+	for _, name := range []string{"work", "home"} {
+		if err := s.Save(ctx, View{Name: name}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.Save(ctx, View{Name: "work", Tenant: "acme", Sort: "task"}); err != nil {
+		t.Fatal(err)
+	}
+	views, _ := s.List(ctx, "")
+	if want, have := "[home work]", fmt.Sprint(names(views)); want != have {
+		t.Errorf("want %s, have %s", want, have)
+	}
+	views, _ = s.List(ctx, "acme")
+	if want, have := "[work]", fmt.Sprint(names(views)); want != have {
+		t.Errorf("acme: want %s, have %s", want, have)
+	}
+	if view, err := s.Get(ctx, "acme", "work"); err != nil || view.Sort != "task" || view.Tenant != "acme" {
+		t.Errorf("acme: want its own view, have %+v, %v", view, err)
+	}
+	if _, err := s.Get(ctx, "globex", "work"); err != ErrViewNotFound {
+		t.Errorf("globex: want %v, have %v", ErrViewNotFound, err)
+	}
+	if err := s.Delete(ctx, "globex", "home"); err != ErrViewNotFound {
+		t.Errorf("globex: want %v, have %v", ErrViewNotFound, err)
+	}
+
+	if err := s.Delete(ctx, "", "work"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Get(ctx, "", "work"); err != ErrViewNotFound {
+		t.Errorf("want %v, have %v", ErrViewNotFound, err)
+	}
+	if err := s.Delete(ctx, "", "work"); err != ErrViewNotFound {
+		t.Errorf("want %v, have %v", ErrViewNotFound, err)
+	}
+	if _, err := s.Get(ctx, "acme", "work"); err != nil {
+		t.Errorf("acme: want its view kept, have %v", err)
+	}
+}
+
+func names(views []View) (names []string) {
+	for _, v := range views {
+		names = append(names, v.Name)
+	}
+	return names
+}
@@ -0,0 +1,102 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// errInternalAddress is returned for a callback to an address internal to
+// the network of the service.
+var errInternalAddress = errors.New("callbacks to internal addresses are refused")
+
+// internalNetworks are the private blocks of addresses, besides those the
+// net.IP methods tell: loopback, link-local, multicast and unspecified.
+var internalNetworks = func() []*net.IPNet {
+	var networks []*net.IPNet
+	for _, cidr := range []string{"10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16", "100.64.0.0/10", "fc00::/7"} {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks = append(networks, network)
+	}
+	return networks
+}()
+
+// internal reports whether ip is internal to the network of the service,
+// unless it's in one of the allowed networks. Subscribers mustn't have the
+// service call such addresses, like those of its neighbours or of the
+// metadata of the cloud it runs in.
+func internal(ip net.IP, allowed []*net.IPNet) bool {
+	for _, network := range allowed {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+	if ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsMulticast() || ip.IsUnspecified() {
+		return true
+	}
+	for _, network := range internalNetworks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckAddress returns ErrInvalidSubscription unless every address the host
+// of the URL of s resolves to may be called back: those of the loopback,
+// private, link-local and unspecified blocks may not, unless they're in one
+// of the allowed networks. The addresses are checked again when calling
+// back, as the host may resolve to others by then.
+func (s Subscription) CheckAddress(ctx context.Context, allowed []*net.IPNet) error {
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSubscription, err)
+	}
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, u.Hostname())
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSubscription, err)
+	}
+	for _, addr := range addrs {
+		if internal(addr.IP, allowed) {
+			return fmt.Errorf("%w: %s resolves to %s: %v", ErrInvalidSubscription, u.Hostname(), addr.IP, errInternalAddress)
+		}
+	}
+	return nil
+}
+
+// newClient returns the client making the callbacks, timing them out after
+// timeout. It refuses to connect to the internal addresses but those of the
+// allowed networks, whatever the host of a callback resolves to then, and
+// doesn't go through proxies, which would connect in its place.
+func newClient(timeout time.Duration, allowed []*net.IPNet) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(_, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || internal(ip, allowed) {
+				return fmt.Errorf("%w: %s", errInternalAddress, host)
+			}
+			return nil
+		},
+	}
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: timeout,
+			MaxIdleConns:        maxInFlight,
+			IdleConnTimeout:     90 * time.Second,
+		},
+	}
+}
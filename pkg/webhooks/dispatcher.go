@@ -0,0 +1,184 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/log"
+)
+
+// The headers of a callback, besides its JSON Content-Type.
+const (
+	EventHeader     = "X-Webhook-Event"
+	DeliveryHeader  = "X-Webhook-Delivery"
+	SignatureHeader = "X-Webhook-Signature"
+)
+
+const (
+	// maxAttempts is the number of times a callback is tried before it's
+	// given up.
+	maxAttempts = 5
+	// maxInFlight bounds the callbacks being delivered at once. Past it, new
+	// callbacks are dropped rather than piling up.
+	maxInFlight = 64
+)
+
+// ErrClosed is returned by Fire once the Dispatcher is closed.
+var ErrClosed = errors.New("webhooks dispatcher closed")
+
+// Dispatcher calls back the subscriptions of a Store with the events fired.
+// Callbacks are delivered in the background: a failing one is retried with
+// an exponential backoff, then logged and given up. Callbacks answered with
+// a 4xx status other than 429, and those to internal addresses, aren't
+// retried. Close the Dispatcher to deliver the callbacks in flight before
+// the process exits.
+type Dispatcher struct {
+	store    Store
+	client   *http.Client
+	logger   log.Logger
+	backoff  time.Duration
+	inFlight chan struct{}
+	wg       sync.WaitGroup
+
+	// mtx guards closed, so that no callback is added once Close waits
+	// for those in flight.
+	mtx    sync.RWMutex
+	closed bool
+	// ctx is that of the callbacks, cancelled by Close when they outlast
+	// it.
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// NewDispatcher returns a Dispatcher calling back the subscriptions of
+// store, timing callbacks out after 5 seconds. It refuses to connect to the
+// internal addresses, see Subscription.CheckAddress, but those of the
+// allowed networks.
+func NewDispatcher(store Store, allowed []*net.IPNet, logger log.Logger) *Dispatcher {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Dispatcher{
+		store:    store,
+		client:   newClient(5*time.Second, allowed),
+		logger:   logger,
+		backoff:  time.Second,
+		inFlight: make(chan struct{}, maxInFlight),
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+}
+
+// Fire calls back the subscriptions wanting e, without waiting for the
+// callbacks. The error is that of listing the subscriptions, or ErrClosed.
+func (d *Dispatcher) Fire(ctx context.Context, e Event) error {
+	d.mtx.RLock()
+	defer d.mtx.RUnlock()
+	if d.closed {
+		return ErrClosed
+	}
+	subs, err := d.store.List(ctx)
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	for _, sub := range subs {
+		if !sub.wants(e) {
+			continue
+		}
+		select {
+		case d.inFlight <- struct{}{}:
+		default:
+			d.logger.Log("webhook", sub.ID, "event", e.ID, "err", "too many callbacks in flight, dropped")
+			continue
+		}
+		d.wg.Add(1)
+		go func(sub Subscription) {
+			defer func() { <-d.inFlight; d.wg.Done() }()
+			d.deliver(d.ctx, sub, e, body)
+		}(sub)
+	}
+	return nil
+}
+
+// Close stops firing events, and waits for the callbacks in flight until
+// ctx is done. Those still in flight then are cancelled, and ctx's error is
+// returned.
+func (d *Dispatcher) Close(ctx context.Context) error {
+	d.mtx.Lock()
+	d.closed = true
+	d.mtx.Unlock()
+	defer d.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		d.cancel()
+		<-done
+		return ctx.Err()
+	}
+}
+
+// deliver calls sub back with body, the encoded e, until it succeeds, it's
+// given up or ctx is done.
+func (d *Dispatcher) deliver(ctx context.Context, sub Subscription, e Event, body []byte) {
+	wait := d.backoff
+	for attempt := 1; ; attempt++ {
+		retry, err := d.post(ctx, sub, e, body)
+		if err == nil {
+			return
+		}
+		if !retry || attempt == maxAttempts || ctx.Err() != nil {
+			d.logger.Log("webhook", sub.ID, "event", e.ID, "attempts", attempt, "err", err)
+			return
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			d.logger.Log("webhook", sub.ID, "event", e.ID, "attempts", attempt, "err", err)
+			return
+		}
+		wait *= 2
+	}
+}
+
+// post makes one callback, and reports whether it's worth retrying when it
+// fails.
+func (d *Dispatcher) post(ctx context.Context, sub Subscription, e Event, body []byte) (retry bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", sub.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, e.Type)
+	req.Header.Set(DeliveryHeader, e.ID)
+	req.Header.Set(SignatureHeader, Sign(sub.Secret, body))
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return !errors.Is(err, errInternalAddress), err
+	}
+	resp.Body.Close()
+	switch {
+	case resp.StatusCode/100 == 2:
+		return false, nil
+	case resp.StatusCode/100 == 4 && resp.StatusCode != http.StatusTooManyRequests:
+		return false, fmt.Errorf("callback: %s", resp.Status)
+	}
+	return true, fmt.Errorf("callback: %s", resp.Status)
+}
@@ -0,0 +1,47 @@
+package webhooks
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"ray.vhatt/todo-gokit/pkg/store"
+)
+
+type mongoStore struct {
+	collection store.Collection
+}
+
+// NewMongoStore returns a Store keeping the subscriptions in the collection
+// collectionName of db, so that they survive restarts and are shared by the
+// replicas.
+func NewMongoStore(db *store.MongoDB, collectionName string) (Store, error) {
+	return mongoStore{collection: db.Collection(collectionName)}, nil
+}
+
+func (s mongoStore) Save(ctx context.Context, sub Subscription) error {
+	return s.collection.Put(ctx, sub.ID, sub)
+}
+
+func (s mongoStore) List(ctx context.Context) ([]Subscription, error) {
+	subs := []Subscription{}
+	err := s.collection.All(ctx, bson.M{}, "", &subs)
+	return subs, err
+}
+
+func (s mongoStore) Delete(ctx context.Context, tenant, id string) error {
+	filter := bson.M{"_id": id}
+	if tenant != "" {
+		filter["tenant"] = tenant
+	} else {
+		filter["tenant"] = bson.M{"$exists": false}
+	}
+	res, err := s.collection.DeleteOne(ctx, filter)
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return ErrSubscriptionNotFound
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package webhooks
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+// Store persists subscriptions.
+type Store interface {
+	// Save creates or replaces the subscription of the same ID.
+	Save(ctx context.Context, s Subscription) error
+	// List returns every subscription, ordered by ID.
+	List(ctx context.Context) ([]Subscription, error)
+	// Delete forgets the subscription id of tenant, or returns
+	// ErrSubscriptionNotFound.
+	Delete(ctx context.Context, tenant, id string) error
+}
+
+type memoryStore struct {
+	mtx  sync.Mutex
+	subs map[string]Subscription
+}
+
+// NewMemoryStore returns a Store keeping the subscriptions in memory, they
+// don't survive restarts.
+func NewMemoryStore() Store {
+	return &memoryStore{subs: make(map[string]Subscription)}
+}
+
+func (s *memoryStore) Save(_ context.Context, sub Subscription) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.subs[sub.ID] = sub
+	return nil
+}
+
+func (s *memoryStore) List(context.Context) ([]Subscription, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	subs := make([]Subscription, 0, len(s.subs))
+	for _, sub := range s.subs {
+		subs = append(subs, sub)
+	}
+	sort.Slice(subs, func(i, j int) bool { return subs[i].ID < subs[j].ID })
+	return subs, nil
+}
+
+func (s *memoryStore) Delete(_ context.Context, tenant, id string) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if sub, ok := s.subs[id]; !ok || sub.Tenant != tenant {
+		return ErrSubscriptionNotFound
+	}
+	delete(s.subs, id)
+	return nil
+}
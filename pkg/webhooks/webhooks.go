@@ -0,0 +1,116 @@
+// Package webhooks calls back the URLs external systems subscribe, when
+// todos change. Every callback is signed with the secret of its
+// subscription, so the receiver can tell it came from the service.
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"ray.vhatt/todo-gokit/pkg/models"
+)
+
+// The types of the events a subscription can be limited to.
+const (
+	ToDoCreated   = "todo.created"
+	ToDoCompleted = "todo.completed"
+	ToDoDeleted   = "todo.deleted"
+	ToDoUnblocked = "todo.unblocked"
+)
+
+var (
+	// ErrSubscriptionNotFound is returned for a subscription ID that wasn't
+	// created, or was deleted.
+	ErrSubscriptionNotFound = errors.New("webhook not found")
+
+	// ErrInvalidSubscription is returned, wrapped with the reason, when
+	// creating a subscription that can't be called back.
+	ErrInvalidSubscription = errors.New("invalid webhook")
+)
+
+// Subscription is a URL called back with the events of a tenant.
+type Subscription struct {
+	ID  string `json:"id" bson:"_id"`
+	URL string `json:"url" bson:"url"`
+	// Events are the types of the events called back, empty means all of
+	// them.
+	Events []string `json:"events,omitempty" bson:"events,omitempty"`
+	// Secret signs the callbacks. It's generated unless given, and only
+	// shown when the subscription is created.
+	Secret  string    `json:"secret,omitempty" bson:"secret"`
+	Tenant  string    `json:"tenant,omitempty" bson:"tenant,omitempty"`
+	Created time.Time `json:"created" bson:"created"`
+}
+
+// Validate returns ErrInvalidSubscription unless s can be called back.
+func (s Subscription) Validate() error {
+	u, err := url.Parse(s.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: url must be an absolute http or https URL", ErrInvalidSubscription)
+	}
+	for _, e := range s.Events {
+		switch e {
+		case ToDoCreated, ToDoCompleted, ToDoDeleted, ToDoUnblocked:
+		default:
+			return fmt.Errorf("%w: unknown event %q", ErrInvalidSubscription, e)
+		}
+	}
+	return nil
+}
+
+// wants reports whether s is called back with e.
+func (s Subscription) wants(e Event) bool {
+	if s.Tenant != e.Tenant {
+		return false
+	}
+	if len(s.Events) == 0 {
+		return true
+	}
+	for _, t := range s.Events {
+		if t == e.Type {
+			return true
+		}
+	}
+	return false
+}
+
+// Event is a change of a todo, the body of a callback.
+type Event struct {
+	ID     string        `json:"id"`
+	Type   string        `json:"type"`
+	TaskID models.TaskID `json:"taskID"`
+	Tenant string        `json:"tenant,omitempty"`
+	At     time.Time     `json:"timestamp"`
+}
+
+// NewID returns a random identifier, for subscriptions and events.
+func NewID() string {
+	return randomHex(12)
+}
+
+// NewSecret returns a random secret to sign callbacks with.
+func NewSecret() string {
+	return randomHex(32)
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+// Sign returns the signature of a callback body with secret, as sent in its
+// SignatureHeader: sha256= then the hex HMAC-SHA256 of the body.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
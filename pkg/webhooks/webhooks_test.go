@@ -0,0 +1,238 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+
+	"ray.vhatt/todo-gokit/pkg/store/mongotest"
+)
+
+func TestDispatcher(t *testing.T) {
+	var (
+		mtx      sync.Mutex
+		received = map[string][]Event{}
+		attempts = map[string]int{}
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mtx.Lock()
+		defer mtx.Unlock()
+		attempts[r.URL.Path]++
+		body, _ := ioutil.ReadAll(r.Body)
+		if Sign("s3cret", body) != r.Header.Get(SignatureHeader) {
+			http.Error(w, "bad signature", http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path == "/flaky" && attempts[r.URL.Path] < 3 {
+			http.Error(w, "try again", http.StatusServiceUnavailable)
+			return
+		}
+		var e Event
+		json.Unmarshal(body, &e)
+		if r.Header.Get(EventHeader) != e.Type || r.Header.Get(DeliveryHeader) != e.ID {
+			http.Error(w, "bad headers", http.StatusBadRequest)
+			return
+		}
+		received[r.URL.Path] = append(received[r.URL.Path], e)
+	}))
+	defer srv.Close()
+
+	store := NewMemoryStore()
+	ctx := context.Background()
+	for _, sub := range []Subscription{
+		{ID: "a", URL: srv.URL + "/all", Secret: "s3cret"},
+		{ID: "b", URL: srv.URL + "/flaky", Secret: "s3cret", Events: []string{ToDoCompleted}},
+		{ID: "c", URL: srv.URL + "/other", Secret: "s3cret", Tenant: "acme"},
+		{ID: "d", URL: srv.URL + "/forged", Secret: "wrong"},
+	} {
+		if err := store.Save(ctx, sub); err != nil {
+			t.Fatal(err)
+		}
+	}
+	d := NewDispatcher(store, loopback(t), log.NewNopLogger())
+	d.backoff = time.Millisecond
+	for _, e := range []Event{{ID: "1", Type: ToDoCreated, TaskID: "000000000000000000000001"}, {ID: "2", Type: ToDoCompleted, TaskID: "000000000000000000000001"}} {
+		if err := d.Fire(ctx, e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	d.wg.Wait()
+
+	if events := received["/all"]; len(events) != 2 {
+		t.Errorf("want every event called back, have %+v", events)
+	}
+	if events := received["/flaky"]; len(events) != 1 || events[0].ID != "2" || attempts["/flaky"] != 3 {
+		t.Errorf("want the completion called back once retried, have %+v in %d attempts", events, attempts["/flaky"])
+	}
+	if attempts["/other"] != 0 {
+		t.Errorf("want no event called back to another tenant, have %d", attempts["/other"])
+	}
+	if attempts["/forged"] != 2 {
+		t.Errorf("want a rejected callback given up, have %d attempts", attempts["/forged"])
+	}
+}
+
+func TestDispatcherInternalAddress(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		atomic.AddInt32(&calls, 1)
+	}))
+	defer srv.Close()
+
+	// Saved as if the host had resolved to a public address then.
+	store := NewMemoryStore()
+	ctx := context.Background()
+	if err := store.Save(ctx, Subscription{ID: "a", URL: srv.URL}); err != nil {
+		t.Fatal(err)
+	}
+	d := NewDispatcher(store, nil, log.NewNopLogger())
+	d.backoff = time.Millisecond
+	if err := d.Fire(ctx, Event{ID: "1", Type: ToDoCreated}); err != nil {
+		t.Fatal(err)
+	}
+	d.wg.Wait()
+	if n := atomic.LoadInt32(&calls); n != 0 {
+		t.Errorf("want no callback to a loopback address, have %d", n)
+	}
+}
+
+func TestDispatcherClose(t *testing.T) {
+	var delivered int32
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/stuck" {
+			select {
+			case <-release:
+			case <-r.Context().Done():
+			}
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+		atomic.AddInt32(&delivered, 1)
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	store := NewMemoryStore()
+	ctx := context.Background()
+	if err := store.Save(ctx, Subscription{ID: "a", URL: srv.URL + "/slow"}); err != nil {
+		t.Fatal(err)
+	}
+	d := NewDispatcher(store, loopback(t), log.NewNopLogger())
+	if err := d.Fire(ctx, Event{ID: "1", Type: ToDoCreated}); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt32(&delivered); n != 1 {
+		t.Errorf("want the callback in flight delivered before Close returns, have %d", n)
+	}
+	if err := d.Fire(ctx, Event{ID: "2", Type: ToDoCreated}); err != ErrClosed {
+		t.Errorf("want %v, have %v", ErrClosed, err)
+	}
+
+	// The callbacks outlasting Close are cancelled.
+	if err := store.Save(ctx, Subscription{ID: "b", URL: srv.URL + "/stuck"}); err != nil {
+		t.Fatal(err)
+	}
+	d = NewDispatcher(store, loopback(t), log.NewNopLogger())
+	d.backoff = time.Hour
+	if err := d.Fire(ctx, Event{ID: "3", Type: ToDoCreated}); err != nil {
+		t.Fatal(err)
+	}
+	closeCtx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	begin := time.Now()
+	if err := d.Close(closeCtx); err != context.DeadlineExceeded {
+		t.Errorf("want %v, have %v", context.DeadlineExceeded, err)
+	}
+	if took := time.Since(begin); took > time.Second {
+		t.Errorf("want the callbacks cancelled once ctx is done, have Close take %s", took)
+	}
+}
+
+func TestCheckAddress(t *testing.T) {
+	for _, test := range []struct {
+		url     string
+		allowed []*net.IPNet
+		ok      bool
+	}{
+		{"http://127.0.0.1:8080/hook", nil, false},
+		{"http://localhost/hook", nil, false},
+		{"http://[::1]/hook", nil, false},
+		{"http://10.1.2.3/hook", nil, false},
+		{"http://169.254.169.254/latest/meta-data", nil, false},
+		{"http://0.0.0.0/hook", nil, false},
+		{"https://93.184.216.34/hook", nil, true},
+		{"http://127.0.0.1:8080/hook", loopback(t), true},
+	} {
+		err := Subscription{URL: test.url}.CheckAddress(context.Background(), test.allowed)
+		if (err == nil) != test.ok || (err != nil && !errors.Is(err, ErrInvalidSubscription)) {
+			t.Errorf("%s: want ok %v, have %v", test.url, test.ok, err)
+		}
+	}
+}
+
+// loopback returns the loopback network, allowed to the tests' servers.
+func loopback(t *testing.T) []*net.IPNet {
+	_, network, err := net.ParseCIDR("127.0.0.0/8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return []*net.IPNet{network}
+}
+
+func TestSubscription(t *testing.T) {
+	for _, test := range []struct {
+		sub Subscription
+		ok  bool
+	}{
+		{Subscription{URL: "https://example.com/hook"}, true},
+		{Subscription{URL: "http://example.com/hook", Events: []string{ToDoCreated, ToDoDeleted}}, true},
+		{Subscription{URL: "/hook"}, false},
+		{Subscription{URL: "ftp://example.com/hook"}, false},
+		{Subscription{URL: "https://example.com/hook", Events: []string{"todo.renamed"}}, false},
+	} {
+		if err := test.sub.Validate(); (err == nil) != test.ok {
+			t.Errorf("%+v: want ok %v, have %v", test.sub, test.ok, err)
+		}
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, NewMemoryStore())
+}
+
+func TestMongoStore(t *testing.T) {
+	s, err := NewMongoStore(mongotest.DB(t), "webhooks")
+	if err != nil {
+		t.Fatal(err)
+	}
+	testStore(t, s)
+}
+
+// testStore runs the checks every Store passes.
+func testStore(t *testing.T, s Store) {
+	ctx := context.Background()
+	s.Save(ctx, Subscription{ID: "a", Tenant: "acme"})
+	if subs, err := s.List(ctx); err != nil || len(subs) != 1 || subs[0].Tenant != "acme" {
+		t.Errorf("want the webhook saved, have %+v, %v", subs, err)
+	}
+	if err := s.Delete(ctx, "globex", "a"); err != ErrSubscriptionNotFound {
+		t.Errorf("want %v deleting another tenant's webhook, have %v", ErrSubscriptionNotFound, err)
+	}
+	if err := s.Delete(ctx, "acme", "a"); err != nil {
+		t.Error(err)
+	}
+}
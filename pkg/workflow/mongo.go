@@ -0,0 +1,32 @@
+package workflow
+
+import (
+	"context"
+
+	"ray.vhatt/todo-gokit/pkg/store"
+)
+
+type mongoStore struct {
+	collection store.Collection
+}
+
+// NewMongoStore returns a Store keeping the workflows in the collection
+// collectionName of db, one document per tenant, so that they survive
+// restarts.
+func NewMongoStore(db *store.MongoDB, collectionName string) (Store, error) {
+	return mongoStore{collection: db.Collection(collectionName)}, nil
+}
+
+func (s mongoStore) Save(ctx context.Context, w Workflow) error {
+	return s.collection.Put(ctx, w.Tenant, w)
+}
+
+func (s mongoStore) Get(ctx context.Context, tenant string) (Workflow, error) {
+	var w Workflow
+	found, err := s.collection.Get(ctx, tenant, &w)
+	if err == nil && !found {
+		w = Default
+		w.Tenant = tenant
+	}
+	return w, err
+}
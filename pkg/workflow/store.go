@@ -0,0 +1,43 @@
+package workflow
+
+import (
+	"context"
+	"sync"
+)
+
+// Store persists the workflows of the tenants.
+type Store interface {
+	// Save creates or replaces the workflow of w.Tenant.
+	Save(ctx context.Context, w Workflow) error
+	// Get returns the workflow of tenant, Default if none was saved.
+	Get(ctx context.Context, tenant string) (Workflow, error)
+}
+
+type memoryStore struct {
+	mtx       sync.Mutex
+	workflows map[string]Workflow
+}
+
+// NewMemoryStore returns a Store keeping the workflows in memory, they
+// don't survive restarts.
+func NewMemoryStore() Store {
+	return &memoryStore{workflows: make(map[string]Workflow)}
+}
+
+func (s *memoryStore) Save(_ context.Context, w Workflow) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.workflows[w.Tenant] = w
+	return nil
+}
+
+func (s *memoryStore) Get(_ context.Context, tenant string) (Workflow, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	w, ok := s.workflows[tenant]
+	if !ok {
+		w = Default
+		w.Tenant = tenant
+	}
+	return w, nil
+}
@@ -0,0 +1,164 @@
+// Package workflow defines the states the todos of a tenant go through, and
+// the transitions allowed between them, and where the workflows are kept.
+package workflow
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"ray.vhatt/todo-gokit/pkg/models"
+)
+
+var (
+	// ErrInvalidWorkflow is returned, wrapped with the reason, when saving
+	// a workflow that can't be followed.
+	ErrInvalidWorkflow = errors.New("invalid workflow")
+
+	// ErrUnknownState is returned, wrapped with the state, for a state the
+	// workflow doesn't define.
+	ErrUnknownState = errors.New("unknown state")
+
+	// ErrTransitionNotAllowed is returned, wrapped with the transition, for
+	// a move between states the workflow doesn't allow.
+	ErrTransitionNotAllowed = errors.New("transition not allowed")
+)
+
+// MaxStates is the largest number of states a workflow defines.
+const MaxStates = 20
+
+// State is a state of the todos. The todos in a Done state are done, their
+// Status is true.
+type State struct {
+	Name string `json:"name" bson:"name"`
+	Done bool   `json:"done,omitempty" bson:"done,omitempty"`
+}
+
+// Transition is an allowed move of a todo from a state to another.
+type Transition struct {
+	From string `json:"from" bson:"from"`
+	To   string `json:"to" bson:"to"`
+}
+
+// Workflow is the state machine of the todos of a tenant. The first state
+// is where the todos start, and where they go back to when reopened; the
+// first Done state is where they go when completed. Without Transitions,
+// every move is allowed.
+type Workflow struct {
+	Tenant      string       `json:"-" bson:"_id"`
+	States      []State      `json:"states" bson:"states"`
+	Transitions []Transition `json:"transitions,omitempty" bson:"transitions,omitempty"`
+	Updated     time.Time    `json:"updated,omitempty" bson:"updated"`
+}
+
+// Default is the workflow of the tenants that didn't save theirs. It
+// allows every move.
+var Default = Workflow{States: []State{
+	{Name: "open"},
+	{Name: "in-progress"},
+	{Name: "blocked"},
+	{Name: "done", Done: true},
+}}
+
+var validName = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,31}$`)
+
+// Validate returns ErrInvalidWorkflow unless w can be saved.
+func (w Workflow) Validate() error {
+	if len(w.States) > MaxStates {
+		return fmt.Errorf("%w: %d states, the limit is %d", ErrInvalidWorkflow, len(w.States), MaxStates)
+	}
+	if len(w.States) == 0 || w.States[0].Done {
+		return fmt.Errorf("%w: the first state must be pending", ErrInvalidWorkflow)
+	}
+	seen := make(map[string]bool, len(w.States))
+	for _, s := range w.States {
+		if !validName.MatchString(s.Name) {
+			return fmt.Errorf("%w: state %q must be 1 to 32 lower case letters, digits or dashes", ErrInvalidWorkflow, s.Name)
+		}
+		if seen[s.Name] {
+			return fmt.Errorf("%w: state %q defined twice", ErrInvalidWorkflow, s.Name)
+		}
+		seen[s.Name] = true
+	}
+	if w.DoneState() == "" {
+		return fmt.Errorf("%w: no state is done", ErrInvalidWorkflow)
+	}
+	for _, t := range w.Transitions {
+		if !seen[t.From] || !seen[t.To] {
+			return fmt.Errorf("%w: transition %s → %s between unknown states", ErrInvalidWorkflow, t.From, t.To)
+		}
+		if t.From == t.To {
+			return fmt.Errorf("%w: transition %s → %s goes nowhere", ErrInvalidWorkflow, t.From, t.To)
+		}
+	}
+	return nil
+}
+
+// Initial returns the state the todos start in.
+func (w Workflow) Initial() string {
+	return w.States[0].Name
+}
+
+// DoneState returns the state the todos go to when completed, or empty if
+// none is done.
+func (w Workflow) DoneState() string {
+	for _, s := range w.States {
+		if s.Done {
+			return s.Name
+		}
+	}
+	return ""
+}
+
+// state returns the state name.
+func (w Workflow) state(name string) (State, bool) {
+	for _, s := range w.States {
+		if s.Name == name {
+			return s, true
+		}
+	}
+	return State{}, false
+}
+
+// Done reports whether the todos in state are done, or returns
+// ErrUnknownState.
+func (w Workflow) Done(state string) (bool, error) {
+	s, ok := w.state(state)
+	if !ok {
+		return false, fmt.Errorf("%w: %q", ErrUnknownState, state)
+	}
+	return s.Done, nil
+}
+
+// StateOf returns the state of todo. Stores keep no state for the todos
+// that were never moved, or whose status was set since, nor can they tell
+// the states the workflow dropped: those todos are in the initial state
+// while pending, the done state once done.
+func (w Workflow) StateOf(todo models.ToDoItem) string {
+	if s, ok := w.state(todo.State); ok && s.Done == todo.Status {
+		return s.Name
+	}
+	if todo.Status {
+		return w.DoneState()
+	}
+	return w.Initial()
+}
+
+// Check returns ErrTransitionNotAllowed unless a todo may move from the
+// state from to the state to, or ErrUnknownState for an unknown to. Staying
+// put is always allowed.
+func (w Workflow) Check(from, to string) error {
+	if _, err := w.Done(to); err != nil {
+		return err
+	}
+	if from == to || len(w.Transitions) == 0 {
+		return nil
+	}
+	for _, t := range w.Transitions {
+		if t.From == from && t.To == to {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s → %s", ErrTransitionNotAllowed, from, to)
+}
@@ -0,0 +1,115 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"ray.vhatt/todo-gokit/pkg/models"
+	"ray.vhatt/todo-gokit/pkg/store/mongotest"
+)
+
+// review is a workflow where the todos must be reviewed before done.
+var review = Workflow{
+	States: []State{{Name: "open"}, {Name: "review"}, {Name: "done", Done: true}, {Name: "wontfix", Done: true}},
+	Transitions: []Transition{
+		{From: "open", To: "review"},
+		{From: "review", To: "open"},
+		{From: "review", To: "done"},
+		{From: "open", To: "wontfix"},
+		{From: "done", To: "open"},
+		{From: "wontfix", To: "open"},
+	},
+}
+
+func TestValidate(t *testing.T) {
+	for _, w := range []Workflow{Default, review} {
+		if err := w.Validate(); err != nil {
+			t.Errorf("%+v: want valid, have %v", w, err)
+		}
+	}
+	for _, w := range []Workflow{
+		{},
+		{States: []State{{Name: "done", Done: true}, {Name: "open"}}},
+		{States: []State{{Name: "open"}, {Name: "review"}}},
+		{States: []State{{Name: "open"}, {Name: "Done", Done: true}}},
+		{States: []State{{Name: "open"}, {Name: "open", Done: true}}},
+		{States: []State{{Name: "open"}, {Name: "done", Done: true}}, Transitions: []Transition{{From: "open", To: "closed"}}},
+		{States: []State{{Name: "open"}, {Name: "done", Done: true}}, Transitions: []Transition{{From: "open", To: "open"}}},
+	} {
+		if err := w.Validate(); !errors.Is(err, ErrInvalidWorkflow) {
+			t.Errorf("%+v: want %v, have %v", w, ErrInvalidWorkflow, err)
+		}
+	}
+}
+
+func TestStateOf(t *testing.T) {
+	for _, test := range []struct {
+		todo models.ToDoItem
+		want string
+	}{
+		{models.ToDoItem{}, "open"},
+		{models.ToDoItem{Status: true}, "done"},
+		{models.ToDoItem{State: "review"}, "review"},
+		{models.ToDoItem{State: "wontfix", Status: true}, "wontfix"},
+		// The status was set since the todo moved, or the state was dropped.
+		{models.ToDoItem{State: "review", Status: true}, "done"},
+		{models.ToDoItem{State: "triage"}, "open"},
+	} {
+		if have := review.StateOf(test.todo); have != test.want {
+			t.Errorf("%+v: want %s, have %s", test.todo, test.want, have)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	for _, test := range []struct {
+		from, to string
+		want     error
+	}{
+		{"open", "review", nil},
+		{"review", "done", nil},
+		{"open", "open", nil},
+		{"open", "done", ErrTransitionNotAllowed},
+		{"done", "review", ErrTransitionNotAllowed},
+		{"open", "triage", ErrUnknownState},
+	} {
+		if err := review.Check(test.from, test.to); !errors.Is(err, test.want) {
+			t.Errorf("%s → %s: want %v, have %v", test.from, test.to, test.want, err)
+		}
+	}
+	if err := Default.Check("done", "blocked"); err != nil {
+		t.Errorf("want every move allowed without transitions, have %v", err)
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, NewMemoryStore())
+}
+
+func TestMongoStore(t *testing.T) {
+	s, err := NewMongoStore(mongotest.DB(t), "workflows")
+	if err != nil {
+		t.Fatal(err)
+	}
+	testStore(t, s)
+}
+
+// testStore runs the checks every Store passes.
+func testStore(t *testing.T, s Store) {
+	ctx := context.Background()
+	if w, err := s.Get(ctx, "acme"); err != nil || w.Tenant != "acme" || w.Initial() != Default.Initial() {
+		t.Errorf("want the default workflow, have %+v, %v", w, err)
+	}
+	w := review
+	w.Tenant = "acme"
+	if err := s.Save(ctx, w); err != nil {
+		t.Fatal(err)
+	}
+	if w, err := s.Get(ctx, "acme"); err != nil || len(w.Transitions) != len(review.Transitions) {
+		t.Errorf("want the workflow saved, have %+v, %v", w, err)
+	}
+	if w, err := s.Get(ctx, "globex"); err != nil || len(w.Transitions) != 0 {
+		t.Errorf("want the default workflow of another tenant, have %+v, %v", w, err)
+	}
+}